@@ -0,0 +1,5 @@
+// Package quotesv1 holds the gRPC API definition and the code generated
+// from it. Regenerate after editing quotes.proto.
+package quotesv1
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative quotes.proto
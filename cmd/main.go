@@ -1,17 +1,38 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"log/slog"
+	"net"
 	"net/http"
 	"os"
+	"os/signal"
+	quotesv1 "quotes-mini-service/api/quotes/v1"
+	"quotes-mini-service/internal/apikey"
 	"quotes-mini-service/internal/config"
+	"quotes-mini-service/internal/events"
+	"quotes-mini-service/internal/health"
 	"quotes-mini-service/internal/quote"
-	del "quotes-mini-service/internal/quote/handlers/delete"
-	"quotes-mini-service/internal/quote/handlers/get"
-	"quotes-mini-service/internal/quote/handlers/save"
+	"quotes-mini-service/internal/quote/handlers/rotation"
+	"quotes-mini-service/internal/quote/rpc"
+	"quotes-mini-service/internal/router"
+	"quotes-mini-service/internal/stats"
 	"quotes-mini-service/internal/storage"
+	"quotes-mini-service/internal/webhook"
+	"quotes-mini-service/pkg/interceptor"
+	"quotes-mini-service/pkg/jwt"
 	"quotes-mini-service/pkg/middleware"
 	"quotes-mini-service/pkg/sl"
+	"quotes-mini-service/pkg/tracing"
+	"strings"
+	"syscall"
+	"time"
+
+	"google.golang.org/grpc"
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
 )
 
 func main() {
@@ -19,18 +40,91 @@ func main() {
 	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
 	log.Info("initializing server", slog.String("address", conf.Address))
 	log.Debug("logger debug mode enabled")
+	tracer, err := setupTracing(log, conf.Tracing)
+	if err != nil {
+		log.Error("failed to initialize tracing", sl.Err(err))
+		os.Exit(1)
+	}
+	if tracer != nil {
+		tracing.SetDefault(tracer)
+		defer tracer.Shutdown(context.Background())
+		log.Info("tracing enabled", slog.String("exporter", conf.Tracing.Exporter))
+	}
 	db, err := storage.NewStorage(conf.Database)
 	if err != nil {
 		log.Error("failed to initialize storage", sl.Err(err))
+		os.Exit(1)
+	}
+	defer db.Close()
+	keysRepository := apikey.NewKeysRepository(log, db)
+	if len(os.Args) > 1 && os.Args[1] == "apikey" {
+		if err = apikey.Command(context.Background(), keysRepository, os.Args[2:], os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		return
+	}
+	quotesRepository := quote.NewQuotesRepository(db)
+	var queryRepository quote.Store = quotesRepository
+	if conf.Cache.TTL > 0 {
+		queryRepository = quote.NewCachedRepository(queryRepository, conf.Cache.TTL)
+		log.Info("read cache enabled", slog.Duration("ttl", conf.Cache.TTL))
+	}
+	var recorder *stats.Recorder
+	if conf.Stats.FlushInterval > 0 {
+		recorder = stats.NewRecorder(log, stats.NewStatsRepository(db), stats.RecorderOptions{
+			Interval:   conf.Stats.FlushInterval,
+			MaxPending: conf.Stats.MaxPending,
+		})
+		queryRepository = quote.NewTrackedRepository(queryRepository, recorder)
+		log.Info("view tracking enabled", slog.Duration("flush_interval", conf.Stats.FlushInterval))
 	}
-	queryRepository := quote.NewQuotesRepository(db)
 	log.Info("initializing query repository")
-	router := http.NewServeMux()
-	handler := middleware.New(log)(router)
-	router.HandleFunc("POST /quotes", save.New(log, queryRepository))
-	router.HandleFunc("DELETE /quotes/{id}", del.New(log, queryRepository))
-	router.HandleFunc("GET /quotes", get.AllParam(log, queryRepository))
-	router.HandleFunc("GET /quotes/random", get.Random(log, queryRepository))
+	authenticators := []middleware.Authenticator{middleware.APIKey{Store: keysRepository}}
+	if conf.Auth.JWKS != "" {
+		verifier, err := setupJWT(conf.Auth.JWT)
+		if err != nil {
+			log.Error("failed to load jwks", sl.Err(err))
+			os.Exit(1)
+		}
+		authenticators = append(authenticators, middleware.Bearer{Verifier: verifier, AllowAdmin: conf.Auth.AllowAdmin})
+		log.Info("jwt bearer authentication enabled", slog.String("jwks", conf.Auth.JWKS))
+	}
+	trustedProxies, err := middleware.ParsePrefixes(conf.RateLimit.TrustedProxies)
+	if err != nil {
+		log.Error("invalid trusted proxies", sl.Err(err))
+		os.Exit(1)
+	}
+	limiter := middleware.NewRateLimiter(trustedProxies, conf.RateLimit.IdleTTL)
+	defer limiter.Close()
+	broker := events.NewBroker(log, events.NewEventsRepository(db), events.BrokerOptions{
+		PollInterval:   conf.Stream.PollInterval,
+		MaxSubscribers: conf.Stream.MaxSubscribers,
+		History:        conf.Stream.History,
+	})
+	hub := rotation.NewHub(conf.WebSocket.MaxConnections)
+	probe := &health.Probe{}
+	deps := router.Deps{
+		DB:             db,
+		Quotes:         queryRepository,
+		Authenticators: authenticators,
+		Tracer:         tracer,
+		Broker:         broker,
+		Hub:            hub,
+		Limiter:        limiter,
+		Probe:          probe,
+	}
+	if recorder != nil {
+		deps.Views = recorder
+	}
+	handler, err := router.New(log, conf, deps)
+	if err != nil {
+		log.Error("failed to register routes", sl.Err(err))
+		os.Exit(1)
+	}
+	if !conf.Auth.Enabled {
+		log.Warn("authentication disabled, quote routes accept anonymous writes and deletes")
+	}
 	log.Info("starting server", slog.String("address", conf.Address))
 	server := http.Server{
 		Addr:         conf.Address,
@@ -39,8 +133,147 @@ func main() {
 		WriteTimeout: conf.Timeout,
 		IdleTimeout:  conf.IdleTimeout,
 	}
-	if err = server.ListenAndServe(); err != nil {
-		log.Error("failed to start server")
+	server.RegisterOnShutdown(broker.Close)
+	server.RegisterOnShutdown(hub.Close)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go broker.Run(ctx)
+	if recorder != nil {
+		go recorder.Run(ctx)
+	}
+	dispatcherDone := make(chan struct{})
+	if conf.Webhooks.Interval > 0 {
+		dispatcher := webhook.NewDispatcher(log, webhook.NewWebhooksRepository(db), webhook.DispatcherOptions{
+			Interval:    conf.Webhooks.Interval,
+			Timeout:     conf.Webhooks.Timeout,
+			MaxAttempts: conf.Webhooks.MaxAttempts,
+			Backoff:     conf.Webhooks.Backoff,
+			MaxBackoff:  conf.Webhooks.MaxBackoff,
+		})
+		go func() {
+			defer close(dispatcherDone)
+			dispatcher.Run(ctx)
+		}()
+	} else {
+		close(dispatcherDone)
+		log.Warn("webhook delivery disabled")
+	}
+	serverErr := make(chan error, 2)
+	go func() {
+		serverErr <- server.ListenAndServe()
+	}()
+	var grpcServer *grpc.Server
+	var grpcHealth *grpchealth.Server
+	if conf.GRPC.Address != "" {
+		listener, err := net.Listen("tcp", conf.GRPC.Address)
+		if err != nil {
+			log.Error("failed to listen for grpc", sl.Err(err))
+			os.Exit(1)
+		}
+		grpcServer, grpcHealth = setupGRPC(log, conf.Auth.Enabled, authenticators, queryRepository)
+		log.Info("starting grpc server", slog.String("address", conf.GRPC.Address))
+		go func() {
+			serverErr <- grpcServer.Serve(listener)
+		}()
+	}
+	select {
+	case err = <-serverErr:
+		log.Error("failed to start server", sl.Err(err))
+		return
+	case <-ctx.Done():
+	}
+	log.Info("shutting down server")
+	probe.Drain()
+	time.Sleep(conf.ShutdownDelay)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), conf.ShutdownTimeout)
+	defer cancel()
+	if err = server.Shutdown(shutdownCtx); err != nil {
+		log.Error("failed to shut down server gracefully", sl.Err(err))
 	}
+	if grpcServer != nil {
+		stopGRPC(shutdownCtx, grpcServer, grpcHealth)
+	}
+	if err = hub.Wait(shutdownCtx); err != nil {
+		log.Error("websocket connections did not close in time", sl.Err(err))
+	}
+	if recorder != nil {
+		if err = recorder.Flush(shutdownCtx); err != nil {
+			log.Error("failed to write views", sl.Err(err))
+		}
+	}
+	<-dispatcherDone
+	log.Info("server stopped")
+}
+
+func setupTracing(log *slog.Logger, conf config.Tracing) (*tracing.Tracer, error) {
+	var exporter tracing.Exporter
+	switch conf.Exporter {
+	case "stdout":
+		exporter = tracing.NewWriterExporter(os.Stdout)
+	case "file":
+		f, err := os.OpenFile(conf.File, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			return nil, err
+		}
+		exporter = tracing.NewWriterExporter(f)
+	case "otlp":
+		exporter = tracing.NewOTLPExporter(conf.OTLPEndpoint, conf.ServiceName, nil)
+	default:
+		return nil, nil
+	}
+	return tracing.New(log, conf.ServiceName, exporter), nil
+}
+
+func setupGRPC(log *slog.Logger, requireScopes bool, authenticators []middleware.Authenticator, store rpc.Store) (*grpc.Server, *grpchealth.Server) {
+	interceptors := []grpc.UnaryServerInterceptor{
+		interceptor.RequestID,
+		interceptor.Logger(log),
+		interceptor.Recover(log),
+		interceptor.Authenticate(log, authenticators...),
+	}
+	if requireScopes {
+		interceptors = append(interceptors, interceptor.RequireScope(rpc.Scopes))
+	}
+	server := grpc.NewServer(grpc.ChainUnaryInterceptor(interceptors...))
+	quotesv1.RegisterQuoteServiceServer(server, rpc.NewServer(log, store))
+	healthServer := grpchealth.NewServer()
+	healthServer.SetServingStatus(quotesv1.QuoteService_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(server, healthServer)
+	reflection.Register(server)
+	return server, healthServer
+}
+
+// stopGRPC reports NOT_SERVING and waits for in-flight calls until ctx
+// expires, then cuts the remaining connections.
+func stopGRPC(ctx context.Context, server *grpc.Server, healthServer *grpchealth.Server) {
+	healthServer.Shutdown()
+	stopped := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		server.Stop()
+	}
+}
 
+func setupJWT(conf config.JWT) (*jwt.Verifier, error) {
+	verifier := &jwt.Verifier{
+		Issuer:          conf.Issuer,
+		Audience:        conf.Audience,
+		Leeway:          conf.Leeway,
+		AllowMissingExp: !conf.RequireExp,
+	}
+	if strings.HasPrefix(conf.JWKS, "http://") || strings.HasPrefix(conf.JWKS, "https://") {
+		verifier.Keys = jwt.NewRemoteKeySet(conf.JWKS, conf.JWKSTTL)
+		return verifier, nil
+	}
+	keys, err := jwt.LoadFile(conf.JWKS)
+	if err != nil {
+		return nil, err
+	}
+	verifier.Keys = keys
+	return verifier, nil
 }
@@ -0,0 +1,481 @@
+// Package api describes the HTTP routes of the service for the OpenAPI
+// document. Every route registered by internal/router needs an entry here.
+package api
+
+import (
+	"fmt"
+	"maps"
+	"net/http"
+	"quotes-mini-service/internal/apikey/handlers/create"
+	keylist "quotes-mini-service/internal/apikey/handlers/list"
+	auditlist "quotes-mini-service/internal/audit/handlers/list"
+	"quotes-mini-service/internal/collection"
+	"quotes-mini-service/internal/collection/handlers/collections"
+	"quotes-mini-service/internal/graph"
+	"quotes-mini-service/internal/health"
+	"quotes-mini-service/internal/quote"
+	"quotes-mini-service/internal/quote/handlers/get"
+	"quotes-mini-service/internal/quote/handlers/revisions"
+	"quotes-mini-service/internal/quote/handlers/save"
+	"quotes-mini-service/internal/quote/handlers/update"
+	"quotes-mini-service/internal/quote/handlers/vote"
+	"quotes-mini-service/internal/stats"
+	"quotes-mini-service/internal/webhook"
+	"quotes-mini-service/internal/webhook/handlers/deadletters"
+	"quotes-mini-service/internal/webhook/handlers/subscriptions"
+	"quotes-mini-service/pkg/openapi"
+	"quotes-mini-service/pkg/res"
+	"strings"
+)
+
+const (
+	SpecPath = "/openapi.json"
+	UIPath   = "/docs/"
+)
+
+// API versions are path prefixes. V1 keeps the original response shapes,
+// V2 pages collections and answers errors with problem details.
+const (
+	V1 = "v1"
+	V2 = "v2"
+)
+
+var errorBody = res.Response{}
+
+// Versioned moves the path of a route pattern under the version prefix,
+// e.g. "GET /quotes" becomes "GET /v1/quotes".
+func Versioned(version, pattern string) string {
+	method, path, _ := strings.Cut(pattern, " ")
+	return method + " /" + version + path
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+var operations = map[string]openapi.Operation{
+	"POST /quotes": {
+		Summary:   "Add a quote",
+		Tags:      []string{"quotes"},
+		Request:   save.Request{},
+		Responses: map[int]any{201: quote.Quote{}, 400: errorBody, 409: errorBody, 500: errorBody},
+	},
+	"GET /quotes": {
+		Summary: "List quotes",
+		Tags:    []string{"quotes"},
+		Query: []openapi.Param{
+			{Name: "author", Description: "Only quotes by this author."},
+			sourceParam, yearFromParam, yearToParam, langParam, sortParam,
+		},
+		Responses: map[int]any{200: get.GetWithParamResponse{}, 304: nil, 400: errorBody, 500: errorBody},
+	},
+	"GET /quotes/top": {
+		Summary: "List the best rated quotes of a period",
+		Tags:    []string{"votes"},
+		Query: []openapi.Param{
+			{
+				Name:        "period",
+				Description: "Count the votes of the last day, week, month or year; all ranks by the totals. Defaults to week.",
+				Schema:      &openapi.Schema{Type: "string", Enum: []any{"day", "week", "month", "year", "all"}},
+			},
+			{Name: "limit", Schema: &openapi.Schema{Type: "integer", Minimum: ptr(1.0), Maximum: ptr(100.0)}},
+		},
+		Responses: map[int]any{200: get.TopResponse{}, 400: errorBody, 500: errorBody},
+	},
+	"POST /quotes/{id}/like": {
+		Summary:   "Like a quote",
+		Tags:      []string{"votes"},
+		Responses: map[int]any{200: quote.Quote{}, 201: quote.Quote{}, 400: errorBody, 404: errorBody, 500: errorBody},
+	},
+	"PUT /quotes/{id}/rating": {
+		Summary:   "Rate a quote from 1 to 5",
+		Tags:      []string{"votes"},
+		Request:   vote.RateRequest{},
+		Responses: map[int]any{200: quote.Quote{}, 400: errorBody, 404: errorBody, 500: errorBody},
+	},
+	"GET /quotes/{id}": {
+		Summary:   "Get a quote",
+		Tags:      []string{"quotes"},
+		Responses: map[int]any{200: quote.Quote{}, 304: nil, 400: errorBody, 404: errorBody, 500: errorBody},
+	},
+	"PUT /quotes/{id}": {
+		Summary:   "Edit a quote",
+		Tags:      []string{"quotes"},
+		Request:   update.Request{},
+		Responses: map[int]any{200: quote.Quote{}, 400: errorBody, 404: errorBody, 409: errorBody, 500: errorBody},
+	},
+	"DELETE /quotes/{id}": {
+		Summary:   "Delete a quote",
+		Tags:      []string{"quotes"},
+		Responses: map[int]any{204: nil, 400: errorBody, 404: errorBody, 500: errorBody},
+	},
+	"GET /quotes/random": {
+		Summary:   "Get a random quote",
+		Tags:      []string{"quotes"},
+		Query:     []openapi.Param{randomLangParam},
+		Responses: map[int]any{200: quote.Quote{}, 400: errorBody, 404: errorBody, 500: errorBody},
+	},
+	"GET /quotes/random/batch": {
+		Summary: "Get several random quotes, at most one version of each",
+		Tags:    []string{"quotes"},
+		Query: []openapi.Param{
+			randomLangParam,
+			{Name: "count", Description: "Defaults to 5.", Schema: &openapi.Schema{Type: "integer", Minimum: ptr(1.0), Maximum: ptr(20.0)}},
+		},
+		Responses: map[int]any{200: get.GetWithParamResponse{}, 400: errorBody, 500: errorBody},
+	},
+	"GET /quotes/{id}/translations": {
+		Summary:   "List the other language versions of a quote",
+		Tags:      []string{"quotes"},
+		Responses: map[int]any{200: get.GetWithParamResponse{}, 304: nil, 400: errorBody, 404: errorBody, 500: errorBody},
+	},
+	"GET /quotes/random/ws": {
+		Summary:   "Rotate random quotes over a WebSocket",
+		Tags:      []string{"quotes"},
+		Responses: map[int]any{101: nil, 400: errorBody, 503: errorBody},
+	},
+	"GET /quotes/stream": {
+		Summary: "Stream quote events (SSE)",
+		Tags:    []string{"quotes"},
+		Query: []openapi.Param{{
+			Name:        "last_event_id",
+			Description: "Resume after this event; the Last-Event-ID header takes precedence.",
+			Schema:      &openapi.Schema{Type: "integer", Minimum: ptr(0.0)},
+		}},
+		Responses: map[int]any{
+			200: openapi.Media{Type: "text/event-stream", Schema: &openapi.Schema{Type: "string"}},
+			400: errorBody,
+			503: errorBody,
+		},
+	},
+	"GET /quotes/{id}/revisions": {
+		Summary:   "List the revisions of a quote",
+		Tags:      []string{"revisions"},
+		Responses: map[int]any{200: revisions.ListResponse{}, 400: errorBody, 404: errorBody, 500: errorBody},
+	},
+	"GET /quotes/{id}/revisions/diff": {
+		Summary: "Compare two revisions",
+		Tags:    []string{"revisions"},
+		Query: []openapi.Param{
+			{Name: "from", Required: true, Schema: &openapi.Schema{Type: "integer"}},
+			{Name: "to", Required: true, Schema: &openapi.Schema{Type: "integer"}},
+		},
+		Responses: map[int]any{200: quote.Diff{}, 400: errorBody, 404: errorBody, 500: errorBody},
+	},
+	"POST /quotes/{id}/revisions/{rev}/restore": {
+		Summary:   "Restore a revision",
+		Tags:      []string{"revisions"},
+		Responses: map[int]any{200: quote.Quote{}, 400: errorBody, 404: errorBody, 409: errorBody, 500: errorBody},
+	},
+	"POST /graphql": {
+		Summary: "Run a GraphQL query",
+		Tags:    []string{"graphql"},
+		Request: graph.Request{},
+		Responses: map[int]any{
+			200: openapi.Media{Type: "application/json", Schema: &openapi.Schema{
+				Type:        "object",
+				Description: "A GraphQL response with data and errors.",
+			}},
+			400: errorBody,
+		},
+	},
+	"POST /collections": {
+		Summary:   "Create a collection",
+		Tags:      []string{"collections"},
+		Request:   collections.Request{},
+		Responses: map[int]any{201: collection.Collection{}, 400: errorBody, 500: errorBody},
+	},
+	"GET /collections": {
+		Summary:   "List collections",
+		Tags:      []string{"collections"},
+		Responses: map[int]any{200: collections.ListResponse{}, 500: errorBody},
+	},
+	"GET /collections/{id}": {
+		Summary:   "Get a collection with its quotes in order",
+		Tags:      []string{"collections"},
+		Responses: map[int]any{200: collection.Details{}, 400: errorBody, 404: errorBody, 500: errorBody},
+	},
+	"PUT /collections/{id}": {
+		Summary:   "Rename a collection",
+		Tags:      []string{"collections"},
+		Request:   collections.Request{},
+		Responses: map[int]any{200: collection.Collection{}, 400: errorBody, 404: errorBody, 500: errorBody},
+	},
+	"DELETE /collections/{id}": {
+		Summary:   "Delete a collection",
+		Tags:      []string{"collections"},
+		Responses: map[int]any{204: nil, 400: errorBody, 404: errorBody, 500: errorBody},
+	},
+	"POST /collections/{id}/quotes": {
+		Summary:   "Add a quote to a collection",
+		Tags:      []string{"collections"},
+		Request:   collections.AddRequest{},
+		Responses: map[int]any{201: collection.Details{}, 400: errorBody, 404: errorBody, 409: errorBody, 500: errorBody},
+	},
+	"PUT /collections/{id}/quotes": {
+		Summary:   "Reorder the quotes of a collection",
+		Tags:      []string{"collections"},
+		Request:   collections.ReorderRequest{},
+		Responses: map[int]any{200: collection.Details{}, 400: errorBody, 404: errorBody, 500: errorBody},
+	},
+	"DELETE /collections/{id}/quotes/{quote_id}": {
+		Summary:   "Remove a quote from a collection",
+		Tags:      []string{"collections"},
+		Responses: map[int]any{204: nil, 400: errorBody, 404: errorBody, 500: errorBody},
+	},
+	"GET /collections/{id}/random": {
+		Summary:   "Get a random quote from a collection",
+		Tags:      []string{"collections"},
+		Responses: map[int]any{200: quote.Quote{}, 400: errorBody, 404: errorBody, 500: errorBody},
+	},
+	"GET /stats": {
+		Summary: "Read view and popularity statistics",
+		Tags:    []string{"stats"},
+		Query: []openapi.Param{
+			{Name: "days", Description: "Length of the daily series, today included. Defaults to 30.", Schema: &openapi.Schema{Type: "integer", Minimum: ptr(1.0), Maximum: ptr(365.0)}},
+			{Name: "limit", Description: "Number of top viewed quotes. Defaults to 10.", Schema: &openapi.Schema{Type: "integer", Minimum: ptr(1.0), Maximum: ptr(100.0)}},
+		},
+		Responses: map[int]any{200: stats.Summary{}, 400: errorBody, 500: errorBody},
+	},
+	"GET /audit": {
+		Summary: "Read the audit log",
+		Tags:    []string{"audit"},
+		Query: []openapi.Param{
+			{Name: "entity"},
+			{Name: "entity_id", Schema: &openapi.Schema{Type: "integer", Minimum: ptr(1.0)}},
+			{Name: "actor"},
+			{Name: "since", Schema: &openapi.Schema{Type: "string", Format: "date-time"}},
+			{Name: "limit", Schema: &openapi.Schema{Type: "integer", Minimum: ptr(1.0), Maximum: ptr(500.0)}},
+			{Name: "offset", Schema: &openapi.Schema{Type: "integer", Minimum: ptr(0.0)}},
+		},
+		Responses: map[int]any{200: auditlist.Response{}, 400: errorBody, 500: errorBody},
+	},
+	"POST /admin/webhooks": {
+		Summary:   "Subscribe a webhook",
+		Tags:      []string{"webhooks"},
+		Request:   subscriptions.Request{},
+		Responses: map[int]any{201: webhook.Webhook{}, 400: errorBody, 500: errorBody},
+	},
+	"GET /admin/webhooks": {
+		Summary:   "List webhooks",
+		Tags:      []string{"webhooks"},
+		Responses: map[int]any{200: subscriptions.ListResponse{}, 500: errorBody},
+	},
+	"GET /admin/webhooks/{id}": {
+		Summary:   "Get a webhook",
+		Tags:      []string{"webhooks"},
+		Responses: map[int]any{200: webhook.Webhook{}, 400: errorBody, 404: errorBody, 500: errorBody},
+	},
+	"PUT /admin/webhooks/{id}": {
+		Summary:   "Change a webhook",
+		Tags:      []string{"webhooks"},
+		Request:   subscriptions.Request{},
+		Responses: map[int]any{200: webhook.Webhook{}, 400: errorBody, 404: errorBody, 500: errorBody},
+	},
+	"DELETE /admin/webhooks/{id}": {
+		Summary:   "Delete a webhook",
+		Tags:      []string{"webhooks"},
+		Responses: map[int]any{204: nil, 400: errorBody, 404: errorBody, 500: errorBody},
+	},
+	"GET /admin/webhooks/dead-letters": {
+		Summary:   "List deliveries that ran out of attempts",
+		Tags:      []string{"webhooks"},
+		Responses: map[int]any{200: deadletters.ListResponse{}, 500: errorBody},
+	},
+	"POST /admin/webhooks/dead-letters/{id}/retry": {
+		Summary:   "Retry a dead delivery",
+		Tags:      []string{"webhooks"},
+		Responses: map[int]any{202: nil, 400: errorBody, 404: errorBody, 500: errorBody},
+	},
+	"POST /admin/keys": {
+		Summary:   "Create an API key",
+		Tags:      []string{"keys"},
+		Request:   create.Request{},
+		Responses: map[int]any{201: create.Response{}, 400: errorBody, 500: errorBody},
+	},
+	"GET /admin/keys": {
+		Summary:   "List API keys",
+		Tags:      []string{"keys"},
+		Responses: map[int]any{200: keylist.Response{}, 500: errorBody},
+	},
+	"DELETE /admin/keys/{id}": {
+		Summary:   "Revoke an API key",
+		Tags:      []string{"keys"},
+		Responses: map[int]any{204: nil, 400: errorBody, 404: errorBody, 500: errorBody},
+	},
+	"GET /healthz": {
+		Summary:   "Liveness probe",
+		Tags:      []string{"health"},
+		Responses: map[int]any{200: health.StatusResponse{}},
+	},
+	"GET /readyz": {
+		Summary:   "Readiness probe",
+		Tags:      []string{"health"},
+		Responses: map[int]any{200: health.StatusResponse{}, 503: health.StatusResponse{}},
+	},
+	"GET /version": {
+		Summary:   "Build information",
+		Tags:      []string{"health"},
+		Responses: map[int]any{200: health.VersionResponse{}},
+	},
+	"GET /metrics": {
+		Summary:   "Prometheus metrics",
+		Tags:      []string{"health"},
+		Responses: map[int]any{200: openapi.Media{Type: "text/plain", Schema: &openapi.Schema{Type: "string"}}},
+	},
+	"GET " + SpecPath: {
+		Summary:   "This document",
+		Tags:      []string{"health"},
+		Responses: map[int]any{200: openapi.Media{Type: "application/json", Schema: &openapi.Schema{Type: "object"}}},
+	},
+}
+
+var sortParam = openapi.Param{
+	Name:        "sort",
+	Description: "Order by average rating or likes, best first. Defaults to the id.",
+	Schema:      &openapi.Schema{Type: "string", Enum: []any{quote.SortRating, quote.SortLikes}},
+}
+
+var (
+	sourceParam   = openapi.Param{Name: "source", Description: "Only quotes from this book or speech."}
+	yearFromParam = openapi.Param{
+		Name:        "year_from",
+		Description: "Only quotes dated this year or later; negative years are BC.",
+		Schema:      &openapi.Schema{Type: "integer"},
+	}
+	yearToParam = openapi.Param{
+		Name:        "year_to",
+		Description: "Only quotes dated this year or earlier.",
+		Schema:      &openapi.Schema{Type: "integer"},
+	}
+)
+
+var (
+	langParam = openapi.Param{
+		Name:        "lang",
+		Description: "Only quotes in this language, e.g. en or ru. Defaults to the languages of the Accept-Language header.",
+	}
+	randomLangParam = openapi.Param{
+		Name:        "lang",
+		Description: "Only quotes in this language, e.g. en or ru. Without it the languages of the Accept-Language header are preferred.",
+	}
+)
+
+// v2 replaces the operations whose shape changed in V2.
+var v2 = map[string]openapi.Operation{
+	"GET /quotes": {
+		Summary: "List quotes a page at a time",
+		Tags:    []string{"quotes"},
+		Query: []openapi.Param{
+			{Name: "author", Description: "Only quotes by this author."},
+			{Name: "tag", Description: "Only quotes with this tag."},
+			sourceParam, yearFromParam, yearToParam,
+			langParam,
+			sortParam,
+			{Name: "limit", Schema: &openapi.Schema{Type: "integer", Minimum: ptr(1.0), Maximum: ptr(100.0)}},
+			{Name: "offset", Schema: &openapi.Schema{Type: "integer", Minimum: ptr(0.0)}},
+		},
+		Responses: map[int]any{200: get.PageResponse{}, 400: errorBody, 500: errorBody},
+	},
+}
+
+// Docs collects the operations of the routes as they are registered.
+type Docs struct {
+	b *openapi.Builder
+}
+
+func NewDocs(version string) *Docs {
+	b := openapi.NewBuilder(openapi.Info{Title: "quotes-mini-service", Version: version})
+	b.Security(map[string]*openapi.SecurityScheme{
+		"apiKey": {Type: "apiKey", In: "header", Name: "X-API-Key"},
+		"bearer": {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+	})
+	return &Docs{b: b}
+}
+
+// Add documents a route served as is, outside the API versions. scope is
+// empty when the route is served without authentication.
+func (d *Docs) Add(pattern, scope string) error {
+	op, ok := operations[pattern]
+	if !ok {
+		return fmt.Errorf("api.Docs.Add: route %q is not documented", pattern)
+	}
+	if err := d.add(pattern, op, scope, false, false); err != nil {
+		return fmt.Errorf("api.Docs.Add: %w", err)
+	}
+	return nil
+}
+
+// AddVersion documents a route served under the version prefix.
+func (d *Docs) AddVersion(version, pattern, scope string) error {
+	op, ok := operations[pattern]
+	if !ok {
+		return fmt.Errorf("api.Docs.AddVersion: route %q is not documented", pattern)
+	}
+	if changed, ok := v2[pattern]; ok && version == V2 {
+		op = changed
+	}
+	if err := d.add(Versioned(version, pattern), op, scope, true, version == V2); err != nil {
+		return fmt.Errorf("api.Docs.AddVersion: %w", err)
+	}
+	return nil
+}
+
+// AddAlias documents the unversioned path of a V1 route, kept for old
+// clients until its sunset.
+func (d *Docs) AddAlias(pattern, scope string) error {
+	op, ok := operations[pattern]
+	if !ok {
+		return fmt.Errorf("api.Docs.AddAlias: route %q is not documented", pattern)
+	}
+	op.Deprecated = true
+	if err := d.add(pattern, op, scope, true, false); err != nil {
+		return fmt.Errorf("api.Docs.AddAlias: %w", err)
+	}
+	return nil
+}
+
+// add documents the operation under pattern. API routes may be rate
+// limited; V2 routes answer errors with problem details.
+func (d *Docs) add(pattern string, op openapi.Operation, scope string, limited, problems bool) error {
+	op.Responses = maps.Clone(op.Responses)
+	if limited {
+		op.Responses[http.StatusTooManyRequests] = errorBody
+	}
+	if scope != "" {
+		op.Scope = scope
+		op.Responses[http.StatusUnauthorized] = errorBody
+		op.Responses[http.StatusForbidden] = errorBody
+	}
+	if op.Request != nil {
+		op.Responses[http.StatusRequestEntityTooLarge] = errorBody
+	}
+	switch method, _, _ := strings.Cut(pattern, " "); method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		// An Idempotency-Key reused with another request.
+		op.Responses[http.StatusUnprocessableEntity] = errorBody
+	}
+	if problems {
+		problem := openapi.Media{Type: "application/problem+json", Schema: d.b.Schema(res.Problem{})}
+		for code, body := range op.Responses {
+			if _, ok := body.(res.Response); ok {
+				op.Responses[code] = problem
+			}
+		}
+	}
+	return d.b.Add(pattern, op)
+}
+
+func (d *Docs) Document() *openapi.Document {
+	return d.b.Document()
+}
+
+// Patterns lists every documented route, registered or not, without
+// version prefixes.
+func Patterns() []string {
+	patterns := make([]string, 0, len(operations))
+	for p := range operations {
+		patterns = append(patterns, p)
+	}
+	return patterns
+}
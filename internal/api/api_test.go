@@ -0,0 +1,82 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"quotes-mini-service/pkg/openapi"
+	"testing"
+)
+
+func TestDocs_Add(t *testing.T) {
+	docs := NewDocs("test")
+	if err := docs.Add("GET /nowhere", ""); err == nil {
+		t.Error("expected error for an undocumented route")
+	}
+	if err := docs.AddVersion(V1, "POST /quotes", "quotes:write"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	op, _ := docs.Document().Operation("POST /v1/quotes")
+	for _, code := range []string{"201", "401", "403", "413", "422", "429"} {
+		if _, ok := op.Responses[code]; !ok {
+			t.Errorf("expected response %s", code)
+		}
+	}
+	if _, ok := operations["POST /quotes"].Responses[http.StatusUnauthorized]; ok {
+		t.Error("expected the operations table to stay unchanged")
+	}
+
+	data, err := json.Marshal(docs.Document())
+	if err != nil {
+		t.Fatalf("failed to encode document: %v", err)
+	}
+	var decoded map[string]any
+	if err = json.Unmarshal(data, &decoded); err != nil || decoded["openapi"] != openapi.Version {
+		t.Errorf("unexpected document: %s", data)
+	}
+}
+
+func TestDocs_Versions(t *testing.T) {
+	docs := NewDocs("test")
+	for _, add := range []func() error{
+		func() error { return docs.AddVersion(V1, "GET /quotes", "") },
+		func() error { return docs.AddVersion(V2, "GET /quotes", "") },
+		func() error { return docs.AddAlias("GET /quotes", "") },
+	} {
+		if err := add(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	doc := docs.Document()
+
+	v1, _ := doc.Operation("GET /v1/quotes")
+	if v1.Responses["200"].Content["application/json"].Schema.Ref != "#/components/schemas/QuoteGetWithParamResponse" {
+		t.Errorf("expected V1 to keep the list shape, got %+v", v1.Responses["200"].Content)
+	}
+	if _, ok := v1.Responses["500"].Content["application/json"]; !ok {
+		t.Error("expected V1 errors as JSON")
+	}
+	paged, _ := doc.Operation("GET /v2/quotes")
+	if paged.Responses["200"].Content["application/json"].Schema.Ref != "#/components/schemas/QuoteGetPageResponse" {
+		t.Errorf("expected V2 to page quotes, got %+v", paged.Responses["200"].Content)
+	}
+	for _, code := range []string{"400", "429", "500"} {
+		if _, ok := paged.Responses[code].Content["application/problem+json"]; !ok {
+			t.Errorf("expected V2 %s as a problem", code)
+		}
+	}
+	alias, _ := doc.Operation("GET /quotes")
+	if !alias.Deprecated || v1.Deprecated || paged.Deprecated {
+		t.Error("expected only the alias to be deprecated")
+	}
+	for pattern := range v2 {
+		if _, ok := operations[pattern]; !ok {
+			t.Errorf("V2 changes undocumented route %q", pattern)
+		}
+	}
+}
+
+func TestVersioned(t *testing.T) {
+	if got := Versioned(V2, "GET /quotes/{id}"); got != "GET /v2/quotes/{id}" {
+		t.Errorf("unexpected pattern %q", got)
+	}
+}
@@ -0,0 +1,69 @@
+package apikey
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const usage = `usage:
+  apikey create -name <name> -scopes <scope,scope,...>
+  apikey list
+  apikey revoke -id <id>`
+
+// Command implements the "apikey" CLI subcommand used to bootstrap the first
+// admin key before any HTTP admin endpoint can be called.
+func Command(ctx context.Context, repo *KeysRepository, args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return errors.New(usage)
+	}
+	fs := flag.NewFlagSet("apikey "+args[0], flag.ContinueOnError)
+	fs.SetOutput(out)
+	switch args[0] {
+	case "create":
+		name := fs.String("name", "", "key name")
+		scopes := fs.String("scopes", "", "comma separated scopes")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		if *name == "" || *scopes == "" {
+			return errors.New(usage)
+		}
+		key, plain, err := repo.Create(ctx, *name, strings.Split(*scopes, ","))
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "id:     %d\nname:   %s\nscopes: %s\nkey:    %s\n",
+			key.ID, key.Name, strings.Join(key.Scopes, ","), plain)
+		fmt.Fprintln(out, "store the key now, it cannot be shown again")
+		return nil
+	case "list":
+		keys, err := repo.List(ctx)
+		if err != nil {
+			return err
+		}
+		for _, key := range keys {
+			state := "active"
+			if key.RevokedAt != nil {
+				state = "revoked"
+			}
+			fmt.Fprintf(out, "%d\t%s\t%s\t%s\t%s\n",
+				key.ID, key.Prefix, key.Name, strings.Join(key.Scopes, ","), state)
+		}
+		return nil
+	case "revoke":
+		id := fs.Int("id", 0, "key id")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		if *id == 0 {
+			return errors.New(usage)
+		}
+		return repo.Revoke(ctx, *id)
+	default:
+		return errors.New(usage)
+	}
+}
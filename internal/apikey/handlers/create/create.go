@@ -0,0 +1,82 @@
+package create
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"quotes-mini-service/internal/apikey"
+	"quotes-mini-service/pkg/decode"
+	"quotes-mini-service/pkg/middleware"
+	"quotes-mini-service/pkg/res"
+	"quotes-mini-service/pkg/sl"
+	"quotes-mini-service/pkg/tracing"
+	"slices"
+)
+
+type Request struct {
+	Name   string   `json:"name" example:"ci-bot"`
+	Scopes []string `json:"scopes" example:"quotes:read"`
+}
+
+type Response struct {
+	apikey.APIKey
+	Key string `json:"key" example:"qms_1a2b3c4d_5e6f..."`
+}
+
+type KeyCreator interface {
+	Create(ctx context.Context, name string, scopes []string) (*apikey.APIKey, string, error)
+}
+
+func New(log *slog.Logger, create KeyCreator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.apikey.create.New"
+		log := log.With(
+			slog.String("op", op),
+		)
+		ctx, span := tracing.Start(r.Context(), op)
+		defer span.End()
+		var req Request
+		if err := decode.JSON(r.Body, &req); err != nil {
+			log.Error("failed to decode request body", sl.Err(err))
+			res.Json(w, res.Error(decode.Message(err)), decode.Status(err))
+			return
+		}
+		if err := validate(req); err != nil {
+			log.Error(err.Error(), sl.Err(err))
+			res.Json(w, res.Error(err.Error()), http.StatusBadRequest)
+			return
+		}
+		key, plain, err := create.Create(ctx, req.Name, req.Scopes)
+		if err != nil {
+			if errors.Is(err, apikey.ErrUnknownScope) {
+				log.Error("unknown scope", sl.Err(err))
+				res.Json(w, res.Error("unknown scope"), http.StatusBadRequest)
+				return
+			}
+			log.Error("failed to create api key", sl.Err(err))
+			res.Json(w, res.Error("failed to create api key"), http.StatusInternalServerError)
+			return
+		}
+		log.Info("api key created", slog.Int("id", key.ID), slog.String("prefix", key.Prefix))
+		res.Json(w, Response{APIKey: *key, Key: plain}, http.StatusCreated)
+	}
+}
+
+func validate(req Request) error {
+	switch {
+	case req.Name == "" && len(req.Scopes) == 0:
+		return fmt.Errorf("name and scopes are required")
+	case req.Name == "":
+		return fmt.Errorf("name is required")
+	case len(req.Scopes) == 0:
+		return fmt.Errorf("scopes are required")
+	}
+	for _, scope := range req.Scopes {
+		if !slices.Contains(middleware.Scopes, scope) {
+			return fmt.Errorf("unknown scope %q", scope)
+		}
+	}
+	return nil
+}
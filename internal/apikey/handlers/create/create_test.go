@@ -0,0 +1,64 @@
+package create
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"quotes-mini-service/internal/apikey"
+	"testing"
+	"time"
+)
+
+type mockKeyCreator struct{}
+
+func (m *mockKeyCreator) Create(_ context.Context, name string, scopes []string) (*apikey.APIKey, string, error) {
+	return &apikey.APIKey{ID: 1, Name: name, Prefix: "qms_00000000", Scopes: scopes, CreatedAt: time.Now()},
+		"qms_00000000_secret", nil
+}
+
+func TestCreate_Success(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	handler := New(log, &mockKeyCreator{})
+
+	body, _ := json.Marshal(Request{Name: "ci", Scopes: []string{"quotes:read"}})
+	r := httptest.NewRequest(http.MethodPost, "/admin/keys", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+
+	handler(w, r)
+
+	if w.Code != http.StatusCreated {
+		t.Errorf("expected status %d, got %d", http.StatusCreated, w.Code)
+	}
+	var response Response
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if response.Key == "" {
+		t.Error("expected plaintext key in response")
+	}
+}
+
+func TestCreate_Invalid(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	handler := New(log, &mockKeyCreator{})
+
+	for _, req := range []Request{
+		{Scopes: []string{"quotes:read"}},
+		{Name: "ci"},
+		{Name: "ci", Scopes: []string{"quotes:everything"}},
+	} {
+		body, _ := json.Marshal(req)
+		r := httptest.NewRequest(http.MethodPost, "/admin/keys", bytes.NewReader(body))
+		w := httptest.NewRecorder()
+
+		handler(w, r)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%+v: expected status %d, got %d", req, http.StatusBadRequest, w.Code)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package list
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"quotes-mini-service/internal/apikey"
+	"quotes-mini-service/pkg/res"
+	"quotes-mini-service/pkg/sl"
+	"quotes-mini-service/pkg/tracing"
+)
+
+type KeyLister interface {
+	List(ctx context.Context) ([]apikey.APIKey, error)
+}
+
+type Response struct {
+	Keys  []apikey.APIKey `json:"keys"`
+	Count int             `json:"count" example:"1"`
+}
+
+func New(log *slog.Logger, list KeyLister) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.apikey.list.New"
+		log := log.With(
+			slog.String("op", op),
+		)
+		ctx, span := tracing.Start(r.Context(), op)
+		defer span.End()
+		keys, err := list.List(ctx)
+		if err != nil {
+			log.Error("internal server error", sl.Err(err))
+			res.Json(w, res.Error("internal server error"), http.StatusInternalServerError)
+			return
+		}
+		res.Json(w, Response{Keys: keys, Count: len(keys)}, http.StatusOK)
+	}
+}
@@ -0,0 +1,46 @@
+package revoke
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"quotes-mini-service/internal/apikey"
+	"quotes-mini-service/pkg/res"
+	"quotes-mini-service/pkg/sl"
+	"quotes-mini-service/pkg/tracing"
+	"strconv"
+)
+
+type KeyRevoker interface {
+	Revoke(ctx context.Context, id int) error
+}
+
+func New(log *slog.Logger, revoke KeyRevoker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.apikey.revoke.New"
+		log := log.With(
+			slog.String("op", op),
+		)
+		ctx, span := tracing.Start(r.Context(), op)
+		defer span.End()
+		id, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			log.Error("invalid argument", sl.Err(err))
+			res.Json(w, res.Error("invalid argument"), http.StatusBadRequest)
+			return
+		}
+		if err = revoke.Revoke(ctx, id); err != nil {
+			if errors.Is(err, apikey.ErrNotFound) {
+				log.Error("api key not found", sl.Err(err))
+				res.Json(w, res.Error("api key with this id not found"), http.StatusNotFound)
+				return
+			}
+			log.Error("failed to revoke api key", sl.Err(err))
+			res.Json(w, res.Error("internal server error"), http.StatusInternalServerError)
+			return
+		}
+		log.Info("api key revoked", slog.Int("id", id))
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,49 @@
+package revoke
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"quotes-mini-service/internal/apikey"
+	"testing"
+)
+
+type mockKeyRevoker struct {
+	ids map[int]bool
+}
+
+func (m *mockKeyRevoker) Revoke(_ context.Context, id int) error {
+	if !m.ids[id] {
+		return fmt.Errorf("revoke: %w", apikey.ErrNotFound)
+	}
+	delete(m.ids, id)
+	return nil
+}
+
+func TestRevoke(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	handler := New(log, &mockKeyRevoker{ids: map[int]bool{1: true}})
+
+	tests := []struct {
+		id   string
+		want int
+	}{
+		{"1", http.StatusNoContent},
+		{"1", http.StatusNotFound},
+		{"abc", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodDelete, "/admin/keys/"+tt.id, nil)
+		r.SetPathValue("id", tt.id)
+		w := httptest.NewRecorder()
+
+		handler(w, r)
+
+		if w.Code != tt.want {
+			t.Errorf("id %s: expected status %d, got %d", tt.id, tt.want, w.Code)
+		}
+	}
+}
@@ -0,0 +1,13 @@
+package apikey
+
+import "time"
+
+type APIKey struct {
+	ID         int        `json:"id" example:"1"`
+	Name       string     `json:"name" example:"ci-bot"`
+	Prefix     string     `json:"prefix" example:"qms_1a2b3c4d"`
+	Scopes     []string   `json:"scopes" example:"quotes:read"`
+	CreatedAt  time.Time  `json:"created_at" example:"2025-05-29T00:00:00Z"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty" example:"2025-05-29T00:00:00Z"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty" example:"2025-05-29T00:00:00Z"`
+}
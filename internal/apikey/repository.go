@@ -0,0 +1,247 @@
+package apikey
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"quotes-mini-service/internal/audit"
+	"quotes-mini-service/internal/storage"
+	"quotes-mini-service/pkg/middleware"
+	"quotes-mini-service/pkg/sl"
+	"quotes-mini-service/pkg/tracing"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrNotFound     = errors.New("api key not found")
+	ErrUnknownScope = errors.New("unknown scope")
+)
+
+// auditEntity names API keys in the audit log.
+const auditEntity = "api_key"
+
+// touchInterval is how often at most the last use of a key is written.
+const touchInterval = time.Minute
+
+type KeysRepository struct {
+	Database *storage.Db
+	log      *slog.Logger
+	now      func() time.Time
+
+	mu      sync.Mutex
+	touched map[int]time.Time
+}
+
+func NewKeysRepository(log *slog.Logger, database *storage.Db) *KeysRepository {
+	return &KeysRepository{
+		Database: database,
+		log:      log.With(slog.String("component", "apikey/repository")),
+		now:      time.Now,
+		touched:  map[int]time.Time{},
+	}
+}
+
+// Create stores a new key and returns it together with the plaintext secret.
+// Only the SHA-256 hash is persisted, so the secret cannot be shown again.
+func (repo *KeysRepository) Create(ctx context.Context, name string, scopes []string) (_ *APIKey, _ string, err error) {
+	const op = "apikey.repository.Create"
+	ctx, span := tracing.StartQuery(ctx, op, "INSERT")
+	defer tracing.EndQuery(span, &err)
+	for _, scope := range scopes {
+		if !slices.Contains(middleware.Scopes, scope) {
+			return nil, "", fmt.Errorf("%s: %w %q", op, ErrUnknownScope, scope)
+		}
+	}
+	plain, prefix, err := generate()
+	if err != nil {
+		return nil, "", fmt.Errorf("%s: generate key: %w", op, err)
+	}
+	tx, err := repo.Database.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("%s: begin transaction: %w", op, err)
+	}
+	defer tx.Rollback()
+	var key APIKey
+	err = tx.QueryRowContext(ctx,
+		`INSERT INTO api_keys(name, prefix, key_hash, scopes) VALUES(?, ?, ?, ?)
+		RETURNING id, name, prefix, scopes, created_at`,
+		name, prefix, Hash(plain), strings.Join(scopes, " ")).
+		Scan(&key.ID, &key.Name, &key.Prefix, scanScopes(&key.Scopes), &key.CreatedAt)
+	if err != nil {
+		return nil, "", fmt.Errorf("%s: insert key: %w", op, err)
+	}
+	if err = audit.Record(ctx, tx, audit.ActionCreate, auditEntity, key.ID, nil, key); err != nil {
+		return nil, "", fmt.Errorf("%s: %w", op, err)
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, "", fmt.Errorf("%s: commit transaction: %w", op, err)
+	}
+	return &key, plain, nil
+}
+
+func (repo *KeysRepository) List(ctx context.Context) (_ []APIKey, err error) {
+	const op = "apikey.repository.List"
+	ctx, span := tracing.StartQuery(ctx, op, "SELECT")
+	defer tracing.EndQuery(span, &err)
+	rows, err := repo.Database.QueryContext(ctx,
+		`SELECT id, name, prefix, scopes, created_at, last_used_at, revoked_at
+		FROM api_keys ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("%s: query execution: %w", op, err)
+	}
+	defer rows.Close()
+	keys := []APIKey{}
+	for rows.Next() {
+		var key APIKey
+		if err := rows.Scan(&key.ID, &key.Name, &key.Prefix, scanScopes(&key.Scopes),
+			&key.CreatedAt, &key.LastUsedAt, &key.RevokedAt); err != nil {
+			return nil, fmt.Errorf("%s: scan row: %w", op, err)
+		}
+		keys = append(keys, key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: iterate rows: %w", op, err)
+	}
+	return keys, nil
+}
+
+func (repo *KeysRepository) Revoke(ctx context.Context, id int) (err error) {
+	const op = "apikey.repository.Revoke"
+	ctx, span := tracing.StartQuery(ctx, op, "UPDATE")
+	defer tracing.EndQuery(span, &err)
+	tx, err := repo.Database.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: begin transaction: %w", op, err)
+	}
+	defer tx.Rollback()
+	before, err := getKey(ctx, tx, id)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if before.RevokedAt != nil {
+		return fmt.Errorf("%s: key with id %d: %w", op, id, ErrNotFound)
+	}
+	if _, err = tx.ExecContext(ctx, "UPDATE api_keys SET revoked_at = CURRENT_TIMESTAMP WHERE id = ?", id); err != nil {
+		return fmt.Errorf("%s: update key: %w", op, err)
+	}
+	after, err := getKey(ctx, tx, id)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if err = audit.Record(ctx, tx, audit.ActionUpdate, auditEntity, id, before, after); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("%s: commit transaction: %w", op, err)
+	}
+	return nil
+}
+
+func getKey(ctx context.Context, tx *sql.Tx, id int) (*APIKey, error) {
+	var key APIKey
+	err := tx.QueryRowContext(ctx,
+		`SELECT id, name, prefix, scopes, created_at, last_used_at, revoked_at
+		FROM api_keys WHERE id = ?`, id).
+		Scan(&key.ID, &key.Name, &key.Prefix, scanScopes(&key.Scopes), &key.CreatedAt, &key.LastUsedAt, &key.RevokedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("key with id %d: %w", id, ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("select key: %w", err)
+	}
+	return &key, nil
+}
+
+// Verify implements middleware.KeyVerifier.
+func (repo *KeysRepository) Verify(ctx context.Context, plain string) (_ *middleware.Principal, err error) {
+	const op = "apikey.repository.Verify"
+	ctx, span := tracing.StartQuery(ctx, op, "SELECT")
+	defer tracing.EndQuery(span, &err)
+	var (
+		id     int
+		name   string
+		scopes []string
+	)
+	err = repo.Database.QueryRowContext(ctx,
+		"SELECT id, name, scopes FROM api_keys WHERE key_hash = ? AND revoked_at IS NULL", Hash(plain)).
+		Scan(&id, &name, scanScopes(&scopes))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%s: %w", op, middleware.ErrInvalidCredentials)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: query key: %w", op, err)
+	}
+	repo.touch(ctx, id)
+	return &middleware.Principal{
+		Subject: fmt.Sprintf("apikey:%d", id),
+		Name:    name,
+		Method:  "api_key",
+		Scopes:  scopes,
+	}, nil
+}
+
+// touch records the use of a key, at most once per touchInterval. It is
+// best effort: a busy database must not turn a valid key away.
+func (repo *KeysRepository) touch(ctx context.Context, id int) {
+	now := repo.now()
+	repo.mu.Lock()
+	if now.Sub(repo.touched[id]) < touchInterval {
+		repo.mu.Unlock()
+		return
+	}
+	repo.touched[id] = now
+	repo.mu.Unlock()
+	_, err := repo.Database.ExecContext(ctx, "UPDATE api_keys SET last_used_at = ? WHERE id = ?", now.UTC(), id)
+	if err != nil {
+		repo.log.Warn("failed to record key use", slog.Int("id", id), sl.Err(err))
+	}
+}
+
+func Hash(plain string) string {
+	sum := sha256.Sum256([]byte(plain))
+	return hex.EncodeToString(sum[:])
+}
+
+func generate() (plain, prefix string, err error) {
+	id := make([]byte, 4)
+	secret := make([]byte, 24)
+	if _, err = rand.Read(id); err != nil {
+		return "", "", err
+	}
+	if _, err = rand.Read(secret); err != nil {
+		return "", "", err
+	}
+	prefix = "qms_" + hex.EncodeToString(id)
+	return prefix + "_" + hex.EncodeToString(secret), prefix, nil
+}
+
+type scopesScanner struct {
+	dst *[]string
+}
+
+func scanScopes(dst *[]string) *scopesScanner {
+	return &scopesScanner{dst: dst}
+}
+
+func (s *scopesScanner) Scan(src any) error {
+	var str string
+	switch v := src.(type) {
+	case string:
+		str = v
+	case []byte:
+		str = string(v)
+	case nil:
+	default:
+		return fmt.Errorf("unsupported scopes type %T", src)
+	}
+	*s.dst = strings.Fields(str)
+	return nil
+}
@@ -0,0 +1,162 @@
+package apikey
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"quotes-mini-service/internal/storage"
+	"quotes-mini-service/pkg/middleware"
+	"testing"
+	"time"
+)
+
+func setupTestDB(t *testing.T) *storage.Db {
+	db, err := storage.NewStorage(":memory:")
+	if err != nil {
+		t.Fatalf("failed to create test database: %v", err)
+	}
+	return db
+}
+
+func TestKeysRepository_CreateVerify(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewKeysRepository(slog.New(slog.NewTextHandler(os.Stdout, nil)), db)
+	ctx := context.Background()
+
+	key, plain, err := repo.Create(ctx, "ci", []string{middleware.ScopeRead, middleware.ScopeWrite})
+	if err != nil {
+		t.Fatalf("failed to create key: %v", err)
+	}
+	if key.ID == 0 || plain == "" {
+		t.Fatal("expected id and plaintext key")
+	}
+
+	var stored string
+	if err := db.QueryRow("SELECT key_hash FROM api_keys WHERE id = ?", key.ID).Scan(&stored); err != nil {
+		t.Fatalf("failed to read key hash: %v", err)
+	}
+	if stored == plain || stored != Hash(plain) {
+		t.Error("expected only the hash to be stored")
+	}
+
+	p, err := repo.Verify(ctx, plain)
+	if err != nil {
+		t.Fatalf("failed to verify key: %v", err)
+	}
+	if !p.HasScope(middleware.ScopeWrite) || p.HasScope(middleware.ScopeDelete) {
+		t.Errorf("unexpected scopes %v", p.Scopes)
+	}
+
+	if _, err := repo.Verify(ctx, plain+"x"); !errors.Is(err, middleware.ErrInvalidCredentials) {
+		t.Errorf("expected invalid credentials, got %v", err)
+	}
+}
+
+func TestKeysRepository_Revoke(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewKeysRepository(slog.New(slog.NewTextHandler(os.Stdout, nil)), db)
+	ctx := context.Background()
+
+	key, plain, err := repo.Create(ctx, "ci", []string{middleware.ScopeRead})
+	if err != nil {
+		t.Fatalf("failed to create key: %v", err)
+	}
+	if err := repo.Revoke(ctx, key.ID); err != nil {
+		t.Fatalf("failed to revoke key: %v", err)
+	}
+	if _, err := repo.Verify(ctx, plain); !errors.Is(err, middleware.ErrInvalidCredentials) {
+		t.Errorf("expected revoked key to be rejected, got %v", err)
+	}
+	if err := repo.Revoke(ctx, key.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected not found on second revoke, got %v", err)
+	}
+
+	keys, err := repo.List(ctx)
+	if err != nil {
+		t.Fatalf("failed to list keys: %v", err)
+	}
+	if len(keys) != 1 || keys[0].RevokedAt == nil {
+		t.Errorf("expected one revoked key, got %+v", keys)
+	}
+
+	var actions string
+	err = db.QueryRow("SELECT GROUP_CONCAT(action, ' ') FROM (SELECT action FROM audit_log WHERE entity = 'api_key' AND entity_id = ? ORDER BY id)", key.ID).
+		Scan(&actions)
+	if err != nil {
+		t.Fatalf("failed to query audit log: %v", err)
+	}
+	if actions != "create update" {
+		t.Errorf("expected create and revoke to be audited, got %q", actions)
+	}
+	var leaked bool
+	db.QueryRow("SELECT EXISTS (SELECT 1 FROM audit_log WHERE after LIKE ?)", "%"+plain+"%").Scan(&leaked)
+	if leaked {
+		t.Error("expected the plaintext key to stay out of the audit log")
+	}
+}
+
+func TestKeysRepository_UnknownScope(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewKeysRepository(slog.New(slog.NewTextHandler(os.Stdout, nil)), db)
+
+	if _, _, err := repo.Create(context.Background(), "ci", []string{"quotes:everything"}); !errors.Is(err, ErrUnknownScope) {
+		t.Errorf("expected unknown scope error, got %v", err)
+	}
+}
+
+func TestKeysRepository_VerifyTouchThrottled(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewKeysRepository(slog.New(slog.NewTextHandler(os.Stdout, nil)), db)
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	repo.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	key, plain, err := repo.Create(ctx, "ci", []string{middleware.ScopeRead})
+	if err != nil {
+		t.Fatalf("failed to create key: %v", err)
+	}
+	lastUsed := func() time.Time {
+		var at time.Time
+		if err := db.QueryRow("SELECT last_used_at FROM api_keys WHERE id = ?", key.ID).Scan(&at); err != nil {
+			t.Fatalf("failed to read last use: %v", err)
+		}
+		return at
+	}
+
+	if _, err := repo.Verify(ctx, plain); err != nil {
+		t.Fatalf("failed to verify key: %v", err)
+	}
+	first := now
+	now = now.Add(30 * time.Second)
+	if _, err := repo.Verify(ctx, plain); err != nil {
+		t.Fatalf("failed to verify key: %v", err)
+	}
+	if got := lastUsed(); !got.Equal(first) {
+		t.Errorf("expected last use %v within the interval, got %v", first, got)
+	}
+	now = now.Add(time.Minute)
+	if _, err := repo.Verify(ctx, plain); err != nil {
+		t.Fatalf("failed to verify key: %v", err)
+	}
+	if got := lastUsed(); !got.Equal(now) {
+		t.Errorf("expected last use %v after the interval, got %v", now, got)
+	}
+
+	// A failed write is logged, not returned.
+	if _, err := db.Exec("CREATE TRIGGER no_touch BEFORE UPDATE ON api_keys BEGIN SELECT RAISE(ABORT, 'read only'); END"); err != nil {
+		t.Fatalf("failed to create trigger: %v", err)
+	}
+	now = now.Add(time.Hour)
+	if _, err := repo.Verify(ctx, plain); err != nil {
+		t.Errorf("expected verify to ignore a failed touch, got %v", err)
+	}
+}
@@ -0,0 +1,90 @@
+package list
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"quotes-mini-service/internal/audit"
+	"quotes-mini-service/pkg/res"
+	"quotes-mini-service/pkg/sl"
+	"quotes-mini-service/pkg/tracing"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultLimit = 50
+	maxLimit     = 500
+)
+
+type EntryLister interface {
+	List(ctx context.Context, f audit.Filter) ([]audit.Entry, int, error)
+}
+
+type Response struct {
+	Entries []audit.Entry `json:"entries"`
+	Count   int           `json:"count" example:"1"`
+	Total   int           `json:"total" example:"1"`
+	Limit   int           `json:"limit" example:"50"`
+	Offset  int           `json:"offset" example:"0"`
+}
+
+func New(log *slog.Logger, list EntryLister) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.audit.list.New"
+		log := log.With(
+			slog.String("op", op),
+		)
+		ctx, span := tracing.Start(r.Context(), op)
+		defer span.End()
+		f, msg := parseFilter(r)
+		if msg != "" {
+			res.Json(w, res.Error(msg), http.StatusBadRequest)
+			return
+		}
+		entries, total, err := list.List(ctx, f)
+		if err != nil {
+			log.Error("internal server error", sl.Err(err))
+			res.Json(w, res.Error("internal server error"), http.StatusInternalServerError)
+			return
+		}
+		res.Json(w, Response{
+			Entries: entries,
+			Count:   len(entries),
+			Total:   total,
+			Limit:   f.Limit,
+			Offset:  f.Offset,
+		}, http.StatusOK)
+	}
+}
+
+func parseFilter(r *http.Request) (audit.Filter, string) {
+	q := r.URL.Query()
+	f := audit.Filter{
+		Entity: q.Get("entity"),
+		Actor:  q.Get("actor"),
+		Limit:  defaultLimit,
+	}
+	var err error
+	if v := q.Get("entity_id"); v != "" {
+		if f.EntityID, err = strconv.Atoi(v); err != nil || f.EntityID <= 0 {
+			return f, "entity_id must be a positive integer"
+		}
+	}
+	if v := q.Get("since"); v != "" {
+		if f.Since, err = time.Parse(time.RFC3339, v); err != nil {
+			return f, "since must be an RFC 3339 timestamp"
+		}
+	}
+	if v := q.Get("limit"); v != "" {
+		if f.Limit, err = strconv.Atoi(v); err != nil || f.Limit <= 0 || f.Limit > maxLimit {
+			return f, "limit must be between 1 and " + strconv.Itoa(maxLimit)
+		}
+	}
+	if v := q.Get("offset"); v != "" {
+		if f.Offset, err = strconv.Atoi(v); err != nil || f.Offset < 0 {
+			return f, "offset must be a non-negative integer"
+		}
+	}
+	return f, ""
+}
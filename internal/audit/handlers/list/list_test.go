@@ -0,0 +1,60 @@
+package list
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"quotes-mini-service/internal/audit"
+	"testing"
+)
+
+type mockEntryLister struct {
+	filter audit.Filter
+}
+
+func (m *mockEntryLister) List(_ context.Context, f audit.Filter) ([]audit.Entry, int, error) {
+	m.filter = f
+	return []audit.Entry{{ID: 1, Actor: "apikey:1", Action: audit.ActionDelete, Entity: "quote", EntityID: 7}}, 1, nil
+}
+
+func TestList(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	lister := &mockEntryLister{}
+	handler := New(log, lister)
+
+	r := httptest.NewRequest(http.MethodGet, "/audit?entity_id=7&actor=apikey:1&since=2025-05-29T00:00:00Z&limit=10&offset=20", nil)
+	w := httptest.NewRecorder()
+	handler(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	f := lister.filter
+	if f.EntityID != 7 || f.Actor != "apikey:1" || f.Since.IsZero() || f.Limit != 10 || f.Offset != 20 {
+		t.Errorf("unexpected filter %+v", f)
+	}
+	var response Response
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if response.Count != 1 || response.Total != 1 || response.Entries[0].EntityID != 7 {
+		t.Errorf("unexpected response %+v", response)
+	}
+}
+
+func TestList_InvalidParams(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	handler := New(log, &mockEntryLister{})
+
+	for _, query := range []string{"entity_id=abc", "since=yesterday", "limit=0", "limit=100000", "offset=-1"} {
+		r := httptest.NewRequest(http.MethodGet, "/audit?"+query, nil)
+		w := httptest.NewRecorder()
+		handler(w, r)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status %d, got %d", query, http.StatusBadRequest, w.Code)
+		}
+	}
+}
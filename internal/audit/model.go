@@ -0,0 +1,34 @@
+package audit
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	ActionCreate = "create"
+	ActionUpdate = "update"
+	ActionDelete = "delete"
+)
+
+type Entry struct {
+	ID        int64           `json:"id" example:"1"`
+	Actor     string          `json:"actor" example:"apikey:1"`
+	Action    string          `json:"action" example:"delete"`
+	Entity    string          `json:"entity" example:"quote"`
+	EntityID  int             `json:"entity_id" example:"1"`
+	Before    json.RawMessage `json:"before,omitempty"`
+	After     json.RawMessage `json:"after,omitempty"`
+	RequestID string          `json:"request_id,omitempty" example:"5f1c2a9e8b7d4c3a"`
+	CreatedAt time.Time       `json:"created_at" example:"2025-05-29T00:00:00Z"`
+}
+
+// Filter selects entries for List; zero fields match everything.
+type Filter struct {
+	Entity   string
+	EntityID int
+	Actor    string
+	Since    time.Time
+	Limit    int
+	Offset   int
+}
@@ -0,0 +1,134 @@
+package audit
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"quotes-mini-service/internal/storage"
+	"quotes-mini-service/pkg/middleware"
+	"quotes-mini-service/pkg/tracing"
+	"strings"
+	"time"
+)
+
+const anonymous = "anonymous"
+
+// Execer is satisfied by *sql.Tx, so that entries are written in the same
+// transaction as the change they describe.
+type Execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// Record writes an audit entry for a change to entity. The actor and request
+// ID are taken from ctx; before and after are stored as JSON and may be nil.
+func Record(ctx context.Context, tx Execer, action, entity string, entityID int, before, after any) error {
+	const op = "audit.Record"
+	actor := anonymous
+	if p, ok := middleware.PrincipalFromContext(ctx); ok {
+		actor = p.Subject
+	}
+	beforeJSON, err := marshal(before)
+	if err != nil {
+		return fmt.Errorf("%s: encode before: %w", op, err)
+	}
+	afterJSON, err := marshal(after)
+	if err != nil {
+		return fmt.Errorf("%s: encode after: %w", op, err)
+	}
+	var requestID any
+	if id := middleware.RequestIDFromContext(ctx); id != "" {
+		requestID = id
+	}
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO audit_log(actor, action, entity, entity_id, before, after, request_id)
+		VALUES(?, ?, ?, ?, ?, ?, ?)`,
+		actor, action, entity, entityID, beforeJSON, afterJSON, requestID)
+	if err != nil {
+		return fmt.Errorf("%s: insert entry: %w", op, err)
+	}
+	return nil
+}
+
+func marshal(v any) (any, error) {
+	if v == nil {
+		return nil, nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+type LogRepository struct {
+	Database *storage.Db
+}
+
+func NewLogRepository(database *storage.Db) *LogRepository {
+	return &LogRepository{
+		Database: database,
+	}
+}
+
+// List returns the entries matching f, newest first, and the total number of
+// matching entries ignoring Limit and Offset.
+func (repo *LogRepository) List(ctx context.Context, f Filter) (_ []Entry, _ int, err error) {
+	const op = "audit.repository.List"
+	ctx, span := tracing.StartQuery(ctx, op, "SELECT")
+	defer tracing.EndQuery(span, &err)
+	var (
+		conds []string
+		args  []any
+	)
+	if f.Entity != "" {
+		conds, args = append(conds, "entity = ?"), append(args, f.Entity)
+	}
+	if f.EntityID != 0 {
+		conds, args = append(conds, "entity_id = ?"), append(args, f.EntityID)
+	}
+	if f.Actor != "" {
+		conds, args = append(conds, "actor = ?"), append(args, f.Actor)
+	}
+	if !f.Since.IsZero() {
+		conds, args = append(conds, "created_at >= datetime(?)"), append(args, f.Since.UTC().Format(time.RFC3339))
+	}
+	where := ""
+	if len(conds) > 0 {
+		where = " WHERE " + strings.Join(conds, " AND ")
+	}
+	var total int
+	if err := repo.Database.QueryRowContext(ctx, "SELECT COUNT(*) FROM audit_log"+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("%s: count entries: %w", op, err)
+	}
+	rows, err := repo.Database.QueryContext(ctx,
+		`SELECT id, actor, action, entity, entity_id, before, after, COALESCE(request_id, ''), created_at
+		FROM audit_log`+where+` ORDER BY id DESC LIMIT ? OFFSET ?`,
+		append(args, f.Limit, f.Offset)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%s: query execution: %w", op, err)
+	}
+	defer rows.Close()
+	entries := []Entry{}
+	for rows.Next() {
+		var (
+			e             Entry
+			before, after sql.NullString
+		)
+		if err := rows.Scan(&e.ID, &e.Actor, &e.Action, &e.Entity, &e.EntityID,
+			&before, &after, &e.RequestID, &e.CreatedAt); err != nil {
+			return nil, 0, fmt.Errorf("%s: scan row: %w", op, err)
+		}
+		if before.Valid {
+			e.Before = json.RawMessage(before.String)
+		}
+		if after.Valid {
+			e.After = json.RawMessage(after.String)
+		}
+		entries = append(entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("%s: iterate rows: %w", op, err)
+	}
+	return entries, total, nil
+}
@@ -0,0 +1,82 @@
+package audit
+
+import (
+	"context"
+	"quotes-mini-service/internal/storage"
+	"quotes-mini-service/pkg/middleware"
+	"testing"
+	"time"
+)
+
+func setupTestDB(t *testing.T) *storage.Db {
+	db, err := storage.NewStorage(":memory:")
+	if err != nil {
+		t.Fatalf("failed to create test database: %v", err)
+	}
+	return db
+}
+
+func TestRecordAndList(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ctx := middleware.WithRequestID(context.Background(), "req-1")
+	adminCtx := middleware.WithPrincipal(ctx, &middleware.Principal{Subject: "apikey:1"})
+	records := []struct {
+		ctx    context.Context
+		action string
+		id     int
+		before any
+		after  any
+	}{
+		{ctx, ActionCreate, 1, nil, map[string]string{"author": "A"}},
+		{adminCtx, ActionCreate, 2, nil, map[string]string{"author": "B"}},
+		{adminCtx, ActionDelete, 1, map[string]string{"author": "A"}, nil},
+	}
+	for _, r := range records {
+		if err := Record(r.ctx, db, r.action, "quote", r.id, r.before, r.after); err != nil {
+			t.Fatalf("failed to record: %v", err)
+		}
+	}
+
+	repo := NewLogRepository(db)
+	entries, total, err := repo.List(context.Background(), Filter{Limit: 10})
+	if err != nil {
+		t.Fatalf("failed to list: %v", err)
+	}
+	if total != 3 || len(entries) != 3 {
+		t.Fatalf("expected 3 entries, got %d of %d", len(entries), total)
+	}
+	newest := entries[0]
+	if newest.Action != ActionDelete || newest.Actor != "apikey:1" || newest.RequestID != "req-1" {
+		t.Errorf("unexpected newest entry %+v", newest)
+	}
+	if string(newest.Before) != `{"author":"A"}` || newest.After != nil {
+		t.Errorf("unexpected before/after %s %s", newest.Before, newest.After)
+	}
+	if entries[2].Actor != anonymous {
+		t.Errorf("expected anonymous actor, got %q", entries[2].Actor)
+	}
+
+	tests := []struct {
+		name  string
+		f     Filter
+		count int
+		total int
+	}{
+		{"entity id", Filter{EntityID: 1, Limit: 10}, 2, 2},
+		{"actor", Filter{Actor: "apikey:1", Limit: 10}, 2, 2},
+		{"page", Filter{Limit: 1, Offset: 1}, 1, 3},
+		{"since past", Filter{Since: time.Now().Add(-time.Hour), Limit: 10}, 3, 3},
+		{"since future", Filter{Since: time.Now().Add(time.Hour), Limit: 10}, 0, 0},
+	}
+	for _, tt := range tests {
+		entries, total, err := repo.List(context.Background(), tt.f)
+		if err != nil {
+			t.Fatalf("%s: failed to list: %v", tt.name, err)
+		}
+		if len(entries) != tt.count || total != tt.total {
+			t.Errorf("%s: expected %d of %d, got %d of %d", tt.name, tt.count, tt.total, len(entries), total)
+		}
+	}
+}
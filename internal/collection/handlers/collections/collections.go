@@ -0,0 +1,201 @@
+package collections
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"quotes-mini-service/internal/collection"
+	"quotes-mini-service/internal/quote"
+	"quotes-mini-service/pkg/decode"
+	"quotes-mini-service/pkg/res"
+	"quotes-mini-service/pkg/sl"
+	"quotes-mini-service/pkg/tracing"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+type Request struct {
+	Name        string `json:"name" example:"Monday standup"`
+	Description string `json:"description,omitempty" example:"Openers for the weekly meeting."`
+}
+
+type ListResponse struct {
+	Collections []collection.Collection `json:"collections"`
+	Count       int                     `json:"count" example:"1"`
+}
+
+type CollectionStore interface {
+	Create(ctx context.Context, name, description string) (*collection.Collection, error)
+	List(ctx context.Context) ([]collection.Collection, error)
+	Get(ctx context.Context, id int) (*collection.Details, error)
+	Update(ctx context.Context, id int, name, description string) (*collection.Collection, error)
+	Delete(ctx context.Context, id int) error
+	AddQuote(ctx context.Context, id, quoteID, position int) (*collection.Details, error)
+	RemoveQuote(ctx context.Context, id, quoteID int) error
+	Reorder(ctx context.Context, id int, quoteIDs []int) (*collection.Details, error)
+	Random(ctx context.Context, id int) (*quote.Quote, error)
+}
+
+func Create(log *slog.Logger, store CollectionStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.collection.Create"
+		log := log.With(
+			slog.String("op", op),
+		)
+		ctx, span := tracing.Start(r.Context(), op)
+		defer span.End()
+		var req Request
+		if err := decode.JSON(r.Body, &req); err != nil {
+			log.Error("failed to decode request body", sl.Err(err))
+			res.Json(w, res.Error(decode.Message(err)), decode.Status(err))
+			return
+		}
+		if err := validate(&req); err != nil {
+			res.Json(w, res.Error(err.Error()), http.StatusBadRequest)
+			return
+		}
+		c, err := store.Create(ctx, req.Name, req.Description)
+		if err != nil {
+			log.Error("failed to create collection", sl.Err(err))
+			res.Json(w, res.Error("failed to create collection"), http.StatusInternalServerError)
+			return
+		}
+		log.Info("collection created", slog.Int("id", c.ID))
+		res.Json(w, c, http.StatusCreated)
+	}
+}
+
+func List(log *slog.Logger, store CollectionStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.collection.List"
+		log := log.With(
+			slog.String("op", op),
+		)
+		ctx, span := tracing.Start(r.Context(), op)
+		defer span.End()
+		collections, err := store.List(ctx)
+		if err != nil {
+			log.Error("internal server error", sl.Err(err))
+			res.Json(w, res.Error("internal server error"), http.StatusInternalServerError)
+			return
+		}
+		res.Json(w, ListResponse{Collections: collections, Count: len(collections)}, http.StatusOK)
+	}
+}
+
+func Get(log *slog.Logger, store CollectionStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.collection.Get"
+		log := log.With(
+			slog.String("op", op),
+		)
+		ctx, span := tracing.Start(r.Context(), op)
+		defer span.End()
+		id, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			res.Json(w, res.Error("invalid argument"), http.StatusBadRequest)
+			return
+		}
+		d, err := store.Get(ctx, id)
+		if !handleError(log, w, err) {
+			return
+		}
+		res.Json(w, d, http.StatusOK)
+	}
+}
+
+func Update(log *slog.Logger, store CollectionStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.collection.Update"
+		log := log.With(
+			slog.String("op", op),
+		)
+		ctx, span := tracing.Start(r.Context(), op)
+		defer span.End()
+		id, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			res.Json(w, res.Error("invalid argument"), http.StatusBadRequest)
+			return
+		}
+		var req Request
+		if err = decode.JSON(r.Body, &req); err != nil {
+			log.Error("failed to decode request body", sl.Err(err))
+			res.Json(w, res.Error(decode.Message(err)), decode.Status(err))
+			return
+		}
+		if err = validate(&req); err != nil {
+			res.Json(w, res.Error(err.Error()), http.StatusBadRequest)
+			return
+		}
+		c, err := store.Update(ctx, id, req.Name, req.Description)
+		if !handleError(log, w, err) {
+			return
+		}
+		log.Info("collection updated", slog.Int("id", id))
+		res.Json(w, c, http.StatusOK)
+	}
+}
+
+func Delete(log *slog.Logger, store CollectionStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.collection.Delete"
+		log := log.With(
+			slog.String("op", op),
+		)
+		ctx, span := tracing.Start(r.Context(), op)
+		defer span.End()
+		id, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			res.Json(w, res.Error("invalid argument"), http.StatusBadRequest)
+			return
+		}
+		if !handleError(log, w, store.Delete(ctx, id)) {
+			return
+		}
+		log.Info("collection deleted", slog.Int("id", id))
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// handleError writes the error response for err and reports whether the
+// handler may continue.
+func handleError(log *slog.Logger, w http.ResponseWriter, err error) bool {
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, collection.ErrNotFound):
+		res.Json(w, res.Error("collection with this id not found"), http.StatusNotFound)
+	case errors.Is(err, quote.ErrNotFound):
+		res.Json(w, res.Error("quote with this id not found"), http.StatusNotFound)
+	case errors.Is(err, collection.ErrItemNotFound):
+		res.Json(w, res.Error("quote is not in the collection"), http.StatusNotFound)
+	case errors.Is(err, collection.ErrEmpty):
+		res.Json(w, res.Error("collection is empty"), http.StatusNotFound)
+	case errors.Is(err, collection.ErrDuplicate):
+		res.Json(w, res.Error("quote is already in the collection"), http.StatusConflict)
+	case errors.Is(err, collection.ErrInvalidPosition):
+		res.Json(w, res.Error("position is out of range"), http.StatusBadRequest)
+	case errors.Is(err, collection.ErrInvalidOrder):
+		res.Json(w, res.Error("quote_ids must list every quote of the collection once"), http.StatusBadRequest)
+	default:
+		log.Error("internal server error", sl.Err(err))
+		res.Json(w, res.Error("internal server error"), http.StatusInternalServerError)
+	}
+	return false
+}
+
+func validate(req *Request) error {
+	req.Name = strings.TrimSpace(req.Name)
+	switch {
+	case req.Name == "":
+		return fmt.Errorf("name is required")
+	case utf8.RuneCountInString(req.Name) > collection.MaxNameLength:
+		return fmt.Errorf("name must be at most %d characters", collection.MaxNameLength)
+	case utf8.RuneCountInString(req.Description) > collection.MaxDescriptionLength:
+		return fmt.Errorf("description must be at most %d characters", collection.MaxDescriptionLength)
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package collections
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"quotes-mini-service/internal/collection"
+	"quotes-mini-service/internal/quote"
+	"strings"
+	"testing"
+)
+
+// mockCollectionStore knows collection 1, which holds quotes 1 and 2, and
+// the empty collection 2.
+type mockCollectionStore struct{}
+
+func (mockCollectionStore) Create(_ context.Context, name, description string) (*collection.Collection, error) {
+	return &collection.Collection{ID: 3, Name: name, Description: description}, nil
+}
+
+func (mockCollectionStore) List(context.Context) ([]collection.Collection, error) {
+	return []collection.Collection{{ID: 1}, {ID: 2}}, nil
+}
+
+func (mockCollectionStore) Get(_ context.Context, id int) (*collection.Details, error) {
+	if id > 2 {
+		return nil, collection.ErrNotFound
+	}
+	return &collection.Details{Collection: collection.Collection{ID: id}}, nil
+}
+
+func (mockCollectionStore) Update(_ context.Context, id int, name, description string) (*collection.Collection, error) {
+	if id > 2 {
+		return nil, collection.ErrNotFound
+	}
+	return &collection.Collection{ID: id, Name: name, Description: description}, nil
+}
+
+func (mockCollectionStore) Delete(_ context.Context, id int) error {
+	if id > 2 {
+		return collection.ErrNotFound
+	}
+	return nil
+}
+
+func (mockCollectionStore) AddQuote(_ context.Context, id, quoteID, position int) (*collection.Details, error) {
+	switch {
+	case id > 2:
+		return nil, collection.ErrNotFound
+	case quoteID > 3:
+		return nil, quote.ErrNotFound
+	case id == 1 && quoteID <= 2:
+		return nil, collection.ErrDuplicate
+	case position > 3:
+		return nil, collection.ErrInvalidPosition
+	}
+	return &collection.Details{Collection: collection.Collection{ID: id}}, nil
+}
+
+func (mockCollectionStore) RemoveQuote(_ context.Context, id, quoteID int) error {
+	switch {
+	case id > 2:
+		return collection.ErrNotFound
+	case id == 2 || quoteID > 2:
+		return collection.ErrItemNotFound
+	}
+	return nil
+}
+
+func (mockCollectionStore) Reorder(_ context.Context, id int, quoteIDs []int) (*collection.Details, error) {
+	switch {
+	case id > 2:
+		return nil, collection.ErrNotFound
+	case len(quoteIDs) != 2:
+		return nil, collection.ErrInvalidOrder
+	}
+	return &collection.Details{Collection: collection.Collection{ID: id}}, nil
+}
+
+func (mockCollectionStore) Random(_ context.Context, id int) (*quote.Quote, error) {
+	switch {
+	case id > 2:
+		return nil, collection.ErrNotFound
+	case id == 2:
+		return nil, collection.ErrEmpty
+	}
+	return &quote.Quote{ID: 1}, nil
+}
+
+func TestHandlers(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	store := mockCollectionStore{}
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		id      string
+		quoteID string
+		body    string
+		status  int
+	}{
+		{"create", Create(log, store), "", "", `{"name": "Standup"}`, http.StatusCreated},
+		{"create blank", Create(log, store), "", "", `{"name": "  "}`, http.StatusBadRequest},
+		{"create long", Create(log, store), "", "", `{"name": "` + strings.Repeat("x", 101) + `"}`, http.StatusBadRequest},
+		{"list", List(log, store), "", "", "", http.StatusOK},
+		{"get", Get(log, store), "1", "", "", http.StatusOK},
+		{"get missing", Get(log, store), "9", "", "", http.StatusNotFound},
+		{"get invalid", Get(log, store), "x", "", "", http.StatusBadRequest},
+		{"update", Update(log, store), "1", "", `{"name": "Retro"}`, http.StatusOK},
+		{"update missing", Update(log, store), "9", "", `{"name": "Retro"}`, http.StatusNotFound},
+		{"delete", Delete(log, store), "1", "", "", http.StatusNoContent},
+		{"delete missing", Delete(log, store), "9", "", "", http.StatusNotFound},
+		{"add", AddQuote(log, store), "2", "", `{"quote_id": 1}`, http.StatusCreated},
+		{"add without quote", AddQuote(log, store), "2", "", `{}`, http.StatusBadRequest},
+		{"add duplicate", AddQuote(log, store), "1", "", `{"quote_id": 1}`, http.StatusConflict},
+		{"add missing quote", AddQuote(log, store), "2", "", `{"quote_id": 9}`, http.StatusNotFound},
+		{"add to missing", AddQuote(log, store), "9", "", `{"quote_id": 1}`, http.StatusNotFound},
+		{"add out of range", AddQuote(log, store), "2", "", `{"quote_id": 1, "position": 9}`, http.StatusBadRequest},
+		{"remove", RemoveQuote(log, store), "1", "2", "", http.StatusNoContent},
+		{"remove absent", RemoveQuote(log, store), "1", "3", "", http.StatusNotFound},
+		{"remove invalid", RemoveQuote(log, store), "1", "x", "", http.StatusBadRequest},
+		{"reorder", Reorder(log, store), "1", "", `{"quote_ids": [2, 1]}`, http.StatusOK},
+		{"reorder partial", Reorder(log, store), "1", "", `{"quote_ids": [2]}`, http.StatusBadRequest},
+		{"random", Random(log, store), "1", "", "", http.StatusOK},
+		{"random empty", Random(log, store), "2", "", "", http.StatusNotFound},
+		{"random missing", Random(log, store), "9", "", "", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodPost, "/collections", strings.NewReader(tt.body))
+		r.SetPathValue("id", tt.id)
+		r.SetPathValue("quote_id", tt.quoteID)
+		w := httptest.NewRecorder()
+		tt.handler(w, r)
+		if w.Code != tt.status {
+			t.Errorf("%s: expected status %d, got %d: %s", tt.name, tt.status, w.Code, w.Body)
+		}
+	}
+}
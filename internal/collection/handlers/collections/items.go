@@ -0,0 +1,129 @@
+package collections
+
+import (
+	"log/slog"
+	"net/http"
+	"quotes-mini-service/pkg/decode"
+	"quotes-mini-service/pkg/res"
+	"quotes-mini-service/pkg/sl"
+	"quotes-mini-service/pkg/tracing"
+	"strconv"
+)
+
+type AddRequest struct {
+	QuoteID int `json:"quote_id" example:"1"`
+	// Position counts from 1; the quote is appended when it is left out.
+	Position int `json:"position,omitempty" example:"1"`
+}
+
+type ReorderRequest struct {
+	QuoteIDs []int `json:"quote_ids" example:"3"`
+}
+
+func AddQuote(log *slog.Logger, store CollectionStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.collection.AddQuote"
+		log := log.With(
+			slog.String("op", op),
+		)
+		ctx, span := tracing.Start(r.Context(), op)
+		defer span.End()
+		id, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			res.Json(w, res.Error("invalid argument"), http.StatusBadRequest)
+			return
+		}
+		var req AddRequest
+		if err = decode.JSON(r.Body, &req); err != nil {
+			log.Error("failed to decode request body", sl.Err(err))
+			res.Json(w, res.Error(decode.Message(err)), decode.Status(err))
+			return
+		}
+		if req.QuoteID <= 0 {
+			res.Json(w, res.Error("quote_id is required"), http.StatusBadRequest)
+			return
+		}
+		d, err := store.AddQuote(ctx, id, req.QuoteID, req.Position)
+		if !handleError(log, w, err) {
+			return
+		}
+		log.Info("quote added to collection", slog.Int("id", id), slog.Int("quote_id", req.QuoteID))
+		res.Json(w, d, http.StatusCreated)
+	}
+}
+
+func RemoveQuote(log *slog.Logger, store CollectionStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.collection.RemoveQuote"
+		log := log.With(
+			slog.String("op", op),
+		)
+		ctx, span := tracing.Start(r.Context(), op)
+		defer span.End()
+		id, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			res.Json(w, res.Error("invalid argument"), http.StatusBadRequest)
+			return
+		}
+		quoteID, err := strconv.Atoi(r.PathValue("quote_id"))
+		if err != nil {
+			res.Json(w, res.Error("invalid argument"), http.StatusBadRequest)
+			return
+		}
+		if !handleError(log, w, store.RemoveQuote(ctx, id, quoteID)) {
+			return
+		}
+		log.Info("quote removed from collection", slog.Int("id", id), slog.Int("quote_id", quoteID))
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func Reorder(log *slog.Logger, store CollectionStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.collection.Reorder"
+		log := log.With(
+			slog.String("op", op),
+		)
+		ctx, span := tracing.Start(r.Context(), op)
+		defer span.End()
+		id, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			res.Json(w, res.Error("invalid argument"), http.StatusBadRequest)
+			return
+		}
+		var req ReorderRequest
+		if err = decode.JSON(r.Body, &req); err != nil {
+			log.Error("failed to decode request body", sl.Err(err))
+			res.Json(w, res.Error(decode.Message(err)), decode.Status(err))
+			return
+		}
+		d, err := store.Reorder(ctx, id, req.QuoteIDs)
+		if !handleError(log, w, err) {
+			return
+		}
+		log.Info("collection reordered", slog.Int("id", id))
+		res.Json(w, d, http.StatusOK)
+	}
+}
+
+// Random picks a quote from the collection only.
+func Random(log *slog.Logger, store CollectionStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.collection.Random"
+		log := log.With(
+			slog.String("op", op),
+		)
+		ctx, span := tracing.Start(r.Context(), op)
+		defer span.End()
+		id, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			res.Json(w, res.Error("invalid argument"), http.StatusBadRequest)
+			return
+		}
+		q, err := store.Random(ctx, id)
+		if !handleError(log, w, err) {
+			return
+		}
+		res.Render(w, r, q, http.StatusOK)
+	}
+}
@@ -0,0 +1,35 @@
+package collection
+
+import (
+	"quotes-mini-service/internal/quote"
+	"time"
+)
+
+const (
+	MaxNameLength        = 100
+	MaxDescriptionLength = 500
+)
+
+// Collection is a named, ordered set of quotes. Count is kept by triggers
+// on collection_items.
+type Collection struct {
+	ID          int        `json:"id" example:"1"`
+	Name        string     `json:"name" example:"Monday standup"`
+	Description string     `json:"description,omitempty" example:"Openers for the weekly meeting."`
+	Count       int        `json:"count" example:"5"`
+	CreatedBy   string     `json:"created_by,omitempty" example:"apikey:1"`
+	CreatedAt   time.Time  `json:"created_at" example:"2025-05-29T00:00:00Z"`
+	UpdatedAt   *time.Time `json:"updated_at,omitempty" example:"2025-05-30T00:00:00Z"`
+}
+
+// Details is a collection with its quotes in order.
+type Details struct {
+	Collection
+	Quotes []quote.Quote `json:"quotes"`
+}
+
+const collectionColumns = "id, name, description, quote_count, COALESCE(created_by, ''), created_at, updated_at"
+
+func (c *Collection) fields() []any {
+	return []any{&c.ID, &c.Name, &c.Description, &c.Count, &c.CreatedBy, &c.CreatedAt, &c.UpdatedAt}
+}
@@ -0,0 +1,394 @@
+package collection
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"quotes-mini-service/internal/audit"
+	"quotes-mini-service/internal/quote"
+	"quotes-mini-service/internal/storage"
+	"quotes-mini-service/pkg/middleware"
+	"quotes-mini-service/pkg/tracing"
+	"slices"
+)
+
+var (
+	ErrNotFound        = errors.New("collection not found")
+	ErrItemNotFound    = errors.New("quote is not in the collection")
+	ErrDuplicate       = errors.New("quote is already in the collection")
+	ErrEmpty           = errors.New("collection is empty")
+	ErrInvalidPosition = errors.New("invalid position")
+	ErrInvalidOrder    = errors.New("invalid order")
+)
+
+// auditEntity names collections in the audit log.
+const auditEntity = "collection"
+
+type CollectionsRepository struct {
+	Database *storage.Db
+}
+
+func NewCollectionsRepository(database *storage.Db) *CollectionsRepository {
+	return &CollectionsRepository{
+		Database: database,
+	}
+}
+
+func (repo *CollectionsRepository) Create(ctx context.Context, name, description string) (_ *Collection, err error) {
+	const op = "collection.repository.Create"
+	ctx, span := tracing.StartQuery(ctx, op, "INSERT")
+	defer tracing.EndQuery(span, &err)
+	tx, err := repo.Database.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s: begin transaction: %w", op, err)
+	}
+	defer tx.Rollback()
+	var c Collection
+	err = tx.QueryRowContext(ctx,
+		"INSERT INTO collections(name, description, created_by) VALUES(?, ?, ?) RETURNING "+collectionColumns,
+		name, description, creator(ctx)).
+		Scan(c.fields()...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: insert collection: %w", op, err)
+	}
+	if err = audit.Record(ctx, tx, audit.ActionCreate, auditEntity, c.ID, nil, c); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("%s: commit transaction: %w", op, err)
+	}
+	return &c, nil
+}
+
+func (repo *CollectionsRepository) List(ctx context.Context) (_ []Collection, err error) {
+	const op = "collection.repository.List"
+	ctx, span := tracing.StartQuery(ctx, op, "SELECT")
+	defer tracing.EndQuery(span, &err)
+	rows, err := repo.Database.QueryContext(ctx, "SELECT "+collectionColumns+" FROM collections ORDER BY id")
+	if err != nil {
+		return nil, fmt.Errorf("%s: query execution: %w", op, err)
+	}
+	defer rows.Close()
+	collections := []Collection{}
+	for rows.Next() {
+		var c Collection
+		if err := rows.Scan(c.fields()...); err != nil {
+			return nil, fmt.Errorf("%s: scan row: %w", op, err)
+		}
+		collections = append(collections, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: iterate rows: %w", op, err)
+	}
+	return collections, nil
+}
+
+func (repo *CollectionsRepository) Get(ctx context.Context, id int) (_ *Details, err error) {
+	const op = "collection.repository.Get"
+	ctx, span := tracing.StartQuery(ctx, op, "SELECT")
+	defer tracing.EndQuery(span, &err)
+	tx, err := repo.Database.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s: begin transaction: %w", op, err)
+	}
+	defer tx.Rollback()
+	d, err := getDetails(ctx, tx, id)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("%s: commit transaction: %w", op, err)
+	}
+	return d, nil
+}
+
+func (repo *CollectionsRepository) Update(ctx context.Context, id int, name, description string) (_ *Collection, err error) {
+	const op = "collection.repository.Update"
+	ctx, span := tracing.StartQuery(ctx, op, "UPDATE")
+	defer tracing.EndQuery(span, &err)
+	tx, err := repo.Database.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s: begin transaction: %w", op, err)
+	}
+	defer tx.Rollback()
+	before, err := getCollection(ctx, tx, id)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	var c Collection
+	err = tx.QueryRowContext(ctx,
+		"UPDATE collections SET name = ?, description = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ? RETURNING "+collectionColumns,
+		name, description, id).
+		Scan(c.fields()...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: update collection: %w", op, err)
+	}
+	if err = audit.Record(ctx, tx, audit.ActionUpdate, auditEntity, id, before, c); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("%s: commit transaction: %w", op, err)
+	}
+	return &c, nil
+}
+
+// Delete removes the collection and its items; the quotes stay.
+func (repo *CollectionsRepository) Delete(ctx context.Context, id int) (err error) {
+	const op = "collection.repository.Delete"
+	ctx, span := tracing.StartQuery(ctx, op, "DELETE")
+	defer tracing.EndQuery(span, &err)
+	tx, err := repo.Database.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: begin transaction: %w", op, err)
+	}
+	defer tx.Rollback()
+	before, err := getDetails(ctx, tx, id)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if _, err = tx.ExecContext(ctx, "DELETE FROM collections WHERE id = ?", id); err != nil {
+		return fmt.Errorf("%s: delete collection: %w", op, err)
+	}
+	if _, err = tx.ExecContext(ctx, "DELETE FROM collection_items WHERE collection_id = ?", id); err != nil {
+		return fmt.Errorf("%s: delete items: %w", op, err)
+	}
+	if err = audit.Record(ctx, tx, audit.ActionDelete, auditEntity, id, snapshotOf(before), nil); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("%s: commit transaction: %w", op, err)
+	}
+	return nil
+}
+
+// AddQuote inserts the quote at position, counted from 1, and moves the
+// following items down. A zero position appends.
+func (repo *CollectionsRepository) AddQuote(ctx context.Context, id, quoteID, position int) (_ *Details, err error) {
+	const op = "collection.repository.AddQuote"
+	ctx, span := tracing.StartQuery(ctx, op, "INSERT")
+	defer tracing.EndQuery(span, &err)
+	tx, err := repo.Database.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s: begin transaction: %w", op, err)
+	}
+	defer tx.Rollback()
+	before, err := getDetails(ctx, tx, id)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	c := before.Collection
+	if position == 0 {
+		position = c.Count + 1
+	}
+	if position < 1 || position > c.Count+1 {
+		return nil, fmt.Errorf("%s: %w: must be between 1 and %d", op, ErrInvalidPosition, c.Count+1)
+	}
+	var exists bool
+	if err = tx.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM quotes WHERE id = ?)", quoteID).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("%s: find quote: %w", op, err)
+	}
+	if !exists {
+		return nil, fmt.Errorf("%s: quote with id %d: %w", op, quoteID, quote.ErrNotFound)
+	}
+	if _, err = tx.ExecContext(ctx,
+		"UPDATE collection_items SET position = position + 1 WHERE collection_id = ? AND position >= ?", id, position); err != nil {
+		return nil, fmt.Errorf("%s: move items: %w", op, err)
+	}
+	result, err := tx.ExecContext(ctx,
+		"INSERT OR IGNORE INTO collection_items(collection_id, quote_id, position) VALUES(?, ?, ?)", id, quoteID, position)
+	if err != nil {
+		return nil, fmt.Errorf("%s: insert item: %w", op, err)
+	}
+	if n, err := result.RowsAffected(); err != nil || n == 0 {
+		return nil, fmt.Errorf("%s: quote %d: %w", op, quoteID, ErrDuplicate)
+	}
+	return repo.commitDetails(ctx, tx, op, before)
+}
+
+// RemoveQuote takes the quote out of the collection and moves the
+// following items up.
+func (repo *CollectionsRepository) RemoveQuote(ctx context.Context, id, quoteID int) (err error) {
+	const op = "collection.repository.RemoveQuote"
+	ctx, span := tracing.StartQuery(ctx, op, "DELETE")
+	defer tracing.EndQuery(span, &err)
+	tx, err := repo.Database.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: begin transaction: %w", op, err)
+	}
+	defer tx.Rollback()
+	before, err := getDetails(ctx, tx, id)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	var position int
+	err = tx.QueryRowContext(ctx,
+		"DELETE FROM collection_items WHERE collection_id = ? AND quote_id = ? RETURNING position", id, quoteID).
+		Scan(&position)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%s: quote %d: %w", op, quoteID, ErrItemNotFound)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: delete item: %w", op, err)
+	}
+	if _, err = tx.ExecContext(ctx,
+		"UPDATE collection_items SET position = position - 1 WHERE collection_id = ? AND position > ?", id, position); err != nil {
+		return fmt.Errorf("%s: move items: %w", op, err)
+	}
+	_, err = repo.commitDetails(ctx, tx, op, before)
+	return err
+}
+
+// Reorder puts the items in the order of quoteIDs, which must list every
+// quote of the collection exactly once.
+func (repo *CollectionsRepository) Reorder(ctx context.Context, id int, quoteIDs []int) (_ *Details, err error) {
+	const op = "collection.repository.Reorder"
+	ctx, span := tracing.StartQuery(ctx, op, "UPDATE")
+	defer tracing.EndQuery(span, &err)
+	tx, err := repo.Database.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s: begin transaction: %w", op, err)
+	}
+	defer tx.Rollback()
+	current, err := getDetails(ctx, tx, id)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	ids := make([]int, 0, len(current.Quotes))
+	for _, q := range current.Quotes {
+		ids = append(ids, q.ID)
+	}
+	slices.Sort(ids)
+	sorted := slices.Sorted(slices.Values(quoteIDs))
+	if !slices.Equal(ids, sorted) {
+		return nil, fmt.Errorf("%s: %w: quote_ids must list every quote of the collection once", op, ErrInvalidOrder)
+	}
+	for i, quoteID := range quoteIDs {
+		if _, err = tx.ExecContext(ctx,
+			"UPDATE collection_items SET position = ? WHERE collection_id = ? AND quote_id = ?", i+1, id, quoteID); err != nil {
+			return nil, fmt.Errorf("%s: update position: %w", op, err)
+		}
+	}
+	return repo.commitDetails(ctx, tx, op, current)
+}
+
+// Random picks a quote from the collection.
+func (repo *CollectionsRepository) Random(ctx context.Context, id int) (_ *quote.Quote, err error) {
+	const op = "collection.repository.Random"
+	ctx, span := tracing.StartQuery(ctx, op, "SELECT")
+	defer tracing.EndQuery(span, &err)
+	tx, err := repo.Database.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s: begin transaction: %w", op, err)
+	}
+	defer tx.Rollback()
+	c, err := getCollection(ctx, tx, id)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if c.Count == 0 {
+		return nil, fmt.Errorf("%s: collection %d: %w", op, id, ErrEmpty)
+	}
+	var q quote.Quote
+	err = tx.QueryRowContext(ctx, "SELECT "+quote.Columns+` FROM collection_items
+		JOIN quotes ON quotes.id = collection_items.quote_id
+		WHERE collection_id = ? ORDER BY position LIMIT 1 OFFSET ?`, id, rand.IntN(c.Count)).
+		Scan(q.Fields()...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: scan result: %w", op, err)
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("%s: commit transaction: %w", op, err)
+	}
+	return &q, nil
+}
+
+// commitDetails finishes a change to the items of the collection that was
+// before, records it and returns the collection as it is now.
+func (repo *CollectionsRepository) commitDetails(ctx context.Context, tx *sql.Tx, op string, before *Details) (*Details, error) {
+	id := before.ID
+	if err := touch(ctx, tx, id); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	d, err := getDetails(ctx, tx, id)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if err = audit.Record(ctx, tx, audit.ActionUpdate, auditEntity, id, snapshotOf(before), snapshotOf(d)); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("%s: commit transaction: %w", op, err)
+	}
+	return d, nil
+}
+
+func getCollection(ctx context.Context, tx *sql.Tx, id int) (*Collection, error) {
+	var c Collection
+	err := tx.QueryRowContext(ctx, "SELECT "+collectionColumns+" FROM collections WHERE id = ?", id).
+		Scan(c.fields()...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("collection with id %d: %w", id, ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("select collection: %w", err)
+	}
+	return &c, nil
+}
+
+func getDetails(ctx context.Context, tx *sql.Tx, id int) (*Details, error) {
+	c, err := getCollection(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := tx.QueryContext(ctx, "SELECT "+quote.Columns+` FROM collection_items
+		JOIN quotes ON quotes.id = collection_items.quote_id
+		WHERE collection_id = ? ORDER BY position`, id)
+	if err != nil {
+		return nil, fmt.Errorf("select items: %w", err)
+	}
+	defer rows.Close()
+	d := Details{Collection: *c, Quotes: []quote.Quote{}}
+	for rows.Next() {
+		var q quote.Quote
+		if err := rows.Scan(q.Fields()...); err != nil {
+			return nil, fmt.Errorf("scan item: %w", err)
+		}
+		d.Quotes = append(d.Quotes, q)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate items: %w", err)
+	}
+	return &d, nil
+}
+
+// snapshot is how a collection appears in the audit log: its items by quote
+// ID rather than the full quotes.
+type snapshot struct {
+	Collection
+	QuoteIDs []int `json:"quote_ids"`
+}
+
+func snapshotOf(d *Details) snapshot {
+	s := snapshot{Collection: d.Collection, QuoteIDs: make([]int, 0, len(d.Quotes))}
+	for _, q := range d.Quotes {
+		s.QuoteIDs = append(s.QuoteIDs, q.ID)
+	}
+	return s
+}
+
+func touch(ctx context.Context, tx *sql.Tx, id int) error {
+	if _, err := tx.ExecContext(ctx, "UPDATE collections SET updated_at = CURRENT_TIMESTAMP WHERE id = ?", id); err != nil {
+		return fmt.Errorf("touch collection: %w", err)
+	}
+	return nil
+}
+
+// creator is the authenticated subject, or empty for anonymous requests.
+func creator(ctx context.Context) any {
+	if p, ok := middleware.PrincipalFromContext(ctx); ok {
+		return p.Subject
+	}
+	return nil
+}
@@ -0,0 +1,212 @@
+package collection
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"quotes-mini-service/internal/quote"
+	"quotes-mini-service/internal/storage"
+	"quotes-mini-service/pkg/middleware"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func setupTestDB(t *testing.T) (*storage.Db, *quote.QuotesRepository) {
+	db, err := storage.NewStorage(":memory:")
+	if err != nil {
+		t.Fatalf("failed to create test database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	quotes := quote.NewQuotesRepository(db)
+	for i := 1; i <= 4; i++ {
+		if _, err := quotes.Save(context.Background(), "Author", fmt.Sprintf("Quote %d", i)); err != nil {
+			t.Fatalf("failed to save test quote: %v", err)
+		}
+	}
+	return db, quotes
+}
+
+func order(d *Details) string {
+	ids := make([]int, 0, len(d.Quotes))
+	for _, q := range d.Quotes {
+		ids = append(ids, q.ID)
+	}
+	return fmt.Sprint(ids)
+}
+
+func TestCollectionsRepository_Items(t *testing.T) {
+	db, quotes := setupTestDB(t)
+	repo := NewCollectionsRepository(db)
+	ctx := context.Background()
+
+	c, err := repo.Create(ctx, "Standup", "")
+	if err != nil {
+		t.Fatalf("failed to create collection: %v", err)
+	}
+	if _, err = repo.Random(ctx, c.ID); !errors.Is(err, ErrEmpty) {
+		t.Errorf("expected ErrEmpty, got %v", err)
+	}
+	for _, add := range []struct{ quote, position int }{{1, 0}, {2, 0}, {3, 1}, {4, 3}} {
+		if _, err = repo.AddQuote(ctx, c.ID, add.quote, add.position); err != nil {
+			t.Fatalf("failed to add quote %d: %v", add.quote, err)
+		}
+	}
+	d, err := repo.Get(ctx, c.ID)
+	if err != nil {
+		t.Fatalf("failed to get collection: %v", err)
+	}
+	if got := order(d); got != "[3 1 4 2]" || d.Count != 4 {
+		t.Errorf("expected [3 1 4 2] with count 4, got %s with count %d", got, d.Count)
+	}
+
+	if _, err = repo.AddQuote(ctx, c.ID, 1, 0); !errors.Is(err, ErrDuplicate) {
+		t.Errorf("expected ErrDuplicate, got %v", err)
+	}
+	if _, err = repo.AddQuote(ctx, c.ID, 99, 0); !errors.Is(err, quote.ErrNotFound) {
+		t.Errorf("expected quote.ErrNotFound, got %v", err)
+	}
+	if _, err = repo.AddQuote(ctx, c.ID, 1, 6); !errors.Is(err, ErrInvalidPosition) {
+		t.Errorf("expected ErrInvalidPosition, got %v", err)
+	}
+	if _, err = repo.AddQuote(ctx, 99, 1, 0); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+
+	if err = repo.RemoveQuote(ctx, c.ID, 1); err != nil {
+		t.Fatalf("failed to remove quote: %v", err)
+	}
+	if err = repo.RemoveQuote(ctx, c.ID, 1); !errors.Is(err, ErrItemNotFound) {
+		t.Errorf("expected ErrItemNotFound, got %v", err)
+	}
+	if _, err = repo.Reorder(ctx, c.ID, []int{2, 3}); !errors.Is(err, ErrInvalidOrder) {
+		t.Errorf("expected ErrInvalidOrder for a missing quote, got %v", err)
+	}
+	if _, err = repo.Reorder(ctx, c.ID, []int{2, 3, 3}); !errors.Is(err, ErrInvalidOrder) {
+		t.Errorf("expected ErrInvalidOrder for a repeated quote, got %v", err)
+	}
+	if d, err = repo.Reorder(ctx, c.ID, []int{2, 3, 4}); err != nil || order(d) != "[2 3 4]" {
+		t.Errorf("expected [2 3 4], got %v (%v)", d, err)
+	}
+
+	// Deleting a quote takes it out of the collection and keeps the order
+	// dense for the next insert.
+	if err = quotes.Delete(ctx, 3); err != nil {
+		t.Fatalf("failed to delete quote: %v", err)
+	}
+	if d, err = repo.AddQuote(ctx, c.ID, 1, 2); err != nil || order(d) != "[2 1 4]" || d.Count != 3 {
+		t.Errorf("expected [2 1 4] with count 3, got %v (%v)", d, err)
+	}
+	var positions string
+	db.QueryRow("SELECT group_concat(position, ',') FROM (SELECT position FROM collection_items ORDER BY position)").Scan(&positions)
+	if positions != "1,2,3" {
+		t.Errorf("expected positions 1,2,3, got %s", positions)
+	}
+
+	for range 10 {
+		q, err := repo.Random(ctx, c.ID)
+		if err != nil {
+			t.Fatalf("failed to get random quote: %v", err)
+		}
+		if q.ID == 3 {
+			t.Fatalf("picked a deleted quote")
+		}
+	}
+
+	if err = repo.Delete(ctx, c.ID); err != nil {
+		t.Fatalf("failed to delete collection: %v", err)
+	}
+	if _, err = repo.Get(ctx, c.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+	var items int
+	db.QueryRow("SELECT COUNT(*) FROM collection_items").Scan(&items)
+	if items != 0 {
+		t.Errorf("expected the items to go with the collection, got %d", items)
+	}
+}
+
+func TestCollectionsRepository_CRUD(t *testing.T) {
+	db, _ := setupTestDB(t)
+	repo := NewCollectionsRepository(db)
+	ctx := context.Background()
+
+	c, err := repo.Create(ctx, "Standup", "Openers")
+	if err != nil {
+		t.Fatalf("failed to create collection: %v", err)
+	}
+	if c.ID == 0 || c.Name != "Standup" || c.Description != "Openers" || c.CreatedAt.IsZero() || c.UpdatedAt != nil {
+		t.Errorf("unexpected collection %+v", c)
+	}
+	if c, err = repo.Update(ctx, c.ID, "Retro", ""); err != nil || c.Name != "Retro" || c.UpdatedAt == nil {
+		t.Errorf("unexpected collection %+v (%v)", c, err)
+	}
+	if _, err = repo.Update(ctx, 99, "Retro", ""); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+	list, err := repo.List(ctx)
+	if err != nil || len(list) != 1 {
+		t.Errorf("expected one collection, got %+v (%v)", list, err)
+	}
+	if err = repo.Delete(ctx, 99); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestCollectionsRepository_Audit(t *testing.T) {
+	db, _ := setupTestDB(t)
+	repo := NewCollectionsRepository(db)
+	ctx := middleware.WithPrincipal(context.Background(), &middleware.Principal{Subject: "apikey:7"})
+
+	c, err := repo.Create(ctx, "Standup", "")
+	if err != nil {
+		t.Fatalf("failed to create collection: %v", err)
+	}
+	steps := []func() error{
+		func() error { _, err := repo.Update(ctx, c.ID, "Retro", ""); return err },
+		func() error { _, err := repo.AddQuote(ctx, c.ID, 1, 0); return err },
+		func() error { _, err := repo.AddQuote(ctx, c.ID, 2, 0); return err },
+		func() error { _, err := repo.Reorder(ctx, c.ID, []int{2, 1}); return err },
+		func() error { return repo.RemoveQuote(ctx, c.ID, 1) },
+		func() error { return repo.Delete(ctx, c.ID) },
+	}
+	for i, step := range steps {
+		if err := step(); err != nil {
+			t.Fatalf("step %d: %v", i+1, err)
+		}
+	}
+	// Failed changes must not leave entries behind.
+	repo.AddQuote(ctx, c.ID, 3, 0)
+	repo.Delete(ctx, c.ID)
+
+	rows, err := db.Query("SELECT actor, action, entity, entity_id, COALESCE(before, ''), COALESCE(after, '') FROM audit_log ORDER BY id")
+	if err != nil {
+		t.Fatalf("failed to query audit log: %v", err)
+	}
+	defer rows.Close()
+	var got []string
+	var last string
+	for rows.Next() {
+		var actor, action, entity, before, after string
+		var id int
+		if err := rows.Scan(&actor, &action, &entity, &id, &before, &after); err != nil {
+			t.Fatalf("failed to scan: %v", err)
+		}
+		if entity != "collection" {
+			continue
+		}
+		got = append(got, fmt.Sprintf("%s %s %d", actor, action, id))
+		last = before
+	}
+	want := []string{fmt.Sprintf("apikey:7 create %d", c.ID)}
+	for range 5 {
+		want = append(want, fmt.Sprintf("apikey:7 update %d", c.ID))
+	}
+	want = append(want, fmt.Sprintf("apikey:7 delete %d", c.ID))
+	if !slices.Equal(got, want) {
+		t.Errorf("expected audit entries %v, got %v", want, got)
+	}
+	if !strings.Contains(last, `"quote_ids":[2]`) {
+		t.Errorf("expected the deleted collection with its items, got %s", last)
+	}
+}
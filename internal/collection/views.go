@@ -0,0 +1,26 @@
+package collection
+
+import (
+	"context"
+	"quotes-mini-service/internal/quote"
+)
+
+// TrackedRepository records a view for every quote picked at random from a
+// collection, like quote.TrackedRepository does for the quote routes.
+type TrackedRepository struct {
+	*CollectionsRepository
+	views quote.ViewRecorder
+}
+
+func NewTrackedRepository(repo *CollectionsRepository, views quote.ViewRecorder) *TrackedRepository {
+	return &TrackedRepository{CollectionsRepository: repo, views: views}
+}
+
+func (t *TrackedRepository) Random(ctx context.Context, id int) (*quote.Quote, error) {
+	q, err := t.CollectionsRepository.Random(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	t.views.Record(q.ID, quote.ViewRandom)
+	return q, nil
+}
@@ -0,0 +1,45 @@
+package collection
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+type recordedView struct {
+	id     int
+	source string
+}
+
+type sliceRecorder []recordedView
+
+func (s *sliceRecorder) Record(id int, source string) {
+	*s = append(*s, recordedView{id, source})
+}
+
+func (s *sliceRecorder) Forget(id int) {}
+
+func TestTrackedRepository_Random(t *testing.T) {
+	db, _ := setupTestDB(t)
+	views := &sliceRecorder{}
+	repo := NewTrackedRepository(NewCollectionsRepository(db), views)
+	ctx := context.Background()
+
+	c, err := repo.Create(ctx, "Standup", "")
+	if err != nil {
+		t.Fatalf("failed to create collection: %v", err)
+	}
+	repo.Random(ctx, c.ID)
+	repo.Random(ctx, 99)
+	if _, err = repo.AddQuote(ctx, c.ID, 2, 0); err != nil {
+		t.Fatalf("failed to add quote: %v", err)
+	}
+	if _, err = repo.Random(ctx, c.ID); err != nil {
+		t.Fatalf("failed to get random quote: %v", err)
+	}
+	repo.Get(ctx, c.ID)
+
+	if want := []recordedView{{2, "random"}}; !slices.Equal(*views, want) {
+		t.Errorf("expected views %v, got %v", want, *views)
+	}
+}
@@ -1,8 +1,11 @@
 package config
 
 import (
+	"compress/gzip"
 	"log"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -11,12 +14,130 @@ import (
 type Config struct {
 	Database string
 	HTTPServer
+	Tracing
+	Auth
+	RateLimit
+	CORS
+	Cache
+	Stats    Stats
+	Webhooks Webhooks
+	Stream
+	WebSocket WebSocket
+	GRPC      GRPC
+	GraphQL   GraphQL
+	Validation
+	Versioning Versioning
 }
 
 type HTTPServer struct {
-	Address     string
+	Address         string
+	Timeout         time.Duration
+	IdleTimeout     time.Duration
+	ShutdownTimeout time.Duration
+	ShutdownDelay   time.Duration
+	MaxBodyBytes    int64
+	BodyLimits      map[string]int64
+	CompressMinSize int
+	CompressLevel   int
+	IdempotencyTTL  time.Duration
+}
+
+type CORS struct {
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	AllowCredentials bool
+	MaxAge           time.Duration
+}
+
+type Cache struct {
+	// TTL is how long reads stay in the in-process cache; zero disables it.
+	TTL    time.Duration
+	MaxAge time.Duration
+}
+
+// Stats batches view counts; a zero FlushInterval disables view tracking.
+type Stats struct {
+	FlushInterval time.Duration
+	MaxPending    int
+}
+
+type Stream struct {
+	Heartbeat      time.Duration
+	PollInterval   time.Duration
+	MaxSubscribers int
+	History        int
+}
+
+// GRPC is disabled when Address is empty.
+type GRPC struct {
+	Address string
+}
+
+type GraphQL struct {
+	MaxDepth      int
+	MaxComplexity int
+}
+
+// Validation checks requests, and in debug mode responses, against the
+// OpenAPI document.
+type Validation struct {
+	ValidateRequests  bool
+	ValidateResponses bool
+}
+
+// Versioning dates the deprecation of the unversioned API paths.
+type Versioning struct {
+	DeprecatedAt time.Time
+	Sunset       time.Time
+}
+
+type WebSocket struct {
+	MinInterval    time.Duration
+	PingInterval   time.Duration
+	MaxConnections int
+}
+
+// Webhooks configures delivery; a zero Interval disables the dispatcher and
+// leaves events queued in the outbox.
+type Webhooks struct {
+	Interval    time.Duration
 	Timeout     time.Duration
-	IdleTimeout time.Duration
+	MaxAttempts int
+	Backoff     time.Duration
+	MaxBackoff  time.Duration
+}
+
+type Auth struct {
+	Enabled bool
+	JWT
+}
+
+type JWT struct {
+	JWKS     string
+	JWKSTTL  time.Duration
+	Issuer   string
+	Audience string
+	Leeway   time.Duration
+	// RequireExp rejects tokens without an expiry.
+	RequireExp bool
+	// AllowAdmin lets tokens carry the admin scope.
+	AllowAdmin bool
+}
+
+type RateLimit struct {
+	// Default applies to routes without an entry in Routes; empty disables it.
+	Default        string
+	Routes         map[string]string
+	TrustedProxies string
+	IdleTTL        time.Duration
+}
+
+type Tracing struct {
+	ServiceName  string
+	Exporter     string
+	File         string
+	OTLPEndpoint string
 }
 
 func MustLoad() *Config {
@@ -30,20 +151,215 @@ func MustLoad() *Config {
 		log.Fatal("Empty server address")
 	}
 	cfg.Address = addr
-	cfg.Timeout = parseDuration(os.Getenv("APP_TIMEOUT"))
-	cfg.IdleTimeout = parseDuration(os.Getenv("APP_IDLE_TIMEOUT"))
+	cfg.Timeout = parseDuration(os.Getenv("APP_TIMEOUT"), 10*time.Second)
+	cfg.IdleTimeout = parseDuration(os.Getenv("APP_IDLE_TIMEOUT"), 10*time.Second)
+	cfg.ShutdownTimeout = parseDuration(os.Getenv("APP_SHUTDOWN_TIMEOUT"), 10*time.Second)
+	cfg.ShutdownDelay = parseDuration(os.Getenv("APP_SHUTDOWN_DELAY"), 0)
 	db := os.Getenv("APP_DATABASE")
 	if db == "" {
 		log.Fatal("Empty databases configration")
 	}
 	cfg.Database = db
+	cfg.CompressMinSize = int(parseSize(os.Getenv("APP_COMPRESS_MIN_SIZE"), 1<<10))
+	cfg.CompressLevel = parseInt(os.Getenv("APP_COMPRESS_LEVEL"), gzip.DefaultCompression)
+	if cfg.CompressLevel < gzip.HuffmanOnly || cfg.CompressLevel > gzip.BestCompression {
+		log.Fatalf("APP_COMPRESS_LEVEL must be between %d and %d, got %d", gzip.HuffmanOnly, gzip.BestCompression, cfg.CompressLevel)
+	}
+	cfg.IdempotencyTTL = parseDuration(os.Getenv("APP_IDEMPOTENCY_TTL"), 24*time.Hour)
+	cfg.MaxBodyBytes = parseSize(os.Getenv("APP_MAX_BODY_SIZE"), 1<<20)
+	cfg.BodyLimits = map[string]int64{}
+	for route, size := range parseRules("APP_BODY_LIMITS", defaultBodyLimits) {
+		cfg.BodyLimits[route] = parseSize(size, cfg.MaxBodyBytes)
+	}
+	cfg.CORS = CORS{
+		AllowedOrigins:   parseList(os.Getenv("APP_CORS_ORIGINS"), nil),
+		AllowedMethods:   parseList(os.Getenv("APP_CORS_METHODS"), []string{"GET", "POST", "PUT", "DELETE"}),
+		AllowedHeaders:   parseList(os.Getenv("APP_CORS_HEADERS"), []string{"Content-Type", "Authorization", "X-API-Key", "If-None-Match", "Idempotency-Key"}),
+		AllowCredentials: parseBool(os.Getenv("APP_CORS_ALLOW_CREDENTIALS"), false),
+		MaxAge:           parseDuration(os.Getenv("APP_CORS_MAX_AGE"), 10*time.Minute),
+	}
+	cfg.Cache = Cache{
+		TTL:    parseDuration(os.Getenv("APP_CACHE_TTL"), 30*time.Second),
+		MaxAge: parseDuration(os.Getenv("APP_CACHE_MAX_AGE"), 0),
+	}
+	cfg.Stats = Stats{
+		FlushInterval: parseDuration(os.Getenv("APP_STATS_FLUSH_INTERVAL"), 5*time.Second),
+		MaxPending:    parseInt(os.Getenv("APP_STATS_MAX_PENDING"), 1000),
+	}
+	cfg.Webhooks = Webhooks{
+		Interval:    parseDuration(os.Getenv("APP_WEBHOOK_INTERVAL"), time.Second),
+		Timeout:     parseDuration(os.Getenv("APP_WEBHOOK_TIMEOUT"), 5*time.Second),
+		MaxAttempts: parseInt(os.Getenv("APP_WEBHOOK_MAX_ATTEMPTS"), 8),
+		Backoff:     parseDuration(os.Getenv("APP_WEBHOOK_BACKOFF"), 10*time.Second),
+		MaxBackoff:  parseDuration(os.Getenv("APP_WEBHOOK_MAX_BACKOFF"), time.Hour),
+	}
+	cfg.Stream = Stream{
+		Heartbeat:      parseDuration(os.Getenv("APP_STREAM_HEARTBEAT"), 15*time.Second),
+		PollInterval:   parseDuration(os.Getenv("APP_STREAM_POLL_INTERVAL"), 500*time.Millisecond),
+		MaxSubscribers: parseInt(os.Getenv("APP_STREAM_MAX_SUBSCRIBERS"), 100),
+		History:        parseInt(os.Getenv("APP_STREAM_HISTORY"), 10000),
+	}
+	cfg.WebSocket = WebSocket{
+		MinInterval:    parseDuration(os.Getenv("APP_WS_MIN_INTERVAL"), time.Second),
+		PingInterval:   parseDuration(os.Getenv("APP_WS_PING_INTERVAL"), 30*time.Second),
+		MaxConnections: parseInt(os.Getenv("APP_WS_MAX_CONNECTIONS"), 100),
+	}
+	cfg.GRPC.Address = os.Getenv("APP_GRPC_ADDR")
+	cfg.GraphQL = GraphQL{
+		MaxDepth:      parseInt(os.Getenv("APP_GRAPHQL_MAX_DEPTH"), 8),
+		MaxComplexity: parseInt(os.Getenv("APP_GRAPHQL_MAX_COMPLEXITY"), 1000),
+	}
+	cfg.Validation = Validation{
+		ValidateRequests:  parseBool(os.Getenv("APP_VALIDATE_REQUESTS"), true),
+		ValidateResponses: parseBool(os.Getenv("APP_VALIDATE_RESPONSES"), false),
+	}
+	cfg.Versioning = Versioning{
+		DeprecatedAt: parseTime(os.Getenv("APP_API_DEPRECATED_AT"), time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)),
+		Sunset:       parseTime(os.Getenv("APP_API_SUNSET"), time.Date(2027, 4, 19, 0, 0, 0, 0, time.UTC)),
+	}
+	cfg.Tracing = loadTracing()
+	cfg.RateLimit = loadRateLimit()
+	// Scopes are enforced unless explicitly turned off, e.g. for local runs.
+	cfg.Auth.Enabled = parseBool(os.Getenv("APP_AUTH_ENABLED"), true)
+	cfg.Auth.JWT = JWT{
+		JWKS:       os.Getenv("APP_JWT_JWKS"),
+		JWKSTTL:    parseDuration(os.Getenv("APP_JWT_JWKS_TTL"), 15*time.Minute),
+		Issuer:     os.Getenv("APP_JWT_ISSUER"),
+		Audience:   os.Getenv("APP_JWT_AUDIENCE"),
+		Leeway:     parseDuration(os.Getenv("APP_JWT_LEEWAY"), 30*time.Second),
+		RequireExp: parseBool(os.Getenv("APP_JWT_REQUIRE_EXP"), true),
+		AllowAdmin: parseBool(os.Getenv("APP_JWT_ALLOW_ADMIN"), false),
+	}
 	return &cfg
 }
 
-func parseDuration(times string) time.Duration {
+func parseList(value string, fallback []string) []string {
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	if len(list) == 0 {
+		return fallback
+	}
+	return list
+}
+
+// parseSize accepts plain byte counts or values with a KB/MB suffix.
+func parseSize(value string, fallback int64) int64 {
+	value = strings.ToUpper(strings.TrimSpace(value))
+	multiplier := int64(1)
+	switch {
+	case strings.HasSuffix(value, "MB"):
+		multiplier, value = 1<<20, strings.TrimSuffix(value, "MB")
+	case strings.HasSuffix(value, "KB"):
+		multiplier, value = 1<<10, strings.TrimSuffix(value, "KB")
+	case strings.HasSuffix(value, "B"):
+		value = strings.TrimSuffix(value, "B")
+	}
+	size, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+	if err != nil || size <= 0 {
+		return fallback
+	}
+	return size * multiplier
+}
+
+func parseInt(value string, fallback int) int {
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return fallback
+	}
+	return parsed
+}
+
+func parseBool(value string, fallback bool) bool {
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return fallback
+	}
+	return parsed
+}
+
+func parseTime(value string, fallback time.Time) time.Time {
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return fallback
+	}
+	return parsed
+}
+
+func parseDuration(times string, fallback time.Duration) time.Duration {
 	parsedTime, err := time.ParseDuration(times)
 	if err != nil {
-		parsedTime = time.Second * 10
+		parsedTime = fallback
 	}
 	return parsedTime
 }
+
+func loadRateLimit() RateLimit {
+	rl := RateLimit{
+		Default:        os.Getenv("APP_RATE_LIMIT_DEFAULT"),
+		Routes:         parseRules("APP_RATE_LIMITS", ""),
+		TrustedProxies: os.Getenv("APP_TRUSTED_PROXIES"),
+		IdleTTL:        parseDuration(os.Getenv("APP_RATE_LIMIT_IDLE_TTL"), 10*time.Minute),
+	}
+	if rl.IdleTTL <= 0 {
+		log.Fatalf("APP_RATE_LIMIT_IDLE_TTL must be positive, got %s", rl.IdleTTL)
+	}
+	return rl
+}
+
+// defaultBodyLimits keep the write routes well under APP_MAX_BODY_SIZE when
+// APP_BODY_LIMITS is not set.
+const defaultBodyLimits = "POST /quotes=16KB;PUT /quotes/{id}=16KB;POST /admin/keys=4KB"
+
+// parseRules reads per-route settings written as "<route pattern>=<value>"
+// pairs separated by ";", e.g. "POST /quotes=10/m:5;GET /quotes=120/m".
+// def is used when the variable is not set at all.
+func parseRules(key, def string) map[string]string {
+	v, ok := os.LookupEnv(key)
+	if !ok {
+		v = def
+	}
+	rules := map[string]string{}
+	for _, rule := range strings.Split(v, ";") {
+		if strings.TrimSpace(rule) == "" {
+			continue
+		}
+		route, value, ok := strings.Cut(rule, "=")
+		if !ok {
+			log.Fatalf("Invalid %s rule %q", key, rule)
+		}
+		rules[strings.TrimSpace(route)] = strings.TrimSpace(value)
+	}
+	return rules
+}
+
+func loadTracing() Tracing {
+	tr := Tracing{
+		ServiceName:  os.Getenv("APP_SERVICE_NAME"),
+		Exporter:     os.Getenv("APP_TRACING_EXPORTER"),
+		File:         os.Getenv("APP_TRACING_FILE"),
+		OTLPEndpoint: os.Getenv("APP_OTLP_ENDPOINT"),
+	}
+	if tr.ServiceName == "" {
+		tr.ServiceName = "quotes-mini-service"
+	}
+	switch tr.Exporter {
+	case "", "none":
+		tr.Exporter = "none"
+	case "stdout":
+	case "file":
+		if tr.File == "" {
+			log.Fatal("Empty tracing file for file exporter")
+		}
+	case "otlp":
+		if tr.OTLPEndpoint == "" {
+			tr.OTLPEndpoint = "http://localhost:4318"
+		}
+	default:
+		log.Fatalf("Unknown tracing exporter %q", tr.Exporter)
+	}
+	return tr
+}
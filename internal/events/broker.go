@@ -0,0 +1,168 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"quotes-mini-service/pkg/sl"
+	"sync"
+	"time"
+)
+
+var (
+	ErrTooManySubscribers = errors.New("too many subscribers")
+	ErrClosed             = errors.New("broker closed")
+)
+
+const (
+	pollBatch     = 500
+	subscriberBuf = 64
+	pruneInterval = time.Minute
+)
+
+// Subscription receives live events. C is closed when the subscriber falls
+// too far behind or the broker shuts down; the client is expected to
+// reconnect and resume from the last sequence number it saw.
+type Subscription struct {
+	C      <-chan Event
+	c      chan Event
+	broker *Broker
+}
+
+func (s *Subscription) Close() {
+	s.broker.unsubscribe(s)
+}
+
+type BrokerOptions struct {
+	PollInterval   time.Duration
+	MaxSubscribers int
+	// History is how many events are kept for resuming streams.
+	History int
+}
+
+// Broker polls the event table and fans new events out to subscribers.
+// Polling rather than in-process notification keeps it correct when
+// several instances share the database.
+type Broker struct {
+	log  *slog.Logger
+	repo *EventsRepository
+	opts BrokerOptions
+
+	mu     sync.Mutex
+	subs   map[*Subscription]struct{}
+	closed bool
+}
+
+func NewBroker(log *slog.Logger, repo *EventsRepository, opts BrokerOptions) *Broker {
+	return &Broker{
+		log:  log.With(slog.String("component", "events/broker")),
+		repo: repo,
+		opts: opts,
+		subs: map[*Subscription]struct{}{},
+	}
+}
+
+// Since reads stored events for replaying a resumed stream.
+func (b *Broker) Since(ctx context.Context, seq int64, limit int) ([]Event, error) {
+	return b.repo.Since(ctx, seq, limit)
+}
+
+func (b *Broker) Subscribe() (*Subscription, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return nil, ErrClosed
+	}
+	if len(b.subs) >= b.opts.MaxSubscribers {
+		return nil, ErrTooManySubscribers
+	}
+	c := make(chan Event, subscriberBuf)
+	s := &Subscription{C: c, c: c, broker: b}
+	b.subs[s] = struct{}{}
+	return s, nil
+}
+
+func (b *Broker) unsubscribe(s *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.subs[s]; ok {
+		delete(b.subs, s)
+		close(s.c)
+	}
+}
+
+func (b *Broker) Subscribers() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.subs)
+}
+
+// Close ends every subscription and rejects new ones. It is meant to be
+// registered with http.Server.RegisterOnShutdown so that open streams do
+// not hold up a graceful shutdown.
+func (b *Broker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	for s := range b.subs {
+		delete(b.subs, s)
+		close(s.c)
+	}
+}
+
+// Run polls for new events until ctx is cancelled.
+func (b *Broker) Run(ctx context.Context) {
+	last, err := b.repo.Latest(ctx)
+	if err != nil {
+		b.log.Error("failed to read latest event", sl.Err(err))
+	}
+	poll := time.NewTicker(b.opts.PollInterval)
+	defer poll.Stop()
+	prune := time.NewTicker(pruneInterval)
+	defer prune.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-poll.C:
+			last = b.poll(ctx, last)
+		case <-prune.C:
+			if err := b.repo.Prune(ctx, b.opts.History); err != nil && ctx.Err() == nil {
+				b.log.Error("failed to prune events", sl.Err(err))
+			}
+		}
+	}
+}
+
+func (b *Broker) poll(ctx context.Context, last int64) int64 {
+	for {
+		events, err := b.repo.Since(ctx, last, pollBatch)
+		if err != nil {
+			if ctx.Err() == nil {
+				b.log.Error("failed to poll events", sl.Err(err))
+			}
+			return last
+		}
+		for _, e := range events {
+			b.publish(e)
+			last = e.Seq
+		}
+		if len(events) < pollBatch {
+			return last
+		}
+	}
+}
+
+func (b *Broker) publish(e Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for s := range b.subs {
+		select {
+		case s.c <- e:
+		default:
+			// Never block the broker on a slow client.
+			delete(b.subs, s)
+			close(s.c)
+		}
+	}
+}
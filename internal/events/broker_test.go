@@ -0,0 +1,117 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"quotes-mini-service/internal/storage"
+	"testing"
+	"time"
+)
+
+func setupTestDB(t *testing.T) *storage.Db {
+	db, err := storage.NewStorage(":memory:")
+	if err != nil {
+		t.Fatalf("failed to create test database: %v", err)
+	}
+	return db
+}
+
+func newTestBroker(db *storage.Db, max int) *Broker {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	return NewBroker(log, NewEventsRepository(db), BrokerOptions{
+		PollInterval:   10 * time.Millisecond,
+		MaxSubscribers: max,
+		History:        2,
+	})
+}
+
+func TestEventsRepository(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	repo := NewEventsRepository(db)
+	ctx := context.Background()
+
+	for i := 1; i <= 3; i++ {
+		if err := Append(ctx, db, TypeCreated, i, map[string]int{"id": i}); err != nil {
+			t.Fatalf("failed to append: %v", err)
+		}
+	}
+	events, err := repo.Since(ctx, 1, 10)
+	if err != nil || len(events) != 2 || events[0].Seq != 2 || string(events[0].Data) != `{"id":2}` {
+		t.Fatalf("unexpected events %+v (%v)", events, err)
+	}
+	if err = repo.Prune(ctx, 1); err != nil {
+		t.Fatalf("failed to prune: %v", err)
+	}
+	if events, _ = repo.Since(ctx, 0, 10); len(events) != 1 || events[0].Seq != 3 {
+		t.Errorf("expected only the newest event to be kept, got %+v", events)
+	}
+	// Sequence numbers are not reused after pruning.
+	Append(ctx, db, TypeDeleted, 3, nil)
+	if latest, _ := repo.Latest(ctx); latest != 4 {
+		t.Errorf("expected latest seq 4, got %d", latest)
+	}
+}
+
+func TestBroker(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	broker := newTestBroker(db, 2)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go broker.Run(ctx)
+
+	a, err := broker.Subscribe()
+	if err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+	b, _ := broker.Subscribe()
+	if _, err = broker.Subscribe(); !errors.Is(err, ErrTooManySubscribers) {
+		t.Errorf("expected ErrTooManySubscribers, got %v", err)
+	}
+	b.Close()
+
+	time.Sleep(20 * time.Millisecond)
+	Append(ctx, db, TypeCreated, 1, map[string]int{"id": 1})
+	select {
+	case e := <-a.C:
+		if e.Type != TypeCreated || e.QuoteID != 1 {
+			t.Errorf("unexpected event %+v", e)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for event")
+	}
+
+	broker.Close()
+	if _, ok := <-a.C; ok {
+		t.Error("expected subscription to be closed")
+	}
+	if _, err = broker.Subscribe(); !errors.Is(err, ErrClosed) {
+		t.Errorf("expected ErrClosed, got %v", err)
+	}
+	a.Close()
+}
+
+func TestBroker_DropsSlowSubscriber(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	broker := newTestBroker(db, 1)
+
+	s, _ := broker.Subscribe()
+	for i := range subscriberBuf + 1 {
+		broker.publish(Event{Seq: int64(i + 1)})
+	}
+	if broker.Subscribers() != 0 {
+		t.Error("expected slow subscriber to be dropped")
+	}
+	n := 0
+	for range s.C {
+		n++
+	}
+	if n != subscriberBuf {
+		t.Errorf("expected %d buffered events, got %d", subscriberBuf, n)
+	}
+	s.Close()
+}
@@ -0,0 +1,104 @@
+package events
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"quotes-mini-service/internal/storage"
+	"time"
+)
+
+const (
+	TypeCreated = "created"
+	TypeUpdated = "updated"
+	TypeDeleted = "deleted"
+)
+
+// Event is one entry of the quote change sequence. Seq is strictly
+// increasing and never reused, so clients can resume after it.
+type Event struct {
+	Seq       int64           `json:"seq"`
+	Type      string          `json:"type"`
+	QuoteID   int             `json:"quote_id"`
+	Data      json.RawMessage `json:"data"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// Execer is satisfied by *sql.Tx, so that events are appended in the same
+// transaction as the change they describe.
+type Execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+func Append(ctx context.Context, tx Execer, typ string, quoteID int, data any) error {
+	const op = "events.Append"
+	b, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("%s: encode data: %w", op, err)
+	}
+	_, err = tx.ExecContext(ctx, "INSERT INTO quote_events(type, quote_id, data) VALUES(?, ?, ?)",
+		typ, quoteID, string(b))
+	if err != nil {
+		return fmt.Errorf("%s: insert event: %w", op, err)
+	}
+	return nil
+}
+
+type EventsRepository struct {
+	Database *storage.Db
+}
+
+func NewEventsRepository(database *storage.Db) *EventsRepository {
+	return &EventsRepository{
+		Database: database,
+	}
+}
+
+// Since returns up to limit events with a sequence number greater than seq.
+func (repo *EventsRepository) Since(ctx context.Context, seq int64, limit int) ([]Event, error) {
+	const op = "events.repository.Since"
+	rows, err := repo.Database.QueryContext(ctx,
+		"SELECT seq, type, quote_id, data, created_at FROM quote_events WHERE seq > ? ORDER BY seq LIMIT ?",
+		seq, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%s: query execution: %w", op, err)
+	}
+	defer rows.Close()
+	var events []Event
+	for rows.Next() {
+		var (
+			e    Event
+			data string
+		)
+		if err := rows.Scan(&e.Seq, &e.Type, &e.QuoteID, &data, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("%s: scan row: %w", op, err)
+		}
+		e.Data = json.RawMessage(data)
+		events = append(events, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: iterate rows: %w", op, err)
+	}
+	return events, nil
+}
+
+func (repo *EventsRepository) Latest(ctx context.Context) (int64, error) {
+	const op = "events.repository.Latest"
+	var seq int64
+	if err := repo.Database.QueryRowContext(ctx, "SELECT COALESCE(MAX(seq), 0) FROM quote_events").Scan(&seq); err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return seq, nil
+}
+
+// Prune keeps only the newest keep events.
+func (repo *EventsRepository) Prune(ctx context.Context, keep int) error {
+	const op = "events.repository.Prune"
+	_, err := repo.Database.ExecContext(ctx,
+		"DELETE FROM quote_events WHERE seq <= (SELECT COALESCE(MAX(seq), 0) FROM quote_events) - ?", keep)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
@@ -0,0 +1,427 @@
+package graph
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// The GraphQL library limits depth but not cost, so queries are parsed a
+// second time here and scored before execution. Every field costs 1; the
+// children of a paginated field cost as much as the page size asks for.
+
+var errSyntax = errors.New("syntax error")
+
+type selection struct {
+	name     string
+	args     map[string]value
+	children []selection
+	spread   string
+	fragment bool
+	isSpread bool
+}
+
+type value struct {
+	variable string
+	literal  string
+}
+
+type operation struct {
+	defaults  map[string]string
+	selection []selection
+}
+
+type document struct {
+	operations []operation
+	fragments  map[string][]selection
+}
+
+// complexity returns the highest cost among the operations in query.
+// Paginated fields are the ones named in pageFields; their page size is
+// taken from the first argument or, failing that, from pageFields.
+func complexity(query string, variables map[string]any, pageFields map[string]int, maxPage int) (int, error) {
+	doc, err := parseDocument(query)
+	if err != nil {
+		return 0, err
+	}
+	highest := 0
+	for _, op := range doc.operations {
+		c := &coster{doc: doc, op: op, variables: variables, pageFields: pageFields, maxPage: maxPage, visiting: map[string]bool{}}
+		cost, err := c.cost(op.selection)
+		if err != nil {
+			return 0, err
+		}
+		highest = max(highest, cost)
+	}
+	return highest, nil
+}
+
+type coster struct {
+	doc        *document
+	op         operation
+	variables  map[string]any
+	pageFields map[string]int
+	maxPage    int
+	visiting   map[string]bool
+}
+
+func (c *coster) cost(set []selection) (int, error) {
+	total := 0
+	for _, s := range set {
+		var (
+			cost int
+			err  error
+		)
+		switch {
+		case s.isSpread:
+			children, ok := c.doc.fragments[s.spread]
+			if !ok {
+				return 0, fmt.Errorf("unknown fragment %q", s.spread)
+			}
+			if c.visiting[s.spread] {
+				return 0, fmt.Errorf("fragment %q spreads itself", s.spread)
+			}
+			c.visiting[s.spread] = true
+			cost, err = c.cost(children)
+			c.visiting[s.spread] = false
+		case s.fragment:
+			cost, err = c.cost(s.children)
+		default:
+			cost, err = c.cost(s.children)
+			if size, ok := c.pageFields[s.name]; ok {
+				cost *= c.pageSize(s, size)
+			}
+			cost++
+		}
+		if err != nil {
+			return 0, err
+		}
+		total = min(total+cost, math.MaxInt32)
+	}
+	return total, nil
+}
+
+func (c *coster) pageSize(s selection, fallback int) int {
+	arg, ok := s.args["first"]
+	if !ok {
+		return fallback
+	}
+	raw := arg.literal
+	if arg.variable != "" {
+		switch v := c.variables[arg.variable].(type) {
+		case float64:
+			raw = strconv.FormatFloat(v, 'f', -1, 64)
+		case int:
+			raw = strconv.Itoa(v)
+		case nil:
+			raw = c.op.defaults[arg.variable]
+		default:
+			raw = fmt.Sprint(v)
+		}
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil {
+		return fallback
+	}
+	return max(0, min(n, c.maxPage))
+}
+
+type parser struct {
+	lex *lexer
+	tok token
+}
+
+func parseDocument(query string) (doc *document, err error) {
+	p := &parser{lex: &lexer{src: query}}
+	defer func() {
+		if r := recover(); r != nil {
+			perr, ok := r.(parseError)
+			if !ok {
+				panic(r)
+			}
+			doc, err = nil, fmt.Errorf("%w: %s", errSyntax, string(perr))
+		}
+	}()
+	p.next()
+	doc = &document{fragments: map[string][]selection{}}
+	for p.tok.kind != tokEOF {
+		switch {
+		case p.tok.is(tokPunct, "{"):
+			doc.operations = append(doc.operations, operation{selection: p.selectionSet()})
+		case p.tok.is(tokName, "query"), p.tok.is(tokName, "mutation"), p.tok.is(tokName, "subscription"):
+			doc.operations = append(doc.operations, p.operation())
+		case p.tok.is(tokName, "fragment"):
+			p.next()
+			name := p.expect(tokName, "").text
+			p.expect(tokName, "on")
+			p.expect(tokName, "")
+			p.directives()
+			doc.fragments[name] = p.selectionSet()
+		default:
+			p.fail("unexpected %q", p.tok.text)
+		}
+	}
+	return doc, nil
+}
+
+func (p *parser) operation() operation {
+	p.next()
+	op := operation{defaults: map[string]string{}}
+	if p.tok.kind == tokName {
+		p.next()
+	}
+	if p.tok.is(tokPunct, "(") {
+		p.next()
+		for !p.tok.is(tokPunct, ")") {
+			p.expect(tokPunct, "$")
+			name := p.expect(tokName, "").text
+			p.expect(tokPunct, ":")
+			p.typeRef()
+			if p.tok.is(tokPunct, "=") {
+				p.next()
+				op.defaults[name] = p.value().literal
+			}
+			p.directives()
+		}
+		p.next()
+	}
+	p.directives()
+	op.selection = p.selectionSet()
+	return op
+}
+
+func (p *parser) typeRef() {
+	if p.tok.is(tokPunct, "[") {
+		p.next()
+		p.typeRef()
+		p.expect(tokPunct, "]")
+	} else {
+		p.expect(tokName, "")
+	}
+	if p.tok.is(tokPunct, "!") {
+		p.next()
+	}
+}
+
+func (p *parser) selectionSet() []selection {
+	p.expect(tokPunct, "{")
+	var set []selection
+	for !p.tok.is(tokPunct, "}") {
+		if p.tok.kind == tokEOF {
+			p.fail("unterminated selection set")
+		}
+		set = append(set, p.selection())
+	}
+	p.next()
+	return set
+}
+
+func (p *parser) selection() selection {
+	if p.tok.is(tokPunct, "...") {
+		p.next()
+		if p.tok.kind == tokName && p.tok.text != "on" {
+			s := selection{isSpread: true, spread: p.tok.text}
+			p.next()
+			p.directives()
+			return s
+		}
+		if p.tok.is(tokName, "on") {
+			p.next()
+			p.expect(tokName, "")
+		}
+		p.directives()
+		return selection{fragment: true, children: p.selectionSet()}
+	}
+	s := selection{name: p.expect(tokName, "").text}
+	if p.tok.is(tokPunct, ":") {
+		p.next()
+		s.name = p.expect(tokName, "").text
+	}
+	s.args = p.arguments()
+	p.directives()
+	if p.tok.is(tokPunct, "{") {
+		s.children = p.selectionSet()
+	}
+	return s
+}
+
+func (p *parser) arguments() map[string]value {
+	args := map[string]value{}
+	if !p.tok.is(tokPunct, "(") {
+		return args
+	}
+	p.next()
+	for !p.tok.is(tokPunct, ")") {
+		name := p.expect(tokName, "").text
+		p.expect(tokPunct, ":")
+		args[name] = p.value()
+	}
+	p.next()
+	return args
+}
+
+func (p *parser) directives() {
+	for p.tok.is(tokPunct, "@") {
+		p.next()
+		p.expect(tokName, "")
+		p.arguments()
+	}
+}
+
+func (p *parser) value() value {
+	switch {
+	case p.tok.is(tokPunct, "$"):
+		p.next()
+		return value{variable: p.expect(tokName, "").text}
+	case p.tok.is(tokPunct, "["):
+		p.next()
+		for !p.tok.is(tokPunct, "]") {
+			if p.tok.kind == tokEOF {
+				p.fail("unterminated list")
+			}
+			p.value()
+		}
+		p.next()
+		return value{}
+	case p.tok.is(tokPunct, "{"):
+		p.next()
+		for !p.tok.is(tokPunct, "}") {
+			p.expect(tokName, "")
+			p.expect(tokPunct, ":")
+			p.value()
+		}
+		p.next()
+		return value{}
+	case p.tok.kind == tokName, p.tok.kind == tokNumber, p.tok.kind == tokString:
+		v := value{literal: p.tok.text}
+		p.next()
+		return v
+	}
+	p.fail("unexpected %q", p.tok.text)
+	return value{}
+}
+
+func (p *parser) next() {
+	p.tok = p.lex.next()
+}
+
+// expect consumes a token of kind and, when text is not empty, that text.
+func (p *parser) expect(kind tokenKind, text string) token {
+	if p.tok.kind != kind || (text != "" && p.tok.text != text) {
+		p.fail("unexpected %q", p.tok.text)
+	}
+	t := p.tok
+	p.next()
+	return t
+}
+
+type parseError string
+
+func (p *parser) fail(format string, args ...any) {
+	panic(parseError(fmt.Sprintf(format, args...)))
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokPunct
+	tokName
+	tokNumber
+	tokString
+)
+
+type token struct {
+	kind tokenKind
+	text string
+}
+
+func (t token) is(kind tokenKind, text string) bool {
+	return t.kind == kind && t.text == text
+}
+
+type lexer struct {
+	src string
+	pos int
+}
+
+func (l *lexer) next() token {
+	l.skipIgnored()
+	if l.pos >= len(l.src) {
+		return token{kind: tokEOF}
+	}
+	c := l.src[l.pos]
+	switch {
+	case strings.HasPrefix(l.src[l.pos:], "..."):
+		l.pos += 3
+		return token{kind: tokPunct, text: "..."}
+	case strings.IndexByte("!$&():=@[]{}|", c) >= 0:
+		l.pos++
+		return token{kind: tokPunct, text: string(c)}
+	case c == '_' || isLetter(c):
+		start := l.pos
+		for l.pos < len(l.src) && (l.src[l.pos] == '_' || isLetter(l.src[l.pos]) || isDigit(l.src[l.pos])) {
+			l.pos++
+		}
+		return token{kind: tokName, text: l.src[start:l.pos]}
+	case c == '-' || isDigit(c):
+		start := l.pos
+		l.pos++
+		for l.pos < len(l.src) && (isDigit(l.src[l.pos]) || strings.IndexByte(".eE+-", l.src[l.pos]) >= 0) {
+			l.pos++
+		}
+		return token{kind: tokNumber, text: l.src[start:l.pos]}
+	case strings.HasPrefix(l.src[l.pos:], `"""`):
+		start := l.pos + 3
+		for l.pos = start; l.pos < len(l.src) && !strings.HasPrefix(l.src[l.pos:], `"""`); l.pos++ {
+			if strings.HasPrefix(l.src[l.pos:], `\"""`) {
+				l.pos += 3
+			}
+		}
+		if l.pos >= len(l.src) {
+			panic(parseError("unterminated block string"))
+		}
+		l.pos += 3
+		return token{kind: tokString, text: l.src[start : l.pos-3]}
+	case c == '"':
+		start := l.pos + 1
+		for l.pos = start; l.pos < len(l.src) && l.src[l.pos] != '"' && l.src[l.pos] != '\n'; l.pos++ {
+			if l.src[l.pos] == '\\' {
+				l.pos++
+			}
+		}
+		if l.pos >= len(l.src) || l.src[l.pos] != '"' {
+			panic(parseError("unterminated string"))
+		}
+		l.pos++
+		return token{kind: tokString, text: l.src[start : l.pos-1]}
+	}
+	panic(parseError(fmt.Sprintf("unexpected character %q", c)))
+}
+
+func (l *lexer) skipIgnored() {
+	for l.pos < len(l.src) {
+		switch c := l.src[l.pos]; {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',':
+			l.pos++
+		case c == '#':
+			for l.pos < len(l.src) && l.src[l.pos] != '\n' {
+				l.pos++
+			}
+		case strings.HasPrefix(l.src[l.pos:], "\uFEFF"):
+			l.pos += len("\uFEFF")
+		default:
+			return
+		}
+	}
+}
+
+func isLetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
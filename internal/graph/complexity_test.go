@@ -0,0 +1,108 @@
+package graph
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestComplexity(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		variables map[string]any
+		want      int
+	}{
+		{
+			name:  "flat fields",
+			query: `{ random { id text } }`,
+			want:  3,
+		},
+		{
+			name:  "default page size",
+			query: `{ quotes { nodes { id } } }`,
+			want:  1 + 20*2,
+		},
+		{
+			name:  "first literal",
+			query: `{ quotes(first: 5) { totalCount nodes { id text } } }`,
+			want:  1 + 5*(1+1+2),
+		},
+		{
+			name:  "first capped",
+			query: `{ quotes(first: 1000) { nodes { id } } }`,
+			want:  1 + 100*2,
+		},
+		{
+			name:      "first from variable",
+			query:     `query Q($n: Int!) { quotes(first: $n) { nodes { id } } }`,
+			variables: map[string]any{"n": float64(3)},
+			want:      1 + 3*2,
+		},
+		{
+			name:  "first from variable default",
+			query: `query Q($n: Int! = 2) { quotes(first: $n) { nodes { id } } }`,
+			want:  1 + 2*2,
+		},
+		{
+			name:  "nested pages multiply",
+			query: `{ quotes(first: 10) { nodes { author { quotes(first: 10) { id } } } } }`,
+			want:  1 + 10*(1+1+(1+10*1)),
+		},
+		{
+			name: "fragments and aliases",
+			query: `
+				query {
+					a: random { ...Fields }
+					b: quote(id: "1") { ... on Quote { id } }
+				}
+				fragment Fields on Quote { id text tags @include(if: true) }`,
+			want: (1 + 3) + (1 + 1),
+		},
+		{
+			name:  "highest operation wins",
+			query: `query A { random { id } } query B { quotes(first: 2) { nodes { id } } }`,
+			want:  1 + 2*2,
+		},
+		{
+			name:  "mutation with strings",
+			query: `mutation { addQuote(author: "a \"b\"", text: """block""", tags: ["x", "y"]) { id } }`,
+			want:  2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := complexity(tt.query, tt.variables, pageFields, maxFirst)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("expected complexity %d, got %d", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestComplexity_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		query  string
+		syntax bool
+	}{
+		{name: "unterminated selection", query: `{ quotes { nodes { id }`, syntax: true},
+		{name: "unterminated string", query: `{ random(author: "abc) { id } }`, syntax: true},
+		{name: "unexpected character", query: `{ random % }`, syntax: true},
+		{name: "unknown fragment", query: `{ random { ...Missing } }`},
+		{name: "fragment cycle", query: `{ random { ...A } } fragment A on Quote { ...A }`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := complexity(tt.query, nil, pageFields, maxFirst)
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if tt.syntax != errors.Is(err, errSyntax) {
+				t.Errorf("unexpected error kind: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package graph
+
+import (
+	_ "embed"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"quotes-mini-service/pkg/decode"
+	"quotes-mini-service/pkg/res"
+	"quotes-mini-service/pkg/sl"
+	"quotes-mini-service/pkg/tracing"
+
+	"github.com/graph-gophers/graphql-go"
+	gqlerrors "github.com/graph-gophers/graphql-go/errors"
+)
+
+//go:embed schema.graphql
+var schemaSDL string
+
+// pageFields are the fields whose children are repeated once per item, with
+// the page size they default to.
+var pageFields = map[string]int{"quotes": 20}
+
+type Request struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName,omitempty"`
+	Variables     map[string]any `json:"variables,omitempty"`
+}
+
+type Options struct {
+	MaxDepth      int
+	MaxComplexity int
+	// RequireScopes checks the write and delete scopes on mutations.
+	RequireScopes bool
+}
+
+// New serves GraphQL queries over POST. Errors are reported the GraphQL
+// way, in the errors field of a 200 response; only undecodable requests get
+// a 4xx.
+func New(log *slog.Logger, store Store, source BatchSource, opts Options) http.HandlerFunc {
+	schema := graphql.MustParseSchema(schemaSDL, &resolver{
+		log:           log,
+		store:         store,
+		requireScopes: opts.RequireScopes,
+	}, graphql.UseStringDescriptions(), graphql.MaxDepth(opts.MaxDepth),
+		// A whole page has to be resolving at once for the loaders to batch it.
+		graphql.MaxParallelism(maxFirst))
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "graph.New"
+		log := log.With(
+			slog.String("op", op),
+		)
+		ctx, span := tracing.Start(r.Context(), op)
+		defer span.End()
+		var req Request
+		if err := decode.JSON(r.Body, &req); err != nil {
+			log.Error("failed to decode request body", sl.Err(err))
+			res.Json(w, res.Error(decode.Message(err)), decode.Status(err))
+			return
+		}
+		if req.Query == "" {
+			res.Json(w, res.Error("query is required"), http.StatusBadRequest)
+			return
+		}
+		if errs := schema.ValidateWithVariables(req.Query, req.Variables); len(errs) > 0 {
+			res.Json(w, &graphql.Response{Errors: errs}, http.StatusOK)
+			return
+		}
+		cost, err := complexity(req.Query, req.Variables, pageFields, maxFirst)
+		if err != nil {
+			log.Error("failed to score query", sl.Err(err))
+			res.Json(w, failure("failed to score query"), http.StatusOK)
+			return
+		}
+		if opts.MaxComplexity > 0 && cost > opts.MaxComplexity {
+			log.Info("query too complex", slog.Int("complexity", cost))
+			res.Json(w, failure(fmt.Sprintf("query complexity %d exceeds the limit of %d", cost, opts.MaxComplexity)), http.StatusOK)
+			return
+		}
+		ctx = withLoaders(ctx, newLoaders(source))
+		resp := schema.Exec(ctx, req.Query, req.OperationName, req.Variables)
+		if len(resp.Errors) > 0 {
+			log.Debug("query returned errors", slog.Any("errors", resp.Errors))
+		}
+		res.Json(w, resp, http.StatusOK)
+	}
+}
+
+func failure(msg string) *graphql.Response {
+	return &graphql.Response{Errors: []*gqlerrors.QueryError{{Message: msg}}}
+}
@@ -0,0 +1,248 @@
+package graph
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"quotes-mini-service/internal/quote"
+	"quotes-mini-service/internal/storage"
+	"quotes-mini-service/pkg/middleware"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// countingSource counts the bulk queries a request makes.
+type countingSource struct {
+	*quote.QuotesRepository
+	tags, counts, lists atomic.Int32
+}
+
+func (s *countingSource) TagsByQuotes(ctx context.Context, ids []int) (map[int][]string, error) {
+	s.tags.Add(1)
+	return s.QuotesRepository.TagsByQuotes(ctx, ids)
+}
+
+func (s *countingSource) CountByAuthors(ctx context.Context, authors []string) (map[string]int, error) {
+	s.counts.Add(1)
+	return s.QuotesRepository.CountByAuthors(ctx, authors)
+}
+
+func (s *countingSource) ListByAuthors(ctx context.Context, authors []string, limit, offset int) (map[string][]quote.Quote, error) {
+	s.lists.Add(1)
+	return s.QuotesRepository.ListByAuthors(ctx, authors, limit, offset)
+}
+
+type response struct {
+	Data   map[string]json.RawMessage `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+func setup(t *testing.T, opts Options) (http.Handler, *countingSource) {
+	db, err := storage.NewStorage(":memory:")
+	if err != nil {
+		t.Fatalf("failed to create test database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	repo := quote.NewQuotesRepository(db)
+	source := &countingSource{QuotesRepository: repo}
+	return New(log, repo, source, opts), source
+}
+
+func do(t *testing.T, h http.Handler, query string, variables map[string]any, ctx context.Context) response {
+	t.Helper()
+	body, _ := json.Marshal(Request{Query: query, Variables: variables})
+	req := httptest.NewRequest(http.MethodPost, "/graphql", bytes.NewReader(body)).WithContext(ctx)
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, rr.Code, rr.Body)
+	}
+	var resp response
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	return resp
+}
+
+func TestGraphQL(t *testing.T) {
+	h, source := setup(t, Options{MaxDepth: 8, MaxComplexity: 1000})
+	ctx := context.Background()
+
+	const add = `mutation($author: String!, $text: String!, $tags: [String!]) {
+		addQuote(author: $author, text: $text, tags: $tags) { id tags }
+	}`
+	seed := []struct {
+		author, text string
+		tags         []string
+	}{
+		{"Confucius", "one", []string{"Wisdom", "life"}},
+		{"Confucius", "two", nil},
+		{"Seneca", "three", []string{"wisdom"}},
+		{"Seneca", "four", []string{"stoic"}},
+		{"Seneca", "five", nil},
+	}
+	for _, s := range seed {
+		resp := do(t, h, add, map[string]any{"author": s.author, "text": s.text, "tags": s.tags}, ctx)
+		if len(resp.Errors) > 0 {
+			t.Fatalf("addQuote failed: %v", resp.Errors)
+		}
+	}
+
+	source.tags.Store(0)
+	resp := do(t, h, `{
+		quotes(first: 10) {
+			totalCount
+			nodes { text tags author { name quoteCount quotes(first: 1) { text } } }
+		}
+	}`, nil, ctx)
+	if len(resp.Errors) > 0 {
+		t.Fatalf("unexpected errors: %v", resp.Errors)
+	}
+	var quotes struct {
+		TotalCount int
+		Nodes      []struct {
+			Text   string
+			Tags   []string
+			Author struct {
+				Name       string
+				QuoteCount int
+				Quotes     []struct{ Text string }
+			}
+		}
+	}
+	if err := json.Unmarshal(resp.Data["quotes"], &quotes); err != nil {
+		t.Fatalf("failed to decode quotes: %v", err)
+	}
+	if quotes.TotalCount != 5 || len(quotes.Nodes) != 5 {
+		t.Fatalf("expected 5 quotes, got %d of %d", len(quotes.Nodes), quotes.TotalCount)
+	}
+	first := quotes.Nodes[0]
+	if !slices.Equal(first.Tags, []string{"life", "wisdom"}) {
+		t.Errorf("expected normalized tags, got %v", first.Tags)
+	}
+	if quotes.Nodes[1].Tags == nil || len(quotes.Nodes[1].Tags) != 0 {
+		t.Errorf("expected empty tags, got %v", quotes.Nodes[1].Tags)
+	}
+	seneca := quotes.Nodes[4].Author
+	if seneca.Name != "Seneca" || seneca.QuoteCount != 3 || len(seneca.Quotes) != 1 || seneca.Quotes[0].Text != "three" {
+		t.Errorf("unexpected author: %+v", seneca)
+	}
+	// One query per kind of field, however many quotes are on the page.
+	if source.tags.Load() != 1 || source.counts.Load() != 1 || source.lists.Load() != 1 {
+		t.Errorf("expected batched loads, got tags=%d counts=%d lists=%d",
+			source.tags.Load(), source.counts.Load(), source.lists.Load())
+	}
+
+	resp = do(t, h, `{ quotes(tag: "WISDOM") { totalCount nodes { text } } }`, nil, ctx)
+	if !strings.Contains(string(resp.Data["quotes"]), `"totalCount":2`) {
+		t.Errorf("expected 2 quotes tagged wisdom, got %s", resp.Data["quotes"])
+	}
+
+	resp = do(t, h, `{ author(name: "Nobody") { name } quote(id: "999") { id } random(author: "Seneca") { author { name } } }`, nil, ctx)
+	if string(resp.Data["author"]) != "null" || string(resp.Data["quote"]) != "null" {
+		t.Errorf("expected nulls for missing author and quote, got %s and %s", resp.Data["author"], resp.Data["quote"])
+	}
+	if !strings.Contains(string(resp.Data["random"]), "Seneca") {
+		t.Errorf("expected a quote by Seneca, got %s", resp.Data["random"])
+	}
+
+	resp = do(t, h, `mutation { a: deleteQuote(id: "1") b: deleteQuote(id: "1") }`, nil, ctx)
+	if string(resp.Data["a"]) != "true" || string(resp.Data["b"]) != "false" {
+		t.Errorf("expected true then false, got %s and %s", resp.Data["a"], resp.Data["b"])
+	}
+
+	resp = do(t, h, `mutation { addQuote(author: "Seneca", text: "three") { id } }`, nil, ctx)
+	if len(resp.Errors) != 1 || resp.Errors[0].Message != "entry already exists" {
+		t.Errorf("expected duplicate error, got %v", resp.Errors)
+	}
+}
+
+func TestGraphQL_Limits(t *testing.T) {
+	h, _ := setup(t, Options{MaxDepth: 4, MaxComplexity: 100})
+	ctx := context.Background()
+
+	resp := do(t, h, `{ quotes(first: 50) { nodes { id text } } }`, nil, ctx)
+	if len(resp.Errors) != 1 || !strings.Contains(resp.Errors[0].Message, "exceeds the limit of 100") {
+		t.Errorf("expected complexity error, got %v", resp.Errors)
+	}
+
+	resp = do(t, h, `{ random { author { quotes { author { name } } } } }`, nil, ctx)
+	if len(resp.Errors) == 0 {
+		t.Error("expected depth error")
+	}
+
+	resp = do(t, h, `{ missing }`, nil, ctx)
+	if len(resp.Errors) == 0 {
+		t.Error("expected validation error")
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(`{"query": 1}`))
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, rr.Code)
+	}
+}
+
+func TestGraphQL_Scopes(t *testing.T) {
+	h, _ := setup(t, Options{MaxDepth: 8, MaxComplexity: 1000, RequireScopes: true})
+	const add = `mutation { addQuote(author: "a", text: "b") { id } }`
+
+	reader := middleware.WithPrincipal(context.Background(), &middleware.Principal{Subject: "r", Scopes: []string{middleware.ScopeRead}})
+	resp := do(t, h, add, nil, reader)
+	if len(resp.Errors) != 1 || !strings.Contains(resp.Errors[0].Message, "missing scope") {
+		t.Errorf("expected scope error, got %v", resp.Errors)
+	}
+
+	writer := middleware.WithPrincipal(context.Background(), &middleware.Principal{Subject: "w", Scopes: []string{middleware.ScopeRead, middleware.ScopeWrite}})
+	resp = do(t, h, add, nil, writer)
+	if len(resp.Errors) > 0 {
+		t.Errorf("unexpected errors: %v", resp.Errors)
+	}
+}
+
+func TestGraphQL_Citation(t *testing.T) {
+	h, source := setup(t, Options{MaxDepth: 8, MaxComplexity: 1000})
+	ctx := context.Background()
+	cited := quote.Citation{Source: "Letters", Year: -4, URL: "https://example.com", Page: "12", Notes: "Letter 1"}
+	if _, err := source.Create(ctx, quote.Draft{Author: "Seneca", Quote: "one", Citation: cited}); err != nil {
+		t.Fatalf("failed to save quote: %v", err)
+	}
+	if _, err := source.Save(ctx, "Seneca", "two"); err != nil {
+		t.Fatalf("failed to save quote: %v", err)
+	}
+
+	resp := do(t, h, `{ quotes { nodes { source year url page notes } } }`, nil, ctx)
+	if len(resp.Errors) > 0 {
+		t.Fatalf("unexpected errors: %v", resp.Errors)
+	}
+	var quotes struct {
+		Nodes []struct {
+			Source, URL, Page, Notes *string
+			Year                     *int
+		}
+	}
+	if err := json.Unmarshal(resp.Data["quotes"], &quotes); err != nil {
+		t.Fatalf("failed to decode quotes: %v", err)
+	}
+	if len(quotes.Nodes) != 2 {
+		t.Fatalf("expected 2 quotes, got %d", len(quotes.Nodes))
+	}
+	first, second := quotes.Nodes[0], quotes.Nodes[1]
+	if first.Source == nil || *first.Source != cited.Source || first.Year == nil || *first.Year != cited.Year ||
+		first.URL == nil || *first.URL != cited.URL || first.Page == nil || first.Notes == nil {
+		t.Errorf("unexpected citation: %+v", first)
+	}
+	if second.Source != nil || second.Year != nil || second.URL != nil || second.Page != nil || second.Notes != nil {
+		t.Errorf("expected no citation, got %+v", second)
+	}
+}
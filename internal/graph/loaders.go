@@ -0,0 +1,69 @@
+package graph
+
+import (
+	"context"
+	"quotes-mini-service/internal/quote"
+	"quotes-mini-service/pkg/dataloader"
+	"time"
+)
+
+const (
+	loaderWait     = time.Millisecond
+	loaderMaxBatch = 100
+)
+
+// BatchSource answers the per-item lookups of a query in bulk.
+type BatchSource interface {
+	TagsByQuotes(ctx context.Context, ids []int) (map[int][]string, error)
+	CountByAuthors(ctx context.Context, authors []string) (map[string]int, error)
+	ListByAuthors(ctx context.Context, authors []string, limit, offset int) (map[string][]quote.Quote, error)
+}
+
+type authorPage struct {
+	author string
+	limit  int
+	offset int
+}
+
+type loaders struct {
+	tags   *dataloader.Loader[int, []string]
+	counts *dataloader.Loader[string, int]
+	quotes *dataloader.Loader[authorPage, []quote.Quote]
+}
+
+func newLoaders(source BatchSource) *loaders {
+	opts := dataloader.Options{Wait: loaderWait, MaxBatch: loaderMaxBatch}
+	return &loaders{
+		tags:   dataloader.New(source.TagsByQuotes, opts),
+		counts: dataloader.New(source.CountByAuthors, opts),
+		quotes: dataloader.New(func(ctx context.Context, keys []authorPage) (map[authorPage][]quote.Quote, error) {
+			// Authors asked for with the same page share a query.
+			byPage := map[[2]int][]string{}
+			for _, k := range keys {
+				page := [2]int{k.limit, k.offset}
+				byPage[page] = append(byPage[page], k.author)
+			}
+			result := map[authorPage][]quote.Quote{}
+			for page, authors := range byPage {
+				quotes, err := source.ListByAuthors(ctx, authors, page[0], page[1])
+				if err != nil {
+					return nil, err
+				}
+				for author, q := range quotes {
+					result[authorPage{author: author, limit: page[0], offset: page[1]}] = q
+				}
+			}
+			return result, nil
+		}, opts),
+	}
+}
+
+type loadersKey struct{}
+
+func withLoaders(ctx context.Context, l *loaders) context.Context {
+	return context.WithValue(ctx, loadersKey{}, l)
+}
+
+func loadersFrom(ctx context.Context) *loaders {
+	return ctx.Value(loadersKey{}).(*loaders)
+}
@@ -0,0 +1,321 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"quotes-mini-service/internal/quote"
+	"quotes-mini-service/pkg/middleware"
+	"quotes-mini-service/pkg/sl"
+	"strconv"
+	"strings"
+
+	"github.com/graph-gophers/graphql-go"
+)
+
+const maxFirst = 100
+
+var errInternal = errors.New("internal server error")
+
+type Store interface {
+	Create(ctx context.Context, d quote.Draft) (*quote.Quote, error)
+	GetByID(ctx context.Context, id int) (*quote.Quote, error)
+	List(ctx context.Context, f quote.Filter) ([]quote.Quote, int, error)
+	GetRandomParam(ctx context.Context, author string) (*quote.Quote, error)
+	Delete(ctx context.Context, id int) error
+}
+
+type resolver struct {
+	log           *slog.Logger
+	store         Store
+	requireScopes bool
+}
+
+type pageArgs struct {
+	First  int32
+	Offset int32
+}
+
+// limits caps the page at maxFirst, the same bound the complexity check
+// assumes.
+func limits(first, offset int32) (int, int, error) {
+	if first < 0 || offset < 0 {
+		return 0, 0, errors.New("first and offset must not be negative")
+	}
+	return min(int(first), maxFirst), int(offset), nil
+}
+
+func (r *resolver) Quotes(ctx context.Context, args struct {
+	Author *string
+	Tag    *string
+	First  int32
+	Offset int32
+}) (*connectionResolver, error) {
+	limit, offset, err := limits(args.First, args.Offset)
+	if err != nil {
+		return nil, err
+	}
+	f := quote.Filter{Limit: limit, Offset: offset}
+	if args.Author != nil {
+		f.Author = *args.Author
+	}
+	if args.Tag != nil {
+		f.Tag = strings.ToLower(strings.TrimSpace(*args.Tag))
+	}
+	quotes, total, err := r.store.List(ctx, f)
+	if err != nil {
+		return nil, r.internal("graph.Quotes", err)
+	}
+	return &connectionResolver{quotes: quotes, total: total}, nil
+}
+
+func (r *resolver) Quote(ctx context.Context, args struct{ ID graphql.ID }) (*quoteResolver, error) {
+	id, err := parseID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+	q, err := r.store.GetByID(ctx, id)
+	if errors.Is(err, quote.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, r.internal("graph.Quote", err)
+	}
+	return &quoteResolver{q: *q}, nil
+}
+
+func (r *resolver) Random(ctx context.Context, args struct{ Author *string }) (*quoteResolver, error) {
+	var author string
+	if args.Author != nil {
+		author = *args.Author
+	}
+	q, err := r.store.GetRandomParam(ctx, author)
+	if errors.Is(err, quote.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, r.internal("graph.Random", err)
+	}
+	return &quoteResolver{q: *q}, nil
+}
+
+func (r *resolver) Author(ctx context.Context, args struct{ Name string }) (*authorResolver, error) {
+	count, err := loadersFrom(ctx).counts.Load(ctx, args.Name)
+	if err != nil {
+		return nil, r.internal("graph.Author", err)
+	}
+	if count == 0 {
+		return nil, nil
+	}
+	return &authorResolver{name: args.Name}, nil
+}
+
+func (r *resolver) AddQuote(ctx context.Context, args struct {
+	Author string
+	Text   string
+	Tags   *[]string
+}) (*quoteResolver, error) {
+	const op = "graph.AddQuote"
+	if err := r.authorize(ctx, middleware.ScopeWrite); err != nil {
+		return nil, err
+	}
+	switch {
+	case args.Author == "" && args.Text == "":
+		return nil, errors.New("author and text are required")
+	case args.Author == "":
+		return nil, errors.New("author is required")
+	case args.Text == "":
+		return nil, errors.New("text is required")
+	}
+	var tags []string
+	if args.Tags != nil {
+		var err error
+		if tags, err = quote.NormalizeTags(*args.Tags); err != nil {
+			return nil, err
+		}
+	}
+	q, err := r.store.Create(ctx, quote.Draft{Author: args.Author, Quote: args.Text, Tags: tags})
+	if errors.Is(err, quote.ErrDuplicate) {
+		return nil, errors.New("entry already exists")
+	}
+	if err != nil {
+		return nil, r.internal(op, err)
+	}
+	r.log.Info("quote added", slog.String("op", op), slog.Int("id", q.ID))
+	return &quoteResolver{q: *q}, nil
+}
+
+func (r *resolver) DeleteQuote(ctx context.Context, args struct{ ID graphql.ID }) (bool, error) {
+	const op = "graph.DeleteQuote"
+	if err := r.authorize(ctx, middleware.ScopeDelete); err != nil {
+		return false, err
+	}
+	id, err := parseID(args.ID)
+	if err != nil {
+		return false, err
+	}
+	err = r.store.Delete(ctx, id)
+	if errors.Is(err, quote.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, r.internal(op, err)
+	}
+	r.log.Info("quote deleted", slog.String("op", op), slog.Int("id", id))
+	return true, nil
+}
+
+// authorize mirrors RequireScope for mutations; the route itself only
+// requires the read scope.
+func (r *resolver) authorize(ctx context.Context, scope string) error {
+	if !r.requireScopes {
+		return nil
+	}
+	p, ok := middleware.PrincipalFromContext(ctx)
+	if !ok {
+		return errors.New("authentication required")
+	}
+	if !p.HasScope(scope) {
+		return fmt.Errorf("missing scope %s", scope)
+	}
+	return nil
+}
+
+func (r *resolver) internal(op string, err error) error {
+	r.log.Error("internal server error", slog.String("op", op), sl.Err(err))
+	return errInternal
+}
+
+func parseID(id graphql.ID) (int, error) {
+	n, err := strconv.Atoi(string(id))
+	if err != nil {
+		return 0, fmt.Errorf("invalid id %q", id)
+	}
+	return n, nil
+}
+
+type connectionResolver struct {
+	quotes []quote.Quote
+	total  int
+}
+
+func (c *connectionResolver) Nodes() []*quoteResolver {
+	nodes := make([]*quoteResolver, len(c.quotes))
+	for i, q := range c.quotes {
+		nodes[i] = &quoteResolver{q: q}
+	}
+	return nodes
+}
+
+func (c *connectionResolver) TotalCount() int32 {
+	return int32(c.total)
+}
+
+type quoteResolver struct {
+	q quote.Quote
+}
+
+func (r *quoteResolver) ID() graphql.ID {
+	return graphql.ID(strconv.Itoa(r.q.ID))
+}
+
+func (r *quoteResolver) Text() string {
+	return r.q.Quote
+}
+
+func (r *quoteResolver) Author() *authorResolver {
+	return &authorResolver{name: r.q.Author}
+}
+
+func (r *quoteResolver) Tags(ctx context.Context) ([]string, error) {
+	tags, err := loadersFrom(ctx).tags.Load(ctx, r.q.ID)
+	if err != nil {
+		return nil, errInternal
+	}
+	if tags == nil {
+		tags = []string{}
+	}
+	return tags, nil
+}
+
+func (r *quoteResolver) CreatedAt() graphql.Time {
+	return graphql.Time{Time: r.q.CreatedAt}
+}
+
+func (r *quoteResolver) UpdatedAt() *graphql.Time {
+	if r.q.UpdatedAt == nil {
+		return nil
+	}
+	return &graphql.Time{Time: *r.q.UpdatedAt}
+}
+
+func (r *quoteResolver) CreatedBy() *string {
+	if r.q.CreatedBy == "" {
+		return nil
+	}
+	return &r.q.CreatedBy
+}
+
+func (r *quoteResolver) Source() *string {
+	return optional(r.q.Source)
+}
+
+func (r *quoteResolver) Year() *int32 {
+	if r.q.Year == 0 {
+		return nil
+	}
+	year := int32(r.q.Year)
+	return &year
+}
+
+func (r *quoteResolver) URL() *string {
+	return optional(r.q.URL)
+}
+
+func (r *quoteResolver) Page() *string {
+	return optional(r.q.Page)
+}
+
+func (r *quoteResolver) Notes() *string {
+	return optional(r.q.Notes)
+}
+
+func optional(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+type authorResolver struct {
+	name string
+}
+
+func (r *authorResolver) Name() string {
+	return r.name
+}
+
+func (r *authorResolver) QuoteCount(ctx context.Context) (int32, error) {
+	count, err := loadersFrom(ctx).counts.Load(ctx, r.name)
+	if err != nil {
+		return 0, errInternal
+	}
+	return int32(count), nil
+}
+
+func (r *authorResolver) Quotes(ctx context.Context, args pageArgs) ([]*quoteResolver, error) {
+	limit, offset, err := limits(args.First, args.Offset)
+	if err != nil {
+		return nil, err
+	}
+	quotes, err := loadersFrom(ctx).quotes.Load(ctx, authorPage{author: r.name, limit: limit, offset: offset})
+	if err != nil {
+		return nil, errInternal
+	}
+	resolvers := make([]*quoteResolver, len(quotes))
+	for i, q := range quotes {
+		resolvers[i] = &quoteResolver{q: q}
+	}
+	return resolvers, nil
+}
@@ -0,0 +1,136 @@
+package health
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"quotes-mini-service/pkg/res"
+	"quotes-mini-service/pkg/sl"
+	"runtime/debug"
+	"sync/atomic"
+	"time"
+)
+
+// Set at build time with
+// -ldflags "-X quotes-mini-service/internal/health.version=... -X quotes-mini-service/internal/health.commit=...".
+var (
+	version string
+	commit  string
+)
+
+type Checker interface {
+	PingContext(ctx context.Context) error
+	CheckMigrations(ctx context.Context) error
+}
+
+// Probe tracks whether the process should keep receiving traffic. It is
+// drained once graceful shutdown starts so the load balancer stops routing
+// to the instance before connections are closed.
+type Probe struct {
+	draining atomic.Bool
+}
+
+func (p *Probe) Drain() {
+	p.draining.Store(true)
+}
+
+func (p *Probe) Draining() bool {
+	return p.draining.Load()
+}
+
+// Statuses of a readiness check.
+const (
+	checkOK     = "ok"
+	checkFailed = "failed"
+)
+
+type StatusResponse struct {
+	Status string            `json:"status" example:"ok"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+type VersionResponse struct {
+	Version   string `json:"version" example:"v1.2.0"`
+	Commit    string `json:"commit" example:"d87e142"`
+	BuildTime string `json:"build_time,omitempty" example:"2025-05-29T00:00:00Z"`
+	Modified  bool   `json:"modified"`
+	GoVersion string `json:"go_version" example:"go1.24.2"`
+}
+
+func Live() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		res.Json(w, StatusResponse{Status: "ok"}, http.StatusOK)
+	}
+}
+
+func Ready(log *slog.Logger, checker Checker, probe *Probe) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.health.Ready"
+		log := log.With(
+			slog.String("op", op),
+		)
+		if probe.Draining() {
+			res.Json(w, StatusResponse{Status: "unavailable", Checks: map[string]string{"shutdown": "draining"}}, http.StatusServiceUnavailable)
+			return
+		}
+		ctx, cancel := context.WithTimeout(r.Context(), 2*time.Second)
+		defer cancel()
+		// The checks are public, so they only carry a fixed status; the
+		// errors go to the log.
+		checks := map[string]string{"database": checkOK, "migrations": checkOK}
+		ready := true
+		if err := checker.PingContext(ctx); err != nil {
+			log.Error("database ping failed", sl.Err(err))
+			checks["database"] = checkFailed
+			ready = false
+		}
+		if err := checker.CheckMigrations(ctx); err != nil {
+			log.Error("migrations check failed", sl.Err(err))
+			checks["migrations"] = checkFailed
+			ready = false
+		}
+		if !ready {
+			res.Json(w, StatusResponse{Status: "unavailable", Checks: checks}, http.StatusServiceUnavailable)
+			return
+		}
+		res.Json(w, StatusResponse{Status: "ok", Checks: checks}, http.StatusOK)
+	}
+}
+
+func Version() http.HandlerFunc {
+	info := BuildInfo()
+	return func(w http.ResponseWriter, r *http.Request) {
+		res.Json(w, info, http.StatusOK)
+	}
+}
+
+func BuildInfo() VersionResponse {
+	info := VersionResponse{
+		Version: version,
+		Commit:  commit,
+	}
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	info.GoVersion = bi.GoVersion
+	if info.Version == "" {
+		info.Version = bi.Main.Version
+	}
+	for _, s := range bi.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			if info.Commit == "" {
+				info.Commit = s.Value
+			}
+		case "vcs.time":
+			info.BuildTime = s.Value
+		case "vcs.modified":
+			info.Modified = s.Value == "true"
+		}
+	}
+	if info.Version == "" {
+		info.Version = "(devel)"
+	}
+	return info
+}
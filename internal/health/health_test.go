@@ -0,0 +1,96 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+type mockChecker struct {
+	pingErr       error
+	migrationsErr error
+}
+
+func (m *mockChecker) PingContext(context.Context) error {
+	return m.pingErr
+}
+
+func (m *mockChecker) CheckMigrations(context.Context) error {
+	return m.migrationsErr
+}
+
+func TestLive(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+	w := httptest.NewRecorder()
+
+	Live()(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("expected status %d, got %d", http.StatusOK, w.Code)
+	}
+}
+
+func TestReady(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	tests := []struct {
+		name    string
+		checker *mockChecker
+		drain   bool
+		want    int
+		checks  map[string]string
+	}{
+		{"ready", &mockChecker{}, false, http.StatusOK, map[string]string{"database": "ok", "migrations": "ok"}},
+		{"database down", &mockChecker{pingErr: errors.New("open /var/lib/quotes.db: permission denied")}, false, http.StatusServiceUnavailable,
+			map[string]string{"database": "failed", "migrations": "ok"}},
+		{"pending migrations", &mockChecker{migrationsErr: errors.New("schema version 3, expected 15")}, false, http.StatusServiceUnavailable,
+			map[string]string{"database": "ok", "migrations": "failed"}},
+		{"draining", &mockChecker{}, true, http.StatusServiceUnavailable, map[string]string{"shutdown": "draining"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			probe := &Probe{}
+			if tt.drain {
+				probe.Drain()
+			}
+			r := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+			w := httptest.NewRecorder()
+
+			Ready(log, tt.checker, probe)(w, r)
+
+			if w.Code != tt.want {
+				t.Errorf("expected status %d, got %d", tt.want, w.Code)
+			}
+			var response StatusResponse
+			if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if !maps.Equal(response.Checks, tt.checks) {
+				t.Errorf("expected checks %v, got %v", tt.checks, response.Checks)
+			}
+		})
+	}
+}
+
+func TestVersion(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/version", nil)
+	w := httptest.NewRecorder()
+
+	Version()(w, r)
+
+	var response VersionResponse
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if response.GoVersion == "" {
+		t.Error("expected go version")
+	}
+	if response.Version == "" {
+		t.Error("expected version")
+	}
+}
@@ -0,0 +1,109 @@
+package idempotency
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"quotes-mini-service/internal/storage"
+	"quotes-mini-service/pkg/middleware"
+	"time"
+)
+
+type ResponsesRepository struct {
+	Database *storage.Db
+	now      func() time.Time
+}
+
+func NewResponsesRepository(database *storage.Db) *ResponsesRepository {
+	return &ResponsesRepository{
+		Database: database,
+		now:      time.Now,
+	}
+}
+
+func (repo *ResponsesRepository) Reserve(ctx context.Context, scope, key, requestHash string, ttl time.Duration) (*middleware.StoredResponse, error) {
+	const op = "idempotency.repository.Reserve"
+	now := repo.now().UTC()
+	tx, err := repo.Database.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s: begin transaction: %w", op, err)
+	}
+	defer tx.Rollback()
+	if _, err = tx.ExecContext(ctx, "DELETE FROM idempotency_keys WHERE expires_at <= ?", now); err != nil {
+		return nil, fmt.Errorf("%s: purge expired keys: %w", op, err)
+	}
+	// The insert is the reservation: of two concurrent requests with the
+	// same key exactly one inserts, the other finds the row below.
+	res, err := tx.ExecContext(ctx,
+		`INSERT INTO idempotency_keys(scope, key, request_hash, expires_at) VALUES(?, ?, ?, ?)
+		ON CONFLICT(scope, key) DO NOTHING`,
+		scope, key, requestHash, now.Add(ttl))
+	if err != nil {
+		return nil, fmt.Errorf("%s: insert key: %w", op, err)
+	}
+	inserted, err := res.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("%s: rows affection: %w", op, err)
+	}
+	if inserted == 1 {
+		if err = tx.Commit(); err != nil {
+			return nil, fmt.Errorf("%s: commit transaction: %w", op, err)
+		}
+		return nil, nil
+	}
+	var (
+		hash    string
+		status  int
+		headers sql.NullString
+		body    []byte
+	)
+	err = tx.QueryRowContext(ctx,
+		"SELECT request_hash, status, headers, body FROM idempotency_keys WHERE scope = ? AND key = ?",
+		scope, key).Scan(&hash, &status, &headers, &body)
+	if err != nil {
+		return nil, fmt.Errorf("%s: select key: %w", op, err)
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("%s: commit transaction: %w", op, err)
+	}
+	if hash != requestHash {
+		return nil, middleware.ErrIdempotencyMismatch
+	}
+	if status == 0 {
+		return nil, middleware.ErrIdempotencyInProgress
+	}
+	resp := &middleware.StoredResponse{Status: status, Header: http.Header{}, Body: body}
+	if headers.Valid {
+		if err = json.Unmarshal([]byte(headers.String), &resp.Header); err != nil {
+			return nil, fmt.Errorf("%s: decode headers: %w", op, err)
+		}
+	}
+	return resp, nil
+}
+
+func (repo *ResponsesRepository) Complete(ctx context.Context, scope, key string, resp middleware.StoredResponse) error {
+	const op = "idempotency.repository.Complete"
+	headers, err := json.Marshal(resp.Header)
+	if err != nil {
+		return fmt.Errorf("%s: encode headers: %w", op, err)
+	}
+	_, err = repo.Database.ExecContext(ctx,
+		"UPDATE idempotency_keys SET status = ?, headers = ?, body = ? WHERE scope = ? AND key = ?",
+		resp.Status, string(headers), resp.Body, scope, key)
+	if err != nil {
+		return fmt.Errorf("%s: update key: %w", op, err)
+	}
+	return nil
+}
+
+func (repo *ResponsesRepository) Release(ctx context.Context, scope, key string) error {
+	const op = "idempotency.repository.Release"
+	_, err := repo.Database.ExecContext(ctx,
+		"DELETE FROM idempotency_keys WHERE scope = ? AND key = ? AND status = 0", scope, key)
+	if err != nil {
+		return fmt.Errorf("%s: delete key: %w", op, err)
+	}
+	return nil
+}
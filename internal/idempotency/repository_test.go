@@ -0,0 +1,108 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"quotes-mini-service/internal/storage"
+	"quotes-mini-service/pkg/middleware"
+	"sync"
+	"testing"
+	"time"
+)
+
+func setupTestDB(t *testing.T) *storage.Db {
+	db, err := storage.NewStorage(":memory:")
+	if err != nil {
+		t.Fatalf("failed to create test database: %v", err)
+	}
+	return db
+}
+
+func TestResponsesRepository(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewResponsesRepository(db)
+	ctx := context.Background()
+
+	stored, err := repo.Reserve(ctx, "apikey:1", "k1", "hash", time.Hour)
+	if err != nil || stored != nil {
+		t.Fatalf("expected fresh reservation, got %v %v", stored, err)
+	}
+	if _, err = repo.Reserve(ctx, "apikey:1", "k1", "hash", time.Hour); !errors.Is(err, middleware.ErrIdempotencyInProgress) {
+		t.Errorf("expected ErrIdempotencyInProgress, got %v", err)
+	}
+	if stored, err = repo.Reserve(ctx, "apikey:2", "k1", "hash", time.Hour); err != nil || stored != nil {
+		t.Errorf("expected keys to be scoped per client, got %v %v", stored, err)
+	}
+
+	resp := middleware.StoredResponse{
+		Status: http.StatusCreated,
+		Header: http.Header{"Content-Type": {"application/json"}},
+		Body:   []byte(`{"id":1}`),
+	}
+	if err = repo.Complete(ctx, "apikey:1", "k1", resp); err != nil {
+		t.Fatalf("failed to complete: %v", err)
+	}
+	stored, err = repo.Reserve(ctx, "apikey:1", "k1", "hash", time.Hour)
+	if err != nil || stored == nil {
+		t.Fatalf("expected stored response, got %v %v", stored, err)
+	}
+	if stored.Status != http.StatusCreated || string(stored.Body) != `{"id":1}` || stored.Header.Get("Content-Type") != "application/json" {
+		t.Errorf("unexpected stored response %+v", stored)
+	}
+	if _, err = repo.Reserve(ctx, "apikey:1", "k1", "other", time.Hour); !errors.Is(err, middleware.ErrIdempotencyMismatch) {
+		t.Errorf("expected ErrIdempotencyMismatch, got %v", err)
+	}
+
+	// Released and expired keys can be used again.
+	if err = repo.Release(ctx, "apikey:2", "k1"); err != nil {
+		t.Fatalf("failed to release: %v", err)
+	}
+	if stored, _ = repo.Reserve(ctx, "apikey:2", "k1", "other", time.Hour); stored != nil {
+		t.Error("expected released key to be reserved again")
+	}
+	repo.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	if stored, err = repo.Reserve(ctx, "apikey:1", "k1", "other", time.Hour); err != nil || stored != nil {
+		t.Errorf("expected expired key to be reserved again, got %v %v", stored, err)
+	}
+}
+
+func TestResponsesRepository_ConcurrentReserve(t *testing.T) {
+	db, err := storage.NewStorage(filepath.Join(t.TempDir(), "keys.db"))
+	if err != nil {
+		t.Fatalf("failed to create test database: %v", err)
+	}
+	defer db.Close()
+	repo := NewResponsesRepository(db)
+
+	const n = 8
+	errs := make(chan error, n)
+	var wg sync.WaitGroup
+	for range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := repo.Reserve(context.Background(), "apikey:1", "same-key", "hash", time.Hour)
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	var reserved, inProgress int
+	for err := range errs {
+		switch {
+		case err == nil:
+			reserved++
+		case errors.Is(err, middleware.ErrIdempotencyInProgress):
+			inProgress++
+		default:
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+	if reserved != 1 || inProgress != n-1 {
+		t.Errorf("expected 1 reservation and %d in progress, got %d and %d", n-1, reserved, inProgress)
+	}
+}
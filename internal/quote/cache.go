@@ -0,0 +1,222 @@
+package quote
+
+import (
+	"context"
+	"errors"
+	"quotes-mini-service/pkg/metrics"
+	"slices"
+	"sync"
+	"time"
+)
+
+var (
+	cacheHits   = metrics.NewCounter("cache_hits_total", "Reads served from the in-process cache.", "cache")
+	cacheMisses = metrics.NewCounter("cache_misses_total", "Reads that had to go to the database.", "cache")
+)
+
+// Store is the set of repository operations the cache sits in front of.
+type Store interface {
+	Save(ctx context.Context, author, quote string) (*Quote, error)
+	Create(ctx context.Context, d Draft) (*Quote, error)
+	GetAllParam(ctx context.Context, author string) ([]Quote, int, error)
+	List(ctx context.Context, f Filter) ([]Quote, int, error)
+	GetByID(ctx context.Context, id int) (*Quote, error)
+	GetRandom(ctx context.Context) (*Quote, error)
+	GetRandomParam(ctx context.Context, author string) (*Quote, error)
+	RandomBatch(ctx context.Context, langs Languages, count int) ([]Quote, error)
+	Translations(ctx context.Context, id int) ([]Quote, error)
+	Update(ctx context.Context, id int, author, quote string, citation Citation) (*Quote, error)
+	Restore(ctx context.Context, id, rev int) (*Quote, error)
+	Delete(ctx context.Context, id int) error
+	Like(ctx context.Context, id int, voter string) (*Quote, bool, error)
+	Rate(ctx context.Context, id int, voter string, rating int) (*Quote, error)
+	Top(ctx context.Context, since time.Time, limit int) ([]Quote, error)
+}
+
+type listEntry struct {
+	quotes  []Quote
+	expires time.Time
+}
+
+type itemEntry struct {
+	quote   Quote
+	expires time.Time
+}
+
+// CachedRepository keeps list and single-quote reads in memory for ttl.
+// Every successful write drops the cached lists, so the cache is only
+// coherent for a single instance writing through it.
+type CachedRepository struct {
+	store Store
+	ttl   time.Duration
+	now   func() time.Time
+
+	mu    sync.Mutex
+	lists map[string]listEntry
+	items map[int]itemEntry
+	// gen counts invalidations. A read that misses drops its fill when a
+	// write invalidated the cache while it was at the store, so a stale
+	// result cannot outlive the write.
+	gen uint64
+}
+
+func NewCachedRepository(store Store, ttl time.Duration) *CachedRepository {
+	return &CachedRepository{
+		store: store,
+		ttl:   ttl,
+		now:   time.Now,
+		lists: map[string]listEntry{},
+		items: map[int]itemEntry{},
+	}
+}
+
+func (c *CachedRepository) Save(ctx context.Context, author, quote string) (*Quote, error) {
+	q, err := c.store.Save(ctx, author, quote)
+	if err != nil {
+		return nil, err
+	}
+	c.invalidate(q.ID)
+	return q, nil
+}
+
+func (c *CachedRepository) Create(ctx context.Context, d Draft) (*Quote, error) {
+	q, err := c.store.Create(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+	c.invalidate(q.ID)
+	return q, nil
+}
+
+func (c *CachedRepository) Update(ctx context.Context, id int, author, quote string, citation Citation) (*Quote, error) {
+	q, err := c.store.Update(ctx, id, author, quote, citation)
+	if err != nil {
+		return nil, err
+	}
+	c.invalidate(id)
+	return q, nil
+}
+
+func (c *CachedRepository) Restore(ctx context.Context, id, rev int) (*Quote, error) {
+	q, err := c.store.Restore(ctx, id, rev)
+	if err != nil {
+		return nil, err
+	}
+	c.invalidate(id)
+	return q, nil
+}
+
+func (c *CachedRepository) Delete(ctx context.Context, id int) error {
+	err := c.store.Delete(ctx, id)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return err
+	}
+	// Deleting an original relinks its translations, so any cached quote may
+	// be stale.
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.gen++
+	clear(c.lists)
+	clear(c.items)
+	return err
+}
+
+func (c *CachedRepository) Like(ctx context.Context, id int, voter string) (*Quote, bool, error) {
+	q, created, err := c.store.Like(ctx, id, voter)
+	if err != nil {
+		return nil, false, err
+	}
+	if created {
+		c.invalidate(id)
+	}
+	return q, created, nil
+}
+
+func (c *CachedRepository) Rate(ctx context.Context, id int, voter string, rating int) (*Quote, error) {
+	q, err := c.store.Rate(ctx, id, voter, rating)
+	if err != nil {
+		return nil, err
+	}
+	c.invalidate(id)
+	return q, nil
+}
+
+func (c *CachedRepository) GetAllParam(ctx context.Context, author string) ([]Quote, int, error) {
+	c.mu.Lock()
+	entry, ok := c.lists[author]
+	gen := c.gen
+	c.mu.Unlock()
+	if ok && c.now().Before(entry.expires) {
+		cacheHits.Inc("quotes_list")
+		return slices.Clone(entry.quotes), len(entry.quotes), nil
+	}
+	cacheMisses.Inc("quotes_list")
+	quotes, count, err := c.store.GetAllParam(ctx, author)
+	if err != nil {
+		return nil, 0, err
+	}
+	c.mu.Lock()
+	if c.gen == gen {
+		c.lists[author] = listEntry{quotes: slices.Clone(quotes), expires: c.now().Add(c.ttl)}
+	}
+	c.mu.Unlock()
+	return quotes, count, nil
+}
+
+func (c *CachedRepository) GetByID(ctx context.Context, id int) (*Quote, error) {
+	c.mu.Lock()
+	entry, ok := c.items[id]
+	gen := c.gen
+	c.mu.Unlock()
+	if ok && c.now().Before(entry.expires) {
+		cacheHits.Inc("quote")
+		q := entry.quote
+		return &q, nil
+	}
+	cacheMisses.Inc("quote")
+	q, err := c.store.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	if c.gen == gen {
+		c.items[id] = itemEntry{quote: *q, expires: c.now().Add(c.ttl)}
+	}
+	c.mu.Unlock()
+	return q, nil
+}
+
+// List pages are not cached; only GetAllParam serves the hot list route.
+func (c *CachedRepository) List(ctx context.Context, f Filter) ([]Quote, int, error) {
+	return c.store.List(ctx, f)
+}
+
+// Top is not cached; its ranking moves with every vote.
+func (c *CachedRepository) Top(ctx context.Context, since time.Time, limit int) ([]Quote, error) {
+	return c.store.Top(ctx, since, limit)
+}
+
+// GetRandom is never cached.
+func (c *CachedRepository) GetRandom(ctx context.Context) (*Quote, error) {
+	return c.store.GetRandom(ctx)
+}
+
+func (c *CachedRepository) GetRandomParam(ctx context.Context, author string) (*Quote, error) {
+	return c.store.GetRandomParam(ctx, author)
+}
+
+func (c *CachedRepository) RandomBatch(ctx context.Context, langs Languages, count int) ([]Quote, error) {
+	return c.store.RandomBatch(ctx, langs, count)
+}
+
+func (c *CachedRepository) Translations(ctx context.Context, id int) ([]Quote, error) {
+	return c.store.Translations(ctx, id)
+}
+
+func (c *CachedRepository) invalidate(id int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.gen++
+	clear(c.lists)
+	delete(c.items, id)
+}
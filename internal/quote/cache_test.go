@@ -0,0 +1,133 @@
+package quote
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type countingStore struct {
+	Store
+	lists, items int
+}
+
+func (s *countingStore) GetAllParam(ctx context.Context, author string) ([]Quote, int, error) {
+	s.lists++
+	return s.Store.GetAllParam(ctx, author)
+}
+
+func (s *countingStore) GetByID(ctx context.Context, id int) (*Quote, error) {
+	s.items++
+	return s.Store.GetByID(ctx, id)
+}
+
+// racingStore runs write while a read is at the store, after the row was
+// read but before the cache is filled.
+type racingStore struct {
+	Store
+	write func()
+}
+
+func (s *racingStore) GetAllParam(ctx context.Context, author string) ([]Quote, int, error) {
+	quotes, count, err := s.Store.GetAllParam(ctx, author)
+	s.race()
+	return quotes, count, err
+}
+
+func (s *racingStore) GetByID(ctx context.Context, id int) (*Quote, error) {
+	q, err := s.Store.GetByID(ctx, id)
+	s.race()
+	return q, err
+}
+
+func (s *racingStore) race() {
+	if write := s.write; write != nil {
+		s.write = nil
+		write()
+	}
+}
+
+func TestCachedRepository_StaleFill(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	store := &racingStore{Store: NewQuotesRepository(db)}
+	cache := NewCachedRepository(store, time.Minute)
+	ctx := context.Background()
+	saved, err := cache.Save(ctx, "Author", "Quote")
+	if err != nil {
+		t.Fatalf("failed to save quote: %v", err)
+	}
+
+	store.write = func() { cache.Update(ctx, saved.ID, "Author", "Edited", Citation{}) }
+	if q, _ := cache.GetByID(ctx, saved.ID); q.Quote != "Quote" {
+		t.Fatalf("expected the read to see the old text, got %q", q.Quote)
+	}
+	if q, _ := cache.GetByID(ctx, saved.ID); q.Quote != "Edited" {
+		t.Errorf("expected the stale fill to be dropped, got %q", q.Quote)
+	}
+
+	store.write = func() { cache.Save(ctx, "Author", "Another quote") }
+	if _, count, _ := cache.GetAllParam(ctx, ""); count != 1 {
+		t.Fatalf("expected the read to see one quote, got %d", count)
+	}
+	if _, count, _ := cache.GetAllParam(ctx, ""); count != 2 {
+		t.Errorf("expected the stale fill to be dropped, got %d quotes", count)
+	}
+}
+
+func TestCachedRepository(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	store := &countingStore{Store: NewQuotesRepository(db)}
+	cache := NewCachedRepository(store, time.Minute)
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	saved, err := cache.Save(ctx, "Author", "Quote")
+	if err != nil {
+		t.Fatalf("failed to save quote: %v", err)
+	}
+	hits := cacheHits.Value("quotes_list")
+	for range 3 {
+		if _, count, err := cache.GetAllParam(ctx, ""); err != nil || count != 1 {
+			t.Fatalf("unexpected result: count=%d err=%v", count, err)
+		}
+	}
+	if store.lists != 1 {
+		t.Errorf("expected 1 database read, got %d", store.lists)
+	}
+	if got := cacheHits.Value("quotes_list") - hits; got != 2 {
+		t.Errorf("expected 2 cache hits, got %v", got)
+	}
+
+	if _, err = cache.Save(ctx, "Author", "Another quote"); err != nil {
+		t.Fatalf("failed to save quote: %v", err)
+	}
+	if _, count, _ := cache.GetAllParam(ctx, ""); count != 2 {
+		t.Errorf("expected save to invalidate the list, got count %d", count)
+	}
+
+	if _, err = cache.GetByID(ctx, saved.ID); err != nil {
+		t.Fatalf("failed to get quote: %v", err)
+	}
+	if err = cache.Delete(ctx, saved.ID); err != nil {
+		t.Fatalf("failed to delete quote: %v", err)
+	}
+	if _, err = cache.GetByID(ctx, saved.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound after delete, got %v", err)
+	}
+	if _, count, _ := cache.GetAllParam(ctx, ""); count != 1 {
+		t.Errorf("expected delete to invalidate the list, got count %d", count)
+	}
+
+	reads := store.lists
+	now = now.Add(2 * time.Minute)
+	cache.GetAllParam(ctx, "")
+	if store.lists != reads+1 {
+		t.Error("expected expired entry to be reloaded")
+	}
+}
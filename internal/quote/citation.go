@@ -0,0 +1,42 @@
+package quote
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	MaxSourceLength = 200
+	MaxURLLength    = 2048
+	MaxPageLength   = 20
+	MaxNotesLength  = 1000
+	// MinYear is the earliest year a quote can be dated to.
+	MinYear = -3000
+)
+
+// Validate checks the citation of a new quote. The messages are meant for
+// the client.
+func (c Citation) Validate() error {
+	switch {
+	case utf8.RuneCountInString(c.Source) > MaxSourceLength:
+		return fmt.Errorf("source must be at most %d characters", MaxSourceLength)
+	case c.Year < MinYear || c.Year > time.Now().Year():
+		return fmt.Errorf("year must be between %d and %d", MinYear, time.Now().Year())
+	case len(c.URL) > MaxURLLength:
+		return fmt.Errorf("url must be at most %d characters", MaxURLLength)
+	case c.URL != "" && !validURL(c.URL):
+		return fmt.Errorf("url must be an absolute http or https url")
+	case utf8.RuneCountInString(c.Page) > MaxPageLength:
+		return fmt.Errorf("page must be at most %d characters", MaxPageLength)
+	case utf8.RuneCountInString(c.Notes) > MaxNotesLength:
+		return fmt.Errorf("notes must be at most %d characters", MaxNotesLength)
+	}
+	return nil
+}
+
+func validURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
@@ -0,0 +1,78 @@
+package quote
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestCitation_Validate(t *testing.T) {
+	tests := []struct {
+		c     Citation
+		valid bool
+	}{
+		{Citation{}, true},
+		{Citation{Source: "Analects", Year: -479, URL: "http://example.com/a?b=c", Page: "xii", Notes: "n"}, true},
+		{Citation{Year: MinYear - 1}, false},
+		{Citation{Year: 9999}, false},
+		{Citation{URL: "/relative"}, false},
+		{Citation{URL: "mailto:a@example.com"}, false},
+		{Citation{URL: "https://example.com/" + strings.Repeat("a", MaxURLLength)}, false},
+		{Citation{Source: strings.Repeat("ж", MaxSourceLength)}, true},
+		{Citation{Notes: strings.Repeat("n", MaxNotesLength+1)}, false},
+	}
+	for _, tt := range tests {
+		if err := tt.c.Validate(); (err == nil) != tt.valid {
+			t.Errorf("%+v: expected valid=%v, got %v", tt.c, tt.valid, err)
+		}
+	}
+}
+
+func TestQuotesRepository_Citation(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewQuotesRepository(db)
+	ctx := context.Background()
+	analects := Citation{Source: "Analects", Year: -479, URL: "https://example.com/analects", Page: "7", Notes: "Book II"}
+	q, err := repo.Create(ctx, Draft{Author: "Confucius", Quote: "Learning without thought is labor lost.", Citation: analects})
+	if err != nil {
+		t.Fatalf("failed to save quote: %v", err)
+	}
+	if q.Citation != analects {
+		t.Errorf("expected citation %+v, got %+v", analects, q.Citation)
+	}
+	letters, err := repo.Create(ctx, Draft{Author: "Seneca", Quote: "While we teach, we learn.", Citation: Citation{Source: "Letters", Year: 65}})
+	if err != nil {
+		t.Fatalf("failed to save quote: %v", err)
+	}
+	undated, err := repo.Save(ctx, "Confucius", "Real knowledge is to know the extent of one's ignorance.")
+	if err != nil {
+		t.Fatalf("failed to save quote: %v", err)
+	}
+	if undated.Citation != (Citation{}) {
+		t.Errorf("expected no citation, got %+v", undated.Citation)
+	}
+
+	for _, tt := range []struct {
+		f    Filter
+		want []int
+	}{
+		{Filter{Source: "Analects"}, []int{q.ID}},
+		{Filter{YearFrom: -500, YearTo: -1}, []int{q.ID}},
+		{Filter{YearFrom: 1}, []int{letters.ID}},
+		{Filter{YearTo: 100}, []int{q.ID, letters.ID}},
+		{Filter{Author: "Confucius", YearTo: 100}, []int{q.ID}},
+		{Filter{Source: "Letters", YearFrom: 100}, nil},
+	} {
+		tt.f.Limit = -1
+		quotes, total, err := repo.List(ctx, tt.f)
+		if err != nil {
+			t.Fatalf("failed to list quotes: %v", err)
+		}
+		if ids := quoteIDs(quotes); total != len(tt.want) || !slices.Equal(ids, tt.want) {
+			t.Errorf("%+v: expected %v, got %v of %d", tt.f, tt.want, ids, total)
+		}
+	}
+}
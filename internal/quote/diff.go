@@ -0,0 +1,115 @@
+package quote
+
+import "strings"
+
+const (
+	DiffEqual  = "equal"
+	DiffInsert = "insert"
+	DiffDelete = "delete"
+)
+
+type DiffOp struct {
+	Op   string `json:"op" example:"insert"`
+	Text string `json:"text" example:"simple "`
+}
+
+// Diff compares two revisions of a quote field by field.
+type Diff struct {
+	QuoteID int      `json:"quote_id" example:"1"`
+	From    int      `json:"from" example:"1"`
+	To      int      `json:"to" example:"2"`
+	Author  []DiffOp `json:"author"`
+	Quote   []DiffOp `json:"quote"`
+}
+
+func NewDiff(from, to Revision) *Diff {
+	return &Diff{
+		QuoteID: from.QuoteID,
+		From:    from.Revision,
+		To:      to.Revision,
+		Author:  diffWords(from.Author, to.Author),
+		Quote:   diffWords(from.Quote, to.Quote),
+	}
+}
+
+// maxDiffCells bounds the LCS table of diffWords, which takes
+// len(x)*len(y) cells after the common prefix and suffix are cut off.
+// Larger changes are shown as a whole-text replace.
+const maxDiffCells = 1 << 20
+
+// diffWords is a word-level diff based on the longest common subsequence.
+// Each word keeps its trailing spaces, so joining the equal and delete ops
+// gives back a and joining the equal and insert ops gives back b.
+func diffWords(a, b string) []DiffOp {
+	x, y := strings.SplitAfter(a, " "), strings.SplitAfter(b, " ")
+	if a == "" {
+		x = nil
+	}
+	if b == "" {
+		y = nil
+	}
+	ops := []DiffOp{}
+	add := func(op, text string) {
+		if text == "" {
+			return
+		}
+		if n := len(ops); n > 0 && ops[n-1].Op == op {
+			ops[n-1].Text += text
+			return
+		}
+		ops = append(ops, DiffOp{Op: op, Text: text})
+	}
+	prefix := 0
+	for prefix < len(x) && prefix < len(y) && x[prefix] == y[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(x)-prefix && suffix < len(y)-prefix && x[len(x)-1-suffix] == y[len(y)-1-suffix] {
+		suffix++
+	}
+	add(DiffEqual, strings.Join(x[:prefix], ""))
+	tail := strings.Join(x[len(x)-suffix:], "")
+	x, y = x[prefix:len(x)-suffix], y[prefix:len(y)-suffix]
+	if len(x)*len(y) > maxDiffCells {
+		add(DiffDelete, strings.Join(x, ""))
+		add(DiffInsert, strings.Join(y, ""))
+		add(DiffEqual, tail)
+		return ops
+	}
+	// lcs[i][j] is the length of the LCS of x[i:] and y[j:].
+	lcs := make([][]int, len(x)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(y)+1)
+	}
+	for i := len(x) - 1; i >= 0; i-- {
+		for j := len(y) - 1; j >= 0; j-- {
+			if x[i] == y[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	i, j := 0, 0
+	for i < len(x) && j < len(y) {
+		switch {
+		case x[i] == y[j]:
+			add(DiffEqual, x[i])
+			i, j = i+1, j+1
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			add(DiffDelete, x[i])
+			i++
+		default:
+			add(DiffInsert, y[j])
+			j++
+		}
+	}
+	for ; i < len(x); i++ {
+		add(DiffDelete, x[i])
+	}
+	for ; j < len(y); j++ {
+		add(DiffInsert, y[j])
+	}
+	add(DiffEqual, tail)
+	return ops
+}
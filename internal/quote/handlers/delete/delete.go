@@ -1,24 +1,28 @@
 package delete
 
 import (
+	"context"
 	"log/slog"
 	"net/http"
 	"quotes-mini-service/pkg/res"
 	"quotes-mini-service/pkg/sl"
+	"quotes-mini-service/pkg/tracing"
 	"strconv"
 	"strings"
 )
 
 type QuoteDeleter interface {
-	Delete(id int) error
+	Delete(ctx context.Context, id int) error
 }
 
 func New(log *slog.Logger, delete QuoteDeleter) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "handlers.delete.New"
-		log = log.With(
+		log := log.With(
 			slog.String("op", op),
 		)
+		ctx, span := tracing.Start(r.Context(), op)
+		defer span.End()
 		idStr := r.PathValue("id")
 		id, err := strconv.Atoi(idStr)
 		if err != nil {
@@ -27,7 +31,7 @@ func New(log *slog.Logger, delete QuoteDeleter) http.HandlerFunc {
 			return
 		}
 		log.Info("id converted", slog.Any("id", id))
-		err = delete.Delete(id)
+		err = delete.Delete(ctx, id)
 		if err != nil {
 			log.Error(err.Error(), sl.Err(err))
 			if strings.Contains(err.Error(), "not found") {
@@ -1,6 +1,7 @@
 package delete
 
 import (
+	"context"
 	"errors"
 	"log/slog"
 	"net/http"
@@ -23,7 +24,7 @@ func newMockQuoteDeleter() *mockQuoteDeleter {
 	}
 }
 
-func (m *mockQuoteDeleter) Delete(id int) error {
+func (m *mockQuoteDeleter) Delete(_ context.Context, id int) error {
 	if !m.existingIDs[id] {
 		return errors.New("not found")
 	}
@@ -1,23 +1,49 @@
 package get
 
 import (
+	"context"
+	"encoding/xml"
+	"errors"
 	"log/slog"
 	"net/http"
 	"quotes-mini-service/internal/quote"
 	"quotes-mini-service/pkg/res"
 	"quotes-mini-service/pkg/sl"
+	"quotes-mini-service/pkg/tracing"
+	"strconv"
+	"strings"
 )
 
 type QuoteGetter interface {
-	GetAllParam(author string) ([]quote.Quote, int, error)
-	GetRandom() (*quote.Quote, error)
+	GetAllParam(ctx context.Context, author string) ([]quote.Quote, int, error)
+	List(ctx context.Context, f quote.Filter) ([]quote.Quote, int, error)
+	GetByID(ctx context.Context, id int) (*quote.Quote, error)
+	GetRandom(ctx context.Context) (*quote.Quote, error)
+	RandomBatch(ctx context.Context, langs quote.Languages, count int) ([]quote.Quote, error)
+	Translations(ctx context.Context, id int) ([]quote.Quote, error)
 }
 type GetWithParamResponse struct {
-	Quotes []quote.Quote `json:"quotes"`
-	Count  int           `json:"count" example:"100"`
+	XMLName xml.Name      `json:"-" xml:"quotes"`
+	Quotes  []quote.Quote `json:"quotes" xml:"quote"`
+	Count   int           `json:"count" xml:"count,attr" example:"100"`
+}
+
+func (r *GetWithParamResponse) MarshalCSV() ([]string, [][]string) {
+	return quote.QuotesCSV(r.Quotes)
+}
+
+func (r *GetWithParamResponse) MarshalText() string {
+	lines := make([]string, 0, len(r.Quotes))
+	for _, q := range r.Quotes {
+		lines = append(lines, q.MarshalText())
+	}
+	return strings.Join(lines, "\n")
 }
 
 func NewResponseWithParam(quotes []quote.Quote, count int) *GetWithParamResponse {
+	if quotes == nil {
+		quotes = []quote.Quote{}
+	}
 	return &GetWithParamResponse{
 		Quotes: quotes,
 		Count:  count,
@@ -26,16 +52,34 @@ func NewResponseWithParam(quotes []quote.Quote, count int) *GetWithParamResponse
 func AllParam(log *slog.Logger, get QuoteGetter) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "handlers.get.All"
-		log = log.With(
+		log := log.With(
 			slog.String("op", op),
 		)
-		author := r.URL.Query().Get("author")
+		ctx, span := tracing.Start(r.Context(), op)
+		defer span.End()
+		f := quote.Filter{Author: r.URL.Query().Get("author"), Sort: r.URL.Query().Get("sort"), Limit: -1}
+		if !quote.ValidSort(f.Sort) {
+			res.Json(w, res.Error(sortMessage), http.StatusBadRequest)
+			return
+		}
+		msg := parseSource(r.URL.Query(), &f)
+		if msg == "" {
+			f.Langs, msg = listLanguages(w, r)
+		}
+		if msg != "" {
+			res.Json(w, res.Error(msg), http.StatusBadRequest)
+			return
+		}
 		var (
 			quotes []quote.Quote
 			count  int
 			err    error
 		)
-		quotes, count, err = get.GetAllParam(author)
+		if authorOnly(f) {
+			quotes, count, err = get.GetAllParam(ctx, f.Author)
+		} else {
+			quotes, count, err = get.List(ctx, f)
+		}
 		if err != nil {
 			log.Error("internal server error", sl.Err(err))
 			res.Json(w, res.Error("internal server error"), http.StatusInternalServerError)
@@ -43,24 +87,92 @@ func AllParam(log *slog.Logger, get QuoteGetter) http.HandlerFunc {
 		}
 		log.Info("slice of quotes getted")
 		response := NewResponseWithParam(quotes, count)
+		if res.NotModified(w, r, response, quote.ETag(quotes...), quote.LastModified(quotes...)) {
+			log.Debug("quotes not modified")
+			return
+		}
 		log.Info("response id ready", slog.Any("quotes", response), slog.Int("count", count))
-		res.Json(w, response, http.StatusOK)
+		res.Render(w, r, response, http.StatusOK)
+	}
+}
+
+func ByID(log *slog.Logger, get QuoteGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.get.ByID"
+		log := log.With(
+			slog.String("op", op),
+		)
+		ctx, span := tracing.Start(r.Context(), op)
+		defer span.End()
+		id, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			log.Error("invalid argument", sl.Err(err))
+			res.Json(w, res.Error("invalid argument"), http.StatusBadRequest)
+			return
+		}
+		q, err := get.GetByID(ctx, id)
+		if errors.Is(err, quote.ErrNotFound) {
+			res.Json(w, res.Error("entry with this id not found"), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			log.Error("internal server error", sl.Err(err))
+			res.Json(w, res.Error("internal server error"), http.StatusInternalServerError)
+			return
+		}
+		if res.NotModified(w, r, q, quote.ETag(*q), quote.LastModified(*q)) {
+			log.Debug("quote not modified", slog.Int("id", id))
+			return
+		}
+		res.Render(w, r, q, http.StatusOK)
 	}
 }
 
 func Random(log *slog.Logger, get QuoteGetter) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "handlers.get.Random"
-		log = log.With(
+		log := log.With(
 			slog.String("op", op),
 		)
-		randomQuote, err := get.GetRandom()
+		ctx, span := tracing.Start(r.Context(), op)
+		defer span.End()
+		langs, msg := languages(w, r)
+		if msg != "" {
+			res.Json(w, res.Error(msg), http.StatusBadRequest)
+			return
+		}
+		randomQuote, err := random(ctx, get, langs)
+		if errors.Is(err, quote.ErrNotFound) {
+			log.Info("no quotes to choose from")
+			res.Json(w, res.Error("no quotes found"), http.StatusNotFound)
+			return
+		}
 		if err != nil {
 			log.Error("internal server error", sl.Err(err))
 			res.Json(w, res.Error("internal server error"), http.StatusInternalServerError)
 			return
 		}
 		log.Info("quote getted", slog.Any("res", randomQuote))
-		res.Json(w, randomQuote, http.StatusOK)
+		res.Render(w, r, randomQuote, http.StatusOK)
+	}
+}
+
+// authorOnly reports whether GetAllParam, which the cache serves, can answer
+// for the filter.
+func authorOnly(f quote.Filter) bool {
+	return f.Sort == quote.SortID && len(f.Langs) == 0 && f.Source == "" && f.YearFrom == 0 && f.YearTo == 0
+}
+
+func random(ctx context.Context, get QuoteGetter, langs quote.Languages) (*quote.Quote, error) {
+	if len(langs.Codes) == 0 {
+		return get.GetRandom(ctx)
+	}
+	quotes, err := get.RandomBatch(ctx, langs, 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(quotes) == 0 {
+		return nil, quote.ErrNotFound
 	}
+	return &quotes[0], nil
 }
@@ -1,7 +1,10 @@
 package get
 
 import (
+	"context"
+	"encoding/csv"
 	"encoding/json"
+	"encoding/xml"
 	"errors"
 	"log/slog"
 	"net/http"
@@ -26,7 +29,7 @@ func newMockQuoteGetter() *mockQuoteGetter {
 	}
 }
 
-func (m *mockQuoteGetter) GetAllParam(author string) ([]quote.Quote, int, error) {
+func (m *mockQuoteGetter) GetAllParam(_ context.Context, author string) ([]quote.Quote, int, error) {
 	var filtered []quote.Quote
 	for _, q := range m.quotes {
 		if author == "" || q.Author == author {
@@ -36,7 +39,16 @@ func (m *mockQuoteGetter) GetAllParam(author string) ([]quote.Quote, int, error)
 	return filtered, len(filtered), nil
 }
 
-func (m *mockQuoteGetter) GetRandom() (*quote.Quote, error) {
+func (m *mockQuoteGetter) GetByID(_ context.Context, id int) (*quote.Quote, error) {
+	for i := range m.quotes {
+		if m.quotes[i].ID == id {
+			return &m.quotes[i], nil
+		}
+	}
+	return nil, quote.ErrNotFound
+}
+
+func (m *mockQuoteGetter) GetRandom(context.Context) (*quote.Quote, error) {
 	if len(m.quotes) == 0 {
 		return nil, errors.New("no quotes available")
 	} 
@@ -119,4 +131,166 @@ func TestRandom_Success(t *testing.T) {
 	if response.ID == 0 {
 		t.Error("expected non-zero ID")
 	}
-}
\ No newline at end of file
+}
+func TestAllParam_CSV(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	getter := newMockQuoteGetter()
+	handler := AllParam(log, getter)
+
+	r := httptest.NewRequest(http.MethodGet, "/quotes?author=Author1", nil)
+	r.Header.Set("Accept", "text/csv")
+	w := httptest.NewRecorder()
+
+	handler(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	records, err := csv.NewReader(w.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("failed to parse csv: %v", err)
+	}
+	if len(records) != 3 || records[0][1] != "author" || records[1][1] != "Author1" {
+		t.Errorf("unexpected csv records %v", records)
+	}
+}
+
+func TestRandom_XML(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	getter := newMockQuoteGetter()
+	handler := Random(log, getter)
+
+	r := httptest.NewRequest(http.MethodGet, "/quotes/random", nil)
+	r.Header.Set("Accept", "application/xml")
+	w := httptest.NewRecorder()
+
+	handler(w, r)
+
+	var response quote.Quote
+	if err := xml.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode xml: %v", err)
+	}
+	if response.ID != 1 || response.Author != "Author1" {
+		t.Errorf("unexpected quote %+v", response)
+	}
+}
+
+func TestAllParam_NotModified(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	getter := newMockQuoteGetter()
+	handler := AllParam(log, getter)
+
+	w := httptest.NewRecorder()
+	handler(w, httptest.NewRequest(http.MethodGet, "/quotes", nil))
+	etag := w.Header().Get("ETag")
+	if etag == "" || w.Header().Get("Last-Modified") == "" {
+		t.Fatalf("expected validators, got headers %v", w.Header())
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/quotes", nil)
+	r.Header.Set("If-None-Match", etag)
+	w = httptest.NewRecorder()
+	handler(w, r)
+	if w.Code != http.StatusNotModified {
+		t.Errorf("expected status %d, got %d", http.StatusNotModified, w.Code)
+	}
+	if w.Body.Len() != 0 {
+		t.Errorf("expected empty body, got %q", w.Body.String())
+	}
+
+	getter.quotes = getter.quotes[:2]
+	w = httptest.NewRecorder()
+	handler(w, r)
+	if w.Code != http.StatusOK {
+		t.Errorf("expected status %d after a change, got %d", http.StatusOK, w.Code)
+	}
+}
+
+func TestByID(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	handler := ByID(log, newMockQuoteGetter())
+
+	tests := []struct {
+		id     string
+		status int
+	}{
+		{"2", http.StatusOK},
+		{"999", http.StatusNotFound},
+		{"abc", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, "/quotes/"+tt.id, nil)
+		r.SetPathValue("id", tt.id)
+		w := httptest.NewRecorder()
+		handler(w, r)
+		if w.Code != tt.status {
+			t.Errorf("id %s: expected status %d, got %d", tt.id, tt.status, w.Code)
+		}
+	}
+}
+
+func TestAllParam_Sort(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	getter := newMockQuoteGetter()
+	getter.quotes[2].Rating = 5
+	handler := AllParam(log, getter)
+
+	r := httptest.NewRequest(http.MethodGet, "/quotes?sort=rating&author=Author1", nil)
+	w := httptest.NewRecorder()
+	handler(w, r)
+	var response GetWithParamResponse
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if response.Count != 2 || len(response.Quotes) != 2 || response.Quotes[0].ID != 3 {
+		t.Errorf("expected quote 3 first, got %+v", response)
+	}
+
+	r = httptest.NewRequest(http.MethodGet, "/quotes?sort=author", nil)
+	w = httptest.NewRecorder()
+	handler(w, r)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestAllParam_Source(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	getter := newMockQuoteGetter()
+	getter.quotes[0].Citation = quote.Citation{Source: "Analects", Year: -479}
+	getter.quotes[1].Citation = quote.Citation{Source: "Letters", Year: 65}
+	getter.quotes[2].Citation = quote.Citation{Source: "Analects"}
+	handler := AllParam(log, getter)
+
+	tests := []struct {
+		target string
+		status int
+		count  int
+	}{
+		{"/quotes?source=Analects", http.StatusOK, 2},
+		{"/quotes?year_from=-500&year_to=-1", http.StatusOK, 1},
+		{"/quotes?year_from=1", http.StatusOK, 1},
+		{"/quotes?source=Analects&year_to=100", http.StatusOK, 1},
+		{"/quotes?year_to=-", http.StatusBadRequest, 0},
+		{"/quotes?year_from=100&year_to=-100", http.StatusBadRequest, 0},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, tt.target, nil)
+		w := httptest.NewRecorder()
+		handler(w, r)
+		if w.Code != tt.status {
+			t.Errorf("%s: expected status %d, got %d", tt.target, tt.status, w.Code)
+			continue
+		}
+		if tt.status != http.StatusOK {
+			continue
+		}
+		var response GetWithParamResponse
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Fatalf("%s: failed to decode response: %v", tt.target, err)
+		}
+		if response.Count != tt.count {
+			t.Errorf("%s: expected %d quotes, got %d", tt.target, tt.count, response.Count)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package get
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"quotes-mini-service/internal/quote"
+	"quotes-mini-service/pkg/res"
+	"quotes-mini-service/pkg/sl"
+	"quotes-mini-service/pkg/tracing"
+	"strconv"
+)
+
+const (
+	defaultBatch = 5
+	maxBatch     = 20
+	langMessage  = "lang must be a language code such as en or ru"
+)
+
+// langParam is the language the lang parameter restricts a read to. Nil
+// means any language.
+func langParam(r *http.Request) ([]string, string) {
+	v := r.URL.Query().Get("lang")
+	if v == "" {
+		return nil, ""
+	}
+	lang, err := quote.NormalizeLang(v)
+	if err != nil {
+		return nil, langMessage
+	}
+	return []string{lang}, ""
+}
+
+// listLanguages are the languages a list is restricted to: the lang
+// parameter, or else the Accept-Language header. Nil means any language.
+func listLanguages(w http.ResponseWriter, r *http.Request) ([]string, string) {
+	w.Header().Add("Vary", "Accept-Language")
+	only, msg := langParam(r)
+	if msg != "" || only != nil {
+		return only, msg
+	}
+	return quote.PreferredLanguages(r.Header.Get("Accept-Language")), ""
+}
+
+// languages steer a random pick: the lang parameter restricts it, while the
+// Accept-Language header only ranks the languages it lists first.
+func languages(w http.ResponseWriter, r *http.Request) (quote.Languages, string) {
+	w.Header().Add("Vary", "Accept-Language")
+	only, msg := langParam(r)
+	if msg != "" || only != nil {
+		return quote.Languages{Codes: only, Only: true}, msg
+	}
+	return quote.Languages{Codes: quote.PreferredLanguages(r.Header.Get("Accept-Language"))}, ""
+}
+
+// Batch returns several random quotes, at most one version of each quote.
+func Batch(log *slog.Logger, get QuoteGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.get.Batch"
+		log := log.With(
+			slog.String("op", op),
+		)
+		ctx, span := tracing.Start(r.Context(), op)
+		defer span.End()
+		count := defaultBatch
+		if v := r.URL.Query().Get("count"); v != "" {
+			var err error
+			if count, err = strconv.Atoi(v); err != nil || count <= 0 || count > maxBatch {
+				res.Json(w, res.Error("count must be between 1 and "+strconv.Itoa(maxBatch)), http.StatusBadRequest)
+				return
+			}
+		}
+		langs, msg := languages(w, r)
+		if msg != "" {
+			res.Json(w, res.Error(msg), http.StatusBadRequest)
+			return
+		}
+		quotes, err := get.RandomBatch(ctx, langs, count)
+		if err != nil {
+			log.Error("internal server error", sl.Err(err))
+			res.Json(w, res.Error("internal server error"), http.StatusInternalServerError)
+			return
+		}
+		res.Render(w, r, NewResponseWithParam(quotes, len(quotes)), http.StatusOK)
+	}
+}
+
+// Translations lists the other versions of a quote, the original first.
+func Translations(log *slog.Logger, get QuoteGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.get.Translations"
+		log := log.With(
+			slog.String("op", op),
+		)
+		ctx, span := tracing.Start(r.Context(), op)
+		defer span.End()
+		id, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			log.Error("invalid argument", sl.Err(err))
+			res.Json(w, res.Error("invalid argument"), http.StatusBadRequest)
+			return
+		}
+		quotes, err := get.Translations(ctx, id)
+		if errors.Is(err, quote.ErrNotFound) {
+			res.Json(w, res.Error("entry with this id not found"), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			log.Error("internal server error", sl.Err(err))
+			res.Json(w, res.Error("internal server error"), http.StatusInternalServerError)
+			return
+		}
+		response := NewResponseWithParam(quotes, len(quotes))
+		if res.NotModified(w, r, response, quote.ETag(quotes...), quote.LastModified(quotes...)) {
+			log.Debug("translations not modified", slog.Int("id", id))
+			return
+		}
+		res.Render(w, r, response, http.StatusOK)
+	}
+}
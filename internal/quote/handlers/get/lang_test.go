@@ -0,0 +1,188 @@
+package get
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"quotes-mini-service/internal/quote"
+	"slices"
+	"testing"
+	"time"
+)
+
+// RandomBatch of the mock is not random: it returns the best ranked version
+// of each group in the order of the quotes.
+func (m *mockQuoteGetter) RandomBatch(_ context.Context, langs quote.Languages, count int) ([]quote.Quote, error) {
+	rank := func(q quote.Quote) int {
+		if i := slices.Index(langs.Codes, q.Lang); i >= 0 {
+			return i
+		}
+		return len(langs.Codes)
+	}
+	quotes := []quote.Quote{}
+	index := map[int]int{}
+	for _, q := range m.quotes {
+		if langs.Only && rank(q) == len(langs.Codes) {
+			continue
+		}
+		group := cmp.Or(q.TranslationOf, q.ID)
+		if i, ok := index[group]; ok {
+			if rank(q) < rank(quotes[i]) {
+				quotes[i] = q
+			}
+			continue
+		}
+		if len(quotes) < count {
+			index[group] = len(quotes)
+			quotes = append(quotes, q)
+		}
+	}
+	return quotes, nil
+}
+
+func (m *mockQuoteGetter) Translations(_ context.Context, id int) ([]quote.Quote, error) {
+	var root int
+	for _, q := range m.quotes {
+		if q.ID == id {
+			root = cmp.Or(q.TranslationOf, q.ID)
+		}
+	}
+	if root == 0 {
+		return nil, quote.ErrNotFound
+	}
+	quotes := []quote.Quote{}
+	for _, q := range m.quotes {
+		if q.ID != id && (q.ID == root || q.TranslationOf == root) {
+			quotes = append(quotes, q)
+		}
+	}
+	return quotes, nil
+}
+
+func newMockTranslations() *mockQuoteGetter {
+	return &mockQuoteGetter{
+		quotes: []quote.Quote{
+			{ID: 1, Author: "Author1", Quote: "Quote1", Lang: "en", CreatedAt: time.Now()},
+			{ID: 2, Author: "Author1", Quote: "Цитата1", Lang: "ru", TranslationOf: 1, CreatedAt: time.Now()},
+			{ID: 3, Author: "Author2", Quote: "Цитата2", Lang: "ru", CreatedAt: time.Now()},
+			{ID: 4, Author: "Author2", Quote: "Quote2", Lang: "en", TranslationOf: 3, CreatedAt: time.Now()},
+		},
+	}
+}
+
+func TestLanguages(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		target  string
+		accept  string
+		status  int
+		ids     []int
+	}{
+		{"all", AllParam(log, newMockTranslations()), "/quotes", "", http.StatusOK, []int{1, 2, 3, 4}},
+		{"all lang", AllParam(log, newMockTranslations()), "/quotes?lang=ru", "", http.StatusOK, []int{2, 3}},
+		{"all header", AllParam(log, newMockTranslations()), "/quotes", "en-US,en;q=0.9", http.StatusOK, []int{1, 4}},
+		{"all param wins", AllParam(log, newMockTranslations()), "/quotes?lang=RU", "en", http.StatusOK, []int{2, 3}},
+		{"all wildcard", AllParam(log, newMockTranslations()), "/quotes", "*", http.StatusOK, []int{1, 2, 3, 4}},
+		{"all invalid", AllParam(log, newMockTranslations()), "/quotes?lang=english", "", http.StatusBadRequest, nil},
+		{"page header", Page(log, newMockTranslations()), "/v2/quotes", "ru", http.StatusOK, []int{2, 3}},
+		{"batch", Batch(log, newMockTranslations()), "/quotes/random/batch", "", http.StatusOK, []int{1, 3}},
+		{"batch lang", Batch(log, newMockTranslations()), "/quotes/random/batch?lang=en&count=1", "", http.StatusOK, []int{1}},
+		{"batch lang missing", Batch(log, newMockTranslations()), "/quotes/random/batch?lang=de", "", http.StatusOK, []int{}},
+		{"batch header", Batch(log, newMockTranslations()), "/quotes/random/batch", "ru", http.StatusOK, []int{2, 3}},
+		{"batch header fallback", Batch(log, newMockTranslations()), "/quotes/random/batch", "de, en;q=0.5", http.StatusOK, []int{1, 4}},
+		{"batch wildcard", Batch(log, newMockTranslations()), "/quotes/random/batch", "*", http.StatusOK, []int{1, 3}},
+		{"batch count", Batch(log, newMockTranslations()), "/quotes/random/batch?count=21", "", http.StatusBadRequest, nil},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, tt.target, nil)
+		if tt.accept != "" {
+			r.Header.Set("Accept-Language", tt.accept)
+		}
+		w := httptest.NewRecorder()
+		tt.handler(w, r)
+		if w.Code != tt.status {
+			t.Errorf("%s: expected status %d, got %d", tt.name, tt.status, w.Code)
+			continue
+		}
+		if tt.ids == nil {
+			continue
+		}
+		if w.Header().Get("Vary") != "Accept-Language" {
+			t.Errorf("%s: expected Vary: Accept-Language", tt.name)
+		}
+		var body struct {
+			Quotes []quote.Quote `json:"quotes"`
+			Data   []quote.Quote `json:"data"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+			t.Fatalf("%s: failed to decode response: %v", tt.name, err)
+		}
+		ids := []int{}
+		for _, q := range append(body.Quotes, body.Data...) {
+			ids = append(ids, q.ID)
+		}
+		if !slices.Equal(ids, tt.ids) {
+			t.Errorf("%s: expected ids %v, got %v", tt.name, tt.ids, ids)
+		}
+	}
+}
+
+func TestRandom_Lang(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	handler := Random(log, newMockTranslations())
+	r := httptest.NewRequest(http.MethodGet, "/quotes/random?lang=ru", nil)
+	w := httptest.NewRecorder()
+	handler(w, r)
+	var q quote.Quote
+	if err := json.NewDecoder(w.Body).Decode(&q); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if q.Lang != "ru" {
+		t.Errorf("expected a quote in ru, got %q", q.Lang)
+	}
+
+	r = httptest.NewRequest(http.MethodGet, "/quotes/random", nil)
+	r.Header.Set("Accept-Language", "de")
+	w = httptest.NewRecorder()
+	handler(w, r)
+	if w.Code != http.StatusOK {
+		t.Errorf("expected a quote in another language, got status %d", w.Code)
+	}
+
+	r = httptest.NewRequest(http.MethodGet, "/quotes/random?lang=de", nil)
+	w = httptest.NewRecorder()
+	handler(w, r)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected status %d, got %d", http.StatusNotFound, w.Code)
+	}
+}
+
+func TestTranslations(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	handler := Translations(log, newMockTranslations())
+	r := httptest.NewRequest(http.MethodGet, "/quotes/4/translations", nil)
+	r.SetPathValue("id", "4")
+	w := httptest.NewRecorder()
+	handler(w, r)
+	var response GetWithParamResponse
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if response.Count != 1 || response.Quotes[0].ID != 3 {
+		t.Errorf("expected the original 3, got %+v", response.Quotes)
+	}
+
+	r = httptest.NewRequest(http.MethodGet, "/quotes/9/translations", nil)
+	r.SetPathValue("id", "9")
+	w = httptest.NewRecorder()
+	handler(w, r)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected status %d, got %d", http.StatusNotFound, w.Code)
+	}
+}
@@ -0,0 +1,122 @@
+package get
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"quotes-mini-service/internal/quote"
+	"quotes-mini-service/pkg/res"
+	"quotes-mini-service/pkg/sl"
+	"quotes-mini-service/pkg/tracing"
+	"strconv"
+)
+
+const (
+	defaultLimit = 20
+	maxLimit     = 100
+	sortMessage  = "sort must be one of rating, likes"
+)
+
+type QuoteLister interface {
+	List(ctx context.Context, f quote.Filter) ([]quote.Quote, int, error)
+}
+
+type PageResponse struct {
+	Data       []quote.Quote  `json:"data"`
+	Pagination res.Pagination `json:"pagination"`
+}
+
+// Page lists quotes a page at a time, wrapped in a pagination envelope.
+func Page(log *slog.Logger, list QuoteLister) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.get.Page"
+		log := log.With(
+			slog.String("op", op),
+		)
+		ctx, span := tracing.Start(r.Context(), op)
+		defer span.End()
+		f, msg := parseFilter(r.URL.Query())
+		if msg != "" {
+			res.Json(w, res.Error(msg), http.StatusBadRequest)
+			return
+		}
+		if f.Langs, msg = listLanguages(w, r); msg != "" {
+			res.Json(w, res.Error(msg), http.StatusBadRequest)
+			return
+		}
+		quotes, total, err := list.List(ctx, f)
+		if err != nil {
+			log.Error("internal server error", sl.Err(err))
+			res.Json(w, res.Error("internal server error"), http.StatusInternalServerError)
+			return
+		}
+		if quotes == nil {
+			quotes = []quote.Quote{}
+		}
+		p := res.Pagination{Limit: f.Limit, Offset: f.Offset, Total: total}
+		if f.Offset+len(quotes) < total {
+			p.Next = pageLink(r.URL, f.Limit, f.Offset+f.Limit)
+		}
+		if f.Offset > 0 {
+			p.Prev = pageLink(r.URL, f.Limit, max(f.Offset-f.Limit, 0))
+		}
+		res.Json(w, PageResponse{Data: quotes, Pagination: p}, http.StatusOK)
+	}
+}
+
+func parseFilter(q url.Values) (quote.Filter, string) {
+	f := quote.Filter{
+		Author: q.Get("author"),
+		Tag:    q.Get("tag"),
+		Sort:   q.Get("sort"),
+		Limit:  defaultLimit,
+	}
+	if !quote.ValidSort(f.Sort) {
+		return f, sortMessage
+	}
+	if msg := parseSource(q, &f); msg != "" {
+		return f, msg
+	}
+	var err error
+	if v := q.Get("limit"); v != "" {
+		if f.Limit, err = strconv.Atoi(v); err != nil || f.Limit <= 0 || f.Limit > maxLimit {
+			return f, "limit must be between 1 and " + strconv.Itoa(maxLimit)
+		}
+	}
+	if v := q.Get("offset"); v != "" {
+		if f.Offset, err = strconv.Atoi(v); err != nil || f.Offset < 0 {
+			return f, "offset must be a non-negative integer"
+		}
+	}
+	return f, ""
+}
+
+// parseSource reads the source and year range filters shared by both
+// versions of the quote list.
+func parseSource(q url.Values, f *quote.Filter) string {
+	f.Source = q.Get("source")
+	var err error
+	if v := q.Get("year_from"); v != "" {
+		if f.YearFrom, err = strconv.Atoi(v); err != nil {
+			return "year_from must be an integer"
+		}
+	}
+	if v := q.Get("year_to"); v != "" {
+		if f.YearTo, err = strconv.Atoi(v); err != nil {
+			return "year_to must be an integer"
+		}
+	}
+	if f.YearFrom != 0 && f.YearTo != 0 && f.YearFrom > f.YearTo {
+		return "year_from must not be after year_to"
+	}
+	return ""
+}
+
+// pageLink keeps the filters of the current request and moves the window.
+func pageLink(u *url.URL, limit, offset int) string {
+	q := u.Query()
+	q.Set("limit", strconv.Itoa(limit))
+	q.Set("offset", strconv.Itoa(offset))
+	return u.Path + "?" + q.Encode()
+}
@@ -0,0 +1,105 @@
+package get
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"quotes-mini-service/internal/quote"
+	"slices"
+	"testing"
+)
+
+func (m *mockQuoteGetter) List(_ context.Context, f quote.Filter) ([]quote.Quote, int, error) {
+	var filtered []quote.Quote
+	for _, q := range m.quotes {
+		if (f.Author == "" || q.Author == f.Author) && (len(f.Langs) == 0 || slices.Contains(f.Langs, q.Lang)) &&
+			(f.Source == "" || q.Source == f.Source) && inYears(q, f) {
+			filtered = append(filtered, q)
+		}
+	}
+	switch f.Sort {
+	case quote.SortRating:
+		slices.SortStableFunc(filtered, func(a, b quote.Quote) int { return cmp.Compare(b.Rating, a.Rating) })
+	case quote.SortLikes:
+		slices.SortStableFunc(filtered, func(a, b quote.Quote) int { return cmp.Compare(b.Likes, a.Likes) })
+	}
+	total := len(filtered)
+	if f.Limit < 0 {
+		return filtered[min(f.Offset, total):], total, nil
+	}
+	filtered = filtered[min(f.Offset, total):min(f.Offset+f.Limit, total)]
+	return filtered, total, nil
+}
+
+func inYears(q quote.Quote, f quote.Filter) bool {
+	if f.YearFrom == 0 && f.YearTo == 0 {
+		return true
+	}
+	return q.Year != 0 && (f.YearFrom == 0 || q.Year >= f.YearFrom) && (f.YearTo == 0 || q.Year <= f.YearTo)
+}
+
+func TestPage(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	tests := []struct {
+		target string
+		status int
+		ids    []int
+		next   string
+		prev   string
+	}{
+		{"/v2/quotes", http.StatusOK, []int{1, 2, 3}, "", ""},
+		{"/v2/quotes?limit=2", http.StatusOK, []int{1, 2}, "/v2/quotes?limit=2&offset=2", ""},
+		{"/v2/quotes?author=Author1&limit=1&offset=1", http.StatusOK, []int{3}, "", "/v2/quotes?author=Author1&limit=1&offset=0"},
+		{"/v2/quotes?offset=10", http.StatusOK, []int{}, "", "/v2/quotes?limit=20&offset=0"},
+		{"/v2/quotes?limit=0", http.StatusBadRequest, nil, "", ""},
+		{"/v2/quotes?limit=101", http.StatusBadRequest, nil, "", ""},
+		{"/v2/quotes?offset=-1", http.StatusBadRequest, nil, "", ""},
+		{"/v2/quotes?sort=rating", http.StatusOK, []int{3, 2, 1}, "", ""},
+		{"/v2/quotes?sort=likes&limit=1", http.StatusOK, []int{2}, "/v2/quotes?limit=1&offset=1&sort=likes", ""},
+		{"/v2/quotes?sort=author", http.StatusBadRequest, nil, "", ""},
+		{"/v2/quotes?year_from=1900&year_to=2000", http.StatusOK, []int{}, "", ""},
+		{"/v2/quotes?year_from=old", http.StatusBadRequest, nil, "", ""},
+		{"/v2/quotes?year_from=2000&year_to=1900", http.StatusBadRequest, nil, "", ""},
+	}
+	getter := newMockQuoteGetter()
+	getter.quotes[1].Likes, getter.quotes[1].Rating = 3, 4
+	getter.quotes[2].Rating = 4.5
+	for _, tt := range tests {
+		rr := httptest.NewRecorder()
+		Page(log, getter)(rr, httptest.NewRequest(http.MethodGet, tt.target, nil))
+		if rr.Code != tt.status {
+			t.Errorf("%s: expected status %d, got %d", tt.target, tt.status, rr.Code)
+			continue
+		}
+		if tt.status != http.StatusOK {
+			continue
+		}
+		var resp PageResponse
+		if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+			t.Fatalf("%s: failed to decode response: %v", tt.target, err)
+		}
+		if resp.Data == nil {
+			t.Errorf("%s: expected data to be an array", tt.target)
+		}
+		var ids []int
+		for _, q := range resp.Data {
+			ids = append(ids, q.ID)
+		}
+		if len(ids) != len(tt.ids) {
+			t.Errorf("%s: expected ids %v, got %v", tt.target, tt.ids, ids)
+		}
+		for i := range ids {
+			if i < len(tt.ids) && ids[i] != tt.ids[i] {
+				t.Errorf("%s: expected ids %v, got %v", tt.target, tt.ids, ids)
+				break
+			}
+		}
+		if resp.Pagination.Next != tt.next || resp.Pagination.Prev != tt.prev {
+			t.Errorf("%s: unexpected links next=%q prev=%q", tt.target, resp.Pagination.Next, resp.Pagination.Prev)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package get
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"quotes-mini-service/internal/quote"
+	"quotes-mini-service/pkg/res"
+	"quotes-mini-service/pkg/sl"
+	"quotes-mini-service/pkg/tracing"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultPeriod   = "week"
+	defaultTopLimit = 10
+)
+
+// periods maps the period parameter to how far back votes count; "all"
+// ranks by the aggregates stored on the quotes.
+var periods = map[string]time.Duration{
+	"day":   24 * time.Hour,
+	"week":  7 * 24 * time.Hour,
+	"month": 30 * 24 * time.Hour,
+	"year":  365 * 24 * time.Hour,
+	"all":   0,
+}
+
+type TopQuoter interface {
+	Top(ctx context.Context, since time.Time, limit int) ([]quote.Quote, error)
+}
+
+type TopResponse struct {
+	Period string        `json:"period" example:"week"`
+	Quotes []quote.Quote `json:"quotes"`
+}
+
+// Top lists the best rated quotes of a period.
+func Top(log *slog.Logger, top TopQuoter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.get.Top"
+		log := log.With(
+			slog.String("op", op),
+		)
+		ctx, span := tracing.Start(r.Context(), op)
+		defer span.End()
+		period := r.URL.Query().Get("period")
+		if period == "" {
+			period = defaultPeriod
+		}
+		window, ok := periods[period]
+		if !ok {
+			res.Json(w, res.Error("period must be one of day, week, month, year, all"), http.StatusBadRequest)
+			return
+		}
+		limit := defaultTopLimit
+		if v := r.URL.Query().Get("limit"); v != "" {
+			var err error
+			if limit, err = strconv.Atoi(v); err != nil || limit <= 0 || limit > maxLimit {
+				res.Json(w, res.Error("limit must be between 1 and "+strconv.Itoa(maxLimit)), http.StatusBadRequest)
+				return
+			}
+		}
+		var since time.Time
+		if window > 0 {
+			since = time.Now().Add(-window)
+		}
+		quotes, err := top.Top(ctx, since, limit)
+		if err != nil {
+			log.Error("internal server error", sl.Err(err))
+			res.Json(w, res.Error("internal server error"), http.StatusInternalServerError)
+			return
+		}
+		if quotes == nil {
+			quotes = []quote.Quote{}
+		}
+		res.Json(w, TopResponse{Period: period, Quotes: quotes}, http.StatusOK)
+	}
+}
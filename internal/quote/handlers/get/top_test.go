@@ -0,0 +1,71 @@
+package get
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"quotes-mini-service/internal/quote"
+	"testing"
+	"time"
+)
+
+type mockTopQuoter struct {
+	since time.Time
+	limit int
+}
+
+func (m *mockTopQuoter) Top(_ context.Context, since time.Time, limit int) ([]quote.Quote, error) {
+	m.since, m.limit = since, limit
+	return nil, nil
+}
+
+func TestTop(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	tests := []struct {
+		target string
+		status int
+		period string
+		window time.Duration
+		limit  int
+	}{
+		{"/quotes/top", http.StatusOK, "week", 7 * 24 * time.Hour, 10},
+		{"/quotes/top?period=day&limit=3", http.StatusOK, "day", 24 * time.Hour, 3},
+		{"/quotes/top?period=all", http.StatusOK, "all", 0, 10},
+		{"/quotes/top?period=decade", http.StatusBadRequest, "", 0, 0},
+		{"/quotes/top?limit=0", http.StatusBadRequest, "", 0, 0},
+		{"/quotes/top?limit=101", http.StatusBadRequest, "", 0, 0},
+	}
+	for _, tt := range tests {
+		top := &mockTopQuoter{}
+		rr := httptest.NewRecorder()
+		Top(log, top)(rr, httptest.NewRequest(http.MethodGet, tt.target, nil))
+		if rr.Code != tt.status {
+			t.Errorf("%s: expected status %d, got %d", tt.target, tt.status, rr.Code)
+			continue
+		}
+		if tt.status != http.StatusOK {
+			continue
+		}
+		var resp TopResponse
+		if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+			t.Fatalf("%s: failed to decode response: %v", tt.target, err)
+		}
+		if resp.Period != tt.period || resp.Quotes == nil {
+			t.Errorf("%s: unexpected response %+v", tt.target, resp)
+		}
+		if top.limit != tt.limit {
+			t.Errorf("%s: expected limit %d, got %d", tt.target, tt.limit, top.limit)
+		}
+		if tt.window == 0 && !top.since.IsZero() {
+			t.Errorf("%s: expected no lower bound, got %v", tt.target, top.since)
+		}
+		if tt.window > 0 {
+			if d := time.Since(top.since) - tt.window; d < 0 || d > time.Minute {
+				t.Errorf("%s: expected votes since %v ago, got %v", tt.target, tt.window, top.since)
+			}
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package revisions
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"quotes-mini-service/internal/quote"
+	"quotes-mini-service/pkg/res"
+	"quotes-mini-service/pkg/sl"
+	"quotes-mini-service/pkg/tracing"
+	"strconv"
+)
+
+type RevisionLister interface {
+	Revisions(ctx context.Context, id int) ([]quote.Revision, error)
+	Diff(ctx context.Context, id, from, to int) (*quote.Diff, error)
+}
+
+type RevisionRestorer interface {
+	Restore(ctx context.Context, id, rev int) (*quote.Quote, error)
+}
+
+type ListResponse struct {
+	Revisions []quote.Revision `json:"revisions"`
+	Count     int              `json:"count" example:"2"`
+}
+
+func List(log *slog.Logger, list RevisionLister) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.revisions.List"
+		log := log.With(
+			slog.String("op", op),
+		)
+		ctx, span := tracing.Start(r.Context(), op)
+		defer span.End()
+		id, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			res.Json(w, res.Error("invalid argument"), http.StatusBadRequest)
+			return
+		}
+		revisions, err := list.Revisions(ctx, id)
+		if errors.Is(err, quote.ErrNotFound) {
+			res.Json(w, res.Error("entry with this id not found"), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			log.Error("internal server error", sl.Err(err))
+			res.Json(w, res.Error("internal server error"), http.StatusInternalServerError)
+			return
+		}
+		res.Json(w, ListResponse{Revisions: revisions, Count: len(revisions)}, http.StatusOK)
+	}
+}
+
+// Diff compares the revisions given in the from and to query parameters.
+func Diff(log *slog.Logger, list RevisionLister) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.revisions.Diff"
+		log := log.With(
+			slog.String("op", op),
+		)
+		ctx, span := tracing.Start(r.Context(), op)
+		defer span.End()
+		id, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			res.Json(w, res.Error("invalid argument"), http.StatusBadRequest)
+			return
+		}
+		from, errFrom := strconv.Atoi(r.URL.Query().Get("from"))
+		to, errTo := strconv.Atoi(r.URL.Query().Get("to"))
+		if errFrom != nil || errTo != nil {
+			res.Json(w, res.Error("from and to revisions are required"), http.StatusBadRequest)
+			return
+		}
+		diff, err := list.Diff(ctx, id, from, to)
+		if errors.Is(err, quote.ErrNotFound) {
+			res.Json(w, res.Error("revision not found"), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			log.Error("internal server error", sl.Err(err))
+			res.Json(w, res.Error("internal server error"), http.StatusInternalServerError)
+			return
+		}
+		res.Json(w, diff, http.StatusOK)
+	}
+}
+
+func Restore(log *slog.Logger, restore RevisionRestorer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.revisions.Restore"
+		log := log.With(
+			slog.String("op", op),
+		)
+		ctx, span := tracing.Start(r.Context(), op)
+		defer span.End()
+		id, errID := strconv.Atoi(r.PathValue("id"))
+		rev, errRev := strconv.Atoi(r.PathValue("rev"))
+		if errID != nil || errRev != nil {
+			res.Json(w, res.Error("invalid argument"), http.StatusBadRequest)
+			return
+		}
+		restored, err := restore.Restore(ctx, id, rev)
+		switch {
+		case errors.Is(err, quote.ErrRevisionNotFound):
+			res.Json(w, res.Error("revision not found"), http.StatusNotFound)
+			return
+		case errors.Is(err, quote.ErrNotFound):
+			res.Json(w, res.Error("entry with this id not found"), http.StatusNotFound)
+			return
+		case errors.Is(err, quote.ErrDuplicate):
+			log.Error("restored revision conflicts with another quote", sl.Err(err))
+			res.Json(w, res.Error("entry already exists"), http.StatusConflict)
+			return
+		case err != nil:
+			log.Error("failed to restore revision", sl.Err(err))
+			res.Json(w, res.Error("failed to restore revision"), http.StatusInternalServerError)
+			return
+		}
+		log.Info("revision restored", slog.Int("id", id), slog.Int("revision", rev))
+		res.Render(w, r, restored, http.StatusOK)
+	}
+}
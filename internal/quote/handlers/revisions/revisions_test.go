@@ -0,0 +1,70 @@
+package revisions
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"quotes-mini-service/internal/quote"
+	"testing"
+)
+
+type mockRevisions struct{}
+
+func (mockRevisions) Revisions(_ context.Context, id int) ([]quote.Revision, error) {
+	if id != 1 {
+		return nil, quote.ErrNotFound
+	}
+	return []quote.Revision{{QuoteID: 1, Revision: 1}, {QuoteID: 1, Revision: 2}}, nil
+}
+
+func (mockRevisions) Diff(_ context.Context, id, from, to int) (*quote.Diff, error) {
+	if id != 1 || from > 2 || to > 2 {
+		return nil, quote.ErrRevisionNotFound
+	}
+	return &quote.Diff{QuoteID: id, From: from, To: to}, nil
+}
+
+func (mockRevisions) Restore(_ context.Context, id, rev int) (*quote.Quote, error) {
+	switch {
+	case id != 1:
+		return nil, quote.ErrNotFound
+	case rev > 2:
+		return nil, quote.ErrRevisionNotFound
+	case rev == 2:
+		return nil, quote.ErrDuplicate
+	}
+	return &quote.Quote{ID: id}, nil
+}
+
+func TestHandlers(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		target  string
+		id, rev string
+		status  int
+	}{
+		{"list", List(log, mockRevisions{}), "/", "1", "", http.StatusOK},
+		{"list missing quote", List(log, mockRevisions{}), "/", "9", "", http.StatusNotFound},
+		{"diff", Diff(log, mockRevisions{}), "/?from=1&to=2", "1", "", http.StatusOK},
+		{"diff missing revision", Diff(log, mockRevisions{}), "/?from=1&to=5", "1", "", http.StatusNotFound},
+		{"diff without params", Diff(log, mockRevisions{}), "/", "1", "", http.StatusBadRequest},
+		{"restore", Restore(log, mockRevisions{}), "/", "1", "1", http.StatusOK},
+		{"restore conflict", Restore(log, mockRevisions{}), "/", "1", "2", http.StatusConflict},
+		{"restore missing revision", Restore(log, mockRevisions{}), "/", "1", "3", http.StatusNotFound},
+		{"restore missing quote", Restore(log, mockRevisions{}), "/", "9", "1", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, tt.target, nil)
+		r.SetPathValue("id", tt.id)
+		r.SetPathValue("rev", tt.rev)
+		w := httptest.NewRecorder()
+		tt.handler(w, r)
+		if w.Code != tt.status {
+			t.Errorf("%s: expected status %d, got %d", tt.name, tt.status, w.Code)
+		}
+	}
+}
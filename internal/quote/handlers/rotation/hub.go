@@ -0,0 +1,80 @@
+package rotation
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+var (
+	ErrTooManyConnections = errors.New("too many connections")
+	ErrClosed             = errors.New("hub closed")
+)
+
+// Hub caps the number of open WebSocket connections and tells them to go
+// away on shutdown. Hijacked connections are invisible to
+// http.Server.Shutdown, so main closes the hub and waits for it instead.
+type Hub struct {
+	max int
+
+	mu     sync.Mutex
+	active int
+	closed bool
+	done   chan struct{}
+	wg     sync.WaitGroup
+}
+
+func NewHub(max int) *Hub {
+	return &Hub{max: max, done: make(chan struct{})}
+}
+
+func (h *Hub) join() (leave func(), err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return nil, ErrClosed
+	}
+	if h.active >= h.max {
+		return nil, ErrTooManyConnections
+	}
+	h.active++
+	h.wg.Add(1)
+	return func() {
+		h.mu.Lock()
+		h.active--
+		h.mu.Unlock()
+		h.wg.Done()
+	}, nil
+}
+
+func (h *Hub) Connections() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.active
+}
+
+// Close refuses new connections and asks open ones to close with 1001
+// (going away). It is safe to call more than once.
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if !h.closed {
+		h.closed = true
+		close(h.done)
+	}
+}
+
+// Wait blocks until every connection has finished or ctx is done.
+func (h *Hub) Wait(ctx context.Context) error {
+	finished := make(chan struct{})
+	go func() {
+		h.wg.Wait()
+		close(finished)
+	}()
+	select {
+	case <-finished:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,246 @@
+package rotation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"quotes-mini-service/internal/quote"
+	"quotes-mini-service/pkg/res"
+	"quotes-mini-service/pkg/sl"
+	"quotes-mini-service/pkg/tracing"
+	"quotes-mini-service/pkg/websocket"
+	"time"
+)
+
+const (
+	TypeSubscribe    = "subscribe"
+	TypeUnsubscribe  = "unsubscribe"
+	TypeSubscribed   = "subscribed"
+	TypeUnsubscribed = "unsubscribed"
+	TypeQuote        = "quote"
+	TypeError        = "error"
+)
+
+const (
+	defaultInterval = 30 * time.Second
+	readLimit       = 4 << 10
+	writeWait       = 10 * time.Second
+	closeWait       = time.Second
+)
+
+type RandomGetter interface {
+	GetRandomParam(ctx context.Context, author string) (*quote.Quote, error)
+}
+
+// Request is a message from the client. Interval is a Go duration such as
+// "10s"; an empty author removes the filter.
+type Request struct {
+	Type     string `json:"type"`
+	Author   string `json:"author"`
+	Interval string `json:"interval"`
+}
+
+type Message struct {
+	Type     string       `json:"type"`
+	Quote    *quote.Quote `json:"quote,omitempty"`
+	Author   string       `json:"author,omitempty"`
+	Interval string       `json:"interval,omitempty"`
+	Error    string       `json:"error,omitempty"`
+}
+
+type Options struct {
+	MinInterval  time.Duration
+	PingInterval time.Duration
+}
+
+// New serves a WebSocket that pushes a random quote every interval while
+// the client is subscribed. A subscribe message may be sent again at any
+// time to change the author filter or the interval.
+func New(log *slog.Logger, get RandomGetter, hub *Hub, opts Options) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.rotation.New"
+		log := log.With(
+			slog.String("op", op),
+		)
+		ctx, span := tracing.Start(r.Context(), op)
+		defer span.End()
+		leave, err := hub.join()
+		if err != nil {
+			w.Header().Set("Retry-After", "5")
+			res.Json(w, res.Error("too many connections, try again later"), http.StatusServiceUnavailable)
+			return
+		}
+		defer leave()
+		conn, err := websocket.Upgrade(w, r)
+		if errors.Is(err, websocket.ErrBadHandshake) {
+			log.Info("bad websocket handshake", sl.Err(err))
+			w.Header().Set("Sec-WebSocket-Version", "13")
+			res.Json(w, res.Error("websocket handshake expected"), http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			log.Error("failed to upgrade connection", sl.Err(err))
+			res.Json(w, res.Error("internal server error"), http.StatusInternalServerError)
+			return
+		}
+		defer conn.Close()
+		conn.SetReadLimit(readLimit)
+		conn.SetIdleTimeout(2 * opts.PingInterval)
+		log.Info("websocket connected", slog.String("remote", conn.RemoteAddr().String()))
+
+		s := &session{
+			log:  log,
+			conn: conn,
+			get:  get,
+			opts: opts,
+		}
+		s.run(ctx, hub.done)
+	}
+}
+
+type session struct {
+	log  *slog.Logger
+	conn *websocket.Conn
+	get  RandomGetter
+	opts Options
+
+	author string
+	ticker *time.Ticker
+}
+
+func (s *session) run(ctx context.Context, shutdown <-chan struct{}) {
+	requests := make(chan []byte)
+	readErr := make(chan error, 1)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for {
+			_, data, err := s.conn.ReadMessage()
+			if err != nil {
+				readErr <- err
+				return
+			}
+			select {
+			case requests <- data:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	ping := time.NewTicker(s.opts.PingInterval)
+	defer ping.Stop()
+	defer s.stop()
+	for {
+		select {
+		case <-shutdown:
+			s.conn.WriteClose(websocket.CloseGoingAway, "server shutting down")
+			// Give the client a moment to answer the close frame.
+			select {
+			case <-readErr:
+			case <-time.After(closeWait):
+			}
+			return
+		case err := <-readErr:
+			if !websocket.IsClose(err) {
+				s.log.Debug("websocket read failed", sl.Err(err))
+			}
+			return
+		case data := <-requests:
+			if !s.handle(ctx, data) {
+				return
+			}
+		case <-s.tick():
+			if !s.push(ctx) {
+				return
+			}
+		case <-ping.C:
+			s.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := s.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (s *session) handle(ctx context.Context, data []byte) bool {
+	var req Request
+	if err := json.Unmarshal(data, &req); err != nil {
+		return s.send(Message{Type: TypeError, Error: "invalid message"})
+	}
+	switch req.Type {
+	case TypeSubscribe:
+		interval, err := s.interval(req.Interval)
+		if err != nil {
+			return s.send(Message{Type: TypeError, Error: err.Error()})
+		}
+		s.author = req.Author
+		s.stop()
+		s.ticker = time.NewTicker(interval)
+		s.log.Debug("subscribed", slog.String("author", s.author), slog.Duration("interval", interval))
+		return s.send(Message{Type: TypeSubscribed, Author: s.author, Interval: interval.String()}) && s.push(ctx)
+	case TypeUnsubscribe:
+		s.stop()
+		return s.send(Message{Type: TypeUnsubscribed})
+	default:
+		return s.send(Message{Type: TypeError, Error: fmt.Sprintf("unknown message type %q", req.Type)})
+	}
+}
+
+func (s *session) interval(value string) (time.Duration, error) {
+	if value == "" {
+		return max(defaultInterval, s.opts.MinInterval), nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid interval %q", value)
+	}
+	if d < s.opts.MinInterval {
+		return 0, fmt.Errorf("interval must be at least %s", s.opts.MinInterval)
+	}
+	return d, nil
+}
+
+func (s *session) push(ctx context.Context) bool {
+	q, err := s.get.GetRandomParam(ctx, s.author)
+	if errors.Is(err, quote.ErrNotFound) {
+		return s.send(Message{Type: TypeError, Error: "no quotes found"})
+	}
+	if err != nil {
+		s.log.Error("failed to get random quote", sl.Err(err))
+		return s.send(Message{Type: TypeError, Error: "internal server error"})
+	}
+	return s.send(Message{Type: TypeQuote, Quote: q})
+}
+
+func (s *session) send(msg Message) bool {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		s.log.Error("failed to encode message", sl.Err(err))
+		return false
+	}
+	s.conn.SetWriteDeadline(time.Now().Add(writeWait))
+	if err = s.conn.WriteMessage(websocket.TextMessage, data); err != nil {
+		s.log.Debug("websocket write failed", sl.Err(err))
+		return false
+	}
+	return true
+}
+
+// tick is nil while unsubscribed, which blocks that select case.
+func (s *session) tick() <-chan time.Time {
+	if s.ticker == nil {
+		return nil
+	}
+	return s.ticker.C
+}
+
+func (s *session) stop() {
+	if s.ticker != nil {
+		s.ticker.Stop()
+		s.ticker = nil
+	}
+}
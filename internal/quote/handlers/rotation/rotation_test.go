@@ -0,0 +1,185 @@
+package rotation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"quotes-mini-service/internal/quote"
+	"quotes-mini-service/pkg/middleware"
+	"quotes-mini-service/pkg/websocket"
+	"strings"
+	"testing"
+	"time"
+)
+
+type mockGetter struct {
+	quotes []quote.Quote
+}
+
+func (m *mockGetter) GetRandomParam(ctx context.Context, author string) (*quote.Quote, error) {
+	for _, q := range m.quotes {
+		if author == "" || q.Author == author {
+			return &q, nil
+		}
+	}
+	return nil, fmt.Errorf("mock: %w", quote.ErrNotFound)
+}
+
+func newTestServer(t *testing.T, hub *Hub) (*httptest.Server, string) {
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	get := &mockGetter{quotes: []quote.Quote{
+		{ID: 1, Author: "Author 1", Quote: "Quote 1"},
+		{ID: 2, Author: "Author 2", Quote: "Quote 2"},
+	}}
+	h := New(log, get, hub, Options{MinInterval: 10 * time.Millisecond, PingInterval: time.Second})
+	// Wrapped in the logger as in main, to make sure the hijack gets through.
+	srv := httptest.NewServer(middleware.New(log)(h))
+	return srv, "ws" + strings.TrimPrefix(srv.URL, "http")
+}
+
+func send(t *testing.T, conn *websocket.Conn, req Request) {
+	t.Helper()
+	data, _ := json.Marshal(req)
+	if err := conn.WriteMessage(websocket.TextMessage, data); err != nil {
+		t.Fatalf("failed to send: %v", err)
+	}
+}
+
+func receive(t *testing.T, conn *websocket.Conn) Message {
+	t.Helper()
+	_, data, err := conn.ReadMessage()
+	if err != nil {
+		t.Fatalf("failed to receive: %v", err)
+	}
+	var msg Message
+	if err = json.Unmarshal(data, &msg); err != nil {
+		t.Fatalf("failed to decode %s: %v", data, err)
+	}
+	return msg
+}
+
+func TestRotation(t *testing.T) {
+	hub := NewHub(10)
+	srv, url := newTestServer(t, hub)
+	defer srv.Close()
+	conn, err := websocket.Dial(url, nil, time.Second)
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	defer conn.Close()
+
+	send(t, conn, Request{Type: TypeSubscribe, Author: "Author 2", Interval: "20ms"})
+	if msg := receive(t, conn); msg.Type != TypeSubscribed || msg.Interval != "20ms" || msg.Author != "Author 2" {
+		t.Errorf("unexpected subscribe reply %+v", msg)
+	}
+	for range 3 {
+		if msg := receive(t, conn); msg.Type != TypeQuote || msg.Quote.Author != "Author 2" {
+			t.Errorf("unexpected message %+v", msg)
+		}
+	}
+
+	// Filters can be changed mid-stream.
+	send(t, conn, Request{Type: TypeSubscribe, Author: "Author 1", Interval: "20ms"})
+	for msg := receive(t, conn); msg.Type != TypeSubscribed; msg = receive(t, conn) {
+		if msg.Type != TypeQuote {
+			t.Fatalf("unexpected message %+v", msg)
+		}
+	}
+	if msg := receive(t, conn); msg.Type != TypeQuote || msg.Quote.Author != "Author 1" {
+		t.Errorf("expected quote by the new author, got %+v", msg)
+	}
+
+	send(t, conn, Request{Type: TypeUnsubscribe})
+	for msg := receive(t, conn); msg.Type != TypeUnsubscribed; msg = receive(t, conn) {
+	}
+	send(t, conn, Request{Type: TypeSubscribe, Author: "Nobody", Interval: "1h"})
+	receive(t, conn)
+	if msg := receive(t, conn); msg.Type != TypeError || msg.Error != "no quotes found" {
+		t.Errorf("expected no quotes error, got %+v", msg)
+	}
+}
+
+func TestRotation_InvalidRequests(t *testing.T) {
+	hub := NewHub(10)
+	srv, url := newTestServer(t, hub)
+	defer srv.Close()
+	conn, err := websocket.Dial(url, nil, time.Second)
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	defer conn.Close()
+
+	tests := []struct {
+		req  string
+		want string
+	}{
+		{req: `{"type":"subscribe","interval":"1ms"}`, want: "interval must be at least 10ms"},
+		{req: `{"type":"subscribe","interval":"soon"}`, want: `invalid interval "soon"`},
+		{req: `{"type":"next"}`, want: `unknown message type "next"`},
+		{req: `not json`, want: "invalid message"},
+	}
+	for _, tt := range tests {
+		conn.WriteMessage(websocket.TextMessage, []byte(tt.req))
+		if msg := receive(t, conn); msg.Type != TypeError || msg.Error != tt.want {
+			t.Errorf("%s: expected error %q, got %+v", tt.req, tt.want, msg)
+		}
+	}
+}
+
+func TestRotation_Limits(t *testing.T) {
+	hub := NewHub(1)
+	srv, url := newTestServer(t, hub)
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status %d for a plain request, got %d", http.StatusBadRequest, resp.StatusCode)
+	}
+
+	conn, err := websocket.Dial(url, nil, time.Second)
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	defer conn.Close()
+	if _, err = websocket.Dial(url, nil, time.Second); !errors.Is(err, websocket.ErrBadHandshake) {
+		t.Errorf("expected the second connection to be refused, got %v", err)
+	}
+}
+
+func TestRotation_Shutdown(t *testing.T) {
+	hub := NewHub(10)
+	srv, url := newTestServer(t, hub)
+	defer srv.Close()
+	conn, err := websocket.Dial(url, nil, time.Second)
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	defer conn.Close()
+	send(t, conn, Request{Type: TypeSubscribe, Interval: "1h"})
+	receive(t, conn)
+	receive(t, conn)
+
+	hub.Close()
+	_, _, err = conn.ReadMessage()
+	var ce *websocket.CloseError
+	if !errors.As(err, &ce) || ce.Code != websocket.CloseGoingAway {
+		t.Errorf("expected close code %d, got %v", websocket.CloseGoingAway, err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err = hub.Wait(ctx); err != nil {
+		t.Errorf("expected connections to finish, got %v", err)
+	}
+	if _, err = websocket.Dial(url, nil, time.Second); err == nil {
+		t.Error("expected new connections to be refused after close")
+	}
+}
@@ -1,36 +1,45 @@
 package save
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
 	"quotes-mini-service/internal/quote"
+	"quotes-mini-service/pkg/decode"
 	"quotes-mini-service/pkg/res"
 	"quotes-mini-service/pkg/sl"
+	"quotes-mini-service/pkg/tracing"
 	"strings"
 )
 
 type Request struct {
 	Author string `json:"author"`
 	Quote  string `json:"quote"`
+	// Lang is detected from the quote when empty.
+	Lang          string `json:"lang,omitempty" example:"en"`
+	TranslationOf int    `json:"translation_of,omitempty" example:"1"`
+	quote.Citation
 }
 
 type QuoteSaver interface {
-	Save(authorSave, quoteSave string) (*quote.Quote, error)
+	Create(ctx context.Context, d quote.Draft) (*quote.Quote, error)
 }
 
 func New(log *slog.Logger, save QuoteSaver) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "handlers.save.New"
-		log = log.With(
+		log := log.With(
 			slog.String("op", op),
 		)
+		ctx, span := tracing.Start(r.Context(), op)
+		defer span.End()
 		var req Request
-		err := json.NewDecoder(r.Body).Decode(&req)
+		err := decode.JSON(r.Body, &req)
 		if err != nil {
 			log.Error("failed to decode request body", sl.Err(err))
-			res.Json(w, res.Error("failed to decode request"), http.StatusBadRequest)
+			res.Json(w, res.Error(decode.Message(err)), decode.Status(err))
 			return
 		}
 		log.Info("request body decoded", slog.Any("request", req))
@@ -39,8 +48,25 @@ func New(log *slog.Logger, save QuoteSaver) http.HandlerFunc {
 			res.Json(w, res.Error(err.Error()), http.StatusBadRequest)
 			return
 		}
-		newQuote, err := save.Save(req.Author, req.Quote)
+		lang, err := parseLang(req.Lang)
 		if err != nil {
+			log.Error(err.Error(), sl.Err(err))
+			res.Json(w, res.Error(err.Error()), http.StatusBadRequest)
+			return
+		}
+		newQuote, err := save.Create(ctx, quote.Draft{
+			Author:        req.Author,
+			Quote:         req.Quote,
+			Lang:          lang,
+			TranslationOf: req.TranslationOf,
+			Citation:      req.Citation,
+		})
+		if err != nil {
+			if errors.Is(err, quote.ErrOriginalNotFound) {
+				log.Error("original not found", sl.Err(err))
+				res.Json(w, res.Error("translation_of refers to a missing quote"), http.StatusBadRequest)
+				return
+			}
 			if strings.Contains(err.Error(), "duplicate") {
 				log.Error("entry already exists", sl.Err(err))
 				res.Json(w, res.Error("entry already exists"), http.StatusConflict)
@@ -51,7 +77,7 @@ func New(log *slog.Logger, save QuoteSaver) http.HandlerFunc {
 			return
 		}
 		log.Info("quote added", slog.Int64("id", int64(newQuote.ID)))
-		res.Json(w, newQuote, http.StatusCreated)
+		res.Render(w, r, newQuote, http.StatusCreated)
 	}
 }
 
@@ -63,7 +89,20 @@ func validate(req Request) error {
 		return fmt.Errorf("author is required")
 	case req.Quote == "":
 		return fmt.Errorf("quote is required")
+	case req.TranslationOf < 0:
+		return fmt.Errorf("translation_of must be a quote id")
 	default:
-		return nil
+		return req.Citation.Validate()
+	}
+}
+
+func parseLang(v string) (string, error) {
+	if v == "" {
+		return "", nil
+	}
+	l, err := quote.NormalizeLang(v)
+	if err != nil {
+		return "", fmt.Errorf("lang must be a language code such as en or ru")
 	}
+	return l, nil
 }
@@ -2,6 +2,7 @@ package save
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"log/slog"
@@ -25,7 +26,8 @@ func newMockQuoteSaver() *mockQuoteSaver {
 		lastId: 0,
 	}
 }
-func (m *mockQuoteSaver) Save(author, quoteText string) (*quote.Quote, error) {
+func (m *mockQuoteSaver) Create(_ context.Context, d quote.Draft) (*quote.Quote, error) {
+	author, quoteText := d.Author, d.Quote
 	key := author + ":" + quoteText
 	if _, exists := m.quotes[key]; exists {
 		return nil, errors.New("duplicate entry")
@@ -36,6 +38,8 @@ func (m *mockQuoteSaver) Save(author, quoteText string) (*quote.Quote, error) {
 		Author:    author,
 		Quote:     quoteText,
 		CreatedAt: time.Now(),
+		Lang:      d.Lang,
+		Citation:  d.Citation,
 	}
 	m.lastId = newid
 	m.quotes[key] = q
@@ -133,3 +137,77 @@ func TestSave_DuplicateEntry(t *testing.T) {
 		t.Errorf("expected status %d, got %d", http.StatusConflict, w2.Code)
 	}
 }
+
+func TestSave_RejectsUnknownFieldsAndTrailingData(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	repo := newMockQuoteSaver()
+	handler := New(log, repo)
+
+	for _, body := range []string{
+		`{"author":"Test Author","quote":"Test Quote","extra":true}`,
+		`{"author":"Test Author","quote":"Test Quote"}{"author":"x"}`,
+	} {
+		r := httptest.NewRequest(http.MethodPost, "/quotes", strings.NewReader(body))
+		w := httptest.NewRecorder()
+
+		handler(w, r)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status %d, got %d", body, http.StatusBadRequest, w.Code)
+		}
+	}
+}
+
+func TestSave_BodyTooLarge(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	repo := newMockQuoteSaver()
+	handler := New(log, repo)
+
+	body := `{"author":"Test Author","quote":"` + strings.Repeat("a", 64) + `"}`
+	r := httptest.NewRequest(http.MethodPost, "/quotes", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	r.Body = http.MaxBytesReader(w, r.Body, 32)
+
+	handler(w, r)
+
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("expected status %d, got %d", http.StatusRequestEntityTooLarge, w.Code)
+	}
+}
+
+func TestSave_Citation(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	tests := []struct {
+		body   string
+		status int
+	}{
+		{`{"author":"Seneca","quote":"q","source":"Letters","year":65,"url":"https://example.com/letters","page":"12-13","notes":"Letter 1"}`, http.StatusCreated},
+		{`{"author":"Seneca","quote":"q","year":-3001}`, http.StatusBadRequest},
+		{`{"author":"Seneca","quote":"q","year":3000}`, http.StatusBadRequest},
+		{`{"author":"Seneca","quote":"q","url":"example.com/letters"}`, http.StatusBadRequest},
+		{`{"author":"Seneca","quote":"q","url":"ftp://example.com"}`, http.StatusBadRequest},
+		{`{"author":"Seneca","quote":"q","source":"` + strings.Repeat("я", quote.MaxSourceLength+1) + `"}`, http.StatusBadRequest},
+		{`{"author":"Seneca","quote":"q","page":"` + strings.Repeat("1", quote.MaxPageLength+1) + `"}`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		handler := New(log, newMockQuoteSaver())
+		r := httptest.NewRequest(http.MethodPost, "/quotes", strings.NewReader(tt.body))
+		w := httptest.NewRecorder()
+		handler(w, r)
+		if w.Code != tt.status {
+			t.Errorf("%s: expected status %d, got %d", tt.body, tt.status, w.Code)
+			continue
+		}
+		if tt.status != http.StatusCreated {
+			continue
+		}
+		var q quote.Quote
+		if err := json.NewDecoder(w.Body).Decode(&q); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		want := quote.Citation{Source: "Letters", Year: 65, URL: "https://example.com/letters", Page: "12-13", Notes: "Letter 1"}
+		if q.Citation != want {
+			t.Errorf("expected citation %+v, got %+v", want, q.Citation)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"quotes-mini-service/internal/events"
+	"quotes-mini-service/pkg/res"
+	"quotes-mini-service/pkg/sl"
+	"quotes-mini-service/pkg/tracing"
+	"strconv"
+	"time"
+)
+
+const (
+	replayBatch = 500
+	// retryMillis tells EventSource clients how long to wait before
+	// reconnecting.
+	retryMillis = 3000
+)
+
+type EventSource interface {
+	Subscribe() (*events.Subscription, error)
+	Since(ctx context.Context, seq int64, limit int) ([]events.Event, error)
+}
+
+// New streams quote events as Server-Sent Events. A Last-Event-ID header,
+// or the last_event_id query parameter for clients that cannot set headers,
+// resumes the stream after that event. A comment is sent every heartbeat to
+// keep proxies from closing an idle connection.
+func New(log *slog.Logger, source EventSource, heartbeat time.Duration) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.stream.New"
+		log := log.With(
+			slog.String("op", op),
+		)
+		ctx, span := tracing.Start(r.Context(), op)
+		defer span.End()
+		last, err := lastEventID(r)
+		if err != nil {
+			res.Json(w, res.Error("invalid Last-Event-ID"), http.StatusBadRequest)
+			return
+		}
+		sub, err := source.Subscribe()
+		if errors.Is(err, events.ErrTooManySubscribers) || errors.Is(err, events.ErrClosed) {
+			w.Header().Set("Retry-After", strconv.Itoa(retryMillis/1000))
+			res.Json(w, res.Error("too many subscribers, try again later"), http.StatusServiceUnavailable)
+			return
+		}
+		if err != nil {
+			log.Error("failed to subscribe", sl.Err(err))
+			res.Json(w, res.Error("internal server error"), http.StatusInternalServerError)
+			return
+		}
+		defer sub.Close()
+
+		rc := http.NewResponseController(w)
+		// The server's WriteTimeout is an absolute deadline for the whole
+		// response; push it forward before every write instead.
+		send := func(format string, args ...any) bool {
+			if err := rc.SetWriteDeadline(time.Now().Add(2 * heartbeat)); err != nil && !errors.Is(err, http.ErrNotSupported) {
+				return false
+			}
+			if _, err := fmt.Fprintf(w, format, args...); err != nil {
+				return false
+			}
+			return rc.Flush() == nil
+		}
+		h := w.Header()
+		h.Set("Content-Type", "text/event-stream")
+		h.Set("Cache-Control", "no-cache")
+		h.Set("X-Accel-Buffering", "no")
+		w.WriteHeader(http.StatusOK)
+		if !send("retry: %d\n\n", retryMillis) {
+			log.Error("streaming is not supported by the response writer")
+			return
+		}
+
+		write := func(e events.Event) bool {
+			if e.Seq <= last {
+				return true
+			}
+			last = e.Seq
+			return send("id: %d\nevent: %s\ndata: %s\n\n", e.Seq, e.Type, e.Data)
+		}
+		// Live events are buffered from the moment of subscribing, so
+		// anything committed during the replay is not lost; duplicates are
+		// skipped by sequence number.
+		if last > 0 {
+			for {
+				batch, err := source.Since(ctx, last, replayBatch)
+				if err != nil {
+					log.Error("failed to replay events", sl.Err(err))
+					return
+				}
+				for _, e := range batch {
+					if !write(e) {
+						return
+					}
+				}
+				if len(batch) < replayBatch {
+					break
+				}
+			}
+		}
+
+		ticker := time.NewTicker(heartbeat)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case e, ok := <-sub.C:
+				if !ok || !write(e) {
+					return
+				}
+			case <-ticker.C:
+				if !send(": heartbeat\n\n") {
+					return
+				}
+			}
+		}
+	}
+}
+
+func lastEventID(r *http.Request) (int64, error) {
+	id := r.Header.Get("Last-Event-ID")
+	if id == "" {
+		id = r.URL.Query().Get("last_event_id")
+	}
+	if id == "" {
+		return 0, nil
+	}
+	seq, err := strconv.ParseInt(id, 10, 64)
+	if err != nil || seq < 0 {
+		return 0, fmt.Errorf("invalid event id %q", id)
+	}
+	return seq, nil
+}
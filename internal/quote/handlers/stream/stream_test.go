@@ -0,0 +1,121 @@
+package stream
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"quotes-mini-service/internal/events"
+	"quotes-mini-service/internal/storage"
+	"quotes-mini-service/pkg/middleware"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestSource(t *testing.T, max int) (*events.Broker, *storage.Db, context.CancelFunc) {
+	db, err := storage.NewStorage(":memory:")
+	if err != nil {
+		t.Fatalf("failed to create test database: %v", err)
+	}
+	broker := events.NewBroker(slog.New(slog.NewTextHandler(io.Discard, nil)), events.NewEventsRepository(db), events.BrokerOptions{
+		PollInterval:   10 * time.Millisecond,
+		MaxSubscribers: max,
+		History:        100,
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	go broker.Run(ctx)
+	return broker, db, cancel
+}
+
+// readEvents collects the id, event and data lines of n events, skipping
+// comments and the retry field.
+func readEvents(t *testing.T, r *bufio.Reader, n int) []string {
+	t.Helper()
+	var got []string
+	var current []string
+	for len(got) < n {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatalf("failed to read stream: %v (got %v)", err, got)
+		}
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case line == "":
+			if len(current) > 0 {
+				got = append(got, strings.Join(current, "|"))
+				current = nil
+			}
+		case strings.HasPrefix(line, ":"), strings.HasPrefix(line, "retry:"):
+		default:
+			current = append(current, line)
+		}
+	}
+	return got
+}
+
+func TestStream(t *testing.T) {
+	broker, db, cancel := newTestSource(t, 10)
+	defer cancel()
+	defer db.Close()
+	ctx := context.Background()
+	events.Append(ctx, db, events.TypeCreated, 1, map[string]int{"id": 1})
+	events.Append(ctx, db, events.TypeCreated, 2, map[string]int{"id": 2})
+
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	// The logger's writer sits in front of the handler as it does in main.
+	srv := httptest.NewServer(middleware.New(log)(New(log, broker, time.Second)))
+	defer srv.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+	req.Header.Set("Last-Event-ID", "1")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("unexpected content type %q", ct)
+	}
+	r := bufio.NewReader(resp.Body)
+
+	if got := readEvents(t, r, 1); got[0] != `id: 2|event: created|data: {"id":2}` {
+		t.Errorf("unexpected replayed event %q", got[0])
+	}
+	events.Append(ctx, db, events.TypeDeleted, 2, map[string]int{"id": 2})
+	got := readEvents(t, r, 1)
+	if !strings.HasPrefix(got[0], "id: 3|event: deleted|data: ") {
+		t.Errorf("unexpected live event %q", got[0])
+	}
+	var data map[string]int
+	json.Unmarshal([]byte(strings.SplitN(got[0], "data: ", 2)[1]), &data)
+	if data["id"] != 2 {
+		t.Errorf("unexpected event data %v", data)
+	}
+}
+
+func TestStream_Limits(t *testing.T) {
+	broker, db, cancel := newTestSource(t, 0)
+	defer cancel()
+	defer db.Close()
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	handler := New(log, broker, time.Second)
+
+	w := httptest.NewRecorder()
+	handler(w, httptest.NewRequest(http.MethodGet, "/quotes/stream", nil))
+	if w.Code != http.StatusServiceUnavailable || w.Header().Get("Retry-After") == "" {
+		t.Errorf("expected 503 with Retry-After, got %d", w.Code)
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/quotes/stream", nil)
+	r.Header.Set("Last-Event-ID", "abc")
+	w = httptest.NewRecorder()
+	handler(w, r)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+}
@@ -0,0 +1,83 @@
+package update
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"quotes-mini-service/internal/quote"
+	"quotes-mini-service/pkg/decode"
+	"quotes-mini-service/pkg/res"
+	"quotes-mini-service/pkg/sl"
+	"quotes-mini-service/pkg/tracing"
+	"strconv"
+)
+
+// Request replaces the quote as a whole: citation fields left out are
+// cleared.
+type Request struct {
+	Author string `json:"author"`
+	Quote  string `json:"quote"`
+	quote.Citation
+}
+
+type QuoteUpdater interface {
+	Update(ctx context.Context, id int, author, quote string, citation quote.Citation) (*quote.Quote, error)
+}
+
+func New(log *slog.Logger, update QuoteUpdater) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.update.New"
+		log := log.With(
+			slog.String("op", op),
+		)
+		ctx, span := tracing.Start(r.Context(), op)
+		defer span.End()
+		id, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			log.Error("invalid argument", sl.Err(err))
+			res.Json(w, res.Error("invalid argument"), http.StatusBadRequest)
+			return
+		}
+		var req Request
+		if err = decode.JSON(r.Body, &req); err != nil {
+			log.Error("failed to decode request body", sl.Err(err))
+			res.Json(w, res.Error(decode.Message(err)), decode.Status(err))
+			return
+		}
+		if err = validate(req); err != nil {
+			res.Json(w, res.Error(err.Error()), http.StatusBadRequest)
+			return
+		}
+		updated, err := update.Update(ctx, id, req.Author, req.Quote, req.Citation)
+		switch {
+		case errors.Is(err, quote.ErrNotFound):
+			res.Json(w, res.Error("entry with this id not found"), http.StatusNotFound)
+			return
+		case errors.Is(err, quote.ErrDuplicate):
+			log.Error("entry already exists", sl.Err(err))
+			res.Json(w, res.Error("entry already exists"), http.StatusConflict)
+			return
+		case err != nil:
+			log.Error("failed to update quote", sl.Err(err))
+			res.Json(w, res.Error("failed to update quote"), http.StatusInternalServerError)
+			return
+		}
+		log.Info("quote updated", slog.Int("id", id))
+		res.Render(w, r, updated, http.StatusOK)
+	}
+}
+
+func validate(req Request) error {
+	switch {
+	case req.Author == "" && req.Quote == "":
+		return fmt.Errorf("author and quote are required")
+	case req.Author == "":
+		return fmt.Errorf("author is required")
+	case req.Quote == "":
+		return fmt.Errorf("quote is required")
+	default:
+		return req.Citation.Validate()
+	}
+}
@@ -0,0 +1,56 @@
+package update
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"quotes-mini-service/internal/quote"
+	"strings"
+	"testing"
+	"time"
+)
+
+type mockQuoteUpdater struct{}
+
+func (mockQuoteUpdater) Update(_ context.Context, id int, author, text string, citation quote.Citation) (*quote.Quote, error) {
+	switch {
+	case id != 1:
+		return nil, quote.ErrNotFound
+	case text == "taken":
+		return nil, quote.ErrDuplicate
+	}
+	now := time.Now()
+	return &quote.Quote{ID: id, Author: author, Quote: text, CreatedAt: now, UpdatedAt: &now, Citation: citation}, nil
+}
+
+func TestUpdate(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	handler := New(log, mockQuoteUpdater{})
+
+	tests := []struct {
+		id     string
+		body   string
+		status int
+	}{
+		{"1", `{"author":"A","quote":"new"}`, http.StatusOK},
+		{"2", `{"author":"A","quote":"new"}`, http.StatusNotFound},
+		{"1", `{"author":"A","quote":"taken"}`, http.StatusConflict},
+		{"1", `{"author":"A"}`, http.StatusBadRequest},
+		{"1", `{"author":"A","quote":"new","extra":1}`, http.StatusBadRequest},
+		{"1", `{"author":"A","quote":"new","source":"Letters","year":65,"url":"https://example.com"}`, http.StatusOK},
+		{"1", `{"author":"A","quote":"new","url":"ftp://example.com"}`, http.StatusBadRequest},
+		{"1", `{"author":"A","quote":"new","year":3000}`, http.StatusBadRequest},
+		{"x", `{"author":"A","quote":"new"}`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodPut, "/quotes/"+tt.id, strings.NewReader(tt.body))
+		r.SetPathValue("id", tt.id)
+		w := httptest.NewRecorder()
+		handler(w, r)
+		if w.Code != tt.status {
+			t.Errorf("%s %s: expected status %d, got %d", tt.id, tt.body, tt.status, w.Code)
+		}
+	}
+}
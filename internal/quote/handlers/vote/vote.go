@@ -0,0 +1,108 @@
+package vote
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"quotes-mini-service/internal/quote"
+	"quotes-mini-service/pkg/decode"
+	"quotes-mini-service/pkg/middleware"
+	"quotes-mini-service/pkg/res"
+	"quotes-mini-service/pkg/sl"
+	"quotes-mini-service/pkg/tracing"
+	"strconv"
+)
+
+type RateRequest struct {
+	Rating int `json:"rating" example:"5"`
+}
+
+type Voter interface {
+	Like(ctx context.Context, id int, voter string) (*quote.Quote, bool, error)
+	Rate(ctx context.Context, id int, voter string, rating int) (*quote.Quote, error)
+}
+
+// Like answers 201 for a new like and 200 when the client already liked
+// the quote.
+func Like(log *slog.Logger, v Voter, clientIP func(*http.Request) string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.vote.Like"
+		log := log.With(
+			slog.String("op", op),
+		)
+		ctx, span := tracing.Start(r.Context(), op)
+		defer span.End()
+		id, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			log.Error("invalid argument", sl.Err(err))
+			res.Json(w, res.Error("invalid argument"), http.StatusBadRequest)
+			return
+		}
+		q, created, err := v.Like(ctx, id, voter(r, clientIP))
+		if errors.Is(err, quote.ErrNotFound) {
+			res.Json(w, res.Error("entry with this id not found"), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			log.Error("failed to like quote", sl.Err(err))
+			res.Json(w, res.Error("failed to like quote"), http.StatusInternalServerError)
+			return
+		}
+		status := http.StatusOK
+		if created {
+			log.Info("quote liked", slog.Int("id", id))
+			status = http.StatusCreated
+		}
+		res.Render(w, r, q, status)
+	}
+}
+
+// Rate sets the rating of the client for the quote; rating again replaces
+// the previous vote.
+func Rate(log *slog.Logger, v Voter, clientIP func(*http.Request) string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.vote.Rate"
+		log := log.With(
+			slog.String("op", op),
+		)
+		ctx, span := tracing.Start(r.Context(), op)
+		defer span.End()
+		id, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			log.Error("invalid argument", sl.Err(err))
+			res.Json(w, res.Error("invalid argument"), http.StatusBadRequest)
+			return
+		}
+		var req RateRequest
+		if err = decode.JSON(r.Body, &req); err != nil {
+			log.Error("failed to decode request body", sl.Err(err))
+			res.Json(w, res.Error(decode.Message(err)), decode.Status(err))
+			return
+		}
+		q, err := v.Rate(ctx, id, voter(r, clientIP), req.Rating)
+		switch {
+		case errors.Is(err, quote.ErrInvalidRating):
+			res.Json(w, res.Error("rating must be between 1 and 5"), http.StatusBadRequest)
+			return
+		case errors.Is(err, quote.ErrNotFound):
+			res.Json(w, res.Error("entry with this id not found"), http.StatusNotFound)
+			return
+		case err != nil:
+			log.Error("failed to rate quote", sl.Err(err))
+			res.Json(w, res.Error("failed to rate quote"), http.StatusInternalServerError)
+			return
+		}
+		log.Info("quote rated", slog.Int("id", id), slog.Int("rating", req.Rating))
+		res.Render(w, r, q, http.StatusOK)
+	}
+}
+
+// voter identifies the client the same way the rate limiter does: by the
+// authenticated subject, or by address for anonymous requests.
+func voter(r *http.Request, clientIP func(*http.Request) string) string {
+	if p, ok := middleware.PrincipalFromContext(r.Context()); ok {
+		return p.Subject
+	}
+	return "ip:" + clientIP(r)
+}
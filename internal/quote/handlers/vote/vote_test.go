@@ -0,0 +1,116 @@
+package vote
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"quotes-mini-service/internal/quote"
+	"quotes-mini-service/pkg/middleware"
+	"strings"
+	"testing"
+)
+
+type mockVoter struct {
+	likes   map[string]bool
+	ratings map[string]int
+}
+
+func newMockVoter() *mockVoter {
+	return &mockVoter{likes: map[string]bool{}, ratings: map[string]int{}}
+}
+
+func (m *mockVoter) Like(_ context.Context, id int, voter string) (*quote.Quote, bool, error) {
+	if id != 1 {
+		return nil, false, quote.ErrNotFound
+	}
+	created := !m.likes[voter]
+	m.likes[voter] = true
+	return &quote.Quote{ID: id, Likes: len(m.likes)}, created, nil
+}
+
+func (m *mockVoter) Rate(_ context.Context, id int, voter string, rating int) (*quote.Quote, error) {
+	if rating < quote.MinRating || rating > quote.MaxRating {
+		return nil, quote.ErrInvalidRating
+	}
+	if id != 1 {
+		return nil, quote.ErrNotFound
+	}
+	m.ratings[voter] = rating
+	return &quote.Quote{ID: id, Ratings: len(m.ratings)}, nil
+}
+
+func clientIP(r *http.Request) string {
+	return strings.Split(r.RemoteAddr, ":")[0]
+}
+
+func TestLike(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	v := newMockVoter()
+	handler := Like(log, v, clientIP)
+
+	tests := []struct {
+		id      string
+		remote  string
+		subject string
+		status  int
+	}{
+		{"1", "10.0.0.1:1000", "", http.StatusCreated},
+		{"1", "10.0.0.1:2000", "", http.StatusOK},
+		{"1", "10.0.0.2:1000", "", http.StatusCreated},
+		{"1", "10.0.0.1:1000", "apikey:1", http.StatusCreated},
+		{"1", "10.0.0.3:1000", "apikey:1", http.StatusOK},
+		{"2", "10.0.0.1:1000", "", http.StatusNotFound},
+		{"x", "10.0.0.1:1000", "", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodPost, "/quotes/"+tt.id+"/like", nil)
+		r.SetPathValue("id", tt.id)
+		r.RemoteAddr = tt.remote
+		if tt.subject != "" {
+			r = r.WithContext(middleware.WithPrincipal(r.Context(), &middleware.Principal{Subject: tt.subject}))
+		}
+		w := httptest.NewRecorder()
+		handler(w, r)
+		if w.Code != tt.status {
+			t.Errorf("%s from %s (%s): expected status %d, got %d", tt.id, tt.remote, tt.subject, tt.status, w.Code)
+		}
+	}
+	if len(v.likes) != 3 {
+		t.Errorf("expected 3 voters, got %v", v.likes)
+	}
+}
+
+func TestRate(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	v := newMockVoter()
+	handler := Rate(log, v, clientIP)
+
+	tests := []struct {
+		id     string
+		body   string
+		status int
+	}{
+		{"1", `{"rating":4}`, http.StatusOK},
+		{"1", `{"rating":5}`, http.StatusOK},
+		{"1", `{"rating":0}`, http.StatusBadRequest},
+		{"1", `{"rating":6}`, http.StatusBadRequest},
+		{"1", `{"rating":"5"}`, http.StatusBadRequest},
+		{"1", `{"rating":5,"extra":1}`, http.StatusBadRequest},
+		{"2", `{"rating":5}`, http.StatusNotFound},
+		{"x", `{"rating":5}`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodPut, "/quotes/"+tt.id+"/rating", strings.NewReader(tt.body))
+		r.SetPathValue("id", tt.id)
+		w := httptest.NewRecorder()
+		handler(w, r)
+		if w.Code != tt.status {
+			t.Errorf("%s %s: expected status %d, got %d", tt.id, tt.body, tt.status, w.Code)
+		}
+	}
+	if v.ratings["ip:192.0.2.1"] != 5 {
+		t.Errorf("expected the second rating to replace the first, got %v", v.ratings)
+	}
+}
@@ -0,0 +1,91 @@
+package quote
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+var ErrInvalidLang = errors.New("invalid language")
+
+// DetectLanguage guesses the language of a quote from its script: any
+// Cyrillic letter makes it Russian, otherwise any Latin letter English.
+// Migration 13 backfills existing quotes with the same rule.
+func DetectLanguage(text string) string {
+	latin := false
+	for _, r := range text {
+		switch {
+		case unicode.Is(unicode.Cyrillic, r):
+			return "ru"
+		case unicode.Is(unicode.Latin, r):
+			latin = true
+		}
+	}
+	if latin {
+		return "en"
+	}
+	return ""
+}
+
+// Languages steer a random pick. Codes are ranked most preferred first;
+// with Only set quotes in other languages are not eligible at all, as for the
+// lang parameter, while an Accept-Language header merely ranks them lower.
+type Languages struct {
+	Codes []string
+	Only  bool
+}
+
+// NormalizeLang reduces a language tag such as "en-US" to its lower-case
+// primary subtag.
+func NormalizeLang(tag string) (string, error) {
+	primary, _, _ := strings.Cut(strings.TrimSpace(tag), "-")
+	primary = strings.ToLower(primary)
+	if len(primary) < 2 || len(primary) > 3 || strings.ContainsFunc(primary, func(r rune) bool { return r < 'a' || r > 'z' }) {
+		return "", fmt.Errorf("%w: %q is not a language code", ErrInvalidLang, tag)
+	}
+	return primary, nil
+}
+
+// PreferredLanguages parses an Accept-Language header into primary
+// language codes, most preferred first. A wildcard means any language and
+// yields nil, as does a missing header; malformed entries are skipped.
+func PreferredLanguages(header string) []string {
+	type pref struct {
+		lang string
+		q    float64
+	}
+	var prefs []pref
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			var err error
+			if q, err = strconv.ParseFloat(v, 64); err != nil {
+				continue
+			}
+		}
+		if q <= 0 {
+			continue
+		}
+		if strings.TrimSpace(tag) == "*" {
+			return nil
+		}
+		lang, err := NormalizeLang(tag)
+		if err != nil {
+			continue
+		}
+		prefs = append(prefs, pref{lang, q})
+	}
+	slices.SortStableFunc(prefs, func(a, b pref) int { return cmp.Compare(b.q, a.q) })
+	var langs []string
+	for _, p := range prefs {
+		if !slices.Contains(langs, p.lang) {
+			langs = append(langs, p.lang)
+		}
+	}
+	return langs
+}
@@ -1,10 +1,181 @@
 package quote
 
-import "time"
+import (
+	"encoding/xml"
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"time"
+)
 
 type Quote struct {
-	ID        int       `json:"id" example:"1"`
+	XMLName   xml.Name   `json:"-" xml:"quote"`
+	ID        int        `json:"id" xml:"id,attr" example:"1"`
+	Author    string     `json:"author" xml:"author" example:"Confucius"`
+	Quote     string     `json:"quote" xml:"text" example:"Life is simple, but we insist on making it complicated."`
+	CreatedAt time.Time  `json:"created_at" xml:"created_at" example:"2025-05-29T00:00:00Z"`
+	CreatedBy string     `json:"created_by,omitempty" xml:"created_by,omitempty" example:"apikey:1"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty" xml:"updated_at,omitempty" example:"2025-05-30T00:00:00Z"`
+	Likes     int        `json:"likes,omitempty" xml:"likes,attr,omitempty" example:"12"`
+	// Rating is the average of Ratings votes from 1 to 5.
+	Rating  float64 `json:"rating,omitempty" xml:"rating,attr,omitempty" example:"4.5"`
+	Ratings int     `json:"ratings,omitempty" xml:"ratings,attr,omitempty" example:"8"`
+	Lang    string  `json:"lang,omitempty" xml:"lang,attr,omitempty" example:"en"`
+	// TranslationOf is the id of the original quote, zero for originals.
+	TranslationOf int `json:"translation_of,omitempty" xml:"translation_of,attr,omitempty" example:"1"`
+	Citation
+}
+
+// Citation tells where a quote comes from. Every field is optional; a zero
+// Year is unknown and negative years are BC.
+type Citation struct {
+	Source string `json:"source,omitempty" xml:"source,omitempty" example:"The Analects"`
+	Year   int    `json:"year,omitempty" xml:"year,omitempty" example:"-479"`
+	URL    string `json:"url,omitempty" xml:"url,omitempty" example:"https://en.wikisource.org/wiki/The_Analects"`
+	Page   string `json:"page,omitempty" xml:"page,omitempty" example:"12-13"`
+	Notes  string `json:"notes,omitempty" xml:"notes,omitempty" example:"Translated by James Legge."`
+}
+
+// Draft is a quote to be created. An empty Lang is detected from the text;
+// a non-zero TranslationOf links the quote to the group of that quote. Tags
+// are normalized and stored with the quote.
+type Draft struct {
+	Author        string
+	Quote         string
+	Lang          string
+	TranslationOf int
+	Tags          []string
+	Citation
+}
+
+// Orders of List.
+const (
+	SortID     = ""
+	SortRating = "rating"
+	SortLikes  = "likes"
+)
+
+// Filter selects a page of quotes for List. Empty fields match everything;
+// a negative Limit returns every match.
+type Filter struct {
+	Author string
+	Tag    string
+	Langs  []string
+	Source string
+	// YearFrom and YearTo bound the year inclusively; zero leaves that end
+	// open. Quotes without a year only match when both are zero.
+	YearFrom int
+	YearTo   int
+	Sort     string
+	Limit    int
+	Offset   int
+}
+
+// ValidSort reports whether List can order quotes by s.
+func ValidSort(s string) bool {
+	return s == SortID || s == SortRating || s == SortLikes
+}
+
+func (f Filter) orderBy() string {
+	switch f.Sort {
+	case SortRating:
+		return "rating DESC, rating_count DESC, id"
+	case SortLikes:
+		return "likes DESC, id"
+	}
+	return "id"
+}
+
+var csvHeader = []string{"id", "author", "quote", "created_at", "created_by", "updated_at", "source", "year", "url", "page", "notes"}
+
+func (q Quote) csvRecord() []string {
+	var updated string
+	if q.UpdatedAt != nil {
+		updated = q.UpdatedAt.Format(time.RFC3339)
+	}
+	var year string
+	if q.Year != 0 {
+		year = strconv.Itoa(q.Year)
+	}
+	return []string{strconv.Itoa(q.ID), q.Author, q.Quote, q.CreatedAt.Format(time.RFC3339), q.CreatedBy, updated,
+		q.Source, year, q.URL, q.Page, q.Notes}
+}
+
+func (q Quote) MarshalCSV() ([]string, [][]string) {
+	return csvHeader, [][]string{q.csvRecord()}
+}
+
+func (q Quote) MarshalText() string {
+	switch {
+	case q.Source != "" && q.Year != 0:
+		return fmt.Sprintf("%q — %s, %s (%d)", q.Quote, q.Author, q.Source, q.Year)
+	case q.Source != "":
+		return fmt.Sprintf("%q — %s, %s", q.Quote, q.Author, q.Source)
+	}
+	return fmt.Sprintf("%q — %s", q.Quote, q.Author)
+}
+
+// QuotesCSV renders a list of quotes with the same columns as a single quote.
+func QuotesCSV(quotes []Quote) ([]string, [][]string) {
+	rows := make([][]string, 0, len(quotes))
+	for _, q := range quotes {
+		rows = append(rows, q.csvRecord())
+	}
+	return csvHeader, rows
+}
+
+// Fields are the scan destinations for the Columns of a quote.
+func (q *Quote) Fields() []any {
+	return q.fields()
+}
+
+func (q *Quote) fields() []any {
+	return []any{&q.ID, &q.Author, &q.Quote, &q.CreatedAt, &q.CreatedBy, &q.UpdatedAt, &q.Likes, &q.Rating, &q.Ratings, &q.Lang, &q.TranslationOf,
+		&q.Source, &q.Year, &q.URL, &q.Page, &q.Notes}
+}
+
+// ETag is a weak validator for a set of quotes; any change to the rows in
+// the set, including additions, removals and votes, changes it.
+func ETag(quotes ...Quote) string {
+	h := fnv.New64a()
+	for _, q := range quotes {
+		fmt.Fprintf(h, "%d\x00%s\x00%s\x00%d\x00%d\x00%d\x00%d\x00%g\x00%s\x00%d\x00%+v\x00", q.ID, q.Author, q.Quote, q.CreatedAt.UnixNano(), q.modified().UnixNano(),
+			q.Likes, q.Ratings, q.Rating, q.Lang, q.TranslationOf, q.Citation)
+	}
+	return fmt.Sprintf(`W/"%d-%x"`, len(quotes), h.Sum64())
+}
+
+// LastModified is the newest timestamp in the set, zero for an empty set.
+func LastModified(quotes ...Quote) time.Time {
+	var last time.Time
+	for _, q := range quotes {
+		if m := q.modified(); m.After(last) {
+			last = m
+		}
+	}
+	return last
+}
+
+func (q Quote) modified() time.Time {
+	if q.UpdatedAt != nil && q.UpdatedAt.After(q.CreatedAt) {
+		return *q.UpdatedAt
+	}
+	return q.CreatedAt
+}
+
+// Revision is one stored version of a quote. Revision 1 is the quote as it
+// was first saved.
+type Revision struct {
+	QuoteID   int       `json:"quote_id" example:"1"`
+	Revision  int       `json:"revision" example:"2"`
 	Author    string    `json:"author" example:"Confucius"`
 	Quote     string    `json:"quote" example:"Life is simple, but we insist on making it complicated."`
+	CreatedBy string    `json:"created_by,omitempty" example:"apikey:1"`
 	CreatedAt time.Time `json:"created_at" example:"2025-05-29T00:00:00Z"`
+	Citation
+}
+
+func (r *Revision) fields() []any {
+	return []any{&r.QuoteID, &r.Revision, &r.Author, &r.Quote, &r.CreatedBy, &r.CreatedAt,
+		&r.Source, &r.Year, &r.URL, &r.Page, &r.Notes}
 }
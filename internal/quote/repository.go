@@ -1,15 +1,46 @@
 package quote
 
 import (
+	"cmp"
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
 	"math/rand/v2"
+	"quotes-mini-service/internal/audit"
+	"quotes-mini-service/internal/events"
 	"quotes-mini-service/internal/storage"
+	"quotes-mini-service/internal/webhook"
+	"quotes-mini-service/pkg/middleware"
+	"quotes-mini-service/pkg/tracing"
+	"strings"
 
 	"github.com/mattn/go-sqlite3"
 )
 
+var (
+	ErrNotFound  = errors.New("not found")
+	ErrDuplicate = errors.New("duplicate entry")
+	// ErrRevisionNotFound also matches ErrNotFound.
+	ErrRevisionNotFound = fmt.Errorf("revision %w", ErrNotFound)
+	// ErrOriginalNotFound also matches ErrNotFound.
+	ErrOriginalNotFound = fmt.Errorf("original quote %w", ErrNotFound)
+)
+
+const (
+	quoteColumns = "id, author, quote, created_at, COALESCE(created_by, ''), updated_at, likes, rating, rating_count, lang, COALESCE(original_id, 0), " +
+		"source, COALESCE(year, 0), source_url, page, notes"
+	revisionColumns = "quote_id, revision, author, quote, COALESCE(created_by, ''), created_at, " +
+		"source, COALESCE(year, 0), source_url, page, notes"
+)
+
+// Columns selects a whole quote for Quote.Fields, for repositories that join
+// quotes into their own queries.
+const Columns = quoteColumns
+
+// auditEntity names quotes in the audit log.
+const auditEntity = "quote"
+
 type QuotesRepository struct {
 	Database *storage.Db
 }
@@ -19,22 +50,47 @@ func NewQuotesRepository(databse *storage.Db) *QuotesRepository {
 		Database: databse,
 	}
 }
-func (repo *QuotesRepository) Save(authorSave, quoteSave string) (*Quote, error) {
+func (repo *QuotesRepository) Save(ctx context.Context, authorSave, quoteSave string) (*Quote, error) {
+	return repo.Create(ctx, Draft{Author: authorSave, Quote: quoteSave})
+}
+
+// Create saves a new quote. A translation joins the group of its original,
+// which holds at most one quote per language.
+func (repo *QuotesRepository) Create(ctx context.Context, d Draft) (_ *Quote, err error) {
 	const op = "quote.repository.Create"
-	tx, err := repo.Database.Begin()
+	ctx, span := startSpan(ctx, op, "INSERT")
+	defer endSpan(span, &err)
+	lang := d.Lang
+	if lang == "" {
+		lang = DetectLanguage(d.Quote)
+	}
+	tags, err := NormalizeTags(d.Tags)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	tx, err := repo.Database.BeginTx(ctx, nil)
 	if err != nil {
 		return nil, fmt.Errorf("%s: begin transaction: %w", op, err)
 	}
 	defer tx.Rollback()
-	insertStmt, err := tx.Prepare("INSERT INTO quotes(author,quote) VALUES(?, ?)")
+	var original sql.NullInt64
+	if d.TranslationOf != 0 {
+		root, err := groupRoot(ctx, tx, d.TranslationOf, lang)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		original = sql.NullInt64{Int64: int64(root), Valid: true}
+	}
+	insertStmt, err := tx.PrepareContext(ctx, `INSERT INTO quotes(author, quote, created_by, lang, original_id, source, year, source_url, page, notes)
+		VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
 	if err != nil {
 		return nil, fmt.Errorf("%s: prepare statement: %w", op, err)
 	}
 	defer insertStmt.Close()
-	res, err := insertStmt.Exec(authorSave, quoteSave)
+	res, err := insertStmt.ExecContext(ctx, d.Author, d.Quote, creator(ctx), lang, original, d.Source, nullYear(d.Year), d.URL, d.Page, d.Notes)
 	if err != nil {
 		if isDuplicateError(err) {
-			return nil, fmt.Errorf("%s: duplicate entry: %w", op, err)
+			return nil, fmt.Errorf("%s: %w: %v", op, ErrDuplicate, err)
 		}
 		return nil, fmt.Errorf("%s: execute statement: %w", op, err)
 	}
@@ -43,40 +99,52 @@ func (repo *QuotesRepository) Save(authorSave, quoteSave string) (*Quote, error)
 		return nil, fmt.Errorf("%s: failed to get last insert id: %w", op, err)
 	}
 	var quotes Quote
-	selectStmt, err := tx.Prepare("SELECT * FROM quotes WHERE id = ?")
+	selectStmt, err := tx.PrepareContext(ctx, "SELECT "+quoteColumns+" FROM quotes WHERE id = ?")
 	if err != nil {
 		return nil, fmt.Errorf("%s: prepare select: %w", op, err)
 	}
 	defer selectStmt.Close()
-	err = selectStmt.QueryRow(id).
-		Scan(&quotes.ID,
-			&quotes.Author,
-			&quotes.Quote,
-			&quotes.CreatedAt)
+	err = selectStmt.QueryRowContext(ctx, id).
+		Scan(quotes.fields()...)
 	if err != nil {
 		return nil, fmt.Errorf("%s: scan result: %w", op, err)
 	}
+	if err = addRevision(ctx, tx, quotes.ID, d.Author, d.Quote, d.Citation); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if err = insertTags(ctx, tx, quotes.ID, tags); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if err = audit.Record(ctx, tx, audit.ActionCreate, auditEntity, quotes.ID, nil, quotes); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if err = webhook.Enqueue(ctx, tx, webhook.EventQuoteCreated, quotes); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if err = events.Append(ctx, tx, events.TypeCreated, quotes.ID, quotes); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
 	if err = tx.Commit(); err != nil {
 		return nil, fmt.Errorf("%s: commit transaction: %w", op, err)
 	}
+	span.SetAttributes(tracing.Int("db.rows_affected", 1))
 	return &quotes, nil
 }
 
-func (repo *QuotesRepository) GetAllParam(author string) ([]Quote, int, error) {
-	const op = "quote.repository.GetAllParan"
-	var (
-		rows *sql.Rows
-		err  error
-	)
-	tx, err := repo.Database.Begin()
+func (repo *QuotesRepository) GetAllParam(ctx context.Context, author string) (_ []Quote, _ int, err error) {
+	const op = "quote.repository.GetAllParam"
+	ctx, span := startSpan(ctx, op, "SELECT")
+	defer endSpan(span, &err)
+	var rows *sql.Rows
+	tx, err := repo.Database.BeginTx(ctx, nil)
 	if err != nil {
 		return nil, 0, fmt.Errorf("%s: begin transaction: %w", op, err)
 	}
 	defer tx.Rollback()
 	if author == "" {
-		rows, err = tx.Query("SELECT * FROM quotes")
+		rows, err = tx.QueryContext(ctx, "SELECT "+quoteColumns+" FROM quotes")
 	} else {
-		rows, err = tx.Query("SELECT * FROM quotes WHERE author = ?", author)
+		rows, err = tx.QueryContext(ctx, "SELECT "+quoteColumns+" FROM quotes WHERE author = ?", author)
 	}
 	if err != nil {
 		return nil, 0, fmt.Errorf("%s: query execution: %w", op, err)
@@ -85,57 +153,244 @@ func (repo *QuotesRepository) GetAllParam(author string) ([]Quote, int, error) {
 	var quotes []Quote
 	for rows.Next() {
 		var quote Quote
-		if err := rows.Scan(&quote.ID,
-			&quote.Author,
-			&quote.Quote,
-			&quote.CreatedAt); err != nil {
-			return nil, 0, fmt.Errorf("%s: commit transaction: %w", op, err)
+		if err := rows.Scan(quote.fields()...); err != nil {
+			return nil, 0, fmt.Errorf("%s: scan row: %w", op, err)
 		}
 		quotes = append(quotes, quote)
 	}
+	if err = rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("%s: iterate rows: %w", op, err)
+	}
 	if err = tx.Commit(); err != nil {
 		return nil, 0, fmt.Errorf("%s: commit transaction: %w", op, err)
 	}
+	span.SetAttributes(tracing.Int("db.rows_returned", len(quotes)))
 	return quotes, len(quotes), nil
 }
 
-func (repo *QuotesRepository) GetRandom() (*Quote, error) {
-	const op = "quote.repository.GetRandom"
-	tx, err := repo.Database.Begin()
+// List returns a page of quotes ordered by id together with the number of
+// quotes matching the filter.
+func (repo *QuotesRepository) List(ctx context.Context, f Filter) (_ []Quote, _ int, err error) {
+	const op = "quote.repository.List"
+	ctx, span := startSpan(ctx, op, "SELECT")
+	defer endSpan(span, &err)
+	var (
+		conds []string
+		args  []any
+	)
+	if f.Author != "" {
+		conds, args = append(conds, "author = ?"), append(args, f.Author)
+	}
+	if f.Tag != "" {
+		conds, args = append(conds, "id IN (SELECT quote_id FROM quote_tags WHERE tag = ?)"), append(args, f.Tag)
+	}
+	if f.Source != "" {
+		conds, args = append(conds, "source = ?"), append(args, f.Source)
+	}
+	if f.YearFrom != 0 {
+		conds, args = append(conds, "year >= ?"), append(args, f.YearFrom)
+	}
+	if f.YearTo != 0 {
+		conds, args = append(conds, "year <= ?"), append(args, f.YearTo)
+	}
+	if len(f.Langs) > 0 {
+		conds = append(conds, "lang IN ("+placeholders(len(f.Langs))+")")
+		for _, l := range f.Langs {
+			args = append(args, l)
+		}
+	}
+	where := ""
+	if len(conds) > 0 {
+		where = " WHERE " + strings.Join(conds, " AND ")
+	}
+	tx, err := repo.Database.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%s: begin transaction: %w", op, err)
+	}
+	defer tx.Rollback()
+	var total int
+	if err = tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM quotes"+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("%s: count quotes: %w", op, err)
+	}
+	rows, err := tx.QueryContext(ctx, "SELECT "+quoteColumns+" FROM quotes"+where+" ORDER BY "+f.orderBy()+" LIMIT ? OFFSET ?",
+		append(args, f.Limit, f.Offset)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%s: query execution: %w", op, err)
+	}
+	defer rows.Close()
+	quotes := []Quote{}
+	for rows.Next() {
+		var q Quote
+		if err := rows.Scan(q.fields()...); err != nil {
+			return nil, 0, fmt.Errorf("%s: scan row: %w", op, err)
+		}
+		quotes = append(quotes, q)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("%s: iterate rows: %w", op, err)
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, 0, fmt.Errorf("%s: commit transaction: %w", op, err)
+	}
+	span.SetAttributes(tracing.Int("db.rows_returned", len(quotes)))
+	return quotes, total, nil
+}
+
+// CountByAuthors counts the quotes of several authors in one query.
+// Authors without quotes are missing from the result.
+func (repo *QuotesRepository) CountByAuthors(ctx context.Context, authors []string) (_ map[string]int, err error) {
+	const op = "quote.repository.CountByAuthors"
+	ctx, span := startSpan(ctx, op, "SELECT")
+	defer endSpan(span, &err)
+	counts := map[string]int{}
+	if len(authors) == 0 {
+		return counts, nil
+	}
+	args := make([]any, len(authors))
+	for i, a := range authors {
+		args[i] = a
+	}
+	rows, err := repo.Database.QueryContext(ctx,
+		"SELECT author, COUNT(*) FROM quotes WHERE author IN ("+placeholders(len(authors))+") GROUP BY author", args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: query execution: %w", op, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			author string
+			count  int
+		)
+		if err := rows.Scan(&author, &count); err != nil {
+			return nil, fmt.Errorf("%s: scan row: %w", op, err)
+		}
+		counts[author] = count
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: iterate rows: %w", op, err)
+	}
+	return counts, nil
+}
+
+// ListByAuthors returns the same page of quotes for each of several authors
+// in one query.
+func (repo *QuotesRepository) ListByAuthors(ctx context.Context, authors []string, limit, offset int) (_ map[string][]Quote, err error) {
+	const op = "quote.repository.ListByAuthors"
+	ctx, span := startSpan(ctx, op, "SELECT")
+	defer endSpan(span, &err)
+	quotes := map[string][]Quote{}
+	if len(authors) == 0 {
+		return quotes, nil
+	}
+	args := make([]any, 0, len(authors)+2)
+	for _, a := range authors {
+		args = append(args, a)
+	}
+	args = append(args, offset, offset+limit)
+	rows, err := repo.Database.QueryContext(ctx,
+		`SELECT `+quoteColumns+` FROM (
+			SELECT *, ROW_NUMBER() OVER (PARTITION BY author ORDER BY id) AS n
+			FROM quotes WHERE author IN (`+placeholders(len(authors))+`)
+		) WHERE n > ? AND n <= ? ORDER BY author, id`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: query execution: %w", op, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var q Quote
+		if err := rows.Scan(q.fields()...); err != nil {
+			return nil, fmt.Errorf("%s: scan row: %w", op, err)
+		}
+		quotes[q.Author] = append(quotes[q.Author], q)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: iterate rows: %w", op, err)
+	}
+	return quotes, nil
+}
+
+func (repo *QuotesRepository) GetByID(ctx context.Context, id int) (_ *Quote, err error) {
+	const op = "quote.repository.GetByID"
+	ctx, span := startSpan(ctx, op, "SELECT")
+	defer endSpan(span, &err)
+	var q Quote
+	err = repo.Database.QueryRowContext(ctx, "SELECT "+quoteColumns+" FROM quotes WHERE id = ?", id).
+		Scan(q.fields()...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%s: quote with id %d: %w", op, id, ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: scan result: %w", op, err)
+	}
+	span.SetAttributes(tracing.Int("db.rows_returned", 1))
+	return &q, nil
+}
+
+func (repo *QuotesRepository) GetRandom(ctx context.Context) (*Quote, error) {
+	return repo.random(ctx, "quote.repository.GetRandom", "")
+}
+
+// GetRandomParam picks a random quote by author, or from all quotes when
+// author is empty.
+func (repo *QuotesRepository) GetRandomParam(ctx context.Context, author string) (*Quote, error) {
+	return repo.random(ctx, "quote.repository.GetRandomParam", author)
+}
+
+func (repo *QuotesRepository) random(ctx context.Context, op, author string) (_ *Quote, err error) {
+	ctx, span := startSpan(ctx, op, "SELECT")
+	defer endSpan(span, &err)
+	tx, err := repo.Database.BeginTx(ctx, nil)
 	if err != nil {
 		return nil, fmt.Errorf("%s: begin transaction: %w", op, err)
 	}
 	defer tx.Rollback()
 	var count int
-	err = tx.QueryRow("SELECT count_value FROM counters WHERE table_name = 'quotes'").
-		Scan(&count)
+	if author == "" {
+		err = tx.QueryRowContext(ctx, "SELECT count_value FROM counters WHERE table_name = 'quotes'").
+			Scan(&count)
+	} else {
+		err = tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM quotes WHERE author = ?", author).
+			Scan(&count)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to get count: %w", err)
 	}
+	if count == 0 {
+		return nil, fmt.Errorf("%s: no quotes: %w", op, ErrNotFound)
+	}
 	offset := rand.IntN(count)
 	var randomQuote Quote
-	err = tx.QueryRow("SELECT * FROM quotes ORDER BY id LIMIT 1 OFFSET ?", offset).
-		Scan(&randomQuote.ID,
-			&randomQuote.Author,
-			&randomQuote.Quote,
-			&randomQuote.CreatedAt)
+	if author == "" {
+		err = tx.QueryRowContext(ctx, "SELECT "+quoteColumns+" FROM quotes ORDER BY id LIMIT 1 OFFSET ?", offset).
+			Scan(randomQuote.fields()...)
+	} else {
+		err = tx.QueryRowContext(ctx, "SELECT "+quoteColumns+" FROM quotes WHERE author = ? ORDER BY id LIMIT 1 OFFSET ?", author, offset).
+			Scan(randomQuote.fields()...)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("%s: scan result: %w", op, err)
 	}
 	if err = tx.Commit(); err != nil {
 		return nil, fmt.Errorf("%s: commit transaction: %w", op, err)
 	}
+	span.SetAttributes(tracing.Int("db.rows_returned", 1))
 	return &randomQuote, nil
 }
 
-func (repo *QuotesRepository) Delete(id int) error {
+func (repo *QuotesRepository) Delete(ctx context.Context, id int) (err error) {
 	const op = "quote.repository.Delete"
-	tx, err := repo.Database.Begin()
+	ctx, span := startSpan(ctx, op, "DELETE")
+	defer endSpan(span, &err)
+	tx, err := repo.Database.BeginTx(ctx, nil)
 	if err != nil {
 		return fmt.Errorf("%s: begin transaction: %w", op, err)
 	}
 	defer tx.Rollback()
-	result, err := tx.Exec("DELETE FROM quotes WHERE id = ?", id)
+	before, err := getQuote(ctx, tx, id)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	result, err := tx.ExecContext(ctx, "DELETE FROM quotes WHERE id = ?", id)
 	if err != nil {
 		return fmt.Errorf("%s: delete operation: %w", op, err)
 	}
@@ -143,8 +398,38 @@ func (repo *QuotesRepository) Delete(id int) error {
 	if err != nil {
 		return fmt.Errorf("%s: rows affection: %w", op, err)
 	}
+	span.SetAttributes(tracing.Int64("db.rows_affected", rowsAffected))
 	if rowsAffected == 0 {
-		return fmt.Errorf("%s: quote with id %d not found", op, id)
+		return fmt.Errorf("%s: quote with id %d: %w", op, id, ErrNotFound)
+	}
+	// Row ids can be reused after a delete, so the history goes with the quote;
+	// the audit log still keeps the last version.
+	if _, err = tx.ExecContext(ctx, "DELETE FROM quote_revisions WHERE quote_id = ?", id); err != nil {
+		return fmt.Errorf("%s: delete revisions: %w", op, err)
+	}
+	if _, err = tx.ExecContext(ctx, "DELETE FROM quote_tags WHERE quote_id = ?", id); err != nil {
+		return fmt.Errorf("%s: delete tags: %w", op, err)
+	}
+	if err = deleteVotes(ctx, tx, id); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if _, err = tx.ExecContext(ctx, "DELETE FROM quote_views WHERE quote_id = ?", id); err != nil {
+		return fmt.Errorf("%s: delete views: %w", op, err)
+	}
+	if err = deleteCollectionItems(ctx, tx, id); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if err = promoteTranslation(ctx, tx, id); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if err = audit.Record(ctx, tx, audit.ActionDelete, auditEntity, id, before, nil); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if err = webhook.Enqueue(ctx, tx, webhook.EventQuoteDeleted, before); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if err = events.Append(ctx, tx, events.TypeDeleted, id, before); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
 	}
 	if err = tx.Commit(); err != nil {
 		return fmt.Errorf("%s: commit transaction: %w", op, err)
@@ -152,6 +437,240 @@ func (repo *QuotesRepository) Delete(id int) error {
 	return nil
 }
 
+// Update replaces the text, the author and the citation of the quote.
+func (repo *QuotesRepository) Update(ctx context.Context, id int, author, quote string, citation Citation) (_ *Quote, err error) {
+	const op = "quote.repository.Update"
+	ctx, span := startSpan(ctx, op, "UPDATE")
+	defer endSpan(span, &err)
+	tx, err := repo.Database.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s: begin transaction: %w", op, err)
+	}
+	defer tx.Rollback()
+	q, err := update(ctx, tx, id, author, quote, citation)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("%s: commit transaction: %w", op, err)
+	}
+	return q, nil
+}
+
+// Restore makes the content of revision rev the current version of the
+// quote. The restore is itself recorded as a new revision.
+func (repo *QuotesRepository) Restore(ctx context.Context, id, rev int) (_ *Quote, err error) {
+	const op = "quote.repository.Restore"
+	ctx, span := startSpan(ctx, op, "UPDATE")
+	defer endSpan(span, &err)
+	tx, err := repo.Database.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s: begin transaction: %w", op, err)
+	}
+	defer tx.Rollback()
+	r, err := getRevision(ctx, tx, id, rev)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	q, err := update(ctx, tx, id, r.Author, r.Quote, r.Citation)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("%s: commit transaction: %w", op, err)
+	}
+	return q, nil
+}
+
+func (repo *QuotesRepository) Revisions(ctx context.Context, id int) (_ []Revision, err error) {
+	const op = "quote.repository.Revisions"
+	ctx, span := startSpan(ctx, op, "SELECT")
+	defer endSpan(span, &err)
+	tx, err := repo.Database.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s: begin transaction: %w", op, err)
+	}
+	defer tx.Rollback()
+	if _, err = getQuote(ctx, tx, id); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	rows, err := tx.QueryContext(ctx,
+		"SELECT "+revisionColumns+" FROM quote_revisions WHERE quote_id = ? ORDER BY revision", id)
+	if err != nil {
+		return nil, fmt.Errorf("%s: query execution: %w", op, err)
+	}
+	defer rows.Close()
+	revisions := []Revision{}
+	for rows.Next() {
+		var r Revision
+		if err := rows.Scan(r.fields()...); err != nil {
+			return nil, fmt.Errorf("%s: scan row: %w", op, err)
+		}
+		revisions = append(revisions, r)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: iterate rows: %w", op, err)
+	}
+	span.SetAttributes(tracing.Int("db.rows_returned", len(revisions)))
+	return revisions, nil
+}
+
+func (repo *QuotesRepository) Diff(ctx context.Context, id, from, to int) (_ *Diff, err error) {
+	const op = "quote.repository.Diff"
+	ctx, span := startSpan(ctx, op, "SELECT")
+	defer endSpan(span, &err)
+	tx, err := repo.Database.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s: begin transaction: %w", op, err)
+	}
+	defer tx.Rollback()
+	a, err := getRevision(ctx, tx, id, from)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	b, err := getRevision(ctx, tx, id, to)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return NewDiff(*a, *b), nil
+}
+
+// update changes the quote inside tx and records the change as a new
+// revision and in the audit log. Writing the same content again is a no-op.
+func update(ctx context.Context, tx *sql.Tx, id int, author, quote string, citation Citation) (*Quote, error) {
+	before, err := getQuote(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+	if before.Author == author && before.Quote == quote && before.Citation == citation {
+		return before, nil
+	}
+	lang, err := updatedLang(ctx, tx, before, quote)
+	if err != nil {
+		return nil, err
+	}
+	_, err = tx.ExecContext(ctx, `UPDATE quotes SET author = ?, quote = ?, lang = ?,
+		source = ?, year = ?, source_url = ?, page = ?, notes = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?`,
+		author, quote, lang, citation.Source, nullYear(citation.Year), citation.URL, citation.Page, citation.Notes, id)
+	if err != nil {
+		if isDuplicateError(err) {
+			return nil, fmt.Errorf("%w: %v", ErrDuplicate, err)
+		}
+		return nil, fmt.Errorf("update quote: %w", err)
+	}
+	if err = addRevision(ctx, tx, id, author, quote, citation); err != nil {
+		return nil, err
+	}
+	after, err := getQuote(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err = audit.Record(ctx, tx, audit.ActionUpdate, auditEntity, id, before, after); err != nil {
+		return nil, err
+	}
+	if err = webhook.Enqueue(ctx, tx, webhook.EventQuoteUpdated, after); err != nil {
+		return nil, err
+	}
+	if err = events.Append(ctx, tx, events.TypeUpdated, id, after); err != nil {
+		return nil, err
+	}
+	return after, nil
+}
+
+// updatedLang is the language of q once its text becomes quote. A language
+// that was detected from the old text is detected again; one given
+// explicitly, which detection would not have produced, is kept, as is the
+// old language when the group already has a version in the new one.
+func updatedLang(ctx context.Context, tx *sql.Tx, q *Quote, quote string) (string, error) {
+	if quote == q.Quote || q.Lang != DetectLanguage(q.Quote) {
+		return q.Lang, nil
+	}
+	lang := DetectLanguage(quote)
+	if lang == q.Lang {
+		return lang, nil
+	}
+	taken, err := hasVersion(ctx, tx, cmp.Or(q.TranslationOf, q.ID), lang)
+	if err != nil || taken {
+		return q.Lang, err
+	}
+	return lang, nil
+}
+
+// deleteCollectionItems takes the quote out of every collection and closes
+// the gap it leaves in their order.
+func deleteCollectionItems(ctx context.Context, tx *sql.Tx, id int) error {
+	_, err := tx.ExecContext(ctx, `UPDATE collection_items SET position = position - 1
+		WHERE EXISTS (SELECT 1 FROM collection_items d WHERE d.quote_id = ?
+			AND d.collection_id = collection_items.collection_id AND d.position < collection_items.position)`, id)
+	if err != nil {
+		return fmt.Errorf("renumber collection items: %w", err)
+	}
+	if _, err = tx.ExecContext(ctx, "DELETE FROM collection_items WHERE quote_id = ?", id); err != nil {
+		return fmt.Errorf("delete collection items: %w", err)
+	}
+	return nil
+}
+
+func getQuote(ctx context.Context, tx *sql.Tx, id int) (*Quote, error) {
+	var q Quote
+	err := tx.QueryRowContext(ctx, "SELECT "+quoteColumns+" FROM quotes WHERE id = ?", id).
+		Scan(q.fields()...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("quote with id %d: %w", id, ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("select quote: %w", err)
+	}
+	return &q, nil
+}
+
+func getRevision(ctx context.Context, tx *sql.Tx, id, rev int) (*Revision, error) {
+	var r Revision
+	err := tx.QueryRowContext(ctx,
+		"SELECT "+revisionColumns+" FROM quote_revisions WHERE quote_id = ? AND revision = ?", id, rev).
+		Scan(r.fields()...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("quote %d %w: %d", id, ErrRevisionNotFound, rev)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("select revision: %w", err)
+	}
+	return &r, nil
+}
+
+func addRevision(ctx context.Context, tx *sql.Tx, id int, author, quote string, c Citation) error {
+	_, err := tx.ExecContext(ctx,
+		`INSERT INTO quote_revisions(quote_id, revision, author, quote, created_by, source, year, source_url, page, notes)
+		SELECT ?, COALESCE(MAX(revision), 0) + 1, ?, ?, ?, ?, ?, ?, ?, ? FROM quote_revisions WHERE quote_id = ?`,
+		id, author, quote, creator(ctx), c.Source, nullYear(c.Year), c.URL, c.Page, c.Notes, id)
+	if err != nil {
+		return fmt.Errorf("insert revision: %w", err)
+	}
+	return nil
+}
+
+// nullYear stores the unknown year as NULL.
+func nullYear(year int) sql.NullInt64 {
+	return sql.NullInt64{Int64: int64(year), Valid: year != 0}
+}
+
+// creator is the authenticated subject saving the quote, or empty for
+// anonymous requests when authentication is disabled.
+func creator(ctx context.Context) any {
+	if p, ok := middleware.PrincipalFromContext(ctx); ok {
+		return p.Subject
+	}
+	return nil
+}
+
+func startSpan(ctx context.Context, op, statement string) (context.Context, *tracing.Span) {
+	return tracing.StartQuery(ctx, op, statement)
+}
+
+func endSpan(span *tracing.Span, err *error) {
+	tracing.EndQuery(span, err)
+}
+
 func isDuplicateError(err error) bool {
 	var sqliteErr sqlite3.Error
 	if errors.As(err, &sqliteErr) {
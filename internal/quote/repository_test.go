@@ -1,7 +1,12 @@
 package quote
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"quotes-mini-service/internal/storage"
+	"quotes-mini-service/pkg/middleware"
+	"slices"
 	"testing"
 )
 
@@ -19,11 +24,12 @@ func TestQuotesRepository_Save(t *testing.T) {
 	defer db.Close()
 
 	repo := NewQuotesRepository(db)
+	ctx := context.Background()
 
 	author := "Test Author"
 	quoteText := "Test Quote"
 
-	quote, err := repo.Save(author, quoteText)
+	quote, err := repo.Save(ctx, author, quoteText)
 	if err != nil {
 		t.Fatalf("failed to save quote: %v", err)
 	}
@@ -47,18 +53,19 @@ func TestQuotesRepository_Save_Duplicate(t *testing.T) {
 	defer db.Close()
 
 	repo := NewQuotesRepository(db)
+	ctx := context.Background()
 
 	author := "Test Author"
 	quoteText := "Test Quote"
 
 	// First save
-	_, err := repo.Save(author, quoteText)
+	_, err := repo.Save(ctx, author, quoteText)
 	if err != nil {
 		t.Fatalf("failed to save quote: %v", err)
 	}
 
 	// Second save (duplicate)
-	_, err = repo.Save(author, quoteText)
+	_, err = repo.Save(ctx, author, quoteText)
 	if err == nil {
 		t.Error("expected error for duplicate entry")
 	}
@@ -69,6 +76,7 @@ func TestQuotesRepository_GetAllParam(t *testing.T) {
 	defer db.Close()
 
 	repo := NewQuotesRepository(db)
+	ctx := context.Background()
 
 	// Add test data
 	testQuotes := []struct {
@@ -81,12 +89,12 @@ func TestQuotesRepository_GetAllParam(t *testing.T) {
 	}
 
 	for _, tq := range testQuotes {
-		_, err := repo.Save(tq.author, tq.quote)
+		_, err := repo.Save(ctx, tq.author, tq.quote)
 		if err != nil {
 			t.Fatalf("failed to save test quote: %v", err)
 		}
 	}
-	quotes, count, err := repo.GetAllParam("")
+	quotes, count, err := repo.GetAllParam(ctx, "")
 	if err != nil {
 		t.Fatalf("failed to get all quotes: %v", err)
 	}
@@ -97,7 +105,7 @@ func TestQuotesRepository_GetAllParam(t *testing.T) {
 		t.Errorf("expected 3 quotes, got %d", len(quotes))
 	}
 
-	quotes, count, err = repo.GetAllParam("Author1")
+	quotes, count, err = repo.GetAllParam(ctx, "Author1")
 	if err != nil {
 		t.Fatalf("failed to get quotes by author: %v", err)
 	}
@@ -114,13 +122,14 @@ func TestQuotesRepository_GetRandom(t *testing.T) {
 	defer db.Close()
 
 	repo := NewQuotesRepository(db)
+	ctx := context.Background()
 
-	newQuote, err := repo.Save("Test Author", "Test Quote")
+	newQuote, err := repo.Save(ctx, "Test Author", "Test Quote")
 	if err != nil {
 		t.Fatalf("failed to save test quote: %v", err)
 	}
 
-	quote, err := repo.GetRandom()
+	quote, err := repo.GetRandom(ctx)
 	if err != nil {
 		t.Fatalf("failed to get random quote: %v", err)
 	}
@@ -141,18 +150,19 @@ func TestQuotesRepository_Delete(t *testing.T) {
 	defer db.Close()
 
 	repo := NewQuotesRepository(db)
+	ctx := context.Background()
 
-	quote, err := repo.Save("Test Author", "Test Quote")
+	quote, err := repo.Save(ctx, "Test Author", "Test Quote")
 	if err != nil {
 		t.Fatalf("failed to save test quote: %v", err)
 	}
 
-	err = repo.Delete(quote.ID)
+	err = repo.Delete(ctx, quote.ID)
 	if err != nil {
 		t.Fatalf("failed to delete quote: %v", err)
 	}
 
-	_, count, err := repo.GetAllParam("")
+	_, count, err := repo.GetAllParam(ctx, "")
 	if err != nil {
 		t.Fatalf("failed to get quotes: %v", err)
 	}
@@ -166,9 +176,135 @@ func TestQuotesRepository_Delete_NotFound(t *testing.T) {
 	defer db.Close()
 
 	repo := NewQuotesRepository(db)
+	ctx := context.Background()
 
-	err := repo.Delete(999)
+	err := repo.Delete(ctx, 999)
 	if err == nil {
 		t.Error("expected error for non-existent ID")
 	}
 }
+
+func TestQuotesRepository_Save_RecordsCreator(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewQuotesRepository(db)
+	ctx := middleware.WithPrincipal(context.Background(), &middleware.Principal{Subject: "user-42"})
+
+	quote, err := repo.Save(ctx, "Test Author", "Test Quote")
+	if err != nil {
+		t.Fatalf("failed to save quote: %v", err)
+	}
+	if quote.CreatedBy != "user-42" {
+		t.Errorf("expected creator user-42, got %q", quote.CreatedBy)
+	}
+}
+
+func TestQuotesRepository_GetRandom_Empty(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewQuotesRepository(db)
+
+	if _, err := repo.GetRandom(context.Background()); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestQuotesRepository_Audit(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewQuotesRepository(db)
+	ctx := middleware.WithPrincipal(context.Background(), &middleware.Principal{Subject: "apikey:7"})
+
+	saved, err := repo.Save(ctx, "Author", "Quote")
+	if err != nil {
+		t.Fatalf("failed to save quote: %v", err)
+	}
+	if err = repo.Delete(ctx, saved.ID); err != nil {
+		t.Fatalf("failed to delete quote: %v", err)
+	}
+	// A failed delete must not leave an entry behind.
+	repo.Delete(ctx, saved.ID)
+
+	rows, err := db.Query("SELECT actor, action, entity_id, before IS NOT NULL, after IS NOT NULL FROM audit_log ORDER BY id")
+	if err != nil {
+		t.Fatalf("failed to query audit log: %v", err)
+	}
+	defer rows.Close()
+	var got []string
+	for rows.Next() {
+		var actor, action string
+		var id int
+		var before, after bool
+		if err := rows.Scan(&actor, &action, &id, &before, &after); err != nil {
+			t.Fatalf("failed to scan: %v", err)
+		}
+		got = append(got, fmt.Sprintf("%s %s %d %t %t", actor, action, id, before, after))
+	}
+	want := []string{
+		fmt.Sprintf("apikey:7 create %d false true", saved.ID),
+		fmt.Sprintf("apikey:7 delete %d true false", saved.ID),
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("expected audit entries %v, got %v", want, got)
+	}
+}
+
+func TestQuotesRepository_GetRandomParam(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewQuotesRepository(db)
+	ctx := context.Background()
+	repo.Save(ctx, "Author 1", "Quote 1")
+	repo.Save(ctx, "Author 2", "Quote 2")
+	repo.Save(ctx, "Author 1", "Quote 3")
+
+	for range 10 {
+		quote, err := repo.GetRandomParam(ctx, "Author 1")
+		if err != nil {
+			t.Fatalf("failed to get random quote: %v", err)
+		}
+		if quote.Author != "Author 1" {
+			t.Errorf("expected author %q, got %q", "Author 1", quote.Author)
+		}
+	}
+	if _, err := repo.GetRandomParam(ctx, "Nobody"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestQuotesRepository_List(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewQuotesRepository(db)
+	ctx := context.Background()
+	for i := 1; i <= 5; i++ {
+		author := "Author 1"
+		if i%2 == 0 {
+			author = "Author 2"
+		}
+		if _, err := repo.Save(ctx, author, fmt.Sprintf("Quote %d", i)); err != nil {
+			t.Fatalf("failed to save test quote: %v", err)
+		}
+	}
+
+	quotes, total, err := repo.List(ctx, Filter{Limit: 2, Offset: 2})
+	if err != nil {
+		t.Fatalf("failed to list quotes: %v", err)
+	}
+	if total != 5 || len(quotes) != 2 || quotes[0].Quote != "Quote 3" || quotes[1].Quote != "Quote 4" {
+		t.Errorf("unexpected page %+v (total %d)", quotes, total)
+	}
+	quotes, total, _ = repo.List(ctx, Filter{Author: "Author 1", Limit: 10})
+	if total != 3 || len(quotes) != 3 {
+		t.Errorf("expected 3 quotes by Author 1, got %d (total %d)", len(quotes), total)
+	}
+	quotes, _, _ = repo.List(ctx, Filter{Limit: 10, Offset: 10})
+	if quotes == nil || len(quotes) != 0 {
+		t.Errorf("expected an empty page, got %+v", quotes)
+	}
+}
@@ -0,0 +1,163 @@
+package quote
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestQuotesRepository_Revisions(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewQuotesRepository(db)
+	ctx := context.Background()
+
+	q, err := repo.Save(ctx, "Author", "Life is simple")
+	if err != nil {
+		t.Fatalf("failed to save quote: %v", err)
+	}
+	if _, err = repo.Save(ctx, "Other", "Taken"); err != nil {
+		t.Fatalf("failed to save quote: %v", err)
+	}
+	updated, err := repo.Update(ctx, q.ID, "Author", "Life is really simple", Citation{})
+	if err != nil {
+		t.Fatalf("failed to update quote: %v", err)
+	}
+	if updated.Quote != "Life is really simple" || updated.UpdatedAt == nil {
+		t.Errorf("unexpected updated quote %+v", updated)
+	}
+	// Writing the same content again does not add a revision.
+	if _, err = repo.Update(ctx, q.ID, "Author", "Life is really simple", Citation{}); err != nil {
+		t.Fatalf("failed to repeat update: %v", err)
+	}
+	if _, err = repo.Update(ctx, q.ID, "Other", "Taken", Citation{}); !errors.Is(err, ErrDuplicate) {
+		t.Errorf("expected ErrDuplicate, got %v", err)
+	}
+	if _, err = repo.Update(ctx, 999, "A", "B", Citation{}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+
+	restored, err := repo.Restore(ctx, q.ID, 1)
+	if err != nil {
+		t.Fatalf("failed to restore revision: %v", err)
+	}
+	if restored.Quote != "Life is simple" {
+		t.Errorf("expected original text, got %q", restored.Quote)
+	}
+	if _, err = repo.Restore(ctx, q.ID, 42); !errors.Is(err, ErrRevisionNotFound) {
+		t.Errorf("expected ErrRevisionNotFound, got %v", err)
+	}
+
+	revisions, err := repo.Revisions(ctx, q.ID)
+	if err != nil {
+		t.Fatalf("failed to list revisions: %v", err)
+	}
+	var texts []string
+	for _, r := range revisions {
+		texts = append(texts, r.Quote)
+	}
+	if got := strings.Join(texts, "|"); got != "Life is simple|Life is really simple|Life is simple" {
+		t.Errorf("unexpected revisions %q", got)
+	}
+
+	diff, err := repo.Diff(ctx, q.ID, 1, 2)
+	if err != nil {
+		t.Fatalf("failed to diff: %v", err)
+	}
+	if len(diff.Quote) != 3 || diff.Quote[1] != (DiffOp{Op: DiffInsert, Text: "really "}) {
+		t.Errorf("unexpected diff %+v", diff.Quote)
+	}
+
+	if err = repo.Delete(ctx, q.ID); err != nil {
+		t.Fatalf("failed to delete quote: %v", err)
+	}
+	if _, err = repo.Revisions(ctx, q.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound after delete, got %v", err)
+	}
+	var left int
+	db.QueryRow("SELECT COUNT(*) FROM quote_revisions WHERE quote_id = ?", q.ID).Scan(&left)
+	if left != 0 {
+		t.Errorf("expected revisions to be deleted with the quote, %d left", left)
+	}
+}
+
+func TestDiffWords(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want []DiffOp
+	}{
+		{"a b c", "a b c", []DiffOp{{DiffEqual, "a b c"}}},
+		{"a b c", "a x c", []DiffOp{{DiffEqual, "a "}, {DiffDelete, "b "}, {DiffInsert, "x "}, {DiffEqual, "c"}}},
+		{"", "new", []DiffOp{{DiffInsert, "new"}}},
+		{"old", "", []DiffOp{{DiffDelete, "old"}}},
+	}
+	for _, tt := range tests {
+		got := diffWords(tt.a, tt.b)
+		if len(got) != len(tt.want) {
+			t.Errorf("%q -> %q: expected %v, got %v", tt.a, tt.b, tt.want, got)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("%q -> %q: expected %v, got %v", tt.a, tt.b, tt.want, got)
+				break
+			}
+		}
+	}
+}
+
+func TestDiffWords_Large(t *testing.T) {
+	a := strings.Repeat("a ", 2000) + "start " + strings.Repeat("x ", 2000) + "end"
+	b := strings.Repeat("a ", 2000) + "start " + strings.Repeat("y ", 2000) + "end"
+	got := diffWords(a, b)
+	want := []DiffOp{
+		{DiffEqual, strings.Repeat("a ", 2000) + "start "},
+		{DiffDelete, strings.Repeat("x ", 2000)},
+		{DiffInsert, strings.Repeat("y ", 2000)},
+		{DiffEqual, "end"},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("expected a whole-text replace between the common prefix and suffix, got %d ops", len(got))
+	}
+}
+
+func TestQuotesRepository_UpdateCitation(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewQuotesRepository(db)
+	ctx := context.Background()
+
+	original := Citation{Source: "Letters", Year: 65, Page: "12"}
+	q, err := repo.Create(ctx, Draft{Author: "Seneca", Quote: "Luck is what happens", Citation: original})
+	if err != nil {
+		t.Fatalf("failed to create quote: %v", err)
+	}
+	edited := Citation{Source: "Moral Letters to Lucilius", Year: 65, URL: "https://example.com/letters"}
+	updated, err := repo.Update(ctx, q.ID, q.Author, q.Quote, edited)
+	if err != nil {
+		t.Fatalf("failed to update citation: %v", err)
+	}
+	if updated.Citation != edited {
+		t.Errorf("expected citation %+v, got %+v", edited, updated.Citation)
+	}
+
+	revisions, err := repo.Revisions(ctx, q.ID)
+	if err != nil {
+		t.Fatalf("failed to list revisions: %v", err)
+	}
+	if len(revisions) != 2 || revisions[0].Citation != original || revisions[1].Citation != edited {
+		t.Fatalf("expected both citations to be versioned, got %+v", revisions)
+	}
+
+	restored, err := repo.Restore(ctx, q.ID, 1)
+	if err != nil {
+		t.Fatalf("failed to restore revision: %v", err)
+	}
+	if restored.Citation != original {
+		t.Errorf("expected restored citation %+v, got %+v", original, restored.Citation)
+	}
+}
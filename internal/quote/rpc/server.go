@@ -0,0 +1,219 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	quotesv1 "quotes-mini-service/api/quotes/v1"
+	"quotes-mini-service/internal/quote"
+	"quotes-mini-service/pkg/middleware"
+	"quotes-mini-service/pkg/sl"
+	"strconv"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 500
+)
+
+// Scopes lists the scope each QuoteService method needs, for
+// interceptor.RequireScope.
+var Scopes = map[string]string{
+	quotesv1.QuoteService_CreateQuote_FullMethodName:    middleware.ScopeWrite,
+	quotesv1.QuoteService_GetQuote_FullMethodName:       middleware.ScopeRead,
+	quotesv1.QuoteService_ListQuotes_FullMethodName:     middleware.ScopeRead,
+	quotesv1.QuoteService_GetRandomQuote_FullMethodName: middleware.ScopeRead,
+	quotesv1.QuoteService_DeleteQuote_FullMethodName:    middleware.ScopeDelete,
+}
+
+type Store interface {
+	Create(ctx context.Context, d quote.Draft) (*quote.Quote, error)
+	GetByID(ctx context.Context, id int) (*quote.Quote, error)
+	List(ctx context.Context, f quote.Filter) ([]quote.Quote, int, error)
+	GetRandomParam(ctx context.Context, author string) (*quote.Quote, error)
+	Delete(ctx context.Context, id int) error
+}
+
+type Server struct {
+	quotesv1.UnimplementedQuoteServiceServer
+	log   *slog.Logger
+	store Store
+}
+
+func NewServer(log *slog.Logger, store Store) *Server {
+	return &Server{
+		log:   log,
+		store: store,
+	}
+}
+
+func (s *Server) CreateQuote(ctx context.Context, req *quotesv1.CreateQuoteRequest) (*quotesv1.Quote, error) {
+	const op = "rpc.CreateQuote"
+	log := s.log.With(
+		slog.String("op", op),
+	)
+	switch {
+	case req.GetAuthor() == "" && req.GetQuote() == "":
+		return nil, status.Error(codes.InvalidArgument, "author and quote are required")
+	case req.GetAuthor() == "":
+		return nil, status.Error(codes.InvalidArgument, "author is required")
+	case req.GetQuote() == "":
+		return nil, status.Error(codes.InvalidArgument, "quote is required")
+	}
+	citation := quote.Citation{
+		Source: req.GetSource(),
+		Year:   int(req.GetYear()),
+		URL:    req.GetUrl(),
+		Page:   req.GetPage(),
+		Notes:  req.GetNotes(),
+	}
+	if err := citation.Validate(); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	q, err := s.store.Create(ctx, quote.Draft{Author: req.GetAuthor(), Quote: req.GetQuote(), Citation: citation})
+	if errors.Is(err, quote.ErrDuplicate) {
+		return nil, status.Error(codes.AlreadyExists, "entry already exists")
+	}
+	if err != nil {
+		log.Error("failed to add quote", sl.Err(err))
+		return nil, status.Error(codes.Internal, "failed to add quote")
+	}
+	log.Info("quote added", slog.Int("id", q.ID))
+	return toProto(q), nil
+}
+
+func (s *Server) GetQuote(ctx context.Context, req *quotesv1.GetQuoteRequest) (*quotesv1.Quote, error) {
+	const op = "rpc.GetQuote"
+	q, err := s.store.GetByID(ctx, int(req.GetId()))
+	if errors.Is(err, quote.ErrNotFound) {
+		return nil, status.Error(codes.NotFound, "entry with this id not found")
+	}
+	if err != nil {
+		s.log.Error("internal server error", slog.String("op", op), sl.Err(err))
+		return nil, status.Error(codes.Internal, "internal server error")
+	}
+	return toProto(q), nil
+}
+
+func (s *Server) ListQuotes(ctx context.Context, req *quotesv1.ListQuotesRequest) (*quotesv1.ListQuotesResponse, error) {
+	const op = "rpc.ListQuotes"
+	size := int(req.GetPageSize())
+	switch {
+	case size < 0:
+		return nil, status.Error(codes.InvalidArgument, "page_size must not be negative")
+	case size == 0:
+		size = defaultPageSize
+	case size > maxPageSize:
+		size = maxPageSize
+	}
+	offset := 0
+	if token := req.GetPageToken(); token != "" {
+		var err error
+		if offset, err = strconv.Atoi(token); err != nil || offset < 0 {
+			return nil, status.Error(codes.InvalidArgument, "invalid page_token")
+		}
+	}
+	f, err := listFilter(req)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	f.Limit, f.Offset = size, offset
+	quotes, total, err := s.store.List(ctx, f)
+	if err != nil {
+		s.log.Error("internal server error", slog.String("op", op), sl.Err(err))
+		return nil, status.Error(codes.Internal, "internal server error")
+	}
+	resp := &quotesv1.ListQuotesResponse{
+		Quotes:    make([]*quotesv1.Quote, 0, len(quotes)),
+		TotalSize: int32(total),
+	}
+	for i := range quotes {
+		resp.Quotes = append(resp.Quotes, toProto(&quotes[i]))
+	}
+	if next := offset + len(quotes); next < total {
+		resp.NextPageToken = strconv.Itoa(next)
+	}
+	return resp, nil
+}
+
+func (s *Server) GetRandomQuote(ctx context.Context, req *quotesv1.GetRandomQuoteRequest) (*quotesv1.Quote, error) {
+	const op = "rpc.GetRandomQuote"
+	q, err := s.store.GetRandomParam(ctx, req.GetAuthor())
+	if errors.Is(err, quote.ErrNotFound) {
+		return nil, status.Error(codes.NotFound, "no quotes found")
+	}
+	if err != nil {
+		s.log.Error("internal server error", slog.String("op", op), sl.Err(err))
+		return nil, status.Error(codes.Internal, "internal server error")
+	}
+	return toProto(q), nil
+}
+
+func (s *Server) DeleteQuote(ctx context.Context, req *quotesv1.DeleteQuoteRequest) (*emptypb.Empty, error) {
+	const op = "rpc.DeleteQuote"
+	log := s.log.With(
+		slog.String("op", op),
+	)
+	err := s.store.Delete(ctx, int(req.GetId()))
+	if errors.Is(err, quote.ErrNotFound) {
+		return nil, status.Error(codes.NotFound, "entry with this id not found")
+	}
+	if err != nil {
+		log.Error("failed to delete quote", sl.Err(err))
+		return nil, status.Error(codes.Internal, "internal server error")
+	}
+	log.Info("quote deleted", slog.Int64("id", req.GetId()))
+	return &emptypb.Empty{}, nil
+}
+
+// listFilter maps the filters of a ListQuotes request, the same ones GET
+// /quotes takes.
+func listFilter(req *quotesv1.ListQuotesRequest) (quote.Filter, error) {
+	f := quote.Filter{
+		Author:   req.GetAuthor(),
+		Tag:      req.GetTag(),
+		Source:   req.GetSource(),
+		YearFrom: int(req.GetYearFrom()),
+		YearTo:   int(req.GetYearTo()),
+	}
+	if v := req.GetLang(); v != "" {
+		lang, err := quote.NormalizeLang(v)
+		if err != nil {
+			return f, errors.New("lang must be a language code such as en or ru")
+		}
+		f.Langs = []string{lang}
+	}
+	if f.YearFrom != 0 && f.YearTo != 0 && f.YearFrom > f.YearTo {
+		return f, errors.New("year_from must not be after year_to")
+	}
+	return f, nil
+}
+
+func toProto(q *quote.Quote) *quotesv1.Quote {
+	pq := &quotesv1.Quote{
+		Id:            int64(q.ID),
+		Author:        q.Author,
+		Quote:         q.Quote,
+		CreateTime:    timestamppb.New(q.CreatedAt),
+		CreatedBy:     q.CreatedBy,
+		Source:        q.Source,
+		Year:          int32(q.Year),
+		Url:           q.URL,
+		Page:          q.Page,
+		Notes:         q.Notes,
+		Lang:          q.Lang,
+		TranslationOf: int64(q.TranslationOf),
+		Likes:         int32(q.Likes),
+		Rating:        q.Rating,
+		Ratings:       int32(q.Ratings),
+	}
+	if q.UpdatedAt != nil {
+		pq.UpdateTime = timestamppb.New(*q.UpdatedAt)
+	}
+	return pq
+}
@@ -0,0 +1,243 @@
+package rpc
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"os"
+	quotesv1 "quotes-mini-service/api/quotes/v1"
+	"quotes-mini-service/internal/quote"
+	"quotes-mini-service/internal/storage"
+	"quotes-mini-service/pkg/interceptor"
+	"quotes-mini-service/pkg/middleware"
+	"slices"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+type staticKeys map[string]*middleware.Principal
+
+func (k staticKeys) Verify(ctx context.Context, key string) (*middleware.Principal, error) {
+	if p, ok := k[key]; ok {
+		return p, nil
+	}
+	return nil, middleware.ErrInvalidCredentials
+}
+
+func newTestClient(t *testing.T) (quotesv1.QuoteServiceClient, *quote.QuotesRepository) {
+	t.Helper()
+	db, err := storage.NewStorage(":memory:")
+	if err != nil {
+		t.Fatalf("failed to create test database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	keys := staticKeys{
+		"reader": {Subject: "apikey:1", Scopes: []string{middleware.ScopeRead}},
+		"writer": {Subject: "apikey:2", Scopes: []string{middleware.ScopeRead, middleware.ScopeWrite, middleware.ScopeDelete}},
+	}
+	server := grpc.NewServer(grpc.ChainUnaryInterceptor(
+		interceptor.RequestID,
+		interceptor.Logger(log),
+		interceptor.Recover(log),
+		interceptor.Authenticate(log, middleware.APIKey{Store: keys}),
+		interceptor.RequireScope(Scopes),
+	))
+	repo := quote.NewQuotesRepository(db)
+	quotesv1.RegisterQuoteServiceServer(server, NewServer(log, repo))
+	listener := bufconn.Listen(1 << 20)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return quotesv1.NewQuoteServiceClient(conn), repo
+}
+
+func withKey(key string) context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), "x-api-key", key)
+}
+
+func TestQuoteService(t *testing.T) {
+	client, _ := newTestClient(t)
+	ctx := withKey("writer")
+
+	created, err := client.CreateQuote(ctx, &quotesv1.CreateQuoteRequest{Author: "Author 1", Quote: "Quote 1"})
+	if err != nil {
+		t.Fatalf("failed to create quote: %v", err)
+	}
+	if created.GetId() == 0 || created.GetCreatedBy() != "apikey:2" || created.GetCreateTime() == nil {
+		t.Errorf("unexpected quote %v", created)
+	}
+	client.CreateQuote(ctx, &quotesv1.CreateQuoteRequest{Author: "Author 2", Quote: "Quote 2"})
+	client.CreateQuote(ctx, &quotesv1.CreateQuoteRequest{Author: "Author 1", Quote: "Quote 3"})
+
+	_, err = client.CreateQuote(ctx, &quotesv1.CreateQuoteRequest{Author: "Author 1", Quote: "Quote 1"})
+	if status.Code(err) != codes.AlreadyExists {
+		t.Errorf("expected AlreadyExists, got %v", err)
+	}
+	_, err = client.CreateQuote(ctx, &quotesv1.CreateQuoteRequest{Author: "Author 1"})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument, got %v", err)
+	}
+	_, err = client.CreateQuote(ctx, &quotesv1.CreateQuoteRequest{Author: "Author 1", Quote: "Quote 4", Url: "ftp://example.com"})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument for an invalid url, got %v", err)
+	}
+
+	cited, err := client.CreateQuote(ctx, &quotesv1.CreateQuoteRequest{
+		Author: "Confucius", Quote: "Quote 5", Source: "The Analects", Year: -479,
+		Url: "https://en.wikisource.org/wiki/The_Analects", Page: "12", Notes: "Translated by James Legge.",
+	})
+	if err != nil {
+		t.Fatalf("failed to create quote: %v", err)
+	}
+	got, err := client.GetQuote(ctx, &quotesv1.GetQuoteRequest{Id: cited.GetId()})
+	if err != nil || got.GetSource() != "The Analects" || got.GetYear() != -479 || got.GetUrl() == "" || got.GetPage() != "12" || got.GetNotes() == "" {
+		t.Errorf("expected the citation to round-trip, got %v (%v)", got, err)
+	}
+	client.DeleteQuote(ctx, &quotesv1.DeleteQuoteRequest{Id: cited.GetId()})
+
+	got, err = client.GetQuote(ctx, &quotesv1.GetQuoteRequest{Id: created.GetId()})
+	if err != nil || got.GetQuote() != "Quote 1" {
+		t.Errorf("unexpected quote %v (%v)", got, err)
+	}
+	if _, err = client.GetQuote(ctx, &quotesv1.GetQuoteRequest{Id: 100}); status.Code(err) != codes.NotFound {
+		t.Errorf("expected NotFound, got %v", err)
+	}
+
+	page, err := client.ListQuotes(ctx, &quotesv1.ListQuotesRequest{PageSize: 2})
+	if err != nil {
+		t.Fatalf("failed to list quotes: %v", err)
+	}
+	if len(page.GetQuotes()) != 2 || page.GetTotalSize() != 3 || page.GetNextPageToken() == "" {
+		t.Errorf("unexpected first page %v", page)
+	}
+	page, _ = client.ListQuotes(ctx, &quotesv1.ListQuotesRequest{PageSize: 2, PageToken: page.GetNextPageToken()})
+	if len(page.GetQuotes()) != 1 || page.GetQuotes()[0].GetQuote() != "Quote 3" || page.GetNextPageToken() != "" {
+		t.Errorf("unexpected last page %v", page)
+	}
+	page, _ = client.ListQuotes(ctx, &quotesv1.ListQuotesRequest{Author: "Author 2"})
+	if len(page.GetQuotes()) != 1 || page.GetTotalSize() != 1 {
+		t.Errorf("unexpected filtered page %v", page)
+	}
+	if _, err = client.ListQuotes(ctx, &quotesv1.ListQuotesRequest{PageToken: "abc"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument, got %v", err)
+	}
+
+	random, err := client.GetRandomQuote(ctx, &quotesv1.GetRandomQuoteRequest{Author: "Author 2"})
+	if err != nil || random.GetQuote() != "Quote 2" {
+		t.Errorf("unexpected random quote %v (%v)", random, err)
+	}
+
+	if _, err = client.DeleteQuote(ctx, &quotesv1.DeleteQuoteRequest{Id: created.GetId()}); err != nil {
+		t.Errorf("failed to delete quote: %v", err)
+	}
+	if _, err = client.DeleteQuote(ctx, &quotesv1.DeleteQuoteRequest{Id: created.GetId()}); status.Code(err) != codes.NotFound {
+		t.Errorf("expected NotFound, got %v", err)
+	}
+}
+
+func TestQuoteService_ListFilters(t *testing.T) {
+	client, repo := newTestClient(t)
+	ctx := withKey("reader")
+
+	drafts := []quote.Draft{
+		{Author: "Confucius", Quote: "Quote 1", Lang: "en", Tags: []string{"wisdom"}, Citation: quote.Citation{Source: "The Analects", Year: -479}},
+		{Author: "Confucius", Quote: "Цитата 2", Lang: "ru", Tags: []string{"wisdom"}},
+		{Author: "Seneca", Quote: "Quote 3", Lang: "en", Citation: quote.Citation{Source: "Letters", Year: 65}},
+	}
+	for _, d := range drafts {
+		if _, err := repo.Create(context.Background(), d); err != nil {
+			t.Fatalf("failed to create quote: %v", err)
+		}
+	}
+	if _, err := repo.Rate(context.Background(), 1, "voter", 4); err != nil {
+		t.Fatalf("failed to rate quote: %v", err)
+	}
+	if _, _, err := repo.Like(context.Background(), 1, "voter"); err != nil {
+		t.Fatalf("failed to like quote: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		req  *quotesv1.ListQuotesRequest
+		want []int64
+	}{
+		{"tag", &quotesv1.ListQuotesRequest{Tag: "wisdom"}, []int64{1, 2}},
+		{"lang", &quotesv1.ListQuotesRequest{Lang: "en-US"}, []int64{1, 3}},
+		{"source", &quotesv1.ListQuotesRequest{Source: "Letters"}, []int64{3}},
+		{"years", &quotesv1.ListQuotesRequest{YearFrom: -500, YearTo: 1}, []int64{1}},
+		{"combined", &quotesv1.ListQuotesRequest{Author: "Confucius", Lang: "ru"}, []int64{2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := client.ListQuotes(ctx, tt.req)
+			if err != nil {
+				t.Fatalf("failed to list quotes: %v", err)
+			}
+			var got []int64
+			for _, q := range page.GetQuotes() {
+				got = append(got, q.GetId())
+			}
+			if !slices.Equal(got, tt.want) || page.GetTotalSize() != int32(len(tt.want)) {
+				t.Errorf("expected %v, got %v (total %d)", tt.want, got, page.GetTotalSize())
+			}
+		})
+	}
+
+	for _, req := range []*quotesv1.ListQuotesRequest{{Lang: "english!"}, {YearFrom: 100, YearTo: 10}} {
+		if _, err := client.ListQuotes(ctx, req); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("expected InvalidArgument for %v, got %v", req, err)
+		}
+	}
+
+	q, err := client.GetQuote(ctx, &quotesv1.GetQuoteRequest{Id: 1})
+	if err != nil || q.GetLang() != "en" || q.GetLikes() != 1 || q.GetRating() != 4 || q.GetRatings() != 1 {
+		t.Errorf("expected the language and votes on the quote, got %v (%v)", q, err)
+	}
+}
+
+func TestQuoteService_Auth(t *testing.T) {
+	client, _ := newTestClient(t)
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		code codes.Code
+	}{
+		{name: "anonymous", ctx: context.Background(), code: codes.Unauthenticated},
+		{name: "invalid key", ctx: withKey("nope"), code: codes.Unauthenticated},
+		{name: "missing scope", ctx: withKey("reader"), code: codes.PermissionDenied},
+		{name: "allowed", ctx: withKey("writer"), code: codes.OK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := client.CreateQuote(tt.ctx, &quotesv1.CreateQuoteRequest{Author: "Author", Quote: tt.name})
+			if status.Code(err) != tt.code {
+				t.Errorf("expected %s, got %v", tt.code, err)
+			}
+		})
+	}
+
+	var header metadata.MD
+	if _, err := client.ListQuotes(withKey("reader"), &quotesv1.ListQuotesRequest{}, grpc.Header(&header)); err != nil {
+		t.Fatalf("failed to list quotes: %v", err)
+	}
+	if len(header.Get("x-request-id")) != 1 {
+		t.Errorf("expected a request id in the response header, got %v", header)
+	}
+}
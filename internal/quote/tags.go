@@ -0,0 +1,118 @@
+package quote
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	MaxTags      = 10
+	MaxTagLength = 32
+)
+
+var ErrInvalidTag = errors.New("invalid tag")
+
+// NormalizeTags lower-cases and trims tags, drops duplicates and checks the
+// limits.
+func NormalizeTags(tags []string) ([]string, error) {
+	normalized := make([]string, 0, len(tags))
+	for _, t := range tags {
+		t = strings.ToLower(strings.TrimSpace(t))
+		switch {
+		case t == "":
+			return nil, fmt.Errorf("%w: tag must not be empty", ErrInvalidTag)
+		case utf8.RuneCountInString(t) > MaxTagLength:
+			return nil, fmt.Errorf("%w: tag %q is longer than %d characters", ErrInvalidTag, t, MaxTagLength)
+		}
+		if !slices.Contains(normalized, t) {
+			normalized = append(normalized, t)
+		}
+	}
+	if len(normalized) > MaxTags {
+		return nil, fmt.Errorf("%w: at most %d tags are allowed", ErrInvalidTag, MaxTags)
+	}
+	return normalized, nil
+}
+
+// SetTags replaces the tags of a quote.
+func (repo *QuotesRepository) SetTags(ctx context.Context, id int, tags []string) (err error) {
+	const op = "quote.repository.SetTags"
+	ctx, span := startSpan(ctx, op, "UPDATE")
+	defer endSpan(span, &err)
+	tags, err = NormalizeTags(tags)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	tx, err := repo.Database.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: begin transaction: %w", op, err)
+	}
+	defer tx.Rollback()
+	if _, err = getQuote(ctx, tx, id); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if _, err = tx.ExecContext(ctx, "DELETE FROM quote_tags WHERE quote_id = ?", id); err != nil {
+		return fmt.Errorf("%s: delete tags: %w", op, err)
+	}
+	if err = insertTags(ctx, tx, id, tags); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("%s: commit transaction: %w", op, err)
+	}
+	return nil
+}
+
+func insertTags(ctx context.Context, tx *sql.Tx, id int, tags []string) error {
+	for _, t := range tags {
+		if _, err := tx.ExecContext(ctx, "INSERT INTO quote_tags(quote_id, tag) VALUES(?, ?)", id, t); err != nil {
+			return fmt.Errorf("insert tag: %w", err)
+		}
+	}
+	return nil
+}
+
+// TagsByQuotes loads the tags of several quotes in one query. Quotes
+// without tags are missing from the result.
+func (repo *QuotesRepository) TagsByQuotes(ctx context.Context, ids []int) (_ map[int][]string, err error) {
+	const op = "quote.repository.TagsByQuotes"
+	ctx, span := startSpan(ctx, op, "SELECT")
+	defer endSpan(span, &err)
+	if len(ids) == 0 {
+		return map[int][]string{}, nil
+	}
+	args := make([]any, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	rows, err := repo.Database.QueryContext(ctx,
+		"SELECT quote_id, tag FROM quote_tags WHERE quote_id IN ("+placeholders(len(ids))+") ORDER BY quote_id, tag", args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: query execution: %w", op, err)
+	}
+	defer rows.Close()
+	tags := map[int][]string{}
+	for rows.Next() {
+		var (
+			id  int
+			tag string
+		)
+		if err := rows.Scan(&id, &tag); err != nil {
+			return nil, fmt.Errorf("%s: scan row: %w", op, err)
+		}
+		tags[id] = append(tags[id], tag)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: iterate rows: %w", op, err)
+	}
+	return tags, nil
+}
+
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
@@ -0,0 +1,128 @@
+package quote
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeTags(t *testing.T) {
+	got, err := NormalizeTags([]string{" Go ", "go", "SQL"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Equal(got, []string{"go", "sql"}) {
+		t.Errorf("expected [go sql], got %v", got)
+	}
+
+	invalid := [][]string{
+		{""},
+		{strings.Repeat("a", MaxTagLength+1)},
+		{"1", "2", "3", "4", "5", "6", "7", "8", "9", "10", "11"},
+	}
+	for _, tags := range invalid {
+		if _, err := NormalizeTags(tags); !errors.Is(err, ErrInvalidTag) {
+			t.Errorf("expected ErrInvalidTag for %v, got %v", tags, err)
+		}
+	}
+}
+
+func TestQuotesRepository_Tags(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewQuotesRepository(db)
+	ctx := context.Background()
+
+	a, err := repo.Save(ctx, "Author", "first")
+	if err != nil {
+		t.Fatalf("failed to save quote: %v", err)
+	}
+	b, err := repo.Save(ctx, "Author", "second")
+	if err != nil {
+		t.Fatalf("failed to save quote: %v", err)
+	}
+	if err = repo.SetTags(ctx, a.ID, []string{"b", "a"}); err != nil {
+		t.Fatalf("failed to set tags: %v", err)
+	}
+	if err = repo.SetTags(ctx, b.ID, []string{"a"}); err != nil {
+		t.Fatalf("failed to set tags: %v", err)
+	}
+	if err = repo.SetTags(ctx, 999, []string{"a"}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+
+	c, err := repo.Create(ctx, Draft{Author: "Author", Quote: "third", Tags: []string{" C "}})
+	if err != nil {
+		t.Fatalf("failed to create quote: %v", err)
+	}
+	if _, err = repo.Create(ctx, Draft{Author: "Author", Quote: "fourth", Tags: []string{""}}); !errors.Is(err, ErrInvalidTag) {
+		t.Errorf("expected ErrInvalidTag, got %v", err)
+	}
+	if _, total, _ := repo.List(ctx, Filter{Limit: -1}); total != 3 {
+		t.Errorf("expected a quote with invalid tags not to be saved, got %d quotes", total)
+	}
+
+	tags, err := repo.TagsByQuotes(ctx, []int{a.ID, b.ID, c.ID, 999})
+	if err != nil {
+		t.Fatalf("failed to load tags: %v", err)
+	}
+	if !slices.Equal(tags[a.ID], []string{"a", "b"}) || !slices.Equal(tags[b.ID], []string{"a"}) || !slices.Equal(tags[c.ID], []string{"c"}) || tags[999] != nil {
+		t.Errorf("unexpected tags: %v", tags)
+	}
+
+	quotes, total, err := repo.List(ctx, Filter{Tag: "b", Limit: 10})
+	if err != nil {
+		t.Fatalf("failed to list quotes: %v", err)
+	}
+	if total != 1 || len(quotes) != 1 || quotes[0].ID != a.ID {
+		t.Errorf("expected only quote %d tagged b, got %v", a.ID, quotes)
+	}
+
+	if err = repo.Delete(ctx, a.ID); err != nil {
+		t.Fatalf("failed to delete quote: %v", err)
+	}
+	tags, err = repo.TagsByQuotes(ctx, []int{a.ID})
+	if err != nil {
+		t.Fatalf("failed to load tags: %v", err)
+	}
+	if len(tags) != 0 {
+		t.Errorf("expected tags to be deleted with the quote, got %v", tags)
+	}
+}
+
+func TestQuotesRepository_ByAuthors(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewQuotesRepository(db)
+	ctx := context.Background()
+
+	for _, q := range [][2]string{{"A", "1"}, {"A", "2"}, {"A", "3"}, {"B", "4"}} {
+		if _, err := repo.Save(ctx, q[0], q[1]); err != nil {
+			t.Fatalf("failed to save quote: %v", err)
+		}
+	}
+
+	counts, err := repo.CountByAuthors(ctx, []string{"A", "B", "C"})
+	if err != nil {
+		t.Fatalf("failed to count quotes: %v", err)
+	}
+	if counts["A"] != 3 || counts["B"] != 1 || counts["C"] != 0 {
+		t.Errorf("unexpected counts: %v", counts)
+	}
+
+	pages, err := repo.ListByAuthors(ctx, []string{"A", "B"}, 2, 1)
+	if err != nil {
+		t.Fatalf("failed to list quotes: %v", err)
+	}
+	var texts []string
+	for _, q := range pages["A"] {
+		texts = append(texts, q.Quote)
+	}
+	if !slices.Equal(texts, []string{"2", "3"}) || len(pages["B"]) != 0 {
+		t.Errorf("unexpected pages: A=%v B=%v", texts, pages["B"])
+	}
+}
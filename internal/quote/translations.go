@@ -0,0 +1,155 @@
+package quote
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"quotes-mini-service/pkg/tracing"
+	"strings"
+)
+
+// A quote and its translations form a group identified by the id of the
+// original, which is the only quote of the group without original_id.
+const groupColumn = "COALESCE(original_id, id)"
+
+// Translations returns the other versions of the quote, the original first.
+func (repo *QuotesRepository) Translations(ctx context.Context, id int) (_ []Quote, err error) {
+	const op = "quote.repository.Translations"
+	ctx, span := startSpan(ctx, op, "SELECT")
+	defer endSpan(span, &err)
+	tx, err := repo.Database.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s: begin transaction: %w", op, err)
+	}
+	defer tx.Rollback()
+	q, err := getQuote(ctx, tx, id)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	root := q.ID
+	if q.TranslationOf != 0 {
+		root = q.TranslationOf
+	}
+	rows, err := tx.QueryContext(ctx, "SELECT "+quoteColumns+" FROM quotes WHERE "+groupColumn+" = ? AND id != ? ORDER BY original_id IS NOT NULL, id",
+		root, id)
+	if err != nil {
+		return nil, fmt.Errorf("%s: query execution: %w", op, err)
+	}
+	quotes, err := scanQuotes(rows)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("%s: commit transaction: %w", op, err)
+	}
+	span.SetAttributes(tracing.Int("db.rows_returned", len(quotes)))
+	return quotes, nil
+}
+
+// RandomBatch picks up to count random quotes, never two from the same
+// group. Each group is represented by its version in the earliest language
+// of langs, or else by its original; with langs.Only groups without a
+// version in those languages are skipped.
+func (repo *QuotesRepository) RandomBatch(ctx context.Context, langs Languages, count int) (_ []Quote, err error) {
+	const op = "quote.repository.RandomBatch"
+	ctx, span := startSpan(ctx, op, "SELECT")
+	defer endSpan(span, &err)
+	var (
+		where string
+		rank  = "original_id IS NOT NULL"
+		args  []any
+	)
+	if len(langs.Codes) > 0 {
+		var b strings.Builder
+		b.WriteString("CASE lang")
+		for i, l := range langs.Codes {
+			fmt.Fprintf(&b, " WHEN ? THEN %d", i)
+			args = append(args, l)
+		}
+		fmt.Fprintf(&b, " ELSE %d END, %s", len(langs.Codes), rank)
+		rank = b.String()
+	}
+	if langs.Only && len(langs.Codes) > 0 {
+		where = " WHERE lang IN (" + placeholders(len(langs.Codes)) + ")"
+		for _, l := range langs.Codes {
+			args = append(args, l)
+		}
+	}
+	rows, err := repo.Database.QueryContext(ctx, "SELECT "+quoteColumns+` FROM (
+			SELECT *, ROW_NUMBER() OVER (PARTITION BY `+groupColumn+` ORDER BY `+rank+`, id) AS n FROM quotes`+where+`
+		) WHERE n = 1 ORDER BY RANDOM() LIMIT ?`, append(args, count)...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: query execution: %w", op, err)
+	}
+	quotes, err := scanQuotes(rows)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	span.SetAttributes(tracing.Int("db.rows_returned", len(quotes)))
+	return quotes, nil
+}
+
+// groupRoot returns the original of the group a translation into lang joins
+// when it is made from the quote with the given id.
+func groupRoot(ctx context.Context, tx *sql.Tx, id int, lang string) (int, error) {
+	q, err := getQuote(ctx, tx, id)
+	if err != nil {
+		return 0, fmt.Errorf("quote with id %d: %w", id, ErrOriginalNotFound)
+	}
+	root := q.ID
+	if q.TranslationOf != 0 {
+		root = q.TranslationOf
+	}
+	exists, err := hasVersion(ctx, tx, root, lang)
+	if err != nil {
+		return 0, err
+	}
+	if exists {
+		return 0, fmt.Errorf("%w: quote %d already has a %q version", ErrDuplicate, root, lang)
+	}
+	return root, nil
+}
+
+// hasVersion reports whether the group of root has a quote in lang.
+func hasVersion(ctx context.Context, tx *sql.Tx, root int, lang string) (bool, error) {
+	var exists bool
+	err := tx.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM quotes WHERE "+groupColumn+" = ? AND lang = ?)", root, lang).
+		Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("check translation: %w", err)
+	}
+	return exists, nil
+}
+
+// promoteTranslation keeps the group of a deleted original together by
+// making its oldest translation the new original.
+func promoteTranslation(ctx context.Context, tx *sql.Tx, id int) error {
+	var next sql.NullInt64
+	if err := tx.QueryRowContext(ctx, "SELECT MIN(id) FROM quotes WHERE original_id = ?", id).Scan(&next); err != nil {
+		return fmt.Errorf("find translation: %w", err)
+	}
+	if !next.Valid {
+		return nil
+	}
+	_, err := tx.ExecContext(ctx, "UPDATE quotes SET original_id = NULLIF(?, id) WHERE original_id = ?", next.Int64, id)
+	if err != nil {
+		return fmt.Errorf("promote translation: %w", err)
+	}
+	return nil
+}
+
+func scanQuotes(rows *sql.Rows) ([]Quote, error) {
+	defer rows.Close()
+	quotes := []Quote{}
+	for rows.Next() {
+		var q Quote
+		if err := rows.Scan(q.fields()...); err != nil {
+			return nil, fmt.Errorf("scan row: %w", err)
+		}
+		quotes = append(quotes, q)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate rows: %w", err)
+	}
+	return quotes, nil
+}
@@ -0,0 +1,189 @@
+package quote
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestLanguages(t *testing.T) {
+	for text, want := range map[string]string{
+		"Life is simple.":          "en",
+		"Жизнь проста.":            "ru",
+		"Сказал Confucius однажды": "ru",
+		"42 — 17":                  "",
+	} {
+		if got := DetectLanguage(text); got != want {
+			t.Errorf("DetectLanguage(%q): expected %q, got %q", text, want, got)
+		}
+	}
+	for tag, want := range map[string]string{"en": "en", "en-US": "en", " RU ": "ru", "fil": "fil", "english": "", "e": "", "e1": ""} {
+		got, err := NormalizeLang(tag)
+		if got != want || (err != nil) != (want == "") {
+			t.Errorf("NormalizeLang(%q): expected %q, got %q, %v", tag, want, got, err)
+		}
+	}
+	for header, want := range map[string][]string{
+		"":                          nil,
+		"ru":                        {"ru"},
+		"en;q=0.5, ru-RU, ru;q=0.9": {"ru", "en"},
+		"de, *;q=0.1":               nil,
+		"en, fr;q=0, e1, de;q=x":    {"en"},
+	} {
+		if got := PreferredLanguages(header); !slices.Equal(got, want) {
+			t.Errorf("PreferredLanguages(%q): expected %v, got %v", header, want, got)
+		}
+	}
+}
+
+func TestQuotesRepository_Translations(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewQuotesRepository(db)
+	ctx := context.Background()
+	en, err := repo.Save(ctx, "Confucius", "Life is simple.")
+	if err != nil {
+		t.Fatalf("failed to save quote: %v", err)
+	}
+	if en.Lang != "en" || en.TranslationOf != 0 {
+		t.Errorf("expected an original in en, got %q of %d", en.Lang, en.TranslationOf)
+	}
+	ru, err := repo.Create(ctx, Draft{Author: "Конфуций", Quote: "Жизнь проста.", TranslationOf: en.ID})
+	if err != nil {
+		t.Fatalf("failed to save translation: %v", err)
+	}
+	if ru.Lang != "ru" || ru.TranslationOf != en.ID {
+		t.Errorf("expected a translation in ru of %d, got %q of %d", en.ID, ru.Lang, ru.TranslationOf)
+	}
+	// A translation of a translation joins the group of the original.
+	de, err := repo.Create(ctx, Draft{Author: "Konfuzius", Quote: "Das Leben ist einfach.", Lang: "de", TranslationOf: ru.ID})
+	if err != nil {
+		t.Fatalf("failed to save translation: %v", err)
+	}
+	if de.TranslationOf != en.ID {
+		t.Errorf("expected a translation of %d, got %d", en.ID, de.TranslationOf)
+	}
+	if _, err = repo.Create(ctx, Draft{Author: "Confucius", Quote: "Life is easy.", TranslationOf: de.ID}); !errors.Is(err, ErrDuplicate) {
+		t.Errorf("expected ErrDuplicate for a second en version, got %v", err)
+	}
+	if _, err = repo.Create(ctx, Draft{Author: "Confucius", Quote: "Vita", Lang: "la", TranslationOf: 99}); !errors.Is(err, ErrOriginalNotFound) {
+		t.Errorf("expected ErrOriginalNotFound, got %v", err)
+	}
+	other, err := repo.Save(ctx, "Сократ", "Я знаю, что ничего не знаю.")
+	if err != nil {
+		t.Fatalf("failed to save quote: %v", err)
+	}
+
+	translations, err := repo.Translations(ctx, ru.ID)
+	if err != nil {
+		t.Fatalf("failed to list translations: %v", err)
+	}
+	if ids := quoteIDs(translations); !slices.Equal(ids, []int{en.ID, de.ID}) {
+		t.Errorf("expected translations %v, got %v", []int{en.ID, de.ID}, ids)
+	}
+	if _, err = repo.Translations(ctx, 99); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+
+	for _, tt := range []struct {
+		langs Languages
+		want  []int
+	}{
+		{Languages{}, []int{en.ID, other.ID}},
+		{Languages{Codes: []string{"ru"}, Only: true}, []int{ru.ID, other.ID}},
+		{Languages{Codes: []string{"de", "ru"}, Only: true}, []int{de.ID, other.ID}},
+		{Languages{Codes: []string{"en"}, Only: true}, []int{en.ID}},
+		{Languages{Codes: []string{"fr"}, Only: true}, nil},
+		{Languages{Codes: []string{"en"}}, []int{en.ID, other.ID}},
+		{Languages{Codes: []string{"fr", "de"}}, []int{de.ID, other.ID}},
+	} {
+		for range 5 {
+			quotes, err := repo.RandomBatch(ctx, tt.langs, 10)
+			if err != nil {
+				t.Fatalf("failed to pick quotes: %v", err)
+			}
+			ids := quoteIDs(quotes)
+			slices.Sort(ids)
+			if !slices.Equal(ids, tt.want) {
+				t.Errorf("%v: expected %v, got %v", tt.langs, tt.want, ids)
+			}
+		}
+	}
+	quotes, err := repo.RandomBatch(ctx, Languages{}, 1)
+	if err != nil || len(quotes) != 1 {
+		t.Errorf("expected one quote, got %d, %v", len(quotes), err)
+	}
+
+	filtered, total, err := repo.List(ctx, Filter{Langs: []string{"ru"}, Limit: -1})
+	if err != nil {
+		t.Fatalf("failed to list quotes: %v", err)
+	}
+	if ids := quoteIDs(filtered); total != 2 || !slices.Equal(ids, []int{ru.ID, other.ID}) {
+		t.Errorf("expected ru quotes %v, got %v of %d", []int{ru.ID, other.ID}, ids, total)
+	}
+
+	// Deleting the original promotes the oldest translation.
+	if err = repo.Delete(ctx, en.ID); err != nil {
+		t.Fatalf("failed to delete quote: %v", err)
+	}
+	if q, _ := repo.GetByID(ctx, ru.ID); q.TranslationOf != 0 {
+		t.Errorf("expected %d to become the original, got a translation of %d", ru.ID, q.TranslationOf)
+	}
+	if q, _ := repo.GetByID(ctx, de.ID); q.TranslationOf != ru.ID {
+		t.Errorf("expected %d to be a translation of %d, got %d", de.ID, ru.ID, q.TranslationOf)
+	}
+}
+
+func TestQuotesRepository_UpdateLang(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewQuotesRepository(db)
+	ctx := context.Background()
+	detected, err := repo.Save(ctx, "Socrates", "Знаю, что ничего не знаю.")
+	if err != nil {
+		t.Fatalf("failed to save quote: %v", err)
+	}
+	explicit, err := repo.Create(ctx, Draft{Author: "Seneca", Quote: "Vivere militare est.", Lang: "la"})
+	if err != nil {
+		t.Fatalf("failed to save quote: %v", err)
+	}
+	ru, err := repo.Create(ctx, Draft{Author: "Сенека", Quote: "Жить значит бороться.", TranslationOf: explicit.ID})
+	if err != nil {
+		t.Fatalf("failed to save translation: %v", err)
+	}
+	en, err := repo.Create(ctx, Draft{Author: "Seneca", Quote: "To live is to fight.", TranslationOf: explicit.ID})
+	if err != nil {
+		t.Fatalf("failed to save translation: %v", err)
+	}
+
+	for _, tt := range []struct {
+		name  string
+		id    int
+		quote string
+		want  string
+	}{
+		{"detected lang follows the text", detected.ID, "I know that I know nothing.", "en"},
+		{"explicit lang is kept", explicit.ID, "Vivere est militare.", "la"},
+		{"taken lang is kept", ru.ID, "To live means to fight.", "ru"},
+		{"author only", en.ID, "To live is to fight.", "en"},
+	} {
+		q, err := repo.Update(ctx, tt.id, "Seneca", tt.quote, Citation{})
+		if err != nil {
+			t.Fatalf("%s: failed to update quote: %v", tt.name, err)
+		}
+		if q.Lang != tt.want {
+			t.Errorf("%s: expected lang %q, got %q", tt.name, tt.want, q.Lang)
+		}
+	}
+}
+
+func quoteIDs(quotes []Quote) []int {
+	var ids []int
+	for _, q := range quotes {
+		ids = append(ids, q.ID)
+	}
+	return ids
+}
@@ -0,0 +1,76 @@
+package quote
+
+import "context"
+
+// Sources of a view.
+const (
+	ViewByID   = "id"
+	ViewRandom = "random"
+)
+
+// ViewRecorder counts the quotes shown to clients. Record must not block.
+// Forget drops the views of a deleted quote that are not written yet.
+type ViewRecorder interface {
+	Record(id int, source string)
+	Forget(id int)
+}
+
+// TrackedRepository records a view for every quote returned by a
+// single-quote or random read. It sits in front of the cache so that cache
+// hits are counted too.
+type TrackedRepository struct {
+	Store
+	views ViewRecorder
+}
+
+func NewTrackedRepository(store Store, views ViewRecorder) *TrackedRepository {
+	return &TrackedRepository{Store: store, views: views}
+}
+
+func (t *TrackedRepository) GetByID(ctx context.Context, id int) (*Quote, error) {
+	q, err := t.Store.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	t.views.Record(q.ID, ViewByID)
+	return q, nil
+}
+
+// Delete forgets the pending views of the quote, so they cannot be written
+// for a new quote that reuses its id.
+func (t *TrackedRepository) Delete(ctx context.Context, id int) error {
+	if err := t.Store.Delete(ctx, id); err != nil {
+		return err
+	}
+	t.views.Forget(id)
+	return nil
+}
+
+func (t *TrackedRepository) GetRandom(ctx context.Context) (*Quote, error) {
+	q, err := t.Store.GetRandom(ctx)
+	if err != nil {
+		return nil, err
+	}
+	t.views.Record(q.ID, ViewRandom)
+	return q, nil
+}
+
+func (t *TrackedRepository) GetRandomParam(ctx context.Context, author string) (*Quote, error) {
+	q, err := t.Store.GetRandomParam(ctx, author)
+	if err != nil {
+		return nil, err
+	}
+	t.views.Record(q.ID, ViewRandom)
+	return q, nil
+}
+
+func (t *TrackedRepository) RandomBatch(ctx context.Context, langs Languages, count int) ([]Quote, error) {
+	quotes, err := t.Store.RandomBatch(ctx, langs, count)
+	if err != nil {
+		return nil, err
+	}
+	for _, q := range quotes {
+		t.views.Record(q.ID, ViewRandom)
+	}
+	return quotes, nil
+}
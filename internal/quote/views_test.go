@@ -0,0 +1,54 @@
+package quote
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+)
+
+type countingRecorder map[string][]int
+
+func (c countingRecorder) Record(id int, source string) {
+	c[source] = append(c[source], id)
+}
+
+func (c countingRecorder) Forget(id int) {
+	for source, ids := range c {
+		c[source] = slices.DeleteFunc(ids, func(v int) bool { return v == id })
+	}
+}
+
+func TestTrackedRepository(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+	repo := NewQuotesRepository(db)
+	views := countingRecorder{}
+	tracked := NewTrackedRepository(NewCachedRepository(repo, time.Minute), views)
+	if _, err := tracked.GetRandom(ctx); err == nil {
+		t.Fatal("expected no quote in an empty database")
+	}
+	q, err := tracked.Save(ctx, "Author", "Quote")
+	if err != nil {
+		t.Fatalf("failed to save quote: %v", err)
+	}
+	tracked.GetByID(ctx, q.ID)
+	tracked.GetByID(ctx, q.ID)
+	tracked.GetByID(ctx, 999)
+	tracked.GetRandom(ctx)
+	tracked.GetRandomParam(ctx, "Author")
+	tracked.GetAllParam(ctx, "")
+
+	if len(views[ViewByID]) != 2 || len(views[ViewRandom]) != 2 || len(views) != 2 {
+		t.Errorf("expected 2 views by id and 2 random views, got %v", views)
+	}
+
+	if err = tracked.Delete(ctx, q.ID); err != nil {
+		t.Fatalf("failed to delete quote: %v", err)
+	}
+	if len(views[ViewByID]) != 0 || len(views[ViewRandom]) != 0 {
+		t.Errorf("expected the views of the deleted quote to be forgotten, got %v", views)
+	}
+}
@@ -0,0 +1,170 @@
+package quote
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"quotes-mini-service/internal/audit"
+	"quotes-mini-service/pkg/tracing"
+	"time"
+)
+
+const (
+	MinRating = 1
+	MaxRating = 5
+)
+
+var ErrInvalidRating = errors.New("invalid rating")
+
+// Entities of the votes in the audit log, identified by the quote ID.
+const (
+	auditLikeEntity   = "quote_like"
+	auditRatingEntity = "quote_rating"
+)
+
+// vote is how a like or a rating appears in the audit log.
+type vote struct {
+	Voter  string `json:"voter"`
+	Rating int    `json:"rating,omitempty"`
+}
+
+// Like records that voter likes the quote. A voter likes a quote at most
+// once; created is false when the like was already there. The counters on
+// the quote are kept by triggers on quote_likes.
+func (repo *QuotesRepository) Like(ctx context.Context, id int, voter string) (_ *Quote, created bool, err error) {
+	const op = "quote.repository.Like"
+	ctx, span := startSpan(ctx, op, "INSERT")
+	defer endSpan(span, &err)
+	tx, err := repo.Database.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, false, fmt.Errorf("%s: begin transaction: %w", op, err)
+	}
+	defer tx.Rollback()
+	if _, err = getQuote(ctx, tx, id); err != nil {
+		return nil, false, fmt.Errorf("%s: %w", op, err)
+	}
+	res, err := tx.ExecContext(ctx, "INSERT OR IGNORE INTO quote_likes(quote_id, voter) VALUES(?, ?)", id, voter)
+	if err != nil {
+		return nil, false, fmt.Errorf("%s: insert like: %w", op, err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return nil, false, fmt.Errorf("%s: rows affection: %w", op, err)
+	}
+	if n > 0 {
+		if err = audit.Record(ctx, tx, audit.ActionCreate, auditLikeEntity, id, nil, vote{Voter: voter}); err != nil {
+			return nil, false, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+	q, err := getQuote(ctx, tx, id)
+	if err != nil {
+		return nil, false, fmt.Errorf("%s: %w", op, err)
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, false, fmt.Errorf("%s: commit transaction: %w", op, err)
+	}
+	span.SetAttributes(tracing.Int64("db.rows_affected", n))
+	return q, n > 0, nil
+}
+
+// Rate records the rating of voter for the quote, replacing the previous
+// one. The average on the quote is kept by triggers on quote_ratings.
+func (repo *QuotesRepository) Rate(ctx context.Context, id int, voter string, rating int) (_ *Quote, err error) {
+	const op = "quote.repository.Rate"
+	ctx, span := startSpan(ctx, op, "INSERT")
+	defer endSpan(span, &err)
+	if rating < MinRating || rating > MaxRating {
+		return nil, fmt.Errorf("%s: %w: must be between %d and %d", op, ErrInvalidRating, MinRating, MaxRating)
+	}
+	tx, err := repo.Database.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s: begin transaction: %w", op, err)
+	}
+	defer tx.Rollback()
+	if _, err = getQuote(ctx, tx, id); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	var previous int
+	err = tx.QueryRowContext(ctx, "SELECT rating FROM quote_ratings WHERE quote_id = ? AND voter = ?", id, voter).
+		Scan(&previous)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%s: select rating: %w", op, err)
+	}
+	_, err = tx.ExecContext(ctx, `INSERT INTO quote_ratings(quote_id, voter, rating) VALUES(?, ?, ?)
+		ON CONFLICT(quote_id, voter) DO UPDATE SET rating = excluded.rating, updated_at = CURRENT_TIMESTAMP`,
+		id, voter, rating)
+	if err != nil {
+		return nil, fmt.Errorf("%s: upsert rating: %w", op, err)
+	}
+	action, before := audit.ActionCreate, any(nil)
+	if previous != 0 {
+		action, before = audit.ActionUpdate, vote{Voter: voter, Rating: previous}
+	}
+	if err = audit.Record(ctx, tx, action, auditRatingEntity, id, before, vote{Voter: voter, Rating: rating}); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	q, err := getQuote(ctx, tx, id)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("%s: commit transaction: %w", op, err)
+	}
+	return q, nil
+}
+
+// Top returns the best quotes by the votes cast since the given time: the
+// average rating first, then the number of ratings and likes. A zero since
+// ranks by the stored all-time aggregates. Quotes without votes in the
+// period are left out.
+func (repo *QuotesRepository) Top(ctx context.Context, since time.Time, limit int) (_ []Quote, err error) {
+	const op = "quote.repository.Top"
+	ctx, span := startSpan(ctx, op, "SELECT")
+	defer endSpan(span, &err)
+	var rows *sql.Rows
+	if since.IsZero() {
+		rows, err = repo.Database.QueryContext(ctx, "SELECT "+quoteColumns+` FROM quotes
+			WHERE likes > 0 OR rating_count > 0
+			ORDER BY rating DESC, rating_count DESC, likes DESC, id LIMIT ?`, limit)
+	} else {
+		from := since.UTC().Format(time.DateTime)
+		rows, err = repo.Database.QueryContext(ctx, "SELECT "+quoteColumns+` FROM quotes
+			LEFT JOIN (SELECT quote_id, AVG(rating) AS period_rating, COUNT(*) AS period_ratings
+				FROM quote_ratings WHERE updated_at >= ? GROUP BY quote_id) r ON r.quote_id = id
+			LEFT JOIN (SELECT quote_id, COUNT(*) AS period_likes
+				FROM quote_likes WHERE created_at >= ? GROUP BY quote_id) l ON l.quote_id = id
+			WHERE r.quote_id IS NOT NULL OR l.quote_id IS NOT NULL
+			ORDER BY COALESCE(period_rating, 0) DESC, COALESCE(period_ratings, 0) DESC, COALESCE(period_likes, 0) DESC, id
+			LIMIT ?`, from, from, limit)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: query execution: %w", op, err)
+	}
+	defer rows.Close()
+	quotes := []Quote{}
+	for rows.Next() {
+		var q Quote
+		if err := rows.Scan(q.fields()...); err != nil {
+			return nil, fmt.Errorf("%s: scan row: %w", op, err)
+		}
+		quotes = append(quotes, q)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: iterate rows: %w", op, err)
+	}
+	span.SetAttributes(tracing.Int("db.rows_returned", len(quotes)))
+	return quotes, nil
+}
+
+// deleteVotes drops the votes of a deleted quote so that a reused id starts
+// from zero.
+func deleteVotes(ctx context.Context, tx *sql.Tx, id int) error {
+	if _, err := tx.ExecContext(ctx, "DELETE FROM quote_likes WHERE quote_id = ?", id); err != nil {
+		return fmt.Errorf("delete likes: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM quote_ratings WHERE quote_id = ?", id); err != nil {
+		return fmt.Errorf("delete ratings: %w", err)
+	}
+	return nil
+}
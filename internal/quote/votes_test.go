@@ -0,0 +1,145 @@
+package quote
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestQuotesRepository_Votes(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewQuotesRepository(db)
+	ctx := context.Background()
+	q, err := repo.Save(ctx, "Author", "Quote")
+	if err != nil {
+		t.Fatalf("failed to save quote: %v", err)
+	}
+
+	for _, tt := range []struct {
+		voter   string
+		created bool
+		likes   int
+	}{
+		{"apikey:1", true, 1},
+		{"apikey:1", false, 1},
+		{"ip:192.0.2.1", true, 2},
+	} {
+		liked, created, err := repo.Like(ctx, q.ID, tt.voter)
+		if err != nil {
+			t.Fatalf("failed to like quote: %v", err)
+		}
+		if created != tt.created || liked.Likes != tt.likes {
+			t.Errorf("%s: expected created=%v likes=%d, got created=%v likes=%d", tt.voter, tt.created, tt.likes, created, liked.Likes)
+		}
+	}
+
+	for _, tt := range []struct {
+		voter   string
+		rating  int
+		average float64
+		count   int
+	}{
+		{"apikey:1", 5, 5, 1},
+		{"apikey:2", 2, 3.5, 2},
+		{"apikey:2", 4, 4.5, 2},
+	} {
+		rated, err := repo.Rate(ctx, q.ID, tt.voter, tt.rating)
+		if err != nil {
+			t.Fatalf("failed to rate quote: %v", err)
+		}
+		if rated.Rating != tt.average || rated.Ratings != tt.count {
+			t.Errorf("%s rated %d: expected %v from %d ratings, got %v from %d", tt.voter, tt.rating, tt.average, tt.count, rated.Rating, rated.Ratings)
+		}
+	}
+	if _, err = repo.Rate(ctx, q.ID, "apikey:1", 6); !errors.Is(err, ErrInvalidRating) {
+		t.Errorf("expected ErrInvalidRating, got %v", err)
+	}
+	if _, _, err = repo.Like(ctx, 999, "apikey:1"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for like, got %v", err)
+	}
+	if _, err = repo.Rate(ctx, 999, "apikey:1", 3); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for rating, got %v", err)
+	}
+
+	// The repeated like changes nothing and is not audited.
+	var entries string
+	err = db.QueryRow(`SELECT GROUP_CONCAT(entity || ' ' || action, ', ') FROM
+		(SELECT entity, action FROM audit_log WHERE entity IN ('quote_like', 'quote_rating') ORDER BY id)`).Scan(&entries)
+	if err != nil {
+		t.Fatalf("failed to query audit log: %v", err)
+	}
+	want := "quote_like create, quote_like create, quote_rating create, quote_rating create, quote_rating update"
+	if entries != want {
+		t.Errorf("expected audit entries %q, got %q", want, entries)
+	}
+
+	if err = repo.Delete(ctx, q.ID); err != nil {
+		t.Fatalf("failed to delete quote: %v", err)
+	}
+	var votes int
+	if err = db.QueryRow("SELECT (SELECT COUNT(*) FROM quote_likes) + (SELECT COUNT(*) FROM quote_ratings)").Scan(&votes); err != nil {
+		t.Fatalf("failed to count votes: %v", err)
+	}
+	if votes != 0 {
+		t.Errorf("expected the votes to go with the quote, got %d", votes)
+	}
+}
+
+func TestQuotesRepository_Top(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewQuotesRepository(db)
+	ctx := context.Background()
+	for i := 1; i <= 4; i++ {
+		if _, err := repo.Save(ctx, "Author", fmt.Sprintf("Quote %d", i)); err != nil {
+			t.Fatalf("failed to save test quote: %v", err)
+		}
+	}
+	// Quote 1 was rated highly a long time ago, quote 2 this week.
+	repo.Rate(ctx, 1, "a", 5)
+	repo.Rate(ctx, 2, "a", 4)
+	repo.Rate(ctx, 2, "b", 3)
+	repo.Like(ctx, 3, "a")
+	if _, err := db.Exec("UPDATE quote_ratings SET updated_at = datetime('now', '-30 days') WHERE quote_id = 1"); err != nil {
+		t.Fatalf("failed to age rating: %v", err)
+	}
+
+	ids := func(quotes []Quote) []int {
+		var ids []int
+		for _, q := range quotes {
+			ids = append(ids, q.ID)
+		}
+		return ids
+	}
+	week, err := repo.Top(ctx, time.Now().Add(-7*24*time.Hour), 10)
+	if err != nil {
+		t.Fatalf("failed to get top quotes: %v", err)
+	}
+	if got := ids(week); fmt.Sprint(got) != "[2 3]" {
+		t.Errorf("expected quotes [2 3] this week, got %v", got)
+	}
+	all, err := repo.Top(ctx, time.Time{}, 2)
+	if err != nil {
+		t.Fatalf("failed to get top quotes: %v", err)
+	}
+	if got := ids(all); fmt.Sprint(got) != "[1 2]" {
+		t.Errorf("expected quotes [1 2] of all time, got %v", got)
+	}
+
+	sorted, _, err := repo.List(ctx, Filter{Sort: SortRating, Limit: -1})
+	if err != nil {
+		t.Fatalf("failed to list quotes: %v", err)
+	}
+	if got := ids(sorted); fmt.Sprint(got) != "[1 2 3 4]" {
+		t.Errorf("expected quotes by rating [1 2 3 4], got %v", got)
+	}
+	sorted, _, _ = repo.List(ctx, Filter{Sort: SortLikes, Limit: 1})
+	if got := ids(sorted); fmt.Sprint(got) != "[3]" {
+		t.Errorf("expected quote 3 to have the most likes, got %v", got)
+	}
+}
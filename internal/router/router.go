@@ -0,0 +1,274 @@
+// Package router registers the HTTP routes of the service and wraps them in
+// the middleware stack. API routes are served under /v1 and /v2; the
+// unversioned paths remain as deprecated aliases of /v1.
+package router
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"quotes-mini-service/internal/api"
+	"quotes-mini-service/internal/apikey"
+	"quotes-mini-service/internal/apikey/handlers/create"
+	keylist "quotes-mini-service/internal/apikey/handlers/list"
+	"quotes-mini-service/internal/apikey/handlers/revoke"
+	"quotes-mini-service/internal/audit"
+	auditlist "quotes-mini-service/internal/audit/handlers/list"
+	"quotes-mini-service/internal/collection"
+	"quotes-mini-service/internal/collection/handlers/collections"
+	"quotes-mini-service/internal/config"
+	"quotes-mini-service/internal/events"
+	"quotes-mini-service/internal/graph"
+	"quotes-mini-service/internal/health"
+	"quotes-mini-service/internal/idempotency"
+	"quotes-mini-service/internal/quote"
+	del "quotes-mini-service/internal/quote/handlers/delete"
+	"quotes-mini-service/internal/quote/handlers/get"
+	"quotes-mini-service/internal/quote/handlers/revisions"
+	"quotes-mini-service/internal/quote/handlers/rotation"
+	"quotes-mini-service/internal/quote/handlers/save"
+	"quotes-mini-service/internal/quote/handlers/stream"
+	"quotes-mini-service/internal/quote/handlers/update"
+	"quotes-mini-service/internal/quote/handlers/vote"
+	"quotes-mini-service/internal/stats"
+	"quotes-mini-service/internal/stats/handlers/summary"
+	"quotes-mini-service/internal/storage"
+	"quotes-mini-service/internal/webhook"
+	"quotes-mini-service/internal/webhook/handlers/deadletters"
+	"quotes-mini-service/internal/webhook/handlers/subscriptions"
+	"quotes-mini-service/pkg/metrics"
+	"quotes-mini-service/pkg/middleware"
+	"quotes-mini-service/pkg/openapi"
+	"quotes-mini-service/pkg/tracing"
+	"strings"
+)
+
+// Deps are shared with the rest of the process or have a lifecycle of their
+// own, so the caller creates, runs and stops them.
+type Deps struct {
+	DB *storage.Db
+	// Quotes serves reads and writes of the API, possibly through the cache.
+	Quotes         quote.Store
+	Authenticators []middleware.Authenticator
+	Tracer         *tracing.Tracer
+	Broker         *events.Broker
+	Hub            *rotation.Hub
+	Limiter        *middleware.RateLimiter
+	Probe          *health.Probe
+	// Views counts the quotes picked from collections. Nil disables it.
+	Views quote.ViewRecorder
+	// OnViolation replaces logging of responses that break the OpenAPI
+	// document when conf.ValidateResponses is set.
+	OnViolation func(r *http.Request, err error)
+}
+
+type router struct {
+	log        *slog.Logger
+	conf       *config.Config
+	mux        *http.ServeMux
+	docs       *api.Docs
+	limiter    *middleware.RateLimiter
+	idempotent func(http.Handler) http.Handler
+	violation  func(r *http.Request, err error)
+	err        error
+}
+
+// New registers every route and returns the handler of the HTTP server.
+func New(log *slog.Logger, conf *config.Config, deps Deps) (http.Handler, error) {
+	const op = "router.New"
+	rt := &router{
+		log:        log,
+		conf:       conf,
+		mux:        http.NewServeMux(),
+		docs:       api.NewDocs(health.BuildInfo().Version),
+		limiter:    deps.Limiter,
+		idempotent: middleware.Idempotency(log, idempotency.NewResponsesRepository(deps.DB), deps.Limiter.ClientIP, conf.IdempotencyTTL),
+		violation:  deps.OnViolation,
+	}
+	quotesRepository := quote.NewQuotesRepository(deps.DB)
+	keysRepository := apikey.NewKeysRepository(log, deps.DB)
+	webhooksRepository := webhook.NewWebhooksRepository(deps.DB)
+	var collectionsRepository collections.CollectionStore
+	if repo := collection.NewCollectionsRepository(deps.DB); deps.Views != nil {
+		collectionsRepository = collection.NewTrackedRepository(repo, deps.Views)
+	} else {
+		collectionsRepository = repo
+	}
+
+	rt.route("POST /quotes", middleware.ScopeWrite, save.New(log, deps.Quotes))
+	rt.route("DELETE /quotes/{id}", middleware.ScopeDelete, del.New(log, deps.Quotes))
+	rt.route("PUT /quotes/{id}", middleware.ScopeWrite, update.New(log, deps.Quotes))
+	rt.route("GET /quotes/{id}/revisions", middleware.ScopeRead, revisions.List(log, quotesRepository))
+	rt.route("GET /quotes/{id}/revisions/diff", middleware.ScopeRead, revisions.Diff(log, quotesRepository))
+	rt.route("POST /quotes/{id}/revisions/{rev}/restore", middleware.ScopeWrite, revisions.Restore(log, deps.Quotes))
+	cacheControl := middleware.CacheControl(fmt.Sprintf("private, max-age=%d, must-revalidate", int(conf.Cache.MaxAge.Seconds())))
+	rt.versions("GET /quotes", middleware.ScopeRead, cacheControl(get.AllParam(log, deps.Quotes)), get.Page(log, deps.Quotes))
+	rt.route("GET /quotes/{id}", middleware.ScopeRead, cacheControl(get.ByID(log, deps.Quotes)))
+	rt.route("GET /quotes/stream", middleware.ScopeRead, stream.New(log, deps.Broker, conf.Stream.Heartbeat))
+	rt.route("GET /quotes/random/ws", middleware.ScopeRead, rotation.New(log, deps.Quotes, deps.Hub, rotation.Options{
+		MinInterval:  conf.WebSocket.MinInterval,
+		PingInterval: conf.WebSocket.PingInterval,
+	}))
+	rt.route("GET /quotes/random", middleware.ScopeRead, middleware.CacheControl("no-store")(get.Random(log, deps.Quotes)))
+	rt.route("GET /quotes/random/batch", middleware.ScopeRead, middleware.CacheControl("no-store")(get.Batch(log, deps.Quotes)))
+	rt.route("GET /quotes/{id}/translations", middleware.ScopeRead, cacheControl(get.Translations(log, deps.Quotes)))
+	rt.route("GET /quotes/top", middleware.ScopeRead, get.Top(log, deps.Quotes))
+	rt.route("POST /quotes/{id}/like", middleware.ScopeRead, vote.Like(log, deps.Quotes, deps.Limiter.ClientIP))
+	rt.route("PUT /quotes/{id}/rating", middleware.ScopeRead, vote.Rate(log, deps.Quotes, deps.Limiter.ClientIP))
+	rt.route("POST /graphql", middleware.ScopeRead, graph.New(log, deps.Quotes, quotesRepository, graph.Options{
+		MaxDepth:      conf.GraphQL.MaxDepth,
+		MaxComplexity: conf.GraphQL.MaxComplexity,
+		RequireScopes: conf.Auth.Enabled,
+	}))
+	rt.route("POST /collections", middleware.ScopeWrite, collections.Create(log, collectionsRepository))
+	rt.route("GET /collections", middleware.ScopeRead, collections.List(log, collectionsRepository))
+	rt.route("GET /collections/{id}", middleware.ScopeRead, collections.Get(log, collectionsRepository))
+	rt.route("PUT /collections/{id}", middleware.ScopeWrite, collections.Update(log, collectionsRepository))
+	rt.route("DELETE /collections/{id}", middleware.ScopeDelete, collections.Delete(log, collectionsRepository))
+	rt.route("POST /collections/{id}/quotes", middleware.ScopeWrite, collections.AddQuote(log, collectionsRepository))
+	rt.route("PUT /collections/{id}/quotes", middleware.ScopeWrite, collections.Reorder(log, collectionsRepository))
+	rt.route("DELETE /collections/{id}/quotes/{quote_id}", middleware.ScopeWrite, collections.RemoveQuote(log, collectionsRepository))
+	rt.route("GET /collections/{id}/random", middleware.ScopeRead, middleware.CacheControl("no-store")(collections.Random(log, collectionsRepository)))
+	rt.route("GET /stats", middleware.ScopeRead, summary.New(log, stats.NewStatsRepository(deps.DB)))
+	rt.route("GET /audit", middleware.ScopeAdmin, auditlist.New(log, audit.NewLogRepository(deps.DB)))
+	rt.route("POST /admin/webhooks", middleware.ScopeAdmin, subscriptions.Create(log, webhooksRepository))
+	rt.route("GET /admin/webhooks", middleware.ScopeAdmin, subscriptions.List(log, webhooksRepository))
+	rt.route("GET /admin/webhooks/{id}", middleware.ScopeAdmin, subscriptions.Get(log, webhooksRepository))
+	rt.route("PUT /admin/webhooks/{id}", middleware.ScopeAdmin, subscriptions.Update(log, webhooksRepository))
+	rt.route("DELETE /admin/webhooks/{id}", middleware.ScopeAdmin, subscriptions.Delete(log, webhooksRepository))
+	rt.route("GET /admin/webhooks/dead-letters", middleware.ScopeAdmin, deadletters.List(log, webhooksRepository))
+	rt.route("POST /admin/webhooks/dead-letters/{id}/retry", middleware.ScopeAdmin, deadletters.Retry(log, webhooksRepository))
+	rt.route("POST /admin/keys", middleware.ScopeAdmin, create.New(log, keysRepository))
+	rt.route("GET /admin/keys", middleware.ScopeAdmin, keylist.New(log, keysRepository))
+	rt.route("DELETE /admin/keys/{id}", middleware.ScopeAdmin, revoke.New(log, keysRepository))
+
+	rt.handle("GET /healthz", health.Live())
+	rt.handle("GET /readyz", health.Ready(log, deps.DB, deps.Probe))
+	rt.handle("GET /version", health.Version())
+	rt.handle("GET /metrics", metrics.Handler(metrics.Default))
+	if err := rt.docs.Add("GET "+api.SpecPath, ""); err != nil && rt.err == nil {
+		rt.err = err
+	}
+	if rt.err != nil {
+		return nil, fmt.Errorf("%s: %w", op, rt.err)
+	}
+	spec, err := openapi.Handler(rt.docs.Document())
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	rt.mux.HandleFunc("GET "+api.SpecPath, spec)
+	rt.mux.Handle("GET "+api.UIPath, openapi.UI(api.UIPath, api.SpecPath))
+
+	cors := middleware.CORS(middleware.CORSOptions{
+		AllowedOrigins: conf.CORS.AllowedOrigins,
+		AllowedMethods: conf.CORS.AllowedMethods,
+		AllowedHeaders: conf.CORS.AllowedHeaders,
+		ExposedHeaders: []string{
+			"RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "Retry-After", "ETag", "Idempotent-Replayed",
+			"Deprecation", "Sunset", "Link",
+		},
+		AllowCredentials: conf.CORS.AllowCredentials,
+		MaxAge:           conf.CORS.MaxAge,
+	})
+	return middleware.Chain(middleware.Router(rt.mux),
+		middleware.Trace(deps.Tracer),
+		middleware.RequestID,
+		middleware.New(log),
+		middleware.Recover(log),
+		middleware.Compress(conf.CompressMinSize, conf.CompressLevel),
+		middleware.SecureHeaders,
+		cors,
+		middleware.Authenticate(log, deps.Authenticators...),
+	), nil
+}
+
+// route serves h under every API version and keeps the unversioned path as
+// a deprecated alias.
+func (rt *router) route(pattern, scope string, h http.Handler) {
+	rt.versions(pattern, scope, h, h)
+}
+
+// versions is route for handlers whose V2 shape differs from V1.
+func (rt *router) versions(pattern, scope string, v1, v2 http.Handler) {
+	rt.api(api.V1, pattern, scope, v1)
+	rt.api(api.V2, pattern, scope, v2)
+	rt.api("", pattern, scope, v1)
+}
+
+// api registers pattern under version, or as the deprecated alias when
+// version is empty. Settings such as rate and body limits are configured
+// per unversioned pattern and shared by every version.
+func (rt *router) api(version, pattern, scope string, h http.Handler) {
+	if rt.err != nil {
+		return
+	}
+	method, _, _ := strings.Cut(pattern, " ")
+	mutating := method == http.MethodPost || method == http.MethodPut || method == http.MethodPatch || method == http.MethodDelete
+	required := ""
+	if scope == middleware.ScopeAdmin || rt.conf.Auth.Enabled {
+		required = scope
+	}
+	registered := pattern
+	var err error
+	if version == "" {
+		err = rt.docs.AddAlias(pattern, required)
+	} else {
+		registered = api.Versioned(version, pattern)
+		err = rt.docs.AddVersion(version, pattern, required)
+	}
+	if err != nil {
+		rt.err = err
+		return
+	}
+	doc := rt.docs.Document()
+	if mutating {
+		h = middleware.IdempotentRoute(pattern)(rt.idempotent(h))
+	}
+	h = middleware.Validate(rt.log, doc, registered, middleware.ValidateOptions{Requests: rt.conf.ValidateRequests})(h)
+	if required != "" {
+		h = middleware.RequireScope(required)(h)
+	}
+	spec, ok := rt.conf.RateLimit.Routes[pattern]
+	if !ok {
+		spec = rt.conf.RateLimit.Default
+	}
+	if spec != "" {
+		limit, err := middleware.ParseLimit(spec)
+		if err != nil {
+			rt.err = fmt.Errorf("invalid rate limit for %s: %w", pattern, err)
+			return
+		}
+		h = rt.limiter.Limit(pattern, limit)(h)
+	}
+	if mutating && method != http.MethodDelete {
+		size, ok := rt.conf.BodyLimits[pattern]
+		if !ok {
+			size = rt.conf.MaxBodyBytes
+		}
+		h = middleware.MaxBodySize(size)(h)
+	}
+	if version == api.V2 {
+		h = middleware.Problems(h)
+	}
+	// Responses are checked as the client sees them, problems included.
+	h = middleware.Validate(rt.log, doc, registered, middleware.ValidateOptions{
+		Responses:   rt.conf.ValidateResponses,
+		OnViolation: rt.violation,
+	})(h)
+	if version == "" {
+		h = middleware.Deprecation(rt.conf.Versioning.DeprecatedAt, rt.conf.Versioning.Sunset, "/"+api.V1)(h)
+	}
+	rt.mux.Handle(registered, h)
+}
+
+// handle registers a route outside the API versions, without authentication.
+func (rt *router) handle(pattern string, h http.HandlerFunc) {
+	if rt.err != nil {
+		return
+	}
+	if err := rt.docs.Add(pattern, ""); err != nil {
+		rt.err = err
+		return
+	}
+	rt.mux.Handle(pattern, h)
+}
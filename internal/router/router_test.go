@@ -0,0 +1,280 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"quotes-mini-service/internal/api"
+	"quotes-mini-service/internal/apikey"
+	"quotes-mini-service/internal/config"
+	"quotes-mini-service/internal/events"
+	"quotes-mini-service/internal/health"
+	"quotes-mini-service/internal/quote"
+	"quotes-mini-service/internal/quote/handlers/rotation"
+	"quotes-mini-service/internal/storage"
+	"quotes-mini-service/pkg/middleware"
+	"quotes-mini-service/pkg/openapi"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testServer struct {
+	handler http.Handler
+	key     string
+}
+
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+	db, err := storage.NewStorage(":memory:")
+	if err != nil {
+		t.Fatalf("failed to create test database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	keys := apikey.NewKeysRepository(log, db)
+	_, key, err := keys.Create(context.Background(), "test", []string{middleware.ScopeAdmin})
+	if err != nil {
+		t.Fatalf("failed to create api key: %v", err)
+	}
+	limiter := middleware.NewRateLimiter(nil, time.Minute)
+	t.Cleanup(limiter.Close)
+
+	conf := &config.Config{}
+	conf.MaxBodyBytes = 1 << 20
+	conf.IdempotencyTTL = time.Hour
+	conf.Stream.Heartbeat = time.Second
+	conf.GraphQL = config.GraphQL{MaxDepth: 8, MaxComplexity: 1000}
+	conf.Validation = config.Validation{ValidateRequests: true, ValidateResponses: true}
+	conf.Versioning = config.Versioning{
+		DeprecatedAt: time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC),
+		Sunset:       time.Date(2027, 4, 19, 0, 0, 0, 0, time.UTC),
+	}
+	handler, err := New(log, conf, Deps{
+		DB:             db,
+		Quotes:         quote.NewQuotesRepository(db),
+		Authenticators: []middleware.Authenticator{middleware.APIKey{Store: keys}},
+		Broker:         events.NewBroker(log, events.NewEventsRepository(db), events.BrokerOptions{PollInterval: time.Second, MaxSubscribers: 1, History: 10}),
+		Hub:            rotation.NewHub(1),
+		Limiter:        limiter,
+		Probe:          &health.Probe{},
+		OnViolation: func(r *http.Request, err error) {
+			t.Errorf("%s %s: %v", r.Method, r.URL, err)
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create router: %v", err)
+	}
+	return &testServer{handler: handler, key: key}
+}
+
+func (s *testServer) do(method, target, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("X-API-Key", s.key)
+	rr := httptest.NewRecorder()
+	s.handler.ServeHTTP(rr, req)
+	return rr
+}
+
+// TestRoutes_Documented fails when a route in the operations table is not
+// registered, or an API route is missing one of its versions.
+func TestRoutes_Documented(t *testing.T) {
+	s := newTestServer(t)
+	rr := s.do(http.MethodGet, api.SpecPath, "")
+	var doc openapi.Document
+	if err := json.NewDecoder(rr.Body).Decode(&doc); err != nil {
+		t.Fatalf("failed to decode document: %v", err)
+	}
+	served := func(pattern string) *openapi.OperationObject {
+		method, path, _ := strings.Cut(pattern, " ")
+		if item, ok := doc.Paths[path]; ok {
+			return (*item)[strings.ToLower(method)]
+		}
+		return nil
+	}
+	for _, pattern := range api.Patterns() {
+		op := served(pattern)
+		if op == nil {
+			t.Errorf("route %q is documented but not registered", pattern)
+			continue
+		}
+		if !op.Deprecated {
+			continue
+		}
+		for _, version := range []string{api.V1, api.V2} {
+			if served(api.Versioned(version, pattern)) == nil {
+				t.Errorf("route %q is missing from %s", pattern, version)
+			}
+		}
+	}
+}
+
+type call struct {
+	method string
+	target string
+	body   string
+	status int
+}
+
+// TestRoutes_Contract runs the real handlers behind the router, which
+// checks every response against the document.
+func TestRoutes_Contract(t *testing.T) {
+	s := newTestServer(t)
+	calls := []call{
+		{"GET", "/v1/quotes", "", 200},
+		{"GET", "/v1/quotes/random", "", 404},
+		{"POST", "/v1/quotes", `{"author": "Confucius", "quote": "first"}`, 201},
+		{"POST", "/v1/quotes", `{"author": "Confucius", "quote": "first"}`, 409},
+		{"POST", "/v1/quotes", `{"author": "Confucius"}`, 400},
+		{"GET", "/v1/quotes?author=Confucius", "", 200},
+		{"GET", "/v1/quotes/1", "", 200},
+		{"GET", "/v1/quotes/2", "", 404},
+		{"GET", "/v1/quotes/x", "", 400},
+		{"GET", "/v1/quotes/random", "", 200},
+		{"PUT", "/v1/quotes/1", `{"author": "Confucius", "quote": "second"}`, 200},
+		{"PUT", "/v1/quotes/1", `{"author": 1, "quote": "second"}`, 400},
+		{"GET", "/v1/quotes/1/revisions", "", 200},
+		{"GET", "/v1/quotes/1/revisions/diff?from=1&to=2", "", 200},
+		{"GET", "/v1/quotes/1/revisions/diff", "", 400},
+		{"POST", "/v1/quotes/1/revisions/1/restore", "", 200},
+		{"POST", "/v1/quotes/1/like", "", 201},
+		{"POST", "/v1/quotes/1/like", "", 200},
+		{"POST", "/v2/quotes/9/like", "", 404},
+		{"PUT", "/v1/quotes/1/rating", `{"rating": 4}`, 200},
+		{"PUT", "/v1/quotes/1/rating", `{"rating": 9}`, 400},
+		{"PUT", "/v2/quotes/9/rating", `{"rating": 4}`, 404},
+		{"GET", "/v1/quotes/top", "", 200},
+		{"GET", "/v1/quotes/top?period=all&limit=5", "", 200},
+		{"GET", "/v1/quotes/top?period=decade", "", 400},
+		{"GET", "/v1/quotes?sort=rating", "", 200},
+		{"GET", "/v1/quotes?sort=author", "", 400},
+		{"GET", "/v2/quotes?sort=likes", "", 200},
+		{"POST", "/v1/quotes", `{"author": "Confucius", "quote": "первая", "translation_of": 1}`, 201},
+		{"POST", "/v1/quotes", `{"author": "Confucius", "quote": "вторая", "translation_of": 1}`, 409},
+		{"POST", "/v1/quotes", `{"author": "Confucius", "quote": "erste", "translation_of": 9}`, 400},
+		{"POST", "/v1/quotes", `{"author": "Confucius", "quote": "erste", "lang": "german"}`, 400},
+		{"GET", "/v1/quotes?lang=ru", "", 200},
+		{"GET", "/v2/quotes?lang=en", "", 200},
+		{"GET", "/v1/quotes/random?lang=ru", "", 200},
+		{"GET", "/v1/quotes/random?lang=de", "", 404},
+		{"GET", "/v1/quotes/random/batch?count=2", "", 200},
+		{"GET", "/v1/quotes/random/batch?count=0", "", 400},
+		{"GET", "/v1/quotes/2/translations", "", 200},
+		{"GET", "/v1/quotes/9/translations", "", 404},
+		{"POST", "/v1/quotes", `{"author": "Confucius", "quote": "third", "source": "Analects", "year": -479, "url": "https://example.com", "page": "7"}`, 201},
+		{"POST", "/v1/quotes", `{"author": "Confucius", "quote": "fourth", "year": 9999}`, 400},
+		{"GET", "/v1/quotes?source=Analects&year_from=-500&year_to=-400", "", 200},
+		{"GET", "/v2/quotes?source=Analects&year_to=0", "", 200},
+		{"GET", "/v2/quotes?year_from=2000&year_to=1900", "", 400},
+		{"POST", "/v1/graphql", `{"query": "{ quotes { totalCount } }"}`, 200},
+		{"GET", "/v1/stats", "", 200},
+		{"GET", "/v2/stats?days=7&limit=1", "", 200},
+		{"GET", "/v1/stats?days=0", "", 400},
+		{"GET", "/v1/audit", "", 200},
+		{"GET", "/v1/audit?limit=0", "", 400},
+		{"GET", "/v1/audit?since=yesterday", "", 400},
+		{"GET", "/v2/quotes?limit=1", "", 200},
+		{"GET", "/v2/quotes?limit=1000", "", 400},
+		{"GET", "/v2/quotes/9", "", 404},
+		{"POST", "/v2/quotes", `{"author": "Confucius", "quote": "first"}`, 409},
+		{"POST", "/v2/quotes", `{}`, 400},
+		{"GET", "/quotes", "", 200},
+		{"GET", "/quotes/9", "", 404},
+		{"POST", "/v1/collections", `{"name": "Standup"}`, 201},
+		{"POST", "/v1/collections", `{"name": " "}`, 400},
+		{"GET", "/v1/collections/1/random", "", 404},
+		{"POST", "/v1/collections/1/quotes", `{"quote_id": 1}`, 201},
+		{"POST", "/v1/collections/1/quotes", `{"quote_id": 1}`, 409},
+		{"POST", "/v1/collections/1/quotes", `{"quote_id": 9}`, 404},
+		{"POST", "/v1/collections/1/quotes", `{"quote_id": 1, "position": 5}`, 400},
+		{"PUT", "/v1/collections/1/quotes", `{"quote_ids": [1]}`, 200},
+		{"PUT", "/v1/collections/1/quotes", `{"quote_ids": [1, 2]}`, 400},
+		{"GET", "/v1/collections", "", 200},
+		{"GET", "/v1/collections/1", "", 200},
+		{"GET", "/v2/collections/1/random", "", 200},
+		{"PUT", "/v1/collections/1", `{"name": "Retro", "description": "Closing words."}`, 200},
+		{"GET", "/v2/collections/9", "", 404},
+		{"DELETE", "/v1/quotes/1", "", 204},
+		{"GET", "/v1/collections/1/random", "", 404},
+		{"POST", "/v1/collections/1/quotes", `{"quote_id": 1}`, 404},
+		{"DELETE", "/v1/collections/1/quotes/1", "", 404},
+		{"DELETE", "/v1/collections/1", "", 204},
+		{"DELETE", "/v1/quotes/1", "", 404},
+		{"POST", "/v1/admin/webhooks", `{"url": "https://hooks.example.com", "events": ["quote.created"]}`, 201},
+		{"POST", "/v1/admin/webhooks", `{"url": "ftp://x", "events": ["quote.created"]}`, 400},
+		{"GET", "/v1/admin/webhooks", "", 200},
+		{"GET", "/v1/admin/webhooks/1", "", 200},
+		{"PUT", "/v1/admin/webhooks/1", `{"url": "https://hooks.example.com", "events": ["quote.deleted"], "active": false}`, 200},
+		{"GET", "/v1/admin/webhooks/dead-letters", "", 200},
+		{"POST", "/v1/admin/webhooks/dead-letters/1/retry", "", 404},
+		{"DELETE", "/v2/admin/webhooks/1", "", 204},
+		{"POST", "/v1/admin/keys", `{"name": "ci", "scopes": ["quotes:read"]}`, 201},
+		{"GET", "/v1/admin/keys", "", 200},
+		{"DELETE", "/v1/admin/keys/2", "", 204},
+		{"GET", "/healthz", "", 200},
+		{"GET", "/readyz", "", 200},
+		{"GET", "/version", "", 200},
+	}
+	for _, c := range calls {
+		rr := s.do(c.method, c.target, c.body)
+		if rr.Code != c.status {
+			t.Fatalf("%s %s: expected status %d, got %d: %s", c.method, c.target, c.status, rr.Code, rr.Body)
+		}
+	}
+}
+
+func TestRoutes_Versions(t *testing.T) {
+	s := newTestServer(t)
+
+	rr := s.do(http.MethodGet, "/v1/quotes", "")
+	if got := strings.TrimSpace(rr.Body.String()); got != `{"quotes":[],"count":0}` {
+		t.Errorf("expected the V1 list shape, got %s", got)
+	}
+	if rr.Header().Get("Deprecation") != "" {
+		t.Error("expected V1 without Deprecation")
+	}
+
+	rr = s.do(http.MethodGet, "/quotes/7", "")
+	if got := rr.Header().Get("Deprecation"); got != "@1792368000" {
+		t.Errorf("unexpected Deprecation %q", got)
+	}
+	if got := rr.Header().Get("Sunset"); got != "Mon, 19 Apr 2027 00:00:00 GMT" {
+		t.Errorf("unexpected Sunset %q", got)
+	}
+	if got := rr.Header().Get("Link"); got != `</v1/quotes/7>; rel="successor-version"` {
+		t.Errorf("unexpected Link %q", got)
+	}
+	if ct := rr.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("expected the alias to answer like V1, got %q", ct)
+	}
+
+	rr = s.do(http.MethodGet, "/v2/quotes/x", "")
+	if ct := rr.Header().Get("Content-Type"); ct != "application/problem+json" {
+		t.Fatalf("expected a problem, got %q: %s", ct, rr.Body)
+	}
+	var p struct {
+		Status   int    `json:"status"`
+		Instance string `json:"instance"`
+		Errors   []struct {
+			Field string `json:"field"`
+		} `json:"errors"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&p); err != nil {
+		t.Fatalf("failed to decode problem: %v", err)
+	}
+	if p.Status != http.StatusBadRequest || p.Instance != "/v2/quotes/x" || len(p.Errors) != 1 || p.Errors[0].Field != "/path/id" {
+		t.Errorf("unexpected problem %+v", p)
+	}
+
+	rr = s.do(http.MethodGet, "/v2/quotes", "")
+	var page struct {
+		Data       []any          `json:"data"`
+		Pagination map[string]any `json:"pagination"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&page); err != nil || page.Data == nil || page.Pagination["limit"] != 20.0 {
+		t.Errorf("expected a V2 page, got %+v (%v)", page, err)
+	}
+}
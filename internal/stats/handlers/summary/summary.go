@@ -0,0 +1,60 @@
+package summary
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"quotes-mini-service/internal/stats"
+	"quotes-mini-service/pkg/res"
+	"quotes-mini-service/pkg/sl"
+	"quotes-mini-service/pkg/tracing"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultDays  = 30
+	maxDays      = 365
+	defaultLimit = 10
+	maxLimit     = 100
+)
+
+type Summarizer interface {
+	Summary(ctx context.Context, since time.Time, limit int) (*stats.Summary, error)
+}
+
+// New reports the totals per author and tag, the most viewed quotes and
+// the daily series of the last days, today included.
+func New(log *slog.Logger, s Summarizer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.stats.summary.New"
+		log := log.With(
+			slog.String("op", op),
+		)
+		ctx, span := tracing.Start(r.Context(), op)
+		defer span.End()
+		q := r.URL.Query()
+		days, limit := defaultDays, defaultLimit
+		var err error
+		if v := q.Get("days"); v != "" {
+			if days, err = strconv.Atoi(v); err != nil || days <= 0 || days > maxDays {
+				res.Json(w, res.Error("days must be between 1 and "+strconv.Itoa(maxDays)), http.StatusBadRequest)
+				return
+			}
+		}
+		if v := q.Get("limit"); v != "" {
+			if limit, err = strconv.Atoi(v); err != nil || limit <= 0 || limit > maxLimit {
+				res.Json(w, res.Error("limit must be between 1 and "+strconv.Itoa(maxLimit)), http.StatusBadRequest)
+				return
+			}
+		}
+		since := time.Now().UTC().AddDate(0, 0, 1-days)
+		summary, err := s.Summary(ctx, since, limit)
+		if err != nil {
+			log.Error("internal server error", sl.Err(err))
+			res.Json(w, res.Error("internal server error"), http.StatusInternalServerError)
+			return
+		}
+		res.Json(w, summary, http.StatusOK)
+	}
+}
@@ -0,0 +1,54 @@
+package summary
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"quotes-mini-service/internal/stats"
+	"testing"
+	"time"
+)
+
+type mockSummarizer struct {
+	since time.Time
+	limit int
+}
+
+func (m *mockSummarizer) Summary(_ context.Context, since time.Time, limit int) (*stats.Summary, error) {
+	m.since, m.limit = since, limit
+	return &stats.Summary{}, nil
+}
+
+func TestNew(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	today := time.Now().UTC()
+	tests := []struct {
+		target string
+		status int
+		since  string
+		limit  int
+	}{
+		{"/stats", http.StatusOK, today.AddDate(0, 0, -29).Format(time.DateOnly), 10},
+		{"/stats?days=1&limit=3", http.StatusOK, today.Format(time.DateOnly), 3},
+		{"/stats?days=0", http.StatusBadRequest, "", 0},
+		{"/stats?days=366", http.StatusBadRequest, "", 0},
+		{"/stats?limit=x", http.StatusBadRequest, "", 0},
+	}
+	for _, tt := range tests {
+		s := &mockSummarizer{}
+		rr := httptest.NewRecorder()
+		New(log, s)(rr, httptest.NewRequest(http.MethodGet, tt.target, nil))
+		if rr.Code != tt.status {
+			t.Errorf("%s: expected status %d, got %d", tt.target, tt.status, rr.Code)
+			continue
+		}
+		if tt.status != http.StatusOK {
+			continue
+		}
+		if got := s.since.Format(time.DateOnly); got != tt.since || s.limit != tt.limit {
+			t.Errorf("%s: expected since %s and limit %d, got %s and %d", tt.target, tt.since, tt.limit, got, s.limit)
+		}
+	}
+}
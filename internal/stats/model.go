@@ -0,0 +1,46 @@
+package stats
+
+// View is the bucket a view is counted in: one quote, one UTC day and one
+// source.
+type View struct {
+	QuoteID int
+	Day     string
+	Source  string
+}
+
+type Summary struct {
+	Totals      Totals        `json:"totals"`
+	Authors     []GroupCount  `json:"authors"`
+	Tags        []GroupCount  `json:"tags"`
+	TopViewed   []ViewedQuote `json:"top_viewed"`
+	AddedPerDay []DayCount    `json:"added_per_day"`
+	ViewsPerDay []DayCount    `json:"views_per_day"`
+}
+
+type Totals struct {
+	Quotes      int `json:"quotes" example:"120"`
+	Views       int `json:"views" example:"5400"`
+	RandomViews int `json:"random_views" example:"5000"`
+	ByIDViews   int `json:"by_id_views" example:"400"`
+	Likes       int `json:"likes" example:"75"`
+	Ratings     int `json:"ratings" example:"40"`
+}
+
+// GroupCount totals the quotes of one author or tag.
+type GroupCount struct {
+	Name   string `json:"name" example:"Confucius"`
+	Quotes int    `json:"quotes" example:"12"`
+	Views  int    `json:"views" example:"340"`
+}
+
+type ViewedQuote struct {
+	ID     int    `json:"id" example:"1"`
+	Author string `json:"author" example:"Confucius"`
+	Quote  string `json:"quote" example:"Life is simple, but we insist on making it complicated."`
+	Views  int    `json:"views" example:"87"`
+}
+
+type DayCount struct {
+	Day   string `json:"day" example:"2026-10-19"`
+	Count int    `json:"count" example:"3"`
+}
@@ -0,0 +1,118 @@
+package stats
+
+import (
+	"context"
+	"log/slog"
+	"quotes-mini-service/pkg/metrics"
+	"quotes-mini-service/pkg/sl"
+	"sync"
+	"time"
+)
+
+var viewsTotal = metrics.NewCounter("quote_views_total", "Quotes shown to clients by source.", "source")
+
+type ViewWriter interface {
+	AddViews(ctx context.Context, views map[View]int) error
+}
+
+type RecorderOptions struct {
+	// Interval is how often the pending counts are written.
+	Interval time.Duration
+	// MaxPending triggers an early write once this many buckets are waiting.
+	MaxPending int
+}
+
+// Recorder counts views in memory and writes them in batches, so that the
+// read path only takes a mutex.
+type Recorder struct {
+	log   *slog.Logger
+	store ViewWriter
+	opts  RecorderOptions
+	now   func() time.Time
+	full  chan struct{}
+
+	mu      sync.Mutex
+	pending map[View]int
+}
+
+func NewRecorder(log *slog.Logger, store ViewWriter, opts RecorderOptions) *Recorder {
+	if opts.MaxPending <= 0 {
+		opts.MaxPending = 1000
+	}
+	return &Recorder{
+		log:     log.With(slog.String("component", "stats/recorder")),
+		store:   store,
+		opts:    opts,
+		now:     time.Now,
+		full:    make(chan struct{}, 1),
+		pending: map[View]int{},
+	}
+}
+
+func (r *Recorder) Record(id int, source string) {
+	viewsTotal.Inc(source)
+	v := View{QuoteID: id, Day: r.now().UTC().Format(time.DateOnly), Source: source}
+	r.mu.Lock()
+	r.pending[v]++
+	n := len(r.pending)
+	r.mu.Unlock()
+	if n >= r.opts.MaxPending {
+		select {
+		case r.full <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Forget drops the pending views of a deleted quote. SQLite may hand its id
+// to the next quote, which must not inherit them.
+func (r *Recorder) Forget(id int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for v := range r.pending {
+		if v.QuoteID == id {
+			delete(r.pending, v)
+		}
+	}
+}
+
+// Run writes the pending views every interval until ctx is cancelled. Call
+// Flush after the servers have stopped to write the rest.
+func (r *Recorder) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.opts.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-r.full:
+		}
+		if err := r.Flush(ctx); err != nil && ctx.Err() == nil {
+			r.log.Error("failed to write views", sl.Err(err))
+		}
+	}
+}
+
+// Flush writes the pending views. On failure they are kept for the next
+// attempt.
+func (r *Recorder) Flush(ctx context.Context) error {
+	r.mu.Lock()
+	batch := r.pending
+	r.pending = map[View]int{}
+	r.mu.Unlock()
+	if len(batch) == 0 {
+		return nil
+	}
+	if err := r.store.AddViews(ctx, batch); err != nil {
+		r.mu.Lock()
+		for v, n := range r.pending {
+			batch[v] += n
+		}
+		r.pending = batch
+		r.mu.Unlock()
+		return err
+	}
+	r.log.Debug("views written", slog.Int("buckets", len(batch)))
+	return nil
+}
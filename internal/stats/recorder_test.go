@@ -0,0 +1,91 @@
+package stats
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+)
+
+type mockViewWriter struct {
+	fail    bool
+	batches []map[View]int
+}
+
+func (m *mockViewWriter) AddViews(_ context.Context, views map[View]int) error {
+	if m.fail {
+		return errors.New("database is locked")
+	}
+	m.batches = append(m.batches, views)
+	return nil
+}
+
+func TestRecorder(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	store := &mockViewWriter{fail: true}
+	r := NewRecorder(log, store, RecorderOptions{Interval: time.Hour, MaxPending: 3})
+	r.now = func() time.Time { return time.Date(2026, 10, 19, 23, 0, 0, 0, time.UTC) }
+
+	r.Record(1, "random")
+	r.Record(1, "random")
+	r.Record(2, "id")
+	if err := r.Flush(context.Background()); err == nil {
+		t.Fatal("expected the write to fail")
+	}
+	r.Record(1, "random")
+	r.Record(3, "random")
+	select {
+	case <-r.full:
+	default:
+		t.Error("expected an early write once MaxPending buckets are waiting")
+	}
+
+	store.fail = false
+	if err := r.Flush(context.Background()); err != nil {
+		t.Fatalf("failed to flush: %v", err)
+	}
+	if len(store.batches) != 1 {
+		t.Fatalf("expected one batch, got %d", len(store.batches))
+	}
+	want := map[View]int{
+		{1, "2026-10-19", "random"}: 3,
+		{2, "2026-10-19", "id"}:     1,
+		{3, "2026-10-19", "random"}: 1,
+	}
+	got := store.batches[0]
+	if len(got) != len(want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+	for v, n := range want {
+		if got[v] != n {
+			t.Errorf("%+v: expected %d views, got %d", v, n, got[v])
+		}
+	}
+	if err := r.Flush(context.Background()); err != nil || len(store.batches) != 1 {
+		t.Errorf("expected nothing to write, got %d batches (%v)", len(store.batches), err)
+	}
+}
+
+func TestRecorder_Forget(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	store := &mockViewWriter{}
+	r := NewRecorder(log, store, RecorderOptions{Interval: time.Hour})
+
+	r.Record(1, "random")
+	r.Record(1, "id")
+	r.Record(2, "id")
+	r.Forget(1)
+	if err := r.Flush(context.Background()); err != nil {
+		t.Fatalf("failed to flush: %v", err)
+	}
+	if len(store.batches) != 1 || len(store.batches[0]) != 1 {
+		t.Fatalf("expected only the views of quote 2, got %v", store.batches)
+	}
+	for v := range store.batches[0] {
+		if v.QuoteID != 2 {
+			t.Errorf("expected only the views of quote 2, got %+v", v)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package stats
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"quotes-mini-service/internal/quote"
+	"quotes-mini-service/internal/storage"
+	"quotes-mini-service/pkg/tracing"
+	"time"
+)
+
+type StatsRepository struct {
+	Database *storage.Db
+}
+
+func NewStatsRepository(database *storage.Db) *StatsRepository {
+	return &StatsRepository{
+		Database: database,
+	}
+}
+
+// AddViews adds a batch of view counts in one transaction. Views of quotes
+// deleted in the meantime are skipped.
+func (repo *StatsRepository) AddViews(ctx context.Context, views map[View]int) (err error) {
+	const op = "stats.repository.AddViews"
+	ctx, span := tracing.StartQuery(ctx, op, "INSERT")
+	defer tracing.EndQuery(span, &err)
+	tx, err := repo.Database.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: begin transaction: %w", op, err)
+	}
+	defer tx.Rollback()
+	stmt, err := tx.PrepareContext(ctx, `INSERT INTO quote_views(quote_id, day, source, views)
+		SELECT id, ?, ?, ? FROM quotes WHERE id = ?
+		ON CONFLICT(quote_id, day, source) DO UPDATE SET views = views + excluded.views`)
+	if err != nil {
+		return fmt.Errorf("%s: prepare statement: %w", op, err)
+	}
+	defer stmt.Close()
+	for v, n := range views {
+		if _, err = stmt.ExecContext(ctx, v.Day, v.Source, n, v.QuoteID); err != nil {
+			return fmt.Errorf("%s: upsert views: %w", op, err)
+		}
+	}
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("%s: commit transaction: %w", op, err)
+	}
+	return nil
+}
+
+// Summary collects the totals and the top viewed quotes, and the daily
+// series from since up to today. The series have an entry for every day.
+func (repo *StatsRepository) Summary(ctx context.Context, since time.Time, limit int) (_ *Summary, err error) {
+	const op = "stats.repository.Summary"
+	ctx, span := tracing.StartQuery(ctx, op, "SELECT")
+	defer tracing.EndQuery(span, &err)
+	tx, err := repo.Database.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s: begin transaction: %w", op, err)
+	}
+	defer tx.Rollback()
+	var s Summary
+	err = tx.QueryRowContext(ctx, `SELECT
+		(SELECT count_value FROM counters WHERE table_name = 'quotes'),
+		COALESCE((SELECT SUM(views) FROM quote_views), 0),
+		COALESCE((SELECT SUM(views) FROM quote_views WHERE source = ?), 0),
+		COALESCE((SELECT SUM(views) FROM quote_views WHERE source = ?), 0),
+		COALESCE((SELECT SUM(likes) FROM quotes), 0),
+		COALESCE((SELECT SUM(rating_count) FROM quotes), 0)`, quote.ViewRandom, quote.ViewByID).
+		Scan(&s.Totals.Quotes, &s.Totals.Views, &s.Totals.RandomViews, &s.Totals.ByIDViews, &s.Totals.Likes, &s.Totals.Ratings)
+	if err != nil {
+		return nil, fmt.Errorf("%s: totals: %w", op, err)
+	}
+	if s.Authors, err = groupCounts(ctx, tx, `SELECT q.author, COUNT(*), COALESCE(SUM(v.views), 0)
+		FROM quotes q LEFT JOIN (`+viewsByQuote+`) v ON v.quote_id = q.id
+		GROUP BY q.author ORDER BY 3 DESC, 2 DESC, 1`); err != nil {
+		return nil, fmt.Errorf("%s: authors: %w", op, err)
+	}
+	if s.Tags, err = groupCounts(ctx, tx, `SELECT t.tag, COUNT(*), COALESCE(SUM(v.views), 0)
+		FROM quote_tags t LEFT JOIN (`+viewsByQuote+`) v ON v.quote_id = t.quote_id
+		GROUP BY t.tag ORDER BY 3 DESC, 2 DESC, 1`); err != nil {
+		return nil, fmt.Errorf("%s: tags: %w", op, err)
+	}
+	if s.TopViewed, err = topViewed(ctx, tx, limit); err != nil {
+		return nil, fmt.Errorf("%s: top viewed: %w", op, err)
+	}
+	from := since.UTC().Format(time.DateOnly)
+	if s.AddedPerDay, err = dayCounts(ctx, tx, since, `SELECT date(created_at), COUNT(*) FROM quotes
+		WHERE created_at >= ? GROUP BY 1`, from); err != nil {
+		return nil, fmt.Errorf("%s: added per day: %w", op, err)
+	}
+	if s.ViewsPerDay, err = dayCounts(ctx, tx, since, `SELECT day, SUM(views) FROM quote_views
+		WHERE day >= ? GROUP BY 1`, from); err != nil {
+		return nil, fmt.Errorf("%s: views per day: %w", op, err)
+	}
+	return &s, nil
+}
+
+const viewsByQuote = "SELECT quote_id, SUM(views) AS views FROM quote_views GROUP BY quote_id"
+
+func groupCounts(ctx context.Context, tx *sql.Tx, query string) ([]GroupCount, error) {
+	rows, err := tx.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	counts := []GroupCount{}
+	for rows.Next() {
+		var c GroupCount
+		if err := rows.Scan(&c.Name, &c.Quotes, &c.Views); err != nil {
+			return nil, err
+		}
+		counts = append(counts, c)
+	}
+	return counts, rows.Err()
+}
+
+func topViewed(ctx context.Context, tx *sql.Tx, limit int) ([]ViewedQuote, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT q.id, q.author, q.quote, v.views
+		FROM (`+viewsByQuote+`) v JOIN quotes q ON q.id = v.quote_id
+		ORDER BY v.views DESC, q.id LIMIT ?`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	quotes := []ViewedQuote{}
+	for rows.Next() {
+		var q ViewedQuote
+		if err := rows.Scan(&q.ID, &q.Author, &q.Quote, &q.Views); err != nil {
+			return nil, err
+		}
+		quotes = append(quotes, q)
+	}
+	return quotes, rows.Err()
+}
+
+// dayCounts runs a query of (day, count) rows and fills in the days
+// without rows with zeros.
+func dayCounts(ctx context.Context, tx *sql.Tx, since time.Time, query string, args ...any) ([]DayCount, error) {
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	counts := map[string]int{}
+	for rows.Next() {
+		var (
+			day   string
+			count int
+		)
+		if err := rows.Scan(&day, &count); err != nil {
+			return nil, err
+		}
+		counts[day] = count
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	series := []DayCount{}
+	today := time.Now().UTC().Format(time.DateOnly)
+	for d := since.UTC(); ; d = d.AddDate(0, 0, 1) {
+		day := d.Format(time.DateOnly)
+		series = append(series, DayCount{Day: day, Count: counts[day]})
+		if day >= today {
+			break
+		}
+	}
+	return series, nil
+}
@@ -0,0 +1,73 @@
+package stats
+
+import (
+	"context"
+	"quotes-mini-service/internal/quote"
+	"quotes-mini-service/internal/storage"
+	"testing"
+	"time"
+)
+
+func TestStatsRepository_Summary(t *testing.T) {
+	db, err := storage.NewStorage(":memory:")
+	if err != nil {
+		t.Fatalf("failed to create test database: %v", err)
+	}
+	defer db.Close()
+	ctx := context.Background()
+	quotes := quote.NewQuotesRepository(db)
+	for _, q := range []struct{ author, text string }{{"A", "one"}, {"A", "two"}, {"B", "three"}} {
+		if _, err := quotes.Save(ctx, q.author, q.text); err != nil {
+			t.Fatalf("failed to save quote: %v", err)
+		}
+	}
+	if err = quotes.SetTags(ctx, 3, []string{"life"}); err != nil {
+		t.Fatalf("failed to tag quote: %v", err)
+	}
+	quotes.Like(ctx, 1, "a")
+
+	repo := NewStatsRepository(db)
+	today := time.Now().UTC().Format(time.DateOnly)
+	yesterday := time.Now().UTC().AddDate(0, 0, -1).Format(time.DateOnly)
+	batches := []map[View]int{
+		// Quote 99 was deleted before its views were written.
+		{{3, today, quote.ViewRandom}: 4, {1, today, quote.ViewByID}: 1, {99, today, quote.ViewByID}: 7},
+		{{3, today, quote.ViewRandom}: 1, {2, yesterday, quote.ViewRandom}: 2},
+	}
+	for _, b := range batches {
+		if err = repo.AddViews(ctx, b); err != nil {
+			t.Fatalf("failed to add views: %v", err)
+		}
+	}
+
+	s, err := repo.Summary(ctx, time.Now().AddDate(0, 0, -2), 2)
+	if err != nil {
+		t.Fatalf("failed to get summary: %v", err)
+	}
+	want := Totals{Quotes: 3, Views: 8, RandomViews: 7, ByIDViews: 1, Likes: 1}
+	if s.Totals != want {
+		t.Errorf("expected totals %+v, got %+v", want, s.Totals)
+	}
+	if len(s.Authors) != 2 || s.Authors[0] != (GroupCount{"B", 1, 5}) || s.Authors[1] != (GroupCount{"A", 2, 3}) {
+		t.Errorf("unexpected authors %+v", s.Authors)
+	}
+	if len(s.Tags) != 1 || s.Tags[0] != (GroupCount{"life", 1, 5}) {
+		t.Errorf("unexpected tags %+v", s.Tags)
+	}
+	if len(s.TopViewed) != 2 || s.TopViewed[0].ID != 3 || s.TopViewed[0].Views != 5 || s.TopViewed[1].ID != 2 {
+		t.Errorf("unexpected top viewed %+v", s.TopViewed)
+	}
+	if len(s.ViewsPerDay) != 3 || s.ViewsPerDay[1] != (DayCount{yesterday, 2}) || s.ViewsPerDay[2] != (DayCount{today, 6}) {
+		t.Errorf("unexpected views per day %+v", s.ViewsPerDay)
+	}
+	if len(s.AddedPerDay) != 3 || s.AddedPerDay[2] != (DayCount{today, 3}) || s.AddedPerDay[0].Count != 0 {
+		t.Errorf("unexpected quotes added per day %+v", s.AddedPerDay)
+	}
+
+	if err = quotes.Delete(ctx, 3); err != nil {
+		t.Fatalf("failed to delete quote: %v", err)
+	}
+	if s, _ = repo.Summary(ctx, time.Now(), 10); s.Totals.Views != 3 || len(s.Tags) != 0 {
+		t.Errorf("expected the views to go with the quote, got %+v", s)
+	}
+}
@@ -0,0 +1,368 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+type migration struct {
+	version int
+	name    string
+	stmts   []string
+}
+
+// migrations are applied in order and recorded in schema_migrations. Append
+// new entries; never edit one that has already shipped.
+var migrations = []migration{
+	{
+		version: 1,
+		name:    "create quotes",
+		stmts: []string{
+			`CREATE TABLE IF NOT EXISTS quotes(
+		id INTEGER PRIMARY KEY,
+		author TEXT NOT NULL,
+		quote TEXT NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE(author, quote)
+	)`,
+			` CREATE TABLE IF NOT EXISTS counters(
+            table_name TEXT PRIMARY KEY,
+            count_value INTEGER DEFAULT 0
+    )`,
+			`CREATE INDEX IF NOT EXISTS idx_author ON quotes(author)`,
+			`INSERT OR IGNORE INTO counters (table_name, count_value) 
+         VALUES ('quotes', 0)`,
+			`CREATE TRIGGER IF NOT EXISTS update_quotes_counter
+    AFTER INSERT ON quotes
+    BEGIN
+        UPDATE counters SET count_value = count_value + 1 
+        WHERE table_name = 'quotes';
+    END;`,
+			`CREATE TRIGGER IF NOT EXISTS delete_quotes_counter
+    AFTER DELETE ON quotes
+    BEGIN
+        UPDATE counters SET count_value = count_value - 1 
+        WHERE table_name = 'quotes';
+    END;`,
+		},
+	},
+	{
+		version: 2,
+		name:    "create api keys",
+		stmts: []string{
+			`CREATE TABLE IF NOT EXISTS api_keys(
+		id INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		prefix TEXT NOT NULL,
+		key_hash TEXT NOT NULL UNIQUE,
+		scopes TEXT NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		last_used_at DATETIME,
+		revoked_at DATETIME
+	)`,
+		},
+	},
+	{
+		version: 3,
+		name:    "add quotes created_by",
+		stmts: []string{
+			`ALTER TABLE quotes ADD COLUMN created_by TEXT`,
+		},
+	},
+	{
+		version: 4,
+		name:    "create idempotency keys",
+		stmts: []string{
+			`CREATE TABLE IF NOT EXISTS idempotency_keys(
+		scope TEXT NOT NULL,
+		key TEXT NOT NULL,
+		request_hash TEXT NOT NULL,
+		status INTEGER NOT NULL DEFAULT 0,
+		headers TEXT,
+		body BLOB,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		expires_at DATETIME NOT NULL,
+		PRIMARY KEY(scope, key)
+	)`,
+			`CREATE INDEX IF NOT EXISTS idx_idempotency_expires ON idempotency_keys(expires_at)`,
+		},
+	},
+	{
+		version: 5,
+		name:    "create audit log",
+		stmts: []string{
+			`CREATE TABLE IF NOT EXISTS audit_log(
+		id INTEGER PRIMARY KEY,
+		actor TEXT NOT NULL,
+		action TEXT NOT NULL,
+		entity TEXT NOT NULL,
+		entity_id INTEGER NOT NULL,
+		before TEXT,
+		after TEXT,
+		request_id TEXT,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	)`,
+			`CREATE INDEX IF NOT EXISTS idx_audit_entity ON audit_log(entity, entity_id)`,
+			`CREATE INDEX IF NOT EXISTS idx_audit_actor ON audit_log(actor)`,
+		},
+	},
+	{
+		version: 6,
+		name:    "create quote revisions",
+		stmts: []string{
+			`ALTER TABLE quotes ADD COLUMN updated_at DATETIME`,
+			`CREATE TABLE IF NOT EXISTS quote_revisions(
+		quote_id INTEGER NOT NULL,
+		revision INTEGER NOT NULL,
+		author TEXT NOT NULL,
+		quote TEXT NOT NULL,
+		created_by TEXT,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY(quote_id, revision)
+	)`,
+			`INSERT INTO quote_revisions(quote_id, revision, author, quote, created_by, created_at)
+		SELECT id, 1, author, quote, created_by, created_at FROM quotes`,
+		},
+	},
+	{
+		version: 7,
+		name:    "create webhooks",
+		stmts: []string{
+			`CREATE TABLE IF NOT EXISTS webhooks(
+		id INTEGER PRIMARY KEY,
+		url TEXT NOT NULL,
+		secret TEXT NOT NULL,
+		events TEXT NOT NULL,
+		active BOOLEAN NOT NULL DEFAULT 1,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	)`,
+			`CREATE TABLE IF NOT EXISTS webhook_outbox(
+		id INTEGER PRIMARY KEY,
+		webhook_id INTEGER NOT NULL,
+		event_id TEXT NOT NULL,
+		event TEXT NOT NULL,
+		payload TEXT NOT NULL,
+		status TEXT NOT NULL DEFAULT 'pending',
+		attempts INTEGER NOT NULL DEFAULT 0,
+		next_attempt_at DATETIME NOT NULL,
+		last_status INTEGER,
+		last_error TEXT,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		delivered_at DATETIME
+	)`,
+			`CREATE INDEX IF NOT EXISTS idx_webhook_outbox_due ON webhook_outbox(status, next_attempt_at)`,
+		},
+	},
+	{
+		version: 8,
+		name:    "create quote events",
+		stmts: []string{
+			`CREATE TABLE IF NOT EXISTS quote_events(
+		seq INTEGER PRIMARY KEY AUTOINCREMENT,
+		type TEXT NOT NULL,
+		quote_id INTEGER NOT NULL,
+		data TEXT NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	)`,
+		},
+	},
+	{
+		version: 9,
+		name:    "create quote tags",
+		stmts: []string{
+			`CREATE TABLE IF NOT EXISTS quote_tags(
+		quote_id INTEGER NOT NULL,
+		tag TEXT NOT NULL,
+		PRIMARY KEY (quote_id, tag)
+	)`,
+			`CREATE INDEX IF NOT EXISTS idx_quote_tags_tag ON quote_tags(tag)`,
+		},
+	},
+	{
+		version: 10,
+		name:    "create quote votes",
+		stmts: []string{
+			`ALTER TABLE quotes ADD COLUMN likes INTEGER NOT NULL DEFAULT 0`,
+			`ALTER TABLE quotes ADD COLUMN rating_count INTEGER NOT NULL DEFAULT 0`,
+			`ALTER TABLE quotes ADD COLUMN rating_sum INTEGER NOT NULL DEFAULT 0`,
+			`ALTER TABLE quotes ADD COLUMN rating REAL NOT NULL DEFAULT 0`,
+			`CREATE INDEX IF NOT EXISTS idx_quotes_rating ON quotes(rating DESC, rating_count DESC)`,
+			`CREATE TABLE IF NOT EXISTS quote_likes(
+		quote_id INTEGER NOT NULL,
+		voter TEXT NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (quote_id, voter)
+	)`,
+			`CREATE TABLE IF NOT EXISTS quote_ratings(
+		quote_id INTEGER NOT NULL,
+		voter TEXT NOT NULL,
+		rating INTEGER NOT NULL CHECK (rating BETWEEN 1 AND 5),
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (quote_id, voter)
+	)`,
+			`CREATE INDEX IF NOT EXISTS idx_quote_likes_created ON quote_likes(created_at)`,
+			`CREATE INDEX IF NOT EXISTS idx_quote_ratings_updated ON quote_ratings(updated_at)`,
+			`CREATE TRIGGER IF NOT EXISTS insert_quote_like
+    AFTER INSERT ON quote_likes
+    BEGIN
+        UPDATE quotes SET likes = likes + 1 WHERE id = NEW.quote_id;
+    END;`,
+			`CREATE TRIGGER IF NOT EXISTS delete_quote_like
+    AFTER DELETE ON quote_likes
+    BEGIN
+        UPDATE quotes SET likes = likes - 1 WHERE id = OLD.quote_id;
+    END;`,
+			`CREATE TRIGGER IF NOT EXISTS insert_quote_rating
+    AFTER INSERT ON quote_ratings
+    BEGIN
+        UPDATE quotes SET rating_count = rating_count + 1,
+            rating_sum = rating_sum + NEW.rating,
+            rating = (rating_sum + NEW.rating) * 1.0 / (rating_count + 1)
+        WHERE id = NEW.quote_id;
+    END;`,
+			`CREATE TRIGGER IF NOT EXISTS update_quote_rating
+    AFTER UPDATE OF rating ON quote_ratings
+    BEGIN
+        UPDATE quotes SET rating_sum = rating_sum - OLD.rating + NEW.rating,
+            rating = (rating_sum - OLD.rating + NEW.rating) * 1.0 / rating_count
+        WHERE id = NEW.quote_id;
+    END;`,
+			`CREATE TRIGGER IF NOT EXISTS delete_quote_rating
+    AFTER DELETE ON quote_ratings
+    BEGIN
+        UPDATE quotes SET rating_count = rating_count - 1,
+            rating_sum = rating_sum - OLD.rating,
+            rating = CASE WHEN rating_count > 1 THEN (rating_sum - OLD.rating) * 1.0 / (rating_count - 1) ELSE 0 END
+        WHERE id = OLD.quote_id;
+    END;`,
+		},
+	},
+	{
+		version: 11,
+		name:    "create quote views",
+		stmts: []string{
+			`CREATE TABLE IF NOT EXISTS quote_views(
+		quote_id INTEGER NOT NULL,
+		day TEXT NOT NULL,
+		source TEXT NOT NULL,
+		views INTEGER NOT NULL DEFAULT 0,
+		PRIMARY KEY (quote_id, day, source)
+	)`,
+			`CREATE INDEX IF NOT EXISTS idx_quote_views_day ON quote_views(day)`,
+			`CREATE INDEX IF NOT EXISTS idx_quotes_created ON quotes(created_at)`,
+		},
+	},
+	{
+		version: 12,
+		name:    "create collections",
+		stmts: []string{
+			`CREATE TABLE IF NOT EXISTS collections(
+		id INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		description TEXT NOT NULL DEFAULT '',
+		quote_count INTEGER NOT NULL DEFAULT 0,
+		created_by TEXT,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME
+	)`,
+			`CREATE TABLE IF NOT EXISTS collection_items(
+		collection_id INTEGER NOT NULL,
+		quote_id INTEGER NOT NULL,
+		position INTEGER NOT NULL,
+		added_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (collection_id, quote_id)
+	)`,
+			`CREATE INDEX IF NOT EXISTS idx_collection_items_position ON collection_items(collection_id, position)`,
+			`CREATE INDEX IF NOT EXISTS idx_collection_items_quote ON collection_items(quote_id)`,
+			`CREATE TRIGGER IF NOT EXISTS insert_collection_item
+    AFTER INSERT ON collection_items
+    BEGIN
+        UPDATE collections SET quote_count = quote_count + 1 WHERE id = NEW.collection_id;
+    END;`,
+			`CREATE TRIGGER IF NOT EXISTS delete_collection_item
+    AFTER DELETE ON collection_items
+    BEGIN
+        UPDATE collections SET quote_count = quote_count - 1 WHERE id = OLD.collection_id;
+    END;`,
+		},
+	},
+	{
+		version: 13,
+		name:    "add quote languages",
+		stmts: []string{
+			`ALTER TABLE quotes ADD COLUMN lang TEXT NOT NULL DEFAULT ''`,
+			`ALTER TABLE quotes ADD COLUMN original_id INTEGER`,
+			`UPDATE quotes SET lang = CASE
+			WHEN quote GLOB '*[А-яЁё]*' THEN 'ru'
+			WHEN quote GLOB '*[A-Za-z]*' THEN 'en'
+			ELSE '' END`,
+			`CREATE INDEX IF NOT EXISTS idx_quotes_lang ON quotes(lang)`,
+			`CREATE INDEX IF NOT EXISTS idx_quotes_original ON quotes(original_id)`,
+		},
+	},
+	{
+		version: 14,
+		name:    "add quote citations",
+		stmts: []string{
+			`ALTER TABLE quotes ADD COLUMN source TEXT NOT NULL DEFAULT ''`,
+			`ALTER TABLE quotes ADD COLUMN year INTEGER`,
+			`ALTER TABLE quotes ADD COLUMN source_url TEXT NOT NULL DEFAULT ''`,
+			`ALTER TABLE quotes ADD COLUMN page TEXT NOT NULL DEFAULT ''`,
+			`ALTER TABLE quotes ADD COLUMN notes TEXT NOT NULL DEFAULT ''`,
+			`CREATE INDEX IF NOT EXISTS idx_quotes_source ON quotes(source)`,
+			`CREATE INDEX IF NOT EXISTS idx_quotes_year ON quotes(year)`,
+		},
+	},
+	{
+		version: 15,
+		name:    "add revision citations",
+		stmts: []string{
+			`ALTER TABLE quote_revisions ADD COLUMN source TEXT NOT NULL DEFAULT ''`,
+			`ALTER TABLE quote_revisions ADD COLUMN year INTEGER`,
+			`ALTER TABLE quote_revisions ADD COLUMN source_url TEXT NOT NULL DEFAULT ''`,
+			`ALTER TABLE quote_revisions ADD COLUMN page TEXT NOT NULL DEFAULT ''`,
+			`ALTER TABLE quote_revisions ADD COLUMN notes TEXT NOT NULL DEFAULT ''`,
+			// Citations could not be edited so far, so every revision has the
+			// citation the quote has now.
+			`UPDATE quote_revisions SET (source, year, source_url, page, notes) =
+		(SELECT source, year, source_url, page, notes FROM quotes WHERE quotes.id = quote_revisions.quote_id)
+		WHERE EXISTS (SELECT 1 FROM quotes WHERE quotes.id = quote_revisions.quote_id)`,
+		},
+	},
+}
+
+func migrate(db *sql.DB) error {
+	const op = "storage.migrate"
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("%s: begin transaction: %w", op, err)
+	}
+	defer tx.Rollback()
+	_, err = tx.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations(
+		version INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		applied_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	)`)
+	if err != nil {
+		return fmt.Errorf("%s: create schema_migrations: %w", op, err)
+	}
+	var current int
+	if err = tx.QueryRow("SELECT COALESCE(MAX(version), 0) FROM schema_migrations").Scan(&current); err != nil {
+		return fmt.Errorf("%s: read schema version: %w", op, err)
+	}
+	for _, m := range migrations {
+		if m.version <= current {
+			continue
+		}
+		for _, query := range m.stmts {
+			if _, err := tx.Exec(query); err != nil {
+				return fmt.Errorf("%s: migration %d (%s): %w", op, m.version, m.name, err)
+			}
+		}
+		if _, err := tx.Exec("INSERT INTO schema_migrations(version, name) VALUES(?, ?)", m.version, m.name); err != nil {
+			return fmt.Errorf("%s: record migration %d: %w", op, m.version, err)
+		}
+	}
+	return tx.Commit()
+}
@@ -1,8 +1,10 @@
 package storage
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"strings"
 
 	_ "github.com/mattn/go-sqlite3"
 )
@@ -13,81 +15,64 @@ type Db struct {
 
 func NewStorage(dbPath string) (*Db, error) {
 	const op = "storage.NewStorage"
-	db, err := sql.Open("sqlite3", dbPath)
+	db, err := sql.Open("sqlite3", dsn(dbPath))
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
+	// Every connection to an in-memory database gets its own empty database,
+	// so the pool must never open a second one.
+	if isMemory(dbPath) {
+		db.SetMaxOpenConns(1)
+	}
 	if err := db.Ping(); err != nil {
 		db.Close()
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
-	if err := initDb(db); err != nil {
+	if err := migrate(db); err != nil {
 		db.Close()
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 	return &Db{db}, nil
 }
-func initDb(db *sql.DB) error {
-	tx, err := db.Begin()
+
+// CheckMigrations reports an error unless every known migration is recorded
+// as applied.
+func (db *Db) CheckMigrations(ctx context.Context) error {
+	const op = "storage.CheckMigrations"
+	var version int
+	err := db.QueryRowContext(ctx, "SELECT COALESCE(MAX(version), 0) FROM schema_migrations").
+		Scan(&version)
 	if err != nil {
-		return err
-	}
-	defer tx.Rollback()
-	if err := createTables(tx); err != nil {
-		return err
+		return fmt.Errorf("%s: %w", op, err)
 	}
-	if err := createTriggers(tx); err != nil {
-		return err
-	}
-	return tx.Commit()
-}
-func createTables(tx *sql.Tx) error {
-	const op = "Storage.createTable"
-	createTables := []string{
-		`CREATE TABLE IF NOT EXISTS quotes(
-		id INTEGER PRIMARY KEY,
-		author TEXT NOT NULL,
-		quote TEXT NOT NULL,
-		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-		UNIQUE(author, quote)
-	)`,
-		` CREATE TABLE IF NOT EXISTS counters(
-            table_name TEXT PRIMARY KEY,
-            count_value INTEGER DEFAULT 0
-    )`,
-		`CREATE INDEX IF NOT EXISTS idx_author ON quotes(author)`,
-		`INSERT OR IGNORE INTO counters (table_name, count_value) 
-         VALUES ('quotes', 0)`,
-	}
-	for _, query := range createTables {
-		if _, err := tx.Exec(query); err != nil {
-			return fmt.Errorf("%s: %w", op, err)
-		}
+	if latest := migrations[len(migrations)-1].version; version < latest {
+		return fmt.Errorf("%s: schema version %d, expected %d", op, version, latest)
 	}
 	return nil
 }
 
-func createTriggers(tx *sql.Tx) error {
-	const op = "Storage.createTrigger"
-	createTrigger := []string{
-		`CREATE TRIGGER IF NOT EXISTS update_quotes_counter
-    AFTER INSERT ON quotes
-    BEGIN
-        UPDATE counters SET count_value = count_value + 1 
-        WHERE table_name = 'quotes';
-    END;`,
-		`CREATE TRIGGER IF NOT EXISTS delete_quotes_counter
-    AFTER DELETE ON quotes
-    BEGIN
-        UPDATE counters SET count_value = count_value - 1 
-        WHERE table_name = 'quotes';
-    END;
-		`,
+// dsn makes writers wait for a lock instead of failing with "database is
+// locked", and switches file databases to WAL so readers do not block them.
+func dsn(dbPath string) string {
+	params := []string{"_busy_timeout=5000"}
+	if !isMemory(dbPath) {
+		params = append(params, "_journal_mode=WAL")
+	}
+	sep := "?"
+	if strings.Contains(dbPath, "?") {
+		sep = "&"
 	}
-	for _, query := range createTrigger {
-		if _, err := tx.Exec(query); err != nil {
-			return fmt.Errorf("%s: %w", op, err)
+	for _, p := range params {
+		key, _, _ := strings.Cut(p, "=")
+		if strings.Contains(dbPath, key+"=") {
+			continue
 		}
+		dbPath += sep + p
+		sep = "&"
 	}
-	return nil
+	return dbPath
+}
+
+func isMemory(dbPath string) bool {
+	return dbPath == ":memory:" || strings.Contains(dbPath, "mode=memory")
 }
@@ -0,0 +1,70 @@
+package storage
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewStorage_MigrationsApplied(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "quotes.db")
+	db, err := NewStorage(path)
+	if err != nil {
+		t.Fatalf("failed to create storage: %v", err)
+	}
+	if err := db.CheckMigrations(context.Background()); err != nil {
+		t.Errorf("expected migrations to be applied: %v", err)
+	}
+	db.Close()
+
+	// Reopening must not re-run migrations that are already recorded.
+	db, err = NewStorage(path)
+	if err != nil {
+		t.Fatalf("failed to reopen storage: %v", err)
+	}
+	defer db.Close()
+	var count int
+	if err := db.QueryRow("SELECT COUNT(*) FROM schema_migrations").Scan(&count); err != nil {
+		t.Fatalf("failed to count migrations: %v", err)
+	}
+	if count != len(migrations) {
+		t.Errorf("expected %d recorded migrations, got %d", len(migrations), count)
+	}
+}
+
+func TestCheckMigrations_Pending(t *testing.T) {
+	db, err := NewStorage(":memory:")
+	if err != nil {
+		t.Fatalf("failed to create storage: %v", err)
+	}
+	defer db.Close()
+	if _, err := db.Exec("DELETE FROM schema_migrations"); err != nil {
+		t.Fatalf("failed to reset migrations: %v", err)
+	}
+	if err := db.CheckMigrations(context.Background()); err == nil {
+		t.Error("expected error for pending migrations")
+	}
+}
+
+func TestNewStorage_Pragmas(t *testing.T) {
+	db, err := NewStorage(filepath.Join(t.TempDir(), "quotes.db"))
+	if err != nil {
+		t.Fatalf("failed to create storage: %v", err)
+	}
+	defer db.Close()
+
+	var mode string
+	if err := db.QueryRow("PRAGMA journal_mode").Scan(&mode); err != nil {
+		t.Fatalf("failed to read journal mode: %v", err)
+	}
+	if mode != "wal" {
+		t.Errorf("expected wal journal mode, got %s", mode)
+	}
+	var timeout int
+	if err := db.QueryRow("PRAGMA busy_timeout").Scan(&timeout); err != nil {
+		t.Fatalf("failed to read busy timeout: %v", err)
+	}
+	if timeout != 5000 {
+		t.Errorf("expected busy timeout 5000, got %d", timeout)
+	}
+}
@@ -0,0 +1,152 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"quotes-mini-service/pkg/metrics"
+	"quotes-mini-service/pkg/sl"
+	"strconv"
+	"time"
+)
+
+const (
+	SignatureHeader = "X-Webhook-Signature"
+	TimestampHeader = "X-Webhook-Timestamp"
+	EventHeader     = "X-Webhook-Event"
+	DeliveryHeader  = "X-Webhook-Delivery"
+)
+
+var deliveriesTotal = metrics.NewCounter("webhook_deliveries_total", "Webhook delivery attempts by result.", "result")
+
+// Sign returns the value of the signature header: the hex HMAC-SHA256 of
+// "<timestamp>.<body>" keyed with the subscription secret. Receivers should
+// recompute it and reject stale timestamps.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+type DispatcherOptions struct {
+	Interval    time.Duration
+	Timeout     time.Duration
+	MaxAttempts int
+	// Backoff is the delay before the first retry; it doubles on every
+	// further attempt up to MaxBackoff.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	BatchSize  int
+}
+
+// Dispatcher polls the outbox and posts due deliveries to their subscribers.
+type Dispatcher struct {
+	log    *slog.Logger
+	repo   *WebhooksRepository
+	client *http.Client
+	opts   DispatcherOptions
+}
+
+func NewDispatcher(log *slog.Logger, repo *WebhooksRepository, opts DispatcherOptions) *Dispatcher {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 50
+	}
+	return &Dispatcher{
+		log:    log.With(slog.String("component", "webhook/dispatcher")),
+		repo:   repo,
+		client: &http.Client{Timeout: opts.Timeout},
+		opts:   opts,
+	}
+}
+
+// Run dispatches until ctx is cancelled.
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.opts.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := d.DispatchOnce(ctx); err != nil && ctx.Err() == nil {
+				d.log.Error("failed to dispatch webhooks", sl.Err(err))
+			}
+		}
+	}
+}
+
+// DispatchOnce sends one batch of due deliveries and reports how many were
+// attempted.
+func (d *Dispatcher) DispatchOnce(ctx context.Context) (int, error) {
+	targets, err := d.repo.due(ctx, d.opts.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+	for _, t := range targets {
+		status, err := d.send(ctx, t)
+		if err == nil {
+			deliveriesTotal.Inc("delivered")
+			if err := d.repo.delivered(ctx, t.ID, status); err != nil {
+				return 0, err
+			}
+			continue
+		}
+		attempts := t.Attempts + 1
+		var next time.Time
+		if attempts < d.opts.MaxAttempts {
+			next = d.repo.now().Add(d.backoff(attempts))
+			deliveriesTotal.Inc("retry")
+		} else {
+			deliveriesTotal.Inc("dead")
+		}
+		d.log.Warn("webhook delivery failed",
+			slog.Int64("delivery", t.ID),
+			slog.String("url", t.url),
+			slog.Int("attempts", attempts),
+			sl.Err(err),
+		)
+		if err := d.repo.failed(ctx, t.ID, status, err.Error(), next); err != nil {
+			return 0, err
+		}
+	}
+	return len(targets), nil
+}
+
+func (d *Dispatcher) send(ctx context.Context, t target) (int, error) {
+	timestamp := d.repo.now().Unix()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.url, bytes.NewReader(t.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "quotes-mini-service-webhooks")
+	req.Header.Set(EventHeader, t.Event)
+	req.Header.Set(DeliveryHeader, t.EventID)
+	req.Header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(SignatureHeader, Sign(t.secret, timestamp, t.Payload))
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	delay := d.opts.Backoff
+	for i := 1; i < attempts && delay < d.opts.MaxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, d.opts.MaxBackoff)
+}
@@ -0,0 +1,216 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"quotes-mini-service/internal/storage"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func setupTestDB(t *testing.T) *storage.Db {
+	db, err := storage.NewStorage(":memory:")
+	if err != nil {
+		t.Fatalf("failed to create test database: %v", err)
+	}
+	return db
+}
+
+type receiver struct {
+	mu       sync.Mutex
+	status   int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (rc *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.requests = append(rc.requests, r)
+	rc.bodies = append(rc.bodies, body)
+	w.WriteHeader(rc.status)
+}
+
+func enqueue(t *testing.T, db *storage.Db, event string, data any) {
+	t.Helper()
+	if err := Enqueue(context.Background(), db, event, data); err != nil {
+		t.Fatalf("failed to enqueue: %v", err)
+	}
+}
+
+func newTestDispatcher(repo *WebhooksRepository) *Dispatcher {
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	return NewDispatcher(log, repo, DispatcherOptions{
+		Timeout:     time.Second,
+		MaxAttempts: 3,
+		Backoff:     time.Minute,
+		MaxBackoff:  time.Hour,
+	})
+}
+
+func TestDispatcher_Delivers(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	rc := &receiver{status: http.StatusNoContent}
+	srv := httptest.NewServer(rc)
+	defer srv.Close()
+
+	repo := NewWebhooksRepository(db)
+	ctx := context.Background()
+	hook, err := repo.Create(ctx, srv.URL, []string{EventQuoteCreated}, true)
+	if err != nil {
+		t.Fatalf("failed to create webhook: %v", err)
+	}
+	if _, err = repo.Create(ctx, srv.URL, []string{EventQuoteCreated}, false); err != nil {
+		t.Fatalf("failed to create webhook: %v", err)
+	}
+	enqueue(t, db, EventQuoteCreated, map[string]int{"id": 1})
+	enqueue(t, db, EventQuoteDeleted, map[string]int{"id": 1})
+
+	n, err := newTestDispatcher(repo).DispatchOnce(ctx)
+	if err != nil || n != 1 {
+		t.Fatalf("expected 1 delivery, got %d (%v)", n, err)
+	}
+	r, body := rc.requests[0], rc.bodies[0]
+	ts, _ := strconv.ParseInt(r.Header.Get(TimestampHeader), 10, 64)
+	if got := r.Header.Get(SignatureHeader); got != Sign(hook.Secret, ts, body) {
+		t.Errorf("invalid signature %q", got)
+	}
+	if r.Header.Get(EventHeader) != EventQuoteCreated {
+		t.Errorf("unexpected event header %q", r.Header.Get(EventHeader))
+	}
+	var payload Payload
+	if err := json.Unmarshal(body, &payload); err != nil || payload.Event != EventQuoteCreated || payload.ID != r.Header.Get(DeliveryHeader) {
+		t.Errorf("unexpected payload %s (%v)", body, err)
+	}
+
+	if n, _ = newTestDispatcher(repo).DispatchOnce(ctx); n != 0 {
+		t.Errorf("expected delivered entry not to be sent again, sent %d", n)
+	}
+}
+
+func TestDispatcher_RetriesAndDeadLetters(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	rc := &receiver{status: http.StatusInternalServerError}
+	srv := httptest.NewServer(rc)
+	defer srv.Close()
+
+	repo := NewWebhooksRepository(db)
+	now := time.Now()
+	repo.now = func() time.Time { return now }
+	ctx := context.Background()
+	if _, err := repo.Create(ctx, srv.URL, []string{EventQuoteDeleted}, true); err != nil {
+		t.Fatalf("failed to create webhook: %v", err)
+	}
+	enqueue(t, db, EventQuoteDeleted, map[string]int{"id": 1})
+	now = time.Now()
+	d := newTestDispatcher(repo)
+
+	// Attempts are due after 0, 1 and 2 minutes of backoff; the third
+	// failure moves the delivery to the dead letters.
+	for i, wait := range []time.Duration{0, time.Minute, 2 * time.Minute} {
+		now = now.Add(wait - time.Second)
+		if n, _ := d.DispatchOnce(ctx); n != 0 {
+			t.Fatalf("attempt %d: sent before backoff elapsed", i+1)
+		}
+		now = now.Add(time.Second)
+		if n, err := d.DispatchOnce(ctx); n != 1 || err != nil {
+			t.Fatalf("attempt %d: expected a delivery, got %d (%v)", i+1, n, err)
+		}
+	}
+	dead, err := repo.DeadLetters(ctx)
+	if err != nil || len(dead) != 1 {
+		t.Fatalf("expected 1 dead letter, got %d (%v)", len(dead), err)
+	}
+	if dead[0].Attempts != 3 || dead[0].LastStatus == nil || *dead[0].LastStatus != http.StatusInternalServerError {
+		t.Errorf("unexpected dead letter %+v", dead[0])
+	}
+
+	rc.status = http.StatusOK
+	if err = repo.Retry(ctx, dead[0].ID); err != nil {
+		t.Fatalf("failed to retry: %v", err)
+	}
+	if err = repo.Retry(ctx, dead[0].ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for a delivery that is not dead, got %v", err)
+	}
+	if n, _ := d.DispatchOnce(ctx); n != 1 {
+		t.Fatal("expected retried delivery to be sent")
+	}
+	if dead, _ = repo.DeadLetters(ctx); len(dead) != 0 {
+		t.Errorf("expected no dead letters, got %d", len(dead))
+	}
+	var retries int
+	db.QueryRow("SELECT COUNT(*) FROM audit_log WHERE entity = 'webhook_delivery' AND action = 'update'").Scan(&retries)
+	if retries != 1 {
+		t.Errorf("expected one audited retry, got %d", retries)
+	}
+}
+
+func TestWebhooksRepository_CRUD(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	repo := NewWebhooksRepository(db)
+	ctx := context.Background()
+
+	hook, err := repo.Create(ctx, "https://example.com/hook", []string{EventQuoteCreated, EventQuoteDeleted}, true)
+	if err != nil {
+		t.Fatalf("failed to create: %v", err)
+	}
+	if hook.Secret == "" || len(hook.Events) != 2 || !hook.Active {
+		t.Errorf("unexpected webhook %+v", hook)
+	}
+	if _, err = repo.Create(ctx, "https://example.com/hook", []string{"quote.exploded"}, true); !errors.Is(err, ErrUnknownEvent) {
+		t.Errorf("expected ErrUnknownEvent, got %v", err)
+	}
+	updated, err := repo.Update(ctx, hook.ID, "https://example.com/other", []string{EventQuoteDeleted}, false)
+	if err != nil || updated.URL != "https://example.com/other" || updated.Active || updated.Secret != "" {
+		t.Errorf("unexpected update %+v (%v)", updated, err)
+	}
+	enqueue(t, db, EventQuoteDeleted, nil)
+	if err = repo.Delete(ctx, hook.ID); err != nil {
+		t.Fatalf("failed to delete: %v", err)
+	}
+	if _, err = repo.Get(ctx, hook.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+	var left int
+	db.QueryRow("SELECT COUNT(*) FROM webhook_outbox").Scan(&left)
+	if left != 0 {
+		t.Errorf("expected outbox entries to be deleted, %d left", left)
+	}
+
+	rows, err := db.Query("SELECT action, entity_id, COALESCE(before, '') || COALESCE(after, '') FROM audit_log WHERE entity = 'webhook' ORDER BY id")
+	if err != nil {
+		t.Fatalf("failed to query audit log: %v", err)
+	}
+	defer rows.Close()
+	var got []string
+	for rows.Next() {
+		var action, data string
+		var id int
+		if err := rows.Scan(&action, &id, &data); err != nil {
+			t.Fatalf("failed to scan: %v", err)
+		}
+		if strings.Contains(data, hook.Secret) {
+			t.Errorf("expected the secret to stay out of the audit log, got %s", data)
+		}
+		got = append(got, fmt.Sprintf("%s %d", action, id))
+	}
+	want := []string{fmt.Sprintf("create %d", hook.ID), fmt.Sprintf("update %d", hook.ID), fmt.Sprintf("delete %d", hook.ID)}
+	if !slices.Equal(got, want) {
+		t.Errorf("expected audit entries %v, got %v", want, got)
+	}
+}
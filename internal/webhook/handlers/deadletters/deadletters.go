@@ -0,0 +1,68 @@
+package deadletters
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"quotes-mini-service/internal/webhook"
+	"quotes-mini-service/pkg/res"
+	"quotes-mini-service/pkg/sl"
+	"quotes-mini-service/pkg/tracing"
+	"strconv"
+)
+
+type DeadLetterStore interface {
+	DeadLetters(ctx context.Context) ([]webhook.Delivery, error)
+	Retry(ctx context.Context, id int64) error
+}
+
+type ListResponse struct {
+	Deliveries []webhook.Delivery `json:"deliveries"`
+	Count      int                `json:"count" example:"1"`
+}
+
+func List(log *slog.Logger, store DeadLetterStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.deadletters.List"
+		log := log.With(
+			slog.String("op", op),
+		)
+		ctx, span := tracing.Start(r.Context(), op)
+		defer span.End()
+		deliveries, err := store.DeadLetters(ctx)
+		if err != nil {
+			log.Error("internal server error", sl.Err(err))
+			res.Json(w, res.Error("internal server error"), http.StatusInternalServerError)
+			return
+		}
+		res.Json(w, ListResponse{Deliveries: deliveries, Count: len(deliveries)}, http.StatusOK)
+	}
+}
+
+func Retry(log *slog.Logger, store DeadLetterStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.deadletters.Retry"
+		log := log.With(
+			slog.String("op", op),
+		)
+		ctx, span := tracing.Start(r.Context(), op)
+		defer span.End()
+		id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+		if err != nil {
+			res.Json(w, res.Error("invalid argument"), http.StatusBadRequest)
+			return
+		}
+		if err = store.Retry(ctx, id); err != nil {
+			if errors.Is(err, webhook.ErrNotFound) {
+				res.Json(w, res.Error("dead delivery with this id not found"), http.StatusNotFound)
+				return
+			}
+			log.Error("failed to retry delivery", sl.Err(err))
+			res.Json(w, res.Error("internal server error"), http.StatusInternalServerError)
+			return
+		}
+		log.Info("delivery requeued", slog.Int64("id", id))
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
@@ -0,0 +1,195 @@
+package subscriptions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"quotes-mini-service/internal/webhook"
+	"quotes-mini-service/pkg/decode"
+	"quotes-mini-service/pkg/res"
+	"quotes-mini-service/pkg/sl"
+	"quotes-mini-service/pkg/tracing"
+	"slices"
+	"strconv"
+)
+
+type Request struct {
+	URL    string   `json:"url" example:"https://hooks.example.com/quotes"`
+	Events []string `json:"events" example:"quote.created"`
+	// Active defaults to true.
+	Active *bool `json:"active,omitempty" example:"true"`
+}
+
+func (r Request) active() bool {
+	return r.Active == nil || *r.Active
+}
+
+type ListResponse struct {
+	Webhooks []webhook.Webhook `json:"webhooks"`
+	Count    int               `json:"count" example:"1"`
+}
+
+type WebhookStore interface {
+	Create(ctx context.Context, url string, events []string, active bool) (*webhook.Webhook, error)
+	List(ctx context.Context) ([]webhook.Webhook, error)
+	Get(ctx context.Context, id int) (*webhook.Webhook, error)
+	Update(ctx context.Context, id int, url string, events []string, active bool) (*webhook.Webhook, error)
+	Delete(ctx context.Context, id int) error
+}
+
+func Create(log *slog.Logger, store WebhookStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.webhook.Create"
+		log := log.With(
+			slog.String("op", op),
+		)
+		ctx, span := tracing.Start(r.Context(), op)
+		defer span.End()
+		var req Request
+		if err := decode.JSON(r.Body, &req); err != nil {
+			log.Error("failed to decode request body", sl.Err(err))
+			res.Json(w, res.Error(decode.Message(err)), decode.Status(err))
+			return
+		}
+		if err := validate(req); err != nil {
+			res.Json(w, res.Error(err.Error()), http.StatusBadRequest)
+			return
+		}
+		hook, err := store.Create(ctx, req.URL, req.Events, req.active())
+		if err != nil {
+			log.Error("failed to create webhook", sl.Err(err))
+			res.Json(w, res.Error("failed to create webhook"), http.StatusInternalServerError)
+			return
+		}
+		log.Info("webhook created", slog.Int("id", hook.ID), slog.String("url", hook.URL))
+		res.Json(w, hook, http.StatusCreated)
+	}
+}
+
+func List(log *slog.Logger, store WebhookStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.webhook.List"
+		log := log.With(
+			slog.String("op", op),
+		)
+		ctx, span := tracing.Start(r.Context(), op)
+		defer span.End()
+		hooks, err := store.List(ctx)
+		if err != nil {
+			log.Error("internal server error", sl.Err(err))
+			res.Json(w, res.Error("internal server error"), http.StatusInternalServerError)
+			return
+		}
+		res.Json(w, ListResponse{Webhooks: hooks, Count: len(hooks)}, http.StatusOK)
+	}
+}
+
+func Get(log *slog.Logger, store WebhookStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.webhook.Get"
+		log := log.With(
+			slog.String("op", op),
+		)
+		ctx, span := tracing.Start(r.Context(), op)
+		defer span.End()
+		id, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			res.Json(w, res.Error("invalid argument"), http.StatusBadRequest)
+			return
+		}
+		hook, err := store.Get(ctx, id)
+		if !handleError(log, w, err) {
+			return
+		}
+		res.Json(w, hook, http.StatusOK)
+	}
+}
+
+func Update(log *slog.Logger, store WebhookStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.webhook.Update"
+		log := log.With(
+			slog.String("op", op),
+		)
+		ctx, span := tracing.Start(r.Context(), op)
+		defer span.End()
+		id, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			res.Json(w, res.Error("invalid argument"), http.StatusBadRequest)
+			return
+		}
+		var req Request
+		if err = decode.JSON(r.Body, &req); err != nil {
+			log.Error("failed to decode request body", sl.Err(err))
+			res.Json(w, res.Error(decode.Message(err)), decode.Status(err))
+			return
+		}
+		if err = validate(req); err != nil {
+			res.Json(w, res.Error(err.Error()), http.StatusBadRequest)
+			return
+		}
+		hook, err := store.Update(ctx, id, req.URL, req.Events, req.active())
+		if !handleError(log, w, err) {
+			return
+		}
+		log.Info("webhook updated", slog.Int("id", id))
+		res.Json(w, hook, http.StatusOK)
+	}
+}
+
+func Delete(log *slog.Logger, store WebhookStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.webhook.Delete"
+		log := log.With(
+			slog.String("op", op),
+		)
+		ctx, span := tracing.Start(r.Context(), op)
+		defer span.End()
+		id, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			res.Json(w, res.Error("invalid argument"), http.StatusBadRequest)
+			return
+		}
+		if !handleError(log, w, store.Delete(ctx, id)) {
+			return
+		}
+		log.Info("webhook deleted", slog.Int("id", id))
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// handleError writes the error response for err and reports whether the
+// handler may continue.
+func handleError(log *slog.Logger, w http.ResponseWriter, err error) bool {
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, webhook.ErrNotFound):
+		res.Json(w, res.Error("webhook with this id not found"), http.StatusNotFound)
+	default:
+		log.Error("internal server error", sl.Err(err))
+		res.Json(w, res.Error("internal server error"), http.StatusInternalServerError)
+	}
+	return false
+}
+
+func validate(req Request) error {
+	u, err := url.Parse(req.URL)
+	switch {
+	case req.URL == "":
+		return fmt.Errorf("url is required")
+	case err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "":
+		return fmt.Errorf("url must be an absolute http or https URL")
+	case len(req.Events) == 0:
+		return fmt.Errorf("events are required")
+	}
+	for _, event := range req.Events {
+		if !slices.Contains(webhook.Events, event) {
+			return fmt.Errorf("unknown event %q", event)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package subscriptions
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"quotes-mini-service/internal/webhook"
+	"strings"
+	"testing"
+)
+
+type mockWebhookStore struct {
+	hooks map[int]webhook.Webhook
+}
+
+func (m *mockWebhookStore) Create(_ context.Context, url string, events []string, active bool) (*webhook.Webhook, error) {
+	h := webhook.Webhook{ID: len(m.hooks) + 1, URL: url, Events: events, Active: active, Secret: "whsec_test"}
+	m.hooks[h.ID] = h
+	return &h, nil
+}
+
+func (m *mockWebhookStore) List(context.Context) ([]webhook.Webhook, error) {
+	var hooks []webhook.Webhook
+	for _, h := range m.hooks {
+		hooks = append(hooks, h)
+	}
+	return hooks, nil
+}
+
+func (m *mockWebhookStore) Get(_ context.Context, id int) (*webhook.Webhook, error) {
+	h, ok := m.hooks[id]
+	if !ok {
+		return nil, webhook.ErrNotFound
+	}
+	return &h, nil
+}
+
+func (m *mockWebhookStore) Update(_ context.Context, id int, url string, events []string, active bool) (*webhook.Webhook, error) {
+	if _, ok := m.hooks[id]; !ok {
+		return nil, webhook.ErrNotFound
+	}
+	h := webhook.Webhook{ID: id, URL: url, Events: events, Active: active}
+	m.hooks[id] = h
+	return &h, nil
+}
+
+func (m *mockWebhookStore) Delete(_ context.Context, id int) error {
+	if _, ok := m.hooks[id]; !ok {
+		return webhook.ErrNotFound
+	}
+	delete(m.hooks, id)
+	return nil
+}
+
+func TestCreate(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	store := &mockWebhookStore{hooks: map[int]webhook.Webhook{}}
+	handler := Create(log, store)
+
+	tests := []struct {
+		body   string
+		status int
+	}{
+		{`{"url":"https://example.com/hook","events":["quote.created"]}`, http.StatusCreated},
+		{`{"url":"https://example.com/hook","events":["quote.created"],"active":false}`, http.StatusCreated},
+		{`{"url":"ftp://example.com","events":["quote.created"]}`, http.StatusBadRequest},
+		{`{"url":"/relative","events":["quote.created"]}`, http.StatusBadRequest},
+		{`{"url":"https://example.com/hook","events":[]}`, http.StatusBadRequest},
+		{`{"url":"https://example.com/hook","events":["quote.exploded"]}`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		handler(w, httptest.NewRequest(http.MethodPost, "/admin/webhooks", strings.NewReader(tt.body)))
+		if w.Code != tt.status {
+			t.Errorf("%s: expected status %d, got %d", tt.body, tt.status, w.Code)
+		}
+	}
+	if store.hooks[2].Active {
+		t.Error("expected second webhook to be inactive")
+	}
+	var created webhook.Webhook
+	w := httptest.NewRecorder()
+	handler(w, httptest.NewRequest(http.MethodPost, "/admin/webhooks", strings.NewReader(tests[0].body)))
+	if err := json.NewDecoder(w.Body).Decode(&created); err != nil || created.Secret == "" {
+		t.Errorf("expected secret in create response, got %+v (%v)", created, err)
+	}
+}
+
+func TestGetUpdateDelete_NotFound(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	store := &mockWebhookStore{hooks: map[int]webhook.Webhook{}}
+	body := `{"url":"https://example.com/hook","events":["quote.deleted"]}`
+
+	for name, handler := range map[string]http.HandlerFunc{
+		"get":    Get(log, store),
+		"update": Update(log, store),
+		"delete": Delete(log, store),
+	} {
+		r := httptest.NewRequest(http.MethodPut, "/admin/webhooks/7", strings.NewReader(body))
+		r.SetPathValue("id", "7")
+		w := httptest.NewRecorder()
+		handler(w, r)
+		if w.Code != http.StatusNotFound {
+			t.Errorf("%s: expected status %d, got %d", name, http.StatusNotFound, w.Code)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package webhook
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	EventQuoteCreated = "quote.created"
+	EventQuoteUpdated = "quote.updated"
+	EventQuoteDeleted = "quote.deleted"
+)
+
+var Events = []string{EventQuoteCreated, EventQuoteUpdated, EventQuoteDeleted}
+
+const (
+	StatusPending   = "pending"
+	StatusDelivered = "delivered"
+	StatusDead      = "dead"
+)
+
+type Webhook struct {
+	ID     int      `json:"id" example:"1"`
+	URL    string   `json:"url" example:"https://hooks.example.com/quotes"`
+	Events []string `json:"events" example:"quote.created"`
+	Active bool     `json:"active" example:"true"`
+	// Secret is only returned when the subscription is created.
+	Secret    string    `json:"secret,omitempty" example:"whsec_5e6f..."`
+	CreatedAt time.Time `json:"created_at" example:"2025-05-29T00:00:00Z"`
+}
+
+// Delivery is one event queued for one subscription.
+type Delivery struct {
+	ID            int64           `json:"id" example:"1"`
+	WebhookID     int             `json:"webhook_id" example:"1"`
+	EventID       string          `json:"event_id" example:"9f86d081884c7d65"`
+	Event         string          `json:"event" example:"quote.created"`
+	Payload       json.RawMessage `json:"payload"`
+	Status        string          `json:"status" example:"dead"`
+	Attempts      int             `json:"attempts" example:"8"`
+	NextAttemptAt time.Time       `json:"next_attempt_at" example:"2025-05-29T00:00:00Z"`
+	LastStatus    *int            `json:"last_status,omitempty" example:"500"`
+	LastError     *string         `json:"last_error,omitempty" example:"unexpected status 500"`
+	CreatedAt     time.Time       `json:"created_at" example:"2025-05-29T00:00:00Z"`
+	DeliveredAt   *time.Time      `json:"delivered_at,omitempty" example:"2025-05-29T00:00:00Z"`
+}
+
+// Payload is the JSON body posted to subscribers.
+type Payload struct {
+	ID        string    `json:"id"`
+	Event     string    `json:"event"`
+	CreatedAt time.Time `json:"created_at"`
+	Data      any       `json:"data"`
+}
+
+func (w *Webhook) fields() []any {
+	return []any{&w.ID, &w.URL, eventsScanner{&w.Events}, &w.Active, &w.CreatedAt}
+}
+
+func (d *Delivery) fields() []any {
+	return []any{&d.ID, &d.WebhookID, &d.EventID, &d.Event, rawJSON{&d.Payload}, &d.Status, &d.Attempts,
+		&d.NextAttemptAt, &d.LastStatus, &d.LastError, &d.CreatedAt, &d.DeliveredAt}
+}
@@ -0,0 +1,49 @@
+package webhook
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Execer is satisfied by *sql.Tx, so that events are queued in the same
+// transaction as the change they announce.
+type Execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// Enqueue queues event for every active subscription to it. Nothing is sent
+// here; the Dispatcher delivers committed entries.
+func Enqueue(ctx context.Context, tx Execer, event string, data any) error {
+	const op = "webhook.Enqueue"
+	id, err := newID(8)
+	if err != nil {
+		return fmt.Errorf("%s: generate event id: %w", op, err)
+	}
+	now := time.Now().UTC()
+	payload, err := json.Marshal(Payload{ID: id, Event: event, CreatedAt: now, Data: data})
+	if err != nil {
+		return fmt.Errorf("%s: encode payload: %w", op, err)
+	}
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO webhook_outbox(webhook_id, event_id, event, payload, next_attempt_at)
+		SELECT id, ?, ?, ?, ? FROM webhooks
+		WHERE active AND ' ' || events || ' ' LIKE '% ' || ? || ' %'`,
+		id, event, string(payload), now, event)
+	if err != nil {
+		return fmt.Errorf("%s: insert outbox entries: %w", op, err)
+	}
+	return nil
+}
+
+func newID(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,382 @@
+package webhook
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"quotes-mini-service/internal/audit"
+	"quotes-mini-service/internal/storage"
+	"quotes-mini-service/pkg/tracing"
+	"slices"
+	"strings"
+	"time"
+)
+
+var (
+	ErrNotFound     = errors.New("webhook not found")
+	ErrUnknownEvent = errors.New("unknown event")
+)
+
+const deliveryColumns = `id, webhook_id, event_id, event, payload, status, attempts, next_attempt_at,
+	last_status, last_error, created_at, delivered_at`
+
+// Entities of the subscriptions and deliveries in the audit log.
+const (
+	auditEntity         = "webhook"
+	auditDeliveryEntity = "webhook_delivery"
+)
+
+type WebhooksRepository struct {
+	Database *storage.Db
+	now      func() time.Time
+}
+
+func NewWebhooksRepository(database *storage.Db) *WebhooksRepository {
+	return &WebhooksRepository{
+		Database: database,
+		now:      time.Now,
+	}
+}
+
+// Create stores a subscription with a freshly generated signing secret,
+// which is returned only here.
+func (repo *WebhooksRepository) Create(ctx context.Context, url string, events []string, active bool) (_ *Webhook, err error) {
+	const op = "webhook.repository.Create"
+	ctx, span := tracing.StartQuery(ctx, op, "INSERT")
+	defer tracing.EndQuery(span, &err)
+	if err := checkEvents(events); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	secret, err := newID(24)
+	if err != nil {
+		return nil, fmt.Errorf("%s: generate secret: %w", op, err)
+	}
+	tx, err := repo.Database.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s: begin transaction: %w", op, err)
+	}
+	defer tx.Rollback()
+	var w Webhook
+	err = tx.QueryRowContext(ctx,
+		`INSERT INTO webhooks(url, secret, events, active) VALUES(?, ?, ?, ?)
+		RETURNING id, url, events, active, created_at`,
+		url, "whsec_"+secret, strings.Join(events, " "), active).
+		Scan(w.fields()...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: insert webhook: %w", op, err)
+	}
+	// The secret stays out of the audit log.
+	if err = audit.Record(ctx, tx, audit.ActionCreate, auditEntity, w.ID, nil, w); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("%s: commit transaction: %w", op, err)
+	}
+	w.Secret = "whsec_" + secret
+	return &w, nil
+}
+
+func (repo *WebhooksRepository) List(ctx context.Context) (_ []Webhook, err error) {
+	const op = "webhook.repository.List"
+	ctx, span := tracing.StartQuery(ctx, op, "SELECT")
+	defer tracing.EndQuery(span, &err)
+	rows, err := repo.Database.QueryContext(ctx,
+		"SELECT id, url, events, active, created_at FROM webhooks ORDER BY id")
+	if err != nil {
+		return nil, fmt.Errorf("%s: query execution: %w", op, err)
+	}
+	defer rows.Close()
+	webhooks := []Webhook{}
+	for rows.Next() {
+		var w Webhook
+		if err := rows.Scan(w.fields()...); err != nil {
+			return nil, fmt.Errorf("%s: scan row: %w", op, err)
+		}
+		webhooks = append(webhooks, w)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: iterate rows: %w", op, err)
+	}
+	return webhooks, nil
+}
+
+func (repo *WebhooksRepository) Get(ctx context.Context, id int) (_ *Webhook, err error) {
+	const op = "webhook.repository.Get"
+	ctx, span := tracing.StartQuery(ctx, op, "SELECT")
+	defer tracing.EndQuery(span, &err)
+	w, err := getWebhook(ctx, repo.Database, id)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return w, nil
+}
+
+func (repo *WebhooksRepository) Update(ctx context.Context, id int, url string, events []string, active bool) (_ *Webhook, err error) {
+	const op = "webhook.repository.Update"
+	ctx, span := tracing.StartQuery(ctx, op, "UPDATE")
+	defer tracing.EndQuery(span, &err)
+	if err := checkEvents(events); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	tx, err := repo.Database.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s: begin transaction: %w", op, err)
+	}
+	defer tx.Rollback()
+	before, err := getWebhook(ctx, tx, id)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	var w Webhook
+	err = tx.QueryRowContext(ctx,
+		`UPDATE webhooks SET url = ?, events = ?, active = ? WHERE id = ?
+		RETURNING id, url, events, active, created_at`,
+		url, strings.Join(events, " "), active, id).
+		Scan(w.fields()...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: update webhook: %w", op, err)
+	}
+	if err = audit.Record(ctx, tx, audit.ActionUpdate, auditEntity, id, before, w); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("%s: commit transaction: %w", op, err)
+	}
+	return &w, nil
+}
+
+// Delete removes the subscription together with its queued deliveries.
+func (repo *WebhooksRepository) Delete(ctx context.Context, id int) (err error) {
+	const op = "webhook.repository.Delete"
+	ctx, span := tracing.StartQuery(ctx, op, "DELETE")
+	defer tracing.EndQuery(span, &err)
+	tx, err := repo.Database.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: begin transaction: %w", op, err)
+	}
+	defer tx.Rollback()
+	before, err := getWebhook(ctx, tx, id)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if _, err = tx.ExecContext(ctx, "DELETE FROM webhooks WHERE id = ?", id); err != nil {
+		return fmt.Errorf("%s: delete webhook: %w", op, err)
+	}
+	if _, err = tx.ExecContext(ctx, "DELETE FROM webhook_outbox WHERE webhook_id = ?", id); err != nil {
+		return fmt.Errorf("%s: delete deliveries: %w", op, err)
+	}
+	if err = audit.Record(ctx, tx, audit.ActionDelete, auditEntity, id, before, nil); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("%s: commit transaction: %w", op, err)
+	}
+	return nil
+}
+
+// DeadLetters lists deliveries that ran out of attempts, newest first.
+func (repo *WebhooksRepository) DeadLetters(ctx context.Context) (_ []Delivery, err error) {
+	const op = "webhook.repository.DeadLetters"
+	ctx, span := tracing.StartQuery(ctx, op, "SELECT")
+	defer tracing.EndQuery(span, &err)
+	rows, err := repo.Database.QueryContext(ctx,
+		"SELECT "+deliveryColumns+" FROM webhook_outbox WHERE status = ? ORDER BY id DESC", StatusDead)
+	if err != nil {
+		return nil, fmt.Errorf("%s: query execution: %w", op, err)
+	}
+	defer rows.Close()
+	deliveries := []Delivery{}
+	for rows.Next() {
+		var d Delivery
+		if err := rows.Scan(d.fields()...); err != nil {
+			return nil, fmt.Errorf("%s: scan row: %w", op, err)
+		}
+		deliveries = append(deliveries, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: iterate rows: %w", op, err)
+	}
+	return deliveries, nil
+}
+
+// Retry puts a dead delivery back in the queue with a fresh set of attempts.
+func (repo *WebhooksRepository) Retry(ctx context.Context, id int64) (err error) {
+	const op = "webhook.repository.Retry"
+	ctx, span := tracing.StartQuery(ctx, op, "UPDATE")
+	defer tracing.EndQuery(span, &err)
+	tx, err := repo.Database.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: begin transaction: %w", op, err)
+	}
+	defer tx.Rollback()
+	before, err := getDelivery(ctx, tx, id)
+	if errors.Is(err, ErrNotFound) || err == nil && before.Status != StatusDead {
+		return fmt.Errorf("%s: dead delivery %d: %w", op, id, ErrNotFound)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	_, err = tx.ExecContext(ctx,
+		"UPDATE webhook_outbox SET status = ?, attempts = 0, next_attempt_at = ? WHERE id = ?",
+		StatusPending, repo.now().UTC(), id)
+	if err != nil {
+		return fmt.Errorf("%s: update delivery: %w", op, err)
+	}
+	after, err := getDelivery(ctx, tx, id)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	// Audit entity IDs are ints; delivery IDs fit.
+	if err = audit.Record(ctx, tx, audit.ActionUpdate, auditDeliveryEntity, int(id), before, after); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("%s: commit transaction: %w", op, err)
+	}
+	return nil
+}
+
+// querier is satisfied by both the database and a transaction.
+type querier interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+func getWebhook(ctx context.Context, q querier, id int) (*Webhook, error) {
+	var w Webhook
+	err := q.QueryRowContext(ctx,
+		"SELECT id, url, events, active, created_at FROM webhooks WHERE id = ?", id).
+		Scan(w.fields()...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("select webhook: %w", err)
+	}
+	return &w, nil
+}
+
+func getDelivery(ctx context.Context, q querier, id int64) (*Delivery, error) {
+	var d Delivery
+	err := q.QueryRowContext(ctx, "SELECT "+deliveryColumns+" FROM webhook_outbox WHERE id = ?", id).
+		Scan(d.fields()...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("select delivery: %w", err)
+	}
+	return &d, nil
+}
+
+// target is a due delivery together with where and how to send it.
+type target struct {
+	Delivery
+	url    string
+	secret string
+}
+
+func (repo *WebhooksRepository) due(ctx context.Context, limit int) ([]target, error) {
+	const op = "webhook.repository.due"
+	rows, err := repo.Database.QueryContext(ctx,
+		`SELECT o.id, o.webhook_id, o.event_id, o.event, o.payload, o.attempts, w.url, w.secret
+		FROM webhook_outbox o JOIN webhooks w ON w.id = o.webhook_id
+		WHERE o.status = ? AND o.next_attempt_at <= ? AND w.active
+		ORDER BY o.next_attempt_at, o.id LIMIT ?`,
+		StatusPending, repo.now().UTC(), limit)
+	if err != nil {
+		return nil, fmt.Errorf("%s: query execution: %w", op, err)
+	}
+	defer rows.Close()
+	var targets []target
+	for rows.Next() {
+		var t target
+		if err := rows.Scan(&t.ID, &t.WebhookID, &t.EventID, &t.Event, rawJSON{&t.Payload}, &t.Attempts, &t.url, &t.secret); err != nil {
+			return nil, fmt.Errorf("%s: scan row: %w", op, err)
+		}
+		targets = append(targets, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: iterate rows: %w", op, err)
+	}
+	return targets, nil
+}
+
+func (repo *WebhooksRepository) delivered(ctx context.Context, id int64, status int) error {
+	_, err := repo.Database.ExecContext(ctx,
+		`UPDATE webhook_outbox SET status = ?, attempts = attempts + 1, last_status = ?, last_error = NULL,
+		delivered_at = ? WHERE id = ?`,
+		StatusDelivered, status, repo.now().UTC(), id)
+	if err != nil {
+		return fmt.Errorf("webhook.repository.delivered: %w", err)
+	}
+	return nil
+}
+
+// failed records a failed attempt and schedules the next one at next, or
+// moves the delivery to the dead letters when next is zero.
+func (repo *WebhooksRepository) failed(ctx context.Context, id int64, status int, reason string, next time.Time) error {
+	newStatus, nextAttempt := StatusPending, any(next.UTC())
+	if next.IsZero() {
+		newStatus, nextAttempt = StatusDead, repo.now().UTC()
+	}
+	var lastStatus any
+	if status != 0 {
+		lastStatus = status
+	}
+	_, err := repo.Database.ExecContext(ctx,
+		`UPDATE webhook_outbox SET status = ?, attempts = attempts + 1, last_status = ?, last_error = ?,
+		next_attempt_at = ? WHERE id = ?`,
+		newStatus, lastStatus, reason, nextAttempt, id)
+	if err != nil {
+		return fmt.Errorf("webhook.repository.failed: %w", err)
+	}
+	return nil
+}
+
+func checkEvents(events []string) error {
+	for _, e := range events {
+		if !slices.Contains(Events, e) {
+			return fmt.Errorf("%w %q", ErrUnknownEvent, e)
+		}
+	}
+	return nil
+}
+
+type eventsScanner struct {
+	dst *[]string
+}
+
+func (s eventsScanner) Scan(src any) error {
+	var str string
+	switch v := src.(type) {
+	case string:
+		str = v
+	case []byte:
+		str = string(v)
+	case nil:
+	default:
+		return fmt.Errorf("unsupported events type %T", src)
+	}
+	*s.dst = strings.Fields(str)
+	return nil
+}
+
+type rawJSON struct {
+	dst *json.RawMessage
+}
+
+func (s rawJSON) Scan(src any) error {
+	switch v := src.(type) {
+	case string:
+		*s.dst = json.RawMessage(v)
+	case []byte:
+		*s.dst = append(json.RawMessage(nil), v...)
+	case nil:
+		*s.dst = nil
+	default:
+		return fmt.Errorf("unsupported payload type %T", src)
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+// Package dataloader batches and caches lookups made by concurrent
+// resolvers, so that resolving a field on every item of a list costs one
+// query instead of one per item.
+package dataloader
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// BatchFunc loads values for several keys at once. Keys missing from the
+// returned map resolve to the zero value.
+type BatchFunc[K comparable, V any] func(ctx context.Context, keys []K) (map[K]V, error)
+
+type Options struct {
+	// Wait is how long the first Load of a batch waits for more keys.
+	Wait time.Duration
+	// MaxBatch dispatches a batch early once it holds this many keys.
+	MaxBatch int
+}
+
+type result[V any] struct {
+	value V
+	err   error
+	done  chan struct{}
+}
+
+type batch[K comparable, V any] struct {
+	keys    []K
+	results map[K]*result[V]
+	timer   *time.Timer
+}
+
+// Loader is meant to live for a single request: results, errors included,
+// are cached for its whole lifetime.
+type Loader[K comparable, V any] struct {
+	fn   BatchFunc[K, V]
+	opts Options
+
+	mu      sync.Mutex
+	cache   map[K]*result[V]
+	pending *batch[K, V]
+}
+
+func New[K comparable, V any](fn BatchFunc[K, V], opts Options) *Loader[K, V] {
+	return &Loader[K, V]{
+		fn:    fn,
+		opts:  opts,
+		cache: map[K]*result[V]{},
+	}
+}
+
+func (l *Loader[K, V]) Load(ctx context.Context, key K) (V, error) {
+	l.mu.Lock()
+	r, ok := l.cache[key]
+	if !ok {
+		r = &result[V]{done: make(chan struct{})}
+		l.cache[key] = r
+		b := l.pending
+		if b == nil {
+			b = &batch[K, V]{results: map[K]*result[V]{}}
+			l.pending = b
+			b.timer = time.AfterFunc(l.opts.Wait, func() { l.dispatch(ctx, b) })
+		}
+		b.keys = append(b.keys, key)
+		b.results[key] = r
+		if l.opts.MaxBatch > 0 && len(b.keys) >= l.opts.MaxBatch && b.timer.Stop() {
+			l.mu.Unlock()
+			l.dispatch(ctx, b)
+			l.mu.Lock()
+		}
+	}
+	l.mu.Unlock()
+	select {
+	case <-r.done:
+		return r.value, r.err
+	case <-ctx.Done():
+		var zero V
+		return zero, ctx.Err()
+	}
+}
+
+func (l *Loader[K, V]) dispatch(ctx context.Context, b *batch[K, V]) {
+	l.mu.Lock()
+	if l.pending == b {
+		l.pending = nil
+	}
+	l.mu.Unlock()
+	values, err := l.fn(ctx, b.keys)
+	for key, r := range b.results {
+		r.value, r.err = values[key], err
+		close(r.done)
+	}
+}
@@ -0,0 +1,115 @@
+package dataloader
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLoader_Batches(t *testing.T) {
+	var calls atomic.Int32
+	var seen []int
+	loader := New(func(_ context.Context, keys []int) (map[int]string, error) {
+		calls.Add(1)
+		seen = keys
+		values := map[int]string{}
+		for _, k := range keys {
+			if k%2 == 0 {
+				values[k] = "even"
+			}
+		}
+		return values, nil
+	}, Options{Wait: 10 * time.Millisecond})
+
+	ctx := context.Background()
+	var wg sync.WaitGroup
+	got := make([]string, 4)
+	for i := range got {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err := loader.Load(ctx, i)
+			if err != nil {
+				t.Errorf("unexpected error for key %d: %v", i, err)
+			}
+			got[i] = v
+		}()
+	}
+	wg.Wait()
+
+	if calls.Load() != 1 {
+		t.Errorf("expected 1 batch call, got %d", calls.Load())
+	}
+	if len(seen) != 4 {
+		t.Errorf("expected 4 keys in the batch, got %v", seen)
+	}
+	want := []string{"even", "", "even", ""}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("key %d: expected %q, got %q", i, want[i], got[i])
+		}
+	}
+
+	// Cached keys never reach the batch function again.
+	if v, _ := loader.Load(ctx, 2); v != "even" {
+		t.Errorf("expected cached value, got %q", v)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("expected cached load, got %d calls", calls.Load())
+	}
+}
+
+func TestLoader_MaxBatch(t *testing.T) {
+	var calls atomic.Int32
+	loader := New(func(_ context.Context, keys []int) (map[int]int, error) {
+		calls.Add(1)
+		values := map[int]int{}
+		for _, k := range keys {
+			values[k] = k * 10
+		}
+		return values, nil
+	}, Options{Wait: time.Hour, MaxBatch: 2})
+
+	ctx := context.Background()
+	var wg sync.WaitGroup
+	for i := range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err := loader.Load(ctx, i)
+			if err != nil || v != i*10 {
+				t.Errorf("key %d: expected %d, got %d (%v)", i, i*10, v, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if calls.Load() != 2 {
+		t.Errorf("expected 2 batch calls, got %d", calls.Load())
+	}
+}
+
+func TestLoader_Error(t *testing.T) {
+	errBatch := errors.New("batch failed")
+	loader := New(func(_ context.Context, keys []string) (map[string]int, error) {
+		return nil, errBatch
+	}, Options{})
+
+	if _, err := loader.Load(context.Background(), "a"); !errors.Is(err, errBatch) {
+		t.Errorf("expected batch error, got %v", err)
+	}
+}
+
+func TestLoader_ContextCanceled(t *testing.T) {
+	loader := New(func(_ context.Context, keys []int) (map[int]int, error) {
+		return nil, nil
+	}, Options{Wait: time.Hour})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := loader.Load(ctx, 1); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
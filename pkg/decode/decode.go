@@ -0,0 +1,50 @@
+package decode
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+)
+
+var ErrTrailingData = errors.New("request body must contain a single JSON object")
+
+// JSON decodes exactly one JSON value from r into v, rejecting unknown
+// fields and anything after the value.
+func JSON(r io.Reader, v any) error {
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	if err := dec.Decode(&struct{}{}); !errors.Is(err, io.EOF) {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			return err
+		}
+		return ErrTrailingData
+	}
+	return nil
+}
+
+// Status maps a decoding error to the HTTP status a handler should return.
+func Status(err error) int {
+	var maxErr *http.MaxBytesError
+	if errors.As(err, &maxErr) {
+		return http.StatusRequestEntityTooLarge
+	}
+	return http.StatusBadRequest
+}
+
+// Message is a client-safe description of a decoding error.
+func Message(err error) string {
+	var maxErr *http.MaxBytesError
+	switch {
+	case errors.As(err, &maxErr):
+		return "request body too large"
+	case errors.Is(err, ErrTrailingData):
+		return ErrTrailingData.Error()
+	default:
+		return "failed to decode request: " + err.Error()
+	}
+}
@@ -0,0 +1,53 @@
+package decode
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type payload struct {
+	Author string `json:"author"`
+}
+
+func TestJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		ok   bool
+	}{
+		{"valid", `{"author":"Confucius"}`, true},
+		{"trailing whitespace", "{\"author\":\"Confucius\"}\n", true},
+		{"unknown field", `{"author":"Confucius","extra":1}`, false},
+		{"trailing object", `{"author":"a"}{"author":"b"}`, false},
+		{"trailing garbage", `{"author":"a"} x`, false},
+		{"invalid", `{`, false},
+	}
+	for _, tt := range tests {
+		var p payload
+		err := JSON(strings.NewReader(tt.body), &p)
+		if tt.ok && err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+		}
+		if !tt.ok && err == nil {
+			t.Errorf("%s: expected error", tt.name)
+		}
+	}
+}
+
+func TestJSON_TooLarge(t *testing.T) {
+	w := httptest.NewRecorder()
+	body := http.MaxBytesReader(w, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"author":"Confucius"}`)).Body, 5)
+
+	var p payload
+	err := JSON(body, &p)
+	var maxErr *http.MaxBytesError
+	if !errors.As(err, &maxErr) {
+		t.Fatalf("expected max bytes error, got %v", err)
+	}
+	if Status(err) != http.StatusRequestEntityTooLarge {
+		t.Errorf("expected status %d, got %d", http.StatusRequestEntityTooLarge, Status(err))
+	}
+}
@@ -0,0 +1,68 @@
+package interceptor
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"quotes-mini-service/pkg/middleware"
+	"quotes-mini-service/pkg/sl"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Authenticate runs the HTTP authenticators against the call metadata, so
+// x-api-key and authorization work the same way as the headers do. Calls
+// without credentials continue anonymously; RequireScope decides per method.
+func Authenticate(log *slog.Logger, authenticators ...middleware.Authenticator) grpc.UnaryServerInterceptor {
+	log = log.With(
+		slog.String("component", "interceptor/auth"),
+	)
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		header := http.Header{}
+		for k, v := range md {
+			header[http.CanonicalHeaderKey(k)] = v
+		}
+		r := (&http.Request{Header: header}).WithContext(ctx)
+		for _, a := range authenticators {
+			p, err := a.Authenticate(r)
+			if errors.Is(err, middleware.ErrNoCredentials) {
+				continue
+			}
+			if err != nil && !errors.Is(err, middleware.ErrInvalidCredentials) {
+				log.Error("failed to authenticate", sl.Err(err), slog.String("method", info.FullMethod))
+				return nil, status.Error(codes.Internal, "internal server error")
+			}
+			if err != nil {
+				log.Warn("authentication failed", sl.Err(err), slog.String("method", info.FullMethod))
+				return nil, status.Error(codes.Unauthenticated, "invalid credentials")
+			}
+			ctx = middleware.WithPrincipal(ctx, p)
+			break
+		}
+		return handler(ctx, req)
+	}
+}
+
+// RequireScope checks the scope listed for the called method. Methods that
+// are not listed, such as health checks and reflection, are left open.
+func RequireScope(scopes map[string]string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		scope, ok := scopes[info.FullMethod]
+		if !ok {
+			return handler(ctx, req)
+		}
+		p, ok := middleware.PrincipalFromContext(ctx)
+		if !ok {
+			return nil, status.Error(codes.Unauthenticated, "authentication required")
+		}
+		if !p.HasScope(scope) {
+			return nil, status.Error(codes.PermissionDenied, "missing scope "+scope)
+		}
+		return handler(ctx, req)
+	}
+}
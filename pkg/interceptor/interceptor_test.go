@@ -0,0 +1,79 @@
+package interceptor
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"quotes-mini-service/pkg/middleware"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+var info = &grpc.UnaryServerInfo{FullMethod: "/quotes.v1.QuoteService/CreateQuote"}
+
+func TestRecover(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	_, err := Recover(log)(context.Background(), nil, info, func(ctx context.Context, req any) (any, error) {
+		panic("boom")
+	})
+	if status.Code(err) != codes.Internal {
+		t.Errorf("expected Internal, got %v", err)
+	}
+}
+
+type principalKeys map[string]*middleware.Principal
+
+func (k principalKeys) Verify(ctx context.Context, key string) (*middleware.Principal, error) {
+	if p, ok := k[key]; ok {
+		return p, nil
+	}
+	return nil, middleware.ErrInvalidCredentials
+}
+
+func TestAuthenticate(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	keys := principalKeys{"secret": {Subject: "apikey:1", Scopes: []string{middleware.ScopeRead}}}
+	auth := Authenticate(log, middleware.APIKey{Store: keys})
+	require := RequireScope(map[string]string{info.FullMethod: middleware.ScopeRead})
+	handler := func(ctx context.Context, req any) (any, error) {
+		return require(ctx, req, info, func(ctx context.Context, req any) (any, error) {
+			p, _ := middleware.PrincipalFromContext(ctx)
+			return p.Subject, nil
+		})
+	}
+
+	tests := []struct {
+		name string
+		md   metadata.MD
+		code codes.Code
+	}{
+		{name: "x-api-key", md: metadata.Pairs("x-api-key", "secret"), code: codes.OK},
+		{name: "authorization", md: metadata.Pairs("authorization", "ApiKey secret"), code: codes.OK},
+		{name: "invalid", md: metadata.Pairs("x-api-key", "wrong"), code: codes.Unauthenticated},
+		{name: "anonymous", md: metadata.MD{}, code: codes.Unauthenticated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := metadata.NewIncomingContext(context.Background(), tt.md)
+			resp, err := auth(ctx, nil, info, handler)
+			if status.Code(err) != tt.code {
+				t.Fatalf("expected %s, got %v", tt.code, err)
+			}
+			if err == nil && resp != "apikey:1" {
+				t.Errorf("expected principal apikey:1, got %v", resp)
+			}
+		})
+	}
+
+	// Methods without a scope, such as health checks, stay open.
+	health := &grpc.UnaryServerInfo{FullMethod: "/grpc.health.v1.Health/Check"}
+	if _, err := require(context.Background(), nil, health, func(ctx context.Context, req any) (any, error) {
+		return nil, nil
+	}); err != nil {
+		t.Errorf("expected unlisted method to pass, got %v", err)
+	}
+}
@@ -0,0 +1,79 @@
+package interceptor
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"quotes-mini-service/pkg/middleware"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// requestIDKey is the metadata form of middleware.RequestIDHeader.
+const requestIDKey = "x-request-id"
+
+// RequestID reuses an incoming x-request-id or generates one, stores it on
+// the context like the HTTP middleware does, and returns it in the response
+// headers.
+func RequestID(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	var id string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(requestIDKey); len(values) > 0 && validRequestID(values[0]) {
+			id = values[0]
+		}
+	}
+	if id == "" {
+		b := make([]byte, 16)
+		rand.Read(b)
+		id = hex.EncodeToString(b)
+	}
+	grpc.SetHeader(ctx, metadata.Pairs(requestIDKey, id))
+	return handler(middleware.WithRequestID(ctx, id), req)
+}
+
+func validRequestID(id string) bool {
+	if len(id) > 128 {
+		return false
+	}
+	for _, c := range id {
+		if c < 0x21 || c > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// Logger writes one line per call with the same fields as the HTTP access
+// log, the gRPC status code taking the place of the HTTP status.
+func Logger(log *slog.Logger) grpc.UnaryServerInterceptor {
+	log = log.With(
+		slog.String("component", "interceptor/logger"),
+	)
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		entry := log.With(
+			slog.String("method", info.FullMethod),
+		)
+		if p, ok := peer.FromContext(ctx); ok {
+			entry = entry.With(slog.String("remote_addr", p.Addr.String()))
+		}
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if ua := md.Get("user-agent"); len(ua) > 0 {
+				entry = entry.With(slog.String("user_agent", ua[0]))
+			}
+		}
+		if id := middleware.RequestIDFromContext(ctx); id != "" {
+			entry = entry.With(slog.String("request_id", id))
+		}
+		t1 := time.Now()
+		resp, err := handler(ctx, req)
+		entry.Info("request completed",
+			slog.String("code", status.Code(err).String()),
+			slog.String("duration", time.Since(t1).String()))
+		return resp, err
+	}
+}
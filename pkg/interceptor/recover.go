@@ -0,0 +1,41 @@
+package interceptor
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"quotes-mini-service/pkg/metrics"
+	"quotes-mini-service/pkg/middleware"
+	"runtime/debug"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var panicsTotal = metrics.NewCounter("grpc_panics_total", "Panics recovered in gRPC handlers.", "method")
+
+// Recover turns a handler panic into an Internal error and a crash report.
+// Chain it after Logger so the failed call is still logged.
+func Recover(log *slog.Logger) grpc.UnaryServerInterceptor {
+	log = log.With(
+		slog.String("component", "interceptor/recover"),
+	)
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		defer func() {
+			rec := recover()
+			if rec == nil {
+				return
+			}
+			panicsTotal.Inc(info.FullMethod)
+			log.Error("panic recovered",
+				slog.String("panic", fmt.Sprint(rec)),
+				slog.String("request_id", middleware.RequestIDFromContext(ctx)),
+				slog.String("method", info.FullMethod),
+				slog.String("stack", string(debug.Stack())),
+			)
+			err = status.Error(codes.Internal, "unexpected error while handling the request")
+		}()
+		return handler(ctx, req)
+	}
+}
@@ -0,0 +1,237 @@
+package jwt
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+type Key struct {
+	ID     string
+	Alg    string
+	public any
+	secret []byte
+}
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+	K   string `json:"k"`
+}
+
+type KeySet struct {
+	Keys map[string]*Key
+}
+
+func (s *KeySet) Key(_ context.Context, kid string) (*Key, error) {
+	if key, ok := s.Keys[kid]; ok {
+		return key, nil
+	}
+	// Tokens without a kid are accepted only when the set has a single key.
+	if kid == "" && len(s.Keys) == 1 {
+		for _, key := range s.Keys {
+			return key, nil
+		}
+	}
+	return nil, ErrUnknownKey
+}
+
+func ParseKeySet(data []byte) (*KeySet, error) {
+	const op = "jwt.ParseKeySet"
+	var doc struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	set := &KeySet{Keys: make(map[string]*Key, len(doc.Keys))}
+	for _, jwk := range doc.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := parseKey(jwk)
+		if err != nil {
+			return nil, fmt.Errorf("%s: key %q: %w", op, jwk.Kid, err)
+		}
+		set.Keys[key.ID] = key
+	}
+	return set, nil
+}
+
+func parseKey(jwk jsonWebKey) (*Key, error) {
+	key := &Key{ID: jwk.Kid, Alg: jwk.Alg}
+	switch jwk.Kty {
+	case "RSA":
+		n, err := decodeBigInt(jwk.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(jwk.E)
+		if err != nil {
+			return nil, err
+		}
+		key.public = &rsa.PublicKey{N: n, E: int(e.Int64())}
+	case "EC":
+		if jwk.Crv != "P-256" {
+			return nil, fmt.Errorf("unsupported curve %q", jwk.Crv)
+		}
+		x, err := decodeBigInt(jwk.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(jwk.Y)
+		if err != nil {
+			return nil, err
+		}
+		key.public = &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}
+	case "oct":
+		secret, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(jwk.K, "="))
+		if err != nil {
+			return nil, err
+		}
+		key.secret = secret
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", jwk.Kty)
+	}
+	return key, nil
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(data), nil
+}
+
+func LoadFile(path string) (*KeySet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("jwt.LoadFile: %w", err)
+	}
+	return ParseKeySet(data)
+}
+
+// RemoteKeySet fetches a JWKS document over HTTP and caches it. An unknown
+// kid triggers an early refresh so key rotation at the issuer is picked up,
+// but no more often than minRefresh. Concurrent refreshes share one fetch,
+// which runs without holding the lock so a slow issuer only delays the
+// requests that need the new keys.
+type RemoteKeySet struct {
+	url        string
+	client     *http.Client
+	ttl        time.Duration
+	minRefresh time.Duration
+
+	mu        sync.Mutex
+	set       *KeySet
+	fetchedAt time.Time
+	inflight  *fetch
+}
+
+type fetch struct {
+	done chan struct{}
+	set  *KeySet
+	err  error
+}
+
+func NewRemoteKeySet(url string, ttl time.Duration) *RemoteKeySet {
+	return &RemoteKeySet{
+		url:        url,
+		client:     &http.Client{Timeout: 10 * time.Second},
+		ttl:        ttl,
+		minRefresh: time.Minute,
+	}
+}
+
+func (r *RemoteKeySet) Key(ctx context.Context, kid string) (*Key, error) {
+	r.mu.Lock()
+	set, age := r.set, time.Since(r.fetchedAt)
+	r.mu.Unlock()
+	if set == nil || age > r.ttl {
+		var err error
+		if set, err = r.refresh(ctx); err != nil {
+			return nil, err
+		}
+		age = 0
+	}
+	key, err := set.Key(ctx, kid)
+	if err == ErrUnknownKey && age > r.minRefresh {
+		if set, err = r.refresh(ctx); err != nil {
+			return nil, err
+		}
+		return set.Key(ctx, kid)
+	}
+	return key, err
+}
+
+// refresh joins the fetch in flight or starts one. The fetch outlives a
+// caller that gives up, bounded by the client timeout.
+func (r *RemoteKeySet) refresh(ctx context.Context) (*KeySet, error) {
+	r.mu.Lock()
+	f := r.inflight
+	if f == nil {
+		f = &fetch{done: make(chan struct{})}
+		r.inflight = f
+		go func() {
+			f.set, f.err = r.fetch(context.WithoutCancel(ctx))
+			r.mu.Lock()
+			if f.err == nil {
+				r.set, r.fetchedAt = f.set, time.Now()
+			}
+			r.inflight = nil
+			r.mu.Unlock()
+			close(f.done)
+		}()
+	}
+	r.mu.Unlock()
+	select {
+	case <-f.done:
+		return f.set, f.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (r *RemoteKeySet) fetch(ctx context.Context) (*KeySet, error) {
+	const op = "jwt.RemoteKeySet.refresh"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: unexpected status %d", op, resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	set, err := ParseKeySet(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return set, nil
+}
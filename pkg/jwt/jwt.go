@@ -0,0 +1,193 @@
+package jwt
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+	"time"
+)
+
+var (
+	ErrMalformed        = errors.New("malformed token")
+	ErrUnsupportedAlg   = errors.New("unsupported algorithm")
+	ErrUnknownKey       = errors.New("unknown signing key")
+	ErrInvalidSignature = errors.New("invalid signature")
+	ErrExpired          = errors.New("token expired")
+	ErrMissingExpiry    = errors.New("token has no expiry")
+	ErrNotYetValid      = errors.New("token not yet valid")
+	ErrInvalidIssuer    = errors.New("invalid issuer")
+	ErrInvalidAudience  = errors.New("invalid audience")
+)
+
+type Header struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	Typ string `json:"typ"`
+}
+
+// Audience accepts both the single string and the array form of "aud".
+type Audience []string
+
+func (a *Audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = Audience{single}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return err
+	}
+	*a = many
+	return nil
+}
+
+type Claims struct {
+	Subject     string   `json:"sub"`
+	Issuer      string   `json:"iss"`
+	Audience    Audience `json:"aud"`
+	ExpiresAt   int64    `json:"exp"`
+	NotBefore   int64    `json:"nbf"`
+	IssuedAt    int64    `json:"iat"`
+	Name        string   `json:"name"`
+	Scope       string   `json:"scope"`
+	Scp         []string `json:"scp"`
+	Permissions []string `json:"permissions"`
+}
+
+// Scopes merges the space separated "scope" claim with the "scp" and
+// "permissions" arrays used by other identity providers.
+func (c *Claims) Scopes() []string {
+	scopes := strings.Fields(c.Scope)
+	scopes = append(scopes, c.Scp...)
+	return append(scopes, c.Permissions...)
+}
+
+type KeyProvider interface {
+	Key(ctx context.Context, kid string) (*Key, error)
+}
+
+type Verifier struct {
+	Keys     KeyProvider
+	Issuer   string
+	Audience string
+	Leeway   time.Duration
+	// AllowMissingExp accepts tokens without "exp", which never expire.
+	AllowMissingExp bool
+	Now             func() time.Time
+}
+
+func (v *Verifier) Verify(ctx context.Context, token string) (*Claims, error) {
+	const op = "jwt.Verify"
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%s: %w", op, ErrMalformed)
+	}
+	var header Header
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("%s: header: %w", op, ErrMalformed)
+	}
+	key, err := v.Keys.Key(ctx, header.Kid)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%s: signature: %w", op, ErrMalformed)
+	}
+	if err := key.verify(header.Alg, []byte(parts[0]+"."+parts[1]), signature); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	var claims Claims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("%s: claims: %w", op, ErrMalformed)
+	}
+	if err := v.validate(&claims); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return &claims, nil
+}
+
+func (v *Verifier) validate(c *Claims) error {
+	now := time.Now()
+	if v.Now != nil {
+		now = v.Now()
+	}
+	if c.ExpiresAt == 0 && !v.AllowMissingExp {
+		return ErrMissingExpiry
+	}
+	if c.ExpiresAt != 0 && now.After(time.Unix(c.ExpiresAt, 0).Add(v.Leeway)) {
+		return ErrExpired
+	}
+	if c.NotBefore != 0 && now.Before(time.Unix(c.NotBefore, 0).Add(-v.Leeway)) {
+		return ErrNotYetValid
+	}
+	if v.Issuer != "" && c.Issuer != v.Issuer {
+		return ErrInvalidIssuer
+	}
+	if v.Audience != "" && !slices.Contains(c.Audience, v.Audience) {
+		return ErrInvalidAudience
+	}
+	return nil
+}
+
+// verify checks the signature and refuses algorithms that do not match the
+// key type, which rules out RS256/HS256 confusion with a public key.
+func (k *Key) verify(alg string, signed, signature []byte) error {
+	if k.Alg != "" && k.Alg != alg {
+		return ErrUnsupportedAlg
+	}
+	digest := sha256.Sum256(signed)
+	switch alg {
+	case "RS256":
+		pub, ok := k.public.(*rsa.PublicKey)
+		if !ok {
+			return ErrUnsupportedAlg
+		}
+		if err := rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest[:], signature); err != nil {
+			return ErrInvalidSignature
+		}
+		return nil
+	case "ES256":
+		pub, ok := k.public.(*ecdsa.PublicKey)
+		if !ok || len(signature) != 64 {
+			return ErrInvalidSignature
+		}
+		r := new(big.Int).SetBytes(signature[:32])
+		s := new(big.Int).SetBytes(signature[32:])
+		if !ecdsa.Verify(pub, digest[:], r, s) {
+			return ErrInvalidSignature
+		}
+		return nil
+	case "HS256":
+		if k.secret == nil {
+			return ErrUnsupportedAlg
+		}
+		mac := hmac.New(sha256.New, k.secret)
+		mac.Write(signed)
+		if !hmac.Equal(mac.Sum(nil), signature) {
+			return ErrInvalidSignature
+		}
+		return nil
+	default:
+		return ErrUnsupportedAlg
+	}
+}
+
+func decodeSegment(seg string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
@@ -0,0 +1,248 @@
+package jwt
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type testKeys struct {
+	rsa    *rsa.PrivateKey
+	ec     *ecdsa.PrivateKey
+	secret []byte
+}
+
+func newTestKeys(t *testing.T) *testKeys {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate rsa key: %v", err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate ec key: %v", err)
+	}
+	return &testKeys{rsa: rsaKey, ec: ecKey, secret: []byte("0123456789abcdef0123456789abcdef")}
+}
+
+func b64(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func (k *testKeys) jwks() []byte {
+	pad := func(i *big.Int) []byte {
+		out := make([]byte, 32)
+		return i.FillBytes(out)
+	}
+	doc := map[string]any{"keys": []map[string]string{
+		{"kty": "RSA", "kid": "rsa", "alg": "RS256", "n": b64(k.rsa.N.Bytes()), "e": b64(big.NewInt(int64(k.rsa.E)).Bytes())},
+		{"kty": "EC", "kid": "ec", "crv": "P-256", "x": b64(pad(k.ec.X)), "y": b64(pad(k.ec.Y))},
+		{"kty": "oct", "kid": "hmac", "k": b64(k.secret)},
+	}}
+	data, _ := json.Marshal(doc)
+	return data
+}
+
+func (k *testKeys) sign(t *testing.T, alg, kid string, claims map[string]any) string {
+	header, _ := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	signed := b64(header) + "." + b64(payload)
+	digest := sha256.Sum256([]byte(signed))
+	var sig []byte
+	switch alg {
+	case "RS256":
+		var err error
+		sig, err = rsa.SignPKCS1v15(rand.Reader, k.rsa, crypto.SHA256, digest[:])
+		if err != nil {
+			t.Fatalf("sign: %v", err)
+		}
+	case "ES256":
+		r, s, err := ecdsa.Sign(rand.Reader, k.ec, digest[:])
+		if err != nil {
+			t.Fatalf("sign: %v", err)
+		}
+		sig = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	case "HS256":
+		mac := hmac.New(sha256.New, k.secret)
+		mac.Write([]byte(signed))
+		sig = mac.Sum(nil)
+	}
+	return signed + "." + b64(sig)
+}
+
+func validClaims() map[string]any {
+	return map[string]any{
+		"sub":   "user-42",
+		"iss":   "gateway",
+		"aud":   []string{"quotes"},
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"scope": "quotes:read quotes:write",
+	}
+}
+
+func TestVerifier_Algorithms(t *testing.T) {
+	keys := newTestKeys(t)
+	set, err := ParseKeySet(keys.jwks())
+	if err != nil {
+		t.Fatalf("parse jwks: %v", err)
+	}
+	v := &Verifier{Keys: set, Issuer: "gateway", Audience: "quotes"}
+
+	for _, tc := range []struct{ alg, kid string }{{"RS256", "rsa"}, {"ES256", "ec"}, {"HS256", "hmac"}} {
+		t.Run(tc.alg, func(t *testing.T) {
+			claims, err := v.Verify(context.Background(), keys.sign(t, tc.alg, tc.kid, validClaims()))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if claims.Subject != "user-42" {
+				t.Errorf("expected subject user-42, got %s", claims.Subject)
+			}
+			if len(claims.Scopes()) != 2 {
+				t.Errorf("expected 2 scopes, got %v", claims.Scopes())
+			}
+		})
+	}
+}
+
+func TestVerifier_Rejects(t *testing.T) {
+	keys := newTestKeys(t)
+	set, err := ParseKeySet(keys.jwks())
+	if err != nil {
+		t.Fatalf("parse jwks: %v", err)
+	}
+	v := &Verifier{Keys: set, Issuer: "gateway", Audience: "quotes"}
+
+	expired := validClaims()
+	expired["exp"] = time.Now().Add(-time.Hour).Unix()
+	wrongAud := validClaims()
+	wrongAud["aud"] = "other"
+	wrongIss := validClaims()
+	wrongIss["iss"] = "someone"
+	noExp := validClaims()
+	delete(noExp, "exp")
+	tampered := keys.sign(t, "RS256", "rsa", validClaims())
+	tampered = tampered[:len(tampered)-4] + "AAAA"
+
+	tests := []struct {
+		name  string
+		token string
+		want  error
+	}{
+		{"expired", keys.sign(t, "RS256", "rsa", expired), ErrExpired},
+		{"audience", keys.sign(t, "ES256", "ec", wrongAud), ErrInvalidAudience},
+		{"issuer", keys.sign(t, "HS256", "hmac", wrongIss), ErrInvalidIssuer},
+		{"no expiry", keys.sign(t, "RS256", "rsa", noExp), ErrMissingExpiry},
+		{"unknown kid", keys.sign(t, "RS256", "missing", validClaims()), ErrUnknownKey},
+		{"alg confusion", keys.sign(t, "HS256", "rsa", validClaims()), ErrUnsupportedAlg},
+		{"bad signature", tampered, ErrInvalidSignature},
+		{"malformed", "not-a-jwt", ErrMalformed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := v.Verify(context.Background(), tt.token); !errors.Is(err, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestRemoteKeySet(t *testing.T) {
+	keys := newTestKeys(t)
+	var fetches int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches++
+		fmt.Fprint(w, string(keys.jwks()))
+	}))
+	defer srv.Close()
+
+	v := &Verifier{Keys: NewRemoteKeySet(srv.URL, time.Hour)}
+	for i := 0; i < 3; i++ {
+		if _, err := v.Verify(context.Background(), keys.sign(t, "ES256", "ec", validClaims())); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if fetches != 1 {
+		t.Errorf("expected jwks to be cached, fetched %d times", fetches)
+	}
+}
+
+func TestVerifier_AllowMissingExp(t *testing.T) {
+	keys := newTestKeys(t)
+	set, err := ParseKeySet(keys.jwks())
+	if err != nil {
+		t.Fatalf("parse jwks: %v", err)
+	}
+	claims := validClaims()
+	delete(claims, "exp")
+	v := &Verifier{Keys: set, AllowMissingExp: true}
+	if _, err := v.Verify(context.Background(), keys.sign(t, "RS256", "rsa", claims)); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestRemoteKeySet_SlowRefresh(t *testing.T) {
+	keys := newTestKeys(t)
+	var fetches atomic.Int32
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fetches.Add(1) > 1 {
+			<-release
+		}
+		fmt.Fprint(w, string(keys.jwks()))
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	remote := NewRemoteKeySet(srv.URL, time.Hour)
+	remote.minRefresh = 0
+	v := &Verifier{Keys: remote}
+	if _, err := v.Verify(context.Background(), keys.sign(t, "ES256", "ec", validClaims())); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Unknown kids wait for the slow refresh and share it...
+	var wg sync.WaitGroup
+	for range 3 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+			remote.Key(ctx, "missing")
+		}()
+	}
+	// ...while known kids are served from the cache.
+	time.Sleep(10 * time.Millisecond)
+	done := make(chan error, 1)
+	go func() {
+		_, err := v.Verify(context.Background(), keys.sign(t, "ES256", "ec", validClaims()))
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("verification blocked on the jwks refresh")
+	}
+	wg.Wait()
+	if n := fetches.Load(); n != 2 {
+		t.Errorf("expected concurrent refreshes to share one fetch, got %d fetches", n)
+	}
+}
@@ -0,0 +1,114 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Counter is a monotonically increasing value partitioned by label values.
+type Counter struct {
+	name   string
+	help   string
+	labels []string
+
+	mu     sync.Mutex
+	values map[string]float64
+}
+
+type Registry struct {
+	mu       sync.Mutex
+	counters []*Counter
+}
+
+var Default = &Registry{}
+
+func NewCounter(name, help string, labels ...string) *Counter {
+	return Default.NewCounter(name, help, labels...)
+}
+
+// NewCounter returns the counter registered under name, creating it on first
+// use so that packages can declare the same metric independently.
+func (reg *Registry) NewCounter(name, help string, labels ...string) *Counter {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	for _, c := range reg.counters {
+		if c.name == name {
+			return c
+		}
+	}
+	c := &Counter{name: name, help: help, labels: labels, values: map[string]float64{}}
+	reg.counters = append(reg.counters, c)
+	return c
+}
+
+func (c *Counter) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+func (c *Counter) Add(n float64, labelValues ...string) {
+	key := c.key(labelValues)
+	c.mu.Lock()
+	c.values[key] += n
+	c.mu.Unlock()
+}
+
+func (c *Counter) Value(labelValues ...string) float64 {
+	key := c.key(labelValues)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.values[key]
+}
+
+func (c *Counter) key(labelValues []string) string {
+	if len(labelValues) != len(c.labels) {
+		panic(fmt.Sprintf("metrics: %s expects %d label values, got %d", c.name, len(c.labels), len(labelValues)))
+	}
+	return strings.Join(labelValues, "\xff")
+}
+
+func (c *Counter) write(w io.Writer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n", c.name, c.help, c.name)
+	keys := make([]string, 0, len(c.values))
+	for k := range c.values {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	for _, k := range keys {
+		fmt.Fprintf(w, "%s%s %g\n", c.name, c.formatLabels(k), c.values[k])
+	}
+}
+
+func (c *Counter) formatLabels(key string) string {
+	if len(c.labels) == 0 {
+		return ""
+	}
+	values := strings.Split(key, "\xff")
+	pairs := make([]string, len(c.labels))
+	for i, l := range c.labels {
+		pairs[i] = fmt.Sprintf("%s=%q", l, values[i])
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// Write renders every metric in the Prometheus text exposition format.
+func (reg *Registry) Write(w io.Writer) {
+	reg.mu.Lock()
+	counters := slices.Clone(reg.counters)
+	reg.mu.Unlock()
+	for _, c := range counters {
+		c.write(w)
+	}
+}
+
+func Handler(reg *Registry) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		reg.Write(w)
+	}
+}
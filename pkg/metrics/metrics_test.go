@@ -0,0 +1,36 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCounter(t *testing.T) {
+	reg := &Registry{}
+	c := reg.NewCounter("cache_requests_total", "Cache lookups.", "cache", "result")
+	c.Inc("quotes", "hit")
+	c.Inc("quotes", "hit")
+	c.Inc("quotes", "miss")
+
+	if reg.NewCounter("cache_requests_total", "Cache lookups.", "cache", "result") != c {
+		t.Error("expected the registered counter to be reused")
+	}
+	if c.Value("quotes", "hit") != 2 {
+		t.Errorf("expected 2 hits, got %v", c.Value("quotes", "hit"))
+	}
+
+	w := httptest.NewRecorder()
+	Handler(reg)(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := w.Body.String()
+	for _, want := range []string{
+		"# TYPE cache_requests_total counter",
+		`cache_requests_total{cache="quotes",result="hit"} 2`,
+		`cache_requests_total{cache="quotes",result="miss"} 1`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected %q in output:\n%s", want, body)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"quotes-mini-service/pkg/res"
+	"quotes-mini-service/pkg/sl"
+	"slices"
+	"strings"
+)
+
+const (
+	ScopeRead   = "quotes:read"
+	ScopeWrite  = "quotes:write"
+	ScopeDelete = "quotes:delete"
+	ScopeAdmin  = "admin"
+)
+
+var Scopes = []string{ScopeRead, ScopeWrite, ScopeDelete, ScopeAdmin}
+
+var (
+	// ErrNoCredentials is returned by an Authenticator when the request does
+	// not carry the kind of credentials it understands.
+	ErrNoCredentials      = errors.New("no credentials")
+	ErrInvalidCredentials = errors.New("invalid credentials")
+)
+
+const authChallenge = `Bearer realm="quotes", ApiKey realm="quotes"`
+
+type Principal struct {
+	Subject string
+	Name    string
+	Method  string
+	Scopes  []string
+}
+
+func (p *Principal) HasScope(scope string) bool {
+	return slices.Contains(p.Scopes, scope)
+}
+
+type Authenticator interface {
+	Authenticate(r *http.Request) (*Principal, error)
+}
+
+type KeyVerifier interface {
+	Verify(ctx context.Context, key string) (*Principal, error)
+}
+
+// APIKey reads a key from the X-API-Key header or an "Authorization: ApiKey"
+// header and checks it against the key store.
+type APIKey struct {
+	Store KeyVerifier
+}
+
+func (a APIKey) Authenticate(r *http.Request) (*Principal, error) {
+	key := r.Header.Get("X-API-Key")
+	if key == "" {
+		scheme, value, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+		if ok && strings.EqualFold(scheme, "ApiKey") {
+			key = strings.TrimSpace(value)
+		}
+	}
+	if key == "" {
+		return nil, ErrNoCredentials
+	}
+	return a.Store.Verify(r.Context(), key)
+}
+
+type principalKey struct{}
+
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(*Principal)
+	return p, ok && p != nil
+}
+
+// Authenticate tries each authenticator in turn and stores the first
+// principal on the request context. Requests without credentials pass
+// through anonymously so that RequireScope can decide per route; requests
+// with credentials that fail verification are rejected outright.
+func Authenticate(log *slog.Logger, authenticators ...Authenticator) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		log := log.With(
+			slog.String("component", "middleware/auth"),
+		)
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			for _, a := range authenticators {
+				p, err := a.Authenticate(r)
+				if errors.Is(err, ErrNoCredentials) {
+					continue
+				}
+				if err != nil && !errors.Is(err, ErrInvalidCredentials) {
+					log.Error("failed to authenticate", sl.Err(err), slog.String("path", r.URL.Path))
+					res.Json(w, res.Error("internal server error"), http.StatusInternalServerError)
+					return
+				}
+				if err != nil {
+					log.Warn("authentication failed", sl.Err(err), slog.String("path", r.URL.Path))
+					w.Header().Set("WWW-Authenticate", authChallenge)
+					res.Json(w, res.Error("invalid credentials"), http.StatusUnauthorized)
+					return
+				}
+				r = r.WithContext(WithPrincipal(r.Context(), p))
+				break
+			}
+			next.ServeHTTP(w, r)
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
+func RequireScope(scope string) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			p, ok := PrincipalFromContext(r.Context())
+			if !ok {
+				w.Header().Set("WWW-Authenticate", authChallenge)
+				res.Json(w, res.Error("authentication required"), http.StatusUnauthorized)
+				return
+			}
+			if !p.HasScope(scope) {
+				res.Json(w, res.Error("missing scope "+scope), http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		}
+		return http.HandlerFunc(fn)
+	}
+}
@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+type mockKeyVerifier struct {
+	keys map[string]*Principal
+}
+
+func (m *mockKeyVerifier) Verify(_ context.Context, key string) (*Principal, error) {
+	if key == "broken" {
+		return nil, errors.New("database is locked")
+	}
+	p, ok := m.keys[key]
+	if !ok {
+		return nil, ErrInvalidCredentials
+	}
+	return p, nil
+}
+
+func newAuthHandler(scope string) http.Handler {
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	verifier := &mockKeyVerifier{keys: map[string]*Principal{
+		"reader": {Subject: "apikey:1", Scopes: []string{ScopeRead}},
+		"admin":  {Subject: "apikey:2", Scopes: []string{ScopeRead, ScopeDelete}},
+	}}
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	return Authenticate(log, APIKey{Store: verifier})(RequireScope(scope)(ok))
+}
+
+func TestAuth(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		value  string
+		want   int
+	}{
+		{"no credentials", "", "", http.StatusUnauthorized},
+		{"invalid key", "X-API-Key", "nope", http.StatusUnauthorized},
+		{"store failure", "X-API-Key", "broken", http.StatusInternalServerError},
+		{"missing scope", "X-API-Key", "reader", http.StatusForbidden},
+		{"x-api-key header", "X-API-Key", "admin", http.StatusOK},
+		{"authorization header", "Authorization", "ApiKey admin", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := newAuthHandler(ScopeDelete)
+			r := httptest.NewRequest(http.MethodDelete, "/quotes/1", nil)
+			if tt.header != "" {
+				r.Header.Set(tt.header, tt.value)
+			}
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, r)
+
+			if w.Code != tt.want {
+				t.Errorf("expected status %d, got %d", tt.want, w.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"quotes-mini-service/pkg/jwt"
+	"slices"
+	"strings"
+)
+
+type TokenVerifier interface {
+	Verify(ctx context.Context, token string) (*jwt.Claims, error)
+}
+
+// claimScopes maps permission names commonly issued by gateways onto the
+// scopes used by the quote routes.
+var claimScopes = map[string]string{
+	"read":          ScopeRead,
+	"write":         ScopeWrite,
+	"delete":        ScopeDelete,
+	"quotes.read":   ScopeRead,
+	"quotes.write":  ScopeWrite,
+	"quotes.delete": ScopeDelete,
+}
+
+// Bearer authenticates "Authorization: Bearer <jwt>" requests.
+type Bearer struct {
+	Verifier TokenVerifier
+	// AllowAdmin passes an "admin" claim through. Off by default, so an
+	// identity provider cannot grant access to the admin routes.
+	AllowAdmin bool
+}
+
+func (b Bearer) Authenticate(r *http.Request) (*Principal, error) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return nil, ErrNoCredentials
+	}
+	claims, err := b.Verifier.Verify(r.Context(), strings.TrimSpace(token))
+	if err != nil && rejected(err) {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidCredentials, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("%w: token has no subject", ErrInvalidCredentials)
+	}
+	return &Principal{
+		Subject: claims.Subject,
+		Name:    claims.Name,
+		Method:  "jwt",
+		Scopes:  b.mapScopes(claims.Scopes()),
+	}, nil
+}
+
+// rejected reports whether a verification error is the token's fault, as
+// opposed to the key set being unreachable.
+func rejected(err error) bool {
+	for _, target := range []error{
+		jwt.ErrMalformed, jwt.ErrUnsupportedAlg, jwt.ErrUnknownKey, jwt.ErrInvalidSignature,
+		jwt.ErrExpired, jwt.ErrMissingExpiry, jwt.ErrNotYetValid, jwt.ErrInvalidIssuer, jwt.ErrInvalidAudience,
+	} {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+func (b Bearer) mapScopes(claimed []string) []string {
+	var scopes []string
+	for _, c := range claimed {
+		scope, ok := claimScopes[c]
+		if !ok && (c == ScopeRead || c == ScopeWrite || c == ScopeDelete || c == ScopeAdmin && b.AllowAdmin) {
+			scope, ok = c, true
+		}
+		if ok && !slices.Contains(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes
+}
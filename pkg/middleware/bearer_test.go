@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"quotes-mini-service/pkg/jwt"
+	"testing"
+)
+
+type mockTokenVerifier struct{}
+
+func (mockTokenVerifier) Verify(_ context.Context, token string) (*jwt.Claims, error) {
+	if token == "offline" {
+		return nil, errors.New("jwks: connection refused")
+	}
+	if token != "good" {
+		return nil, jwt.ErrInvalidSignature
+	}
+	return &jwt.Claims{Subject: "user-42", Scope: "read delete admin", Permissions: []string{"quotes:write", "unrelated"}}, nil
+}
+
+func TestBearer_Authenticate(t *testing.T) {
+	b := Bearer{Verifier: mockTokenVerifier{}}
+
+	r := httptest.NewRequest(http.MethodGet, "/quotes", nil)
+	if _, err := b.Authenticate(r); !errors.Is(err, ErrNoCredentials) {
+		t.Errorf("expected no credentials, got %v", err)
+	}
+
+	r.Header.Set("Authorization", "Bearer bad")
+	if _, err := b.Authenticate(r); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("expected invalid credentials, got %v", err)
+	}
+
+	r.Header.Set("Authorization", "Bearer offline")
+	if _, err := b.Authenticate(r); err == nil || errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("expected key set failure not to be invalid credentials, got %v", err)
+	}
+
+	r.Header.Set("Authorization", "Bearer good")
+	p, err := b.Authenticate(r)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p.Subject != "user-42" {
+		t.Errorf("expected subject user-42, got %s", p.Subject)
+	}
+	for _, scope := range []string{ScopeRead, ScopeWrite, ScopeDelete} {
+		if !p.HasScope(scope) {
+			t.Errorf("expected scope %s in %v", scope, p.Scopes)
+		}
+	}
+	if len(p.Scopes) != 3 {
+		t.Errorf("expected unknown claims to be dropped, got %v", p.Scopes)
+	}
+}
+
+func TestBearer_AdminClaim(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/quotes", nil)
+	r.Header.Set("Authorization", "Bearer good")
+
+	p, err := Bearer{Verifier: mockTokenVerifier{}}.Authenticate(r)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p.HasScope(ScopeAdmin) {
+		t.Errorf("expected admin claim to be dropped, got %v", p.Scopes)
+	}
+
+	p, err = Bearer{Verifier: mockTokenVerifier{}, AllowAdmin: true}.Authenticate(r)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !p.HasScope(ScopeAdmin) {
+		t.Errorf("expected admin claim with AllowAdmin, got %v", p.Scopes)
+	}
+}
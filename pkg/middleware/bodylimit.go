@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"net/http"
+	"quotes-mini-service/pkg/res"
+)
+
+// MaxBodySize rejects requests whose declared length exceeds n and caps
+// the body reader so that chunked uploads fail once they pass the limit.
+// Handlers see the overflow as *http.MaxBytesError and should answer 413.
+func MaxBodySize(n int64) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			if r.ContentLength > n {
+				res.Json(w, res.Error("request body too large"), http.StatusRequestEntityTooLarge)
+				return
+			}
+			r.Body = http.MaxBytesReader(w, r.Body, n)
+			next.ServeHTTP(w, r)
+		}
+		return http.HandlerFunc(fn)
+	}
+}
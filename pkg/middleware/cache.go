@@ -0,0 +1,42 @@
+package middleware
+
+import "net/http"
+
+// CacheControl sets the Cache-Control header on successful and 304 responses
+// of the wrapped route. Errors are left without it so that they are not
+// cached by intermediaries.
+func CacheControl(directives string) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(&cacheControlWriter{ResponseWriter: w, directives: directives}, r)
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
+type cacheControlWriter struct {
+	http.ResponseWriter
+	directives  string
+	wroteHeader bool
+}
+
+func (cw *cacheControlWriter) WriteHeader(code int) {
+	if !cw.wroteHeader {
+		cw.wroteHeader = true
+		if code == http.StatusOK || code == http.StatusNotModified {
+			cw.Header().Set("Cache-Control", cw.directives)
+		}
+	}
+	cw.ResponseWriter.WriteHeader(code)
+}
+
+func (cw *cacheControlWriter) Write(p []byte) (int, error) {
+	if !cw.wroteHeader {
+		cw.WriteHeader(http.StatusOK)
+	}
+	return cw.ResponseWriter.Write(p)
+}
+
+func (cw *cacheControlWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
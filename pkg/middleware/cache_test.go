@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCacheControl(t *testing.T) {
+	for _, status := range []int{http.StatusOK, http.StatusNotModified, http.StatusNotFound, http.StatusInternalServerError} {
+		h := CacheControl("private, max-age=60")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(status)
+		}))
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/quotes", nil))
+
+		got := w.Header().Get("Cache-Control")
+		cacheable := status == http.StatusOK || status == http.StatusNotModified
+		if cacheable && got != "private, max-age=60" {
+			t.Errorf("status %d: expected Cache-Control, got %q", status, got)
+		}
+		if !cacheable && got != "" {
+			t.Errorf("status %d: expected no Cache-Control, got %q", status, got)
+		}
+	}
+}
@@ -0,0 +1,199 @@
+package middleware
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Compress encodes responses with gzip or deflate when the client accepts it
+// and the body reaches minSize bytes. Smaller bodies, already encoded
+// responses and event streams are passed through untouched.
+func Compress(minSize, level int) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Vary", "Accept-Encoding")
+			encoding := negotiateEncoding(r.Header.Get("Accept-Encoding"))
+			if encoding == "" || r.Method == http.MethodHead {
+				next.ServeHTTP(w, r)
+				return
+			}
+			cw := &compressWriter{
+				ResponseWriter: w,
+				encoding:       encoding,
+				minSize:        minSize,
+				level:          level,
+				status:         http.StatusOK,
+			}
+			// On panic the buffered body is dropped on purpose so that
+			// Recover can still send a clean error response.
+			next.ServeHTTP(cw, r)
+			cw.Close()
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
+// negotiateEncoding picks gzip or deflate from an Accept-Encoding header,
+// honouring q-values and treating "*" as gzip.
+func negotiateEncoding(header string) string {
+	best, bestQ := "", 0.0
+	for _, part := range strings.Split(header, ",") {
+		name, q := parseQuality(part)
+		switch name {
+		case "gzip", "deflate":
+		case "*":
+			name = "gzip"
+		default:
+			continue
+		}
+		if q > bestQ || (q == bestQ && q > 0 && name == "gzip") {
+			best, bestQ = name, q
+		}
+	}
+	return best
+}
+
+func parseQuality(part string) (string, float64) {
+	name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+	q := 1.0
+	for _, p := range strings.Split(params, ";") {
+		k, v, ok := strings.Cut(strings.TrimSpace(p), "=")
+		if ok && strings.TrimSpace(k) == "q" {
+			if parsed, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+				q = parsed
+			}
+		}
+	}
+	return strings.ToLower(strings.TrimSpace(name)), q
+}
+
+type compressWriter struct {
+	http.ResponseWriter
+	encoding    string
+	minSize     int
+	level       int
+	status      int
+	wroteHeader bool
+	decided     bool
+	buf         []byte
+	zw          io.WriteCloser
+}
+
+func (cw *compressWriter) WriteHeader(code int) {
+	if cw.wroteHeader {
+		return
+	}
+	cw.wroteHeader = true
+	cw.status = code
+	// Informational and bodiless responses never get compressed.
+	if code < http.StatusOK || code == http.StatusNoContent || code == http.StatusNotModified {
+		cw.decide(false)
+	}
+}
+
+func (cw *compressWriter) Write(p []byte) (int, error) {
+	if !cw.wroteHeader {
+		cw.WriteHeader(http.StatusOK)
+	}
+	if cw.decided {
+		if cw.zw != nil {
+			return cw.zw.Write(p)
+		}
+		return cw.ResponseWriter.Write(p)
+	}
+	cw.buf = append(cw.buf, p...)
+	if len(cw.buf) >= cw.minSize {
+		if err := cw.decide(cw.compressible()); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+func (cw *compressWriter) compressible() bool {
+	h := cw.Header()
+	if h.Get("Content-Encoding") != "" {
+		return false
+	}
+	ct := h.Get("Content-Type")
+	return !strings.HasPrefix(ct, "text/event-stream") &&
+		!strings.HasPrefix(ct, "image/") &&
+		!strings.HasPrefix(ct, "video/")
+}
+
+// decide commits to compressing or not, sends the headers and the buffered
+// part of the body.
+func (cw *compressWriter) decide(compress bool) error {
+	if cw.decided {
+		return nil
+	}
+	cw.decided = true
+	if compress {
+		cw.zw = cw.newWriter()
+	}
+	if cw.zw != nil {
+		h := cw.Header()
+		h.Set("Content-Encoding", cw.encoding)
+		h.Del("Content-Length")
+	}
+	cw.ResponseWriter.WriteHeader(cw.status)
+	if len(cw.buf) == 0 {
+		return nil
+	}
+	buf := cw.buf
+	cw.buf = nil
+	var err error
+	if cw.zw != nil {
+		_, err = cw.zw.Write(buf)
+	} else {
+		_, err = cw.ResponseWriter.Write(buf)
+	}
+	return err
+}
+
+// newWriter returns the encoder of the negotiated encoding, or nil for an
+// invalid level, which leaves the response uncompressed.
+func (cw *compressWriter) newWriter() io.WriteCloser {
+	if cw.encoding == "gzip" {
+		if zw, err := gzip.NewWriterLevel(cw.ResponseWriter, cw.level); err == nil {
+			return zw
+		}
+		return nil
+	}
+	if zw, err := flate.NewWriter(cw.ResponseWriter, cw.level); err == nil {
+		return zw
+	}
+	return nil
+}
+
+func (cw *compressWriter) Close() error {
+	if !cw.wroteHeader && len(cw.buf) == 0 {
+		// The handler wrote nothing at all; leave the response untouched.
+		return nil
+	}
+	if err := cw.decide(false); err != nil {
+		return err
+	}
+	if cw.zw != nil {
+		return cw.zw.Close()
+	}
+	return nil
+}
+
+func (cw *compressWriter) Flush() {
+	if !cw.decided {
+		cw.decide(len(cw.buf) >= cw.minSize && cw.compressible())
+	}
+	if f, ok := cw.zw.(interface{ Flush() error }); ok {
+		f.Flush()
+	}
+	http.NewResponseController(cw.ResponseWriter).Flush()
+}
+
+func (cw *compressWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
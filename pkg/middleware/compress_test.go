@@ -0,0 +1,104 @@
+package middleware
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newCompressHandler(body string) http.Handler {
+	return Compress(64, gzip.DefaultCompression)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		io.WriteString(w, body)
+	}))
+}
+
+func TestCompress(t *testing.T) {
+	body := strings.Repeat(`{"author":"Confucius"}`, 20)
+	tests := []struct {
+		accept string
+		want   string
+	}{
+		{"gzip", "gzip"},
+		{"deflate", "deflate"},
+		{"deflate;q=1, gzip;q=0.5", "deflate"},
+		{"br", ""},
+		{"gzip;q=0", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, "/quotes", nil)
+		if tt.accept != "" {
+			r.Header.Set("Accept-Encoding", tt.accept)
+		}
+		w := httptest.NewRecorder()
+		newCompressHandler(body).ServeHTTP(w, r)
+
+		if got := w.Header().Get("Content-Encoding"); got != tt.want {
+			t.Errorf("%q: expected encoding %q, got %q", tt.accept, tt.want, got)
+			continue
+		}
+		var reader io.Reader = w.Body
+		switch tt.want {
+		case "gzip":
+			zr, err := gzip.NewReader(w.Body)
+			if err != nil {
+				t.Fatalf("invalid gzip body: %v", err)
+			}
+			reader = zr
+		case "deflate":
+			reader = flate.NewReader(w.Body)
+		}
+		decoded, err := io.ReadAll(reader)
+		if err != nil {
+			t.Fatalf("%q: failed to read body: %v", tt.accept, err)
+		}
+		if string(decoded) != body {
+			t.Errorf("%q: body mismatch", tt.accept)
+		}
+	}
+}
+
+func TestCompress_BelowMinSize(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/quotes", nil)
+	r.Header.Set("Accept-Encoding", "gzip")
+	w := httptest.NewRecorder()
+
+	newCompressHandler(`{"count":0}`).ServeHTTP(w, r)
+
+	if w.Header().Get("Content-Encoding") != "" {
+		t.Error("expected small body to stay uncompressed")
+	}
+	if w.Body.String() != `{"count":0}` {
+		t.Errorf("unexpected body %q", w.Body.String())
+	}
+	if w.Header().Get("Vary") != "Accept-Encoding" {
+		t.Error("expected Vary: Accept-Encoding")
+	}
+}
+
+func TestCompress_InvalidLevel(t *testing.T) {
+	body := strings.Repeat(`{"author":"Confucius"}`, 20)
+	for _, encoding := range []string{"gzip", "deflate"} {
+		handler := Compress(64, 42)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			io.WriteString(w, body)
+		}))
+		r := httptest.NewRequest(http.MethodGet, "/quotes", nil)
+		r.Header.Set("Accept-Encoding", encoding)
+		w := httptest.NewRecorder()
+
+		handler.ServeHTTP(w, r)
+
+		if got := w.Header().Get("Content-Encoding"); got != "" {
+			t.Errorf("%s: expected no encoding for an invalid level, got %q", encoding, got)
+		}
+		if w.Body.String() != body {
+			t.Errorf("%s: expected the plain body", encoding)
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Deprecation marks a route as deprecated since at and to be removed at
+// sunset (RFC 9745, RFC 8594). successor is the path prefix the route lives
+// on now; the Link header points clients to the same path under it.
+func Deprecation(at, sunset time.Time, successor string) func(next http.Handler) http.Handler {
+	deprecation := fmt.Sprintf("@%d", at.Unix())
+	sunsetDate := sunset.UTC().Format(http.TimeFormat)
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			h := w.Header()
+			h.Set("Deprecation", deprecation)
+			h.Set("Sunset", sunsetDate)
+			h.Add("Link", fmt.Sprintf(`<%s%s>; rel="successor-version"`, successor, r.URL.EscapedPath()))
+			next.ServeHTTP(w, r)
+		}
+		return http.HandlerFunc(fn)
+	}
+}
@@ -0,0 +1,208 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"quotes-mini-service/pkg/res"
+	"quotes-mini-service/pkg/sl"
+	"strings"
+	"time"
+)
+
+const (
+	IdempotencyKeyHeader = "Idempotency-Key"
+	maxIdempotencyKeyLen = 255
+)
+
+var (
+	// ErrIdempotencyInProgress is returned by Reserve while the first request
+	// with the same key is still being handled.
+	ErrIdempotencyInProgress = errors.New("request with this idempotency key is in progress")
+	// ErrIdempotencyMismatch is returned by Reserve when the key was first
+	// used with a different request.
+	ErrIdempotencyMismatch = errors.New("idempotency key reused with a different request")
+)
+
+// StoredResponse is the first response sent for an idempotency key.
+type StoredResponse struct {
+	Status int
+	Header http.Header
+	Body   []byte
+}
+
+type IdempotencyStore interface {
+	// Reserve claims key within scope for the request identified by
+	// requestHash. It returns the stored response if the key was already
+	// used for the same request, or nil if the caller now owns the key.
+	Reserve(ctx context.Context, scope, key, requestHash string, ttl time.Duration) (*StoredResponse, error)
+	Complete(ctx context.Context, scope, key string, resp StoredResponse) error
+	Release(ctx context.Context, scope, key string) error
+}
+
+// replayedHeaders are the response headers kept for replays; rate limit,
+// tracing and similar per-request headers are produced afresh.
+var replayedHeaders = []string{"Content-Type", "Content-Language", "Location", "ETag", "Last-Modified"}
+
+// Idempotency replays the stored response when a request repeats an
+// Idempotency-Key already seen from the same client within ttl. Keys are
+// scoped to the authenticated subject, or the client IP for anonymous
+// calls. Server errors are not stored so that the client can retry them.
+func Idempotency(log *slog.Logger, store IdempotencyStore, clientIP func(*http.Request) string, ttl time.Duration) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		log := log.With(
+			slog.String("component", "middleware/idempotency"),
+		)
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(IdempotencyKeyHeader)
+			if key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if len(key) > maxIdempotencyKeyLen {
+				res.Json(w, res.Error("idempotency key is too long"), http.StatusBadRequest)
+				return
+			}
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				var maxErr *http.MaxBytesError
+				if errors.As(err, &maxErr) {
+					res.Json(w, res.Error("request body too large"), http.StatusRequestEntityTooLarge)
+					return
+				}
+				res.Json(w, res.Error("failed to read request body"), http.StatusBadRequest)
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			scope := "ip:" + clientIP(r)
+			if p, ok := PrincipalFromContext(r.Context()); ok {
+				scope = p.Subject
+			}
+			ctx := r.Context()
+			stored, err := store.Reserve(ctx, scope, key, requestHash(r, body), ttl)
+			switch {
+			case errors.Is(err, ErrIdempotencyMismatch):
+				res.Json(w, res.Error(err.Error()), http.StatusUnprocessableEntity)
+				return
+			case errors.Is(err, ErrIdempotencyInProgress):
+				w.Header().Set("Retry-After", "1")
+				res.Json(w, res.Error(err.Error()), http.StatusConflict)
+				return
+			case err != nil:
+				log.Error("failed to reserve idempotency key", sl.Err(err))
+				res.Json(w, res.Error("internal server error"), http.StatusInternalServerError)
+				return
+			case stored != nil:
+				h := w.Header()
+				for name, values := range stored.Header {
+					h[name] = values
+				}
+				h.Set("Idempotent-Replayed", "true")
+				w.WriteHeader(stored.Status)
+				w.Write(stored.Body)
+				return
+			}
+
+			rec := &recordingWriter{ResponseWriter: w, status: http.StatusOK}
+			completed := false
+			defer func() {
+				// Also runs when the handler panics, so that the key is not
+				// stuck in progress until it expires.
+				if completed {
+					return
+				}
+				if err := store.Release(context.WithoutCancel(ctx), scope, key); err != nil {
+					log.Error("failed to release idempotency key", sl.Err(err))
+				}
+			}()
+			next.ServeHTTP(rec, r)
+			if rec.status >= http.StatusInternalServerError {
+				return
+			}
+			resp := StoredResponse{Status: rec.status, Header: http.Header{}, Body: rec.body.Bytes()}
+			for _, name := range replayedHeaders {
+				if v := w.Header().Values(name); len(v) > 0 {
+					resp.Header[name] = v
+				}
+			}
+			if err := store.Complete(context.WithoutCancel(ctx), scope, key, resp); err != nil {
+				log.Error("failed to store idempotent response", sl.Err(err))
+				return
+			}
+			completed = true
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
+type idempotentRouteKey struct{}
+
+// IdempotentRoute records the route pattern a handler was registered for, such as
+// "PUT /quotes/{id}". Idempotency hashes it instead of the request path, so
+// the versioned paths and the alias of one route share their keys.
+func IdempotentRoute(pattern string) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), idempotentRouteKey{}, pattern)))
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
+// requestHash fingerprints the route, its path values and the body so that
+// a key reused for a different request can be detected.
+func requestHash(r *http.Request, body []byte) string {
+	target := r.Method + " " + r.URL.Path
+	if pattern, ok := r.Context().Value(idempotentRouteKey{}).(string); ok {
+		target = expandPattern(pattern, r)
+	}
+	h := sha256.New()
+	io.WriteString(h, target+"\n")
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// expandPattern fills the wildcards of pattern with the values of r.
+func expandPattern(pattern string, r *http.Request) string {
+	segments := strings.Split(pattern, "/")
+	for i, seg := range segments {
+		if name, ok := strings.CutPrefix(seg, "{"); ok {
+			name = strings.TrimSuffix(strings.TrimSuffix(name, "}"), "...")
+			segments[i] = r.PathValue(name)
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+type recordingWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (rw *recordingWriter) WriteHeader(code int) {
+	if !rw.wroteHeader {
+		rw.wroteHeader = true
+		rw.status = code
+	}
+	rw.ResponseWriter.WriteHeader(code)
+}
+
+func (rw *recordingWriter) Write(p []byte) (int, error) {
+	if !rw.wroteHeader {
+		rw.WriteHeader(http.StatusOK)
+	}
+	rw.body.Write(p)
+	return rw.ResponseWriter.Write(p)
+}
+
+func (rw *recordingWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
@@ -0,0 +1,173 @@
+package middleware
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type memoryIdempotencyStore struct {
+	mu      sync.Mutex
+	hashes  map[string]string
+	stored  map[string]*StoredResponse
+	pending map[string]bool
+}
+
+func newMemoryIdempotencyStore() *memoryIdempotencyStore {
+	return &memoryIdempotencyStore{
+		hashes:  map[string]string{},
+		stored:  map[string]*StoredResponse{},
+		pending: map[string]bool{},
+	}
+}
+
+func (s *memoryIdempotencyStore) Reserve(_ context.Context, scope, key, hash string, _ time.Duration) (*StoredResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := scope + "|" + key
+	existing, ok := s.hashes[id]
+	if !ok {
+		s.hashes[id] = hash
+		s.pending[id] = true
+		return nil, nil
+	}
+	if existing != hash {
+		return nil, ErrIdempotencyMismatch
+	}
+	if s.pending[id] {
+		return nil, ErrIdempotencyInProgress
+	}
+	return s.stored[id], nil
+}
+
+func (s *memoryIdempotencyStore) Complete(_ context.Context, scope, key string, resp StoredResponse) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := scope + "|" + key
+	delete(s.pending, id)
+	s.stored[id] = &resp
+	return nil
+}
+
+func (s *memoryIdempotencyStore) Release(_ context.Context, scope, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := scope + "|" + key
+	if s.pending[id] {
+		delete(s.pending, id)
+		delete(s.hashes, id)
+	}
+	return nil
+}
+
+func newIdempotentHandler(store IdempotencyStore, calls *int, status int) http.Handler {
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	clientIP := func(*http.Request) string { return "192.0.2.1" }
+	return Idempotency(log, store, clientIP, time.Hour)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*calls++
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Request-Only", "1")
+		w.WriteHeader(status)
+		w.Write(body)
+	}))
+}
+
+func idempotentRequest(key, body string) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, "/quotes", strings.NewReader(body))
+	if key != "" {
+		r.Header.Set(IdempotencyKeyHeader, key)
+	}
+	return r
+}
+
+func TestIdempotency_Replay(t *testing.T) {
+	calls := 0
+	h := newIdempotentHandler(newMemoryIdempotencyStore(), &calls, http.StatusCreated)
+
+	first := httptest.NewRecorder()
+	h.ServeHTTP(first, idempotentRequest("k1", `{"author":"a"}`))
+	second := httptest.NewRecorder()
+	h.ServeHTTP(second, idempotentRequest("k1", `{"author":"a"}`))
+
+	if calls != 1 {
+		t.Errorf("expected handler to run once, ran %d times", calls)
+	}
+	if second.Code != http.StatusCreated || second.Body.String() != `{"author":"a"}` {
+		t.Errorf("unexpected replay %d %q", second.Code, second.Body.String())
+	}
+	if second.Header().Get("Idempotent-Replayed") != "true" {
+		t.Error("expected Idempotent-Replayed header")
+	}
+	if second.Header().Get("Content-Type") != "application/json" || second.Header().Get("X-Request-Only") != "" {
+		t.Errorf("unexpected replayed headers %v", second.Header())
+	}
+
+	mismatch := httptest.NewRecorder()
+	h.ServeHTTP(mismatch, idempotentRequest("k1", `{"author":"b"}`))
+	if mismatch.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected status %d, got %d", http.StatusUnprocessableEntity, mismatch.Code)
+	}
+
+	h.ServeHTTP(httptest.NewRecorder(), idempotentRequest("", `{"author":"a"}`))
+	if calls != 2 {
+		t.Errorf("expected requests without a key to pass through, calls=%d", calls)
+	}
+}
+
+func TestIdempotency_ServerErrorNotStored(t *testing.T) {
+	calls := 0
+	h := newIdempotentHandler(newMemoryIdempotencyStore(), &calls, http.StatusInternalServerError)
+
+	for range 2 {
+		h.ServeHTTP(httptest.NewRecorder(), idempotentRequest("k1", "{}"))
+	}
+	if calls != 2 {
+		t.Errorf("expected failed request to be retried, calls=%d", calls)
+	}
+}
+
+func TestIdempotency_InProgress(t *testing.T) {
+	store := newMemoryIdempotencyStore()
+	store.Reserve(context.Background(), "ip:192.0.2.1", "k1", requestHash(idempotentRequest("k1", "{}"), []byte("{}")), time.Hour)
+	calls := 0
+	h := newIdempotentHandler(store, &calls, http.StatusCreated)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, idempotentRequest("k1", "{}"))
+	if w.Code != http.StatusConflict || calls != 0 {
+		t.Errorf("expected 409 without calling the handler, got %d calls=%d", w.Code, calls)
+	}
+}
+
+func TestIdempotency_SameRouteAcrossVersions(t *testing.T) {
+	calls := 0
+	h := IdempotentRoute("PUT /quotes/{id}")(newIdempotentHandler(newMemoryIdempotencyStore(), &calls, http.StatusOK))
+	mux := http.NewServeMux()
+	mux.Handle("PUT /quotes/{id}", h)
+	mux.Handle("PUT /v1/quotes/{id}", h)
+	do := func(path string) int {
+		r := httptest.NewRequest(http.MethodPut, path, strings.NewReader("{}"))
+		r.Header.Set(IdempotencyKeyHeader, "k1")
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	if code := do("/v1/quotes/1"); code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, code)
+	}
+	if code := do("/quotes/1"); code != http.StatusOK || calls != 1 {
+		t.Errorf("expected the alias to replay, got %d calls=%d", code, calls)
+	}
+	if code := do("/v1/quotes/2"); code != http.StatusUnprocessableEntity {
+		t.Errorf("expected another id to mismatch, got %d", code)
+	}
+}
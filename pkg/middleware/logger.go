@@ -1,15 +1,20 @@
 package middleware
 
 import (
+	"bufio"
 	"log/slog"
+	"net"
 	"net/http"
+	"quotes-mini-service/pkg/tracing"
 	"time"
 )
 
 type ResponseWriter interface {
 	http.ResponseWriter
+	http.Flusher
 	StatusCode() int
 	BytesWritten() int
+	WroteHeader() bool
 }
 type responseWriter struct {
 	http.ResponseWriter
@@ -39,12 +44,42 @@ func (w *responseWriter) Write(data []byte) (int, error) {
 	w.bytesWritten += n
 	return n, err
 }
+
+// Flush sends buffered data to the client if the underlying writer supports
+// it, so that streaming handlers work behind the logger.
+func (w *responseWriter) Flush() {
+	if !w.wroteHeader {
+		w.WriteHeader(200)
+	}
+	http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Hijack hands the connection over to protocols such as WebSocket and
+// records the switch in the access log.
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, brw, err := http.NewResponseController(w.ResponseWriter).Hijack()
+	if err == nil {
+		w.statusCode = http.StatusSwitchingProtocols
+		w.wroteHeader = true
+	}
+	return conn, brw, err
+}
+
+// Unwrap lets http.ResponseController reach the connection's writer, for
+// example to change write deadlines.
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
 func (w *responseWriter) StatusCode() int {
 	return w.statusCode
 }
 func (w *responseWriter) BytesWritten() int {
 	return w.bytesWritten
 }
+func (w *responseWriter) WroteHeader() bool {
+	return w.wroteHeader
+}
 
 func New(log *slog.Logger) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
@@ -60,6 +95,12 @@ func New(log *slog.Logger) func(next http.Handler) http.Handler {
 				slog.String("remote_addr", r.RemoteAddr),
 				slog.String("user_agent", r.UserAgent()),
 			)
+			if id := RequestIDFromContext(r.Context()); id != "" {
+				entry = entry.With(slog.String("request_id", id))
+			}
+			if sc := tracing.SpanContextFromContext(r.Context()); sc.IsValid() {
+				entry = entry.With(slog.String("trace_id", sc.TraceID.String()))
+			}
 			wr := NewResponseWriter(w)
 			t1 := time.Now()
 			defer func() {
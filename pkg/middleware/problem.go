@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"quotes-mini-service/pkg/res"
+	"strings"
+)
+
+// Problems rewrites the {"status": "Error", "error": ...} bodies handlers
+// answer errors with into RFC 9457 problem details. Successful responses
+// and anything that is not such a body pass through unchanged.
+func Problems(next http.Handler) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		pw := &problemWriter{ResponseWriter: w}
+		next.ServeHTTP(pw, r)
+		if !pw.buffering {
+			return
+		}
+		var body res.Response
+		if err := json.Unmarshal(pw.body.Bytes(), &body); err != nil || body.Status != res.StatusError {
+			w.WriteHeader(pw.status)
+			w.Write(pw.body.Bytes())
+			return
+		}
+		p := res.NewProblem(pw.status, body.Error)
+		p.Instance = r.URL.Path
+		p.RequestID = RequestIDFromContext(r.Context())
+		p.Errors = body.Details
+		w.Header().Del("Content-Length")
+		res.ProblemJson(w, p)
+	}
+	return http.HandlerFunc(fn)
+}
+
+// problemWriter holds back JSON error responses until the handler is done.
+type problemWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	buffering   bool
+	body        bytes.Buffer
+}
+
+func (pw *problemWriter) WriteHeader(code int) {
+	if pw.wroteHeader {
+		return
+	}
+	pw.wroteHeader = true
+	pw.status = code
+	if code >= http.StatusBadRequest && strings.HasPrefix(pw.Header().Get("Content-Type"), "application/json") {
+		pw.buffering = true
+		return
+	}
+	pw.ResponseWriter.WriteHeader(code)
+}
+
+func (pw *problemWriter) Write(p []byte) (int, error) {
+	if !pw.wroteHeader {
+		pw.WriteHeader(http.StatusOK)
+	}
+	if pw.buffering {
+		return pw.body.Write(p)
+	}
+	return pw.ResponseWriter.Write(p)
+}
+
+func (pw *problemWriter) Unwrap() http.ResponseWriter {
+	return pw.ResponseWriter
+}
@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"quotes-mini-service/pkg/res"
+	"testing"
+)
+
+func TestProblems(t *testing.T) {
+	tests := []struct {
+		name        string
+		write       func(w http.ResponseWriter)
+		status      int
+		contentType string
+	}{
+		{"error", func(w http.ResponseWriter) {
+			res.Json(w, res.Response{Status: res.StatusError, Error: "invalid", Details: []res.FieldError{{Field: "/body/a", Message: "is required"}}}, http.StatusBadRequest)
+		}, http.StatusBadRequest, "application/problem+json"},
+		{"success", func(w http.ResponseWriter) { res.Json(w, map[string]int{"id": 1}, http.StatusCreated) }, http.StatusCreated, "application/json"},
+		{"other error body", func(w http.ResponseWriter) { res.Json(w, map[string]int{"id": 1}, http.StatusConflict) }, http.StatusConflict, "application/json"},
+		{"no body", func(w http.ResponseWriter) { w.WriteHeader(http.StatusNotFound) }, http.StatusNotFound, ""},
+	}
+	for _, tt := range tests {
+		h := Problems(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { tt.write(w) }))
+		rr := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/v2/quotes", nil)
+		h.ServeHTTP(rr, r.WithContext(WithRequestID(r.Context(), "abc")))
+		if rr.Code != tt.status {
+			t.Errorf("%s: expected status %d, got %d", tt.name, tt.status, rr.Code)
+		}
+		if ct := rr.Header().Get("Content-Type"); ct != tt.contentType {
+			t.Errorf("%s: expected content type %q, got %q", tt.name, tt.contentType, ct)
+		}
+		if tt.contentType != "application/problem+json" {
+			continue
+		}
+		var p res.Problem
+		if err := json.NewDecoder(rr.Body).Decode(&p); err != nil {
+			t.Fatalf("%s: failed to decode problem: %v", tt.name, err)
+		}
+		if p.Status != tt.status || p.Detail != "invalid" || p.Instance != "/v2/quotes" || p.RequestID != "abc" || len(p.Errors) != 1 {
+			t.Errorf("%s: unexpected problem %+v", tt.name, p)
+		}
+	}
+}
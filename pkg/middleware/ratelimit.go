@@ -0,0 +1,242 @@
+package middleware
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"net/netip"
+	"quotes-mini-service/pkg/res"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Limit is a token bucket: Burst requests may be made at once and the bucket
+// refills at Rate tokens per second.
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// ParseLimit reads limits written as "<requests>/<period>[:<burst>]", for
+// example "10/m", "5/s:20" or "100/1h". The burst defaults to the request count.
+func ParseLimit(s string) (Limit, error) {
+	spec, burstStr, hasBurst := strings.Cut(strings.TrimSpace(s), ":")
+	countStr, periodStr, ok := strings.Cut(spec, "/")
+	if !ok {
+		return Limit{}, fmt.Errorf("invalid rate limit %q", s)
+	}
+	count, err := strconv.Atoi(countStr)
+	if err != nil || count <= 0 {
+		return Limit{}, fmt.Errorf("invalid rate limit count %q", countStr)
+	}
+	var period time.Duration
+	switch periodStr {
+	case "s":
+		period = time.Second
+	case "m":
+		period = time.Minute
+	case "h":
+		period = time.Hour
+	default:
+		period, err = time.ParseDuration(periodStr)
+		if err != nil || period <= 0 {
+			return Limit{}, fmt.Errorf("invalid rate limit period %q", periodStr)
+		}
+	}
+	limit := Limit{Rate: float64(count) / period.Seconds(), Burst: count}
+	if hasBurst {
+		limit.Burst, err = strconv.Atoi(burstStr)
+		if err != nil || limit.Burst <= 0 {
+			return Limit{}, fmt.Errorf("invalid rate limit burst %q", burstStr)
+		}
+	}
+	return limit, nil
+}
+
+type bucket struct {
+	tokens   float64
+	lastSeen time.Time
+	limit    Limit
+}
+
+// refilledAt is when the bucket is full again, after which dropping it is
+// the same as keeping it.
+func (b *bucket) refilledAt() time.Time {
+	missing := float64(b.limit.Burst) - b.tokens
+	return b.lastSeen.Add(time.Duration(missing / b.limit.Rate * float64(time.Second)))
+}
+
+type RateLimiter struct {
+	mu             sync.Mutex
+	buckets        map[string]*bucket
+	trustedProxies []netip.Prefix
+	idleTTL        time.Duration
+	now            func() time.Time
+	stop           chan struct{}
+	stopOnce       sync.Once
+}
+
+// NewRateLimiter starts a janitor that drops buckets idle for longer than
+// idleTTL once they have refilled; call Close to stop it. idleTTL must be
+// positive.
+func NewRateLimiter(trustedProxies []netip.Prefix, idleTTL time.Duration) *RateLimiter {
+	l := &RateLimiter{
+		buckets:        make(map[string]*bucket),
+		trustedProxies: trustedProxies,
+		idleTTL:        idleTTL,
+		now:            time.Now,
+		stop:           make(chan struct{}),
+	}
+	go l.janitor()
+	return l
+}
+
+func (l *RateLimiter) Close() {
+	l.stopOnce.Do(func() {
+		close(l.stop)
+	})
+}
+
+func (l *RateLimiter) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.buckets)
+}
+
+func (l *RateLimiter) janitor() {
+	ticker := time.NewTicker(l.idleTTL / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			l.evict()
+		case <-l.stop:
+			return
+		}
+	}
+}
+
+func (l *RateLimiter) evict() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	cutoff := now.Add(-l.idleTTL)
+	for key, b := range l.buckets {
+		if b.lastSeen.Before(cutoff) && !b.refilledAt().After(now) {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// take consumes a token and reports whether the request is allowed, the
+// tokens left and how long until the next token becomes available.
+func (l *RateLimiter) take(key string, limit Limit) (bool, float64, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Burst), lastSeen: now}
+		l.buckets[key] = b
+	}
+	b.limit = limit
+	b.tokens = math.Min(float64(limit.Burst), b.tokens+now.Sub(b.lastSeen).Seconds()*limit.Rate)
+	b.lastSeen = now
+	if b.tokens < 1 {
+		wait := time.Duration((1 - b.tokens) / limit.Rate * float64(time.Second))
+		return false, b.tokens, wait
+	}
+	b.tokens--
+	return true, b.tokens, 0
+}
+
+// Limit applies limit to the wrapped route. Buckets are keyed by the route
+// name plus the authenticated subject, falling back to the client IP.
+func (l *RateLimiter) Limit(route string, limit Limit) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			client := "ip:" + l.ClientIP(r)
+			if p, ok := PrincipalFromContext(r.Context()); ok {
+				client = p.Subject
+			}
+			allowed, remaining, wait := l.take(route+"|"+client, limit)
+			window := int(math.Ceil(float64(limit.Burst) / limit.Rate))
+			full := int(math.Ceil((float64(limit.Burst) - remaining) / limit.Rate))
+			h := w.Header()
+			h.Set("RateLimit-Limit", strconv.Itoa(limit.Burst))
+			h.Set("RateLimit-Remaining", strconv.Itoa(int(remaining)))
+			h.Set("RateLimit-Reset", strconv.Itoa(full))
+			h.Set("RateLimit-Policy", fmt.Sprintf("%d;w=%d", limit.Burst, window))
+			if !allowed {
+				h.Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+				res.Json(w, res.Error("rate limit exceeded"), http.StatusTooManyRequests)
+				return
+			}
+			next.ServeHTTP(w, r)
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
+// ClientIP returns the peer address, or the right-most untrusted entry of
+// X-Forwarded-For when the peer is one of the trusted proxies. Entries added
+// by the client itself are never trusted.
+func (l *RateLimiter) ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil || !l.trusted(addr) {
+		return host
+	}
+	hops := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			break
+		}
+		if !l.trusted(hop) {
+			return hop.String()
+		}
+		host = hop.String()
+	}
+	return host
+}
+
+func (l *RateLimiter) trusted(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	for _, p := range l.trustedProxies {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+func ParsePrefixes(list string) ([]netip.Prefix, error) {
+	var prefixes []netip.Prefix
+	for _, s := range strings.Split(list, ",") {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+		if !strings.Contains(s, "/") {
+			addr, err := netip.ParseAddr(s)
+			if err != nil {
+				return nil, err
+			}
+			prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+		p, err := netip.ParsePrefix(s)
+		if err != nil {
+			return nil, err
+		}
+		prefixes = append(prefixes, p.Masked())
+	}
+	return prefixes, nil
+}
@@ -0,0 +1,181 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestParseLimit(t *testing.T) {
+	tests := []struct {
+		spec  string
+		rate  float64
+		burst int
+		err   bool
+	}{
+		{"10/s", 10, 10, false},
+		{"60/m:5", 1, 5, false},
+		{"100/10s", 10, 100, false},
+		{"10", 0, 0, true},
+		{"0/m", 0, 0, true},
+		{"10/fortnight", 0, 0, true},
+	}
+	for _, tt := range tests {
+		limit, err := ParseLimit(tt.spec)
+		if tt.err {
+			if err == nil {
+				t.Errorf("%s: expected error", tt.spec)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.spec, err)
+			continue
+		}
+		if limit.Rate != tt.rate || limit.Burst != tt.burst {
+			t.Errorf("%s: expected %v/%d, got %v/%d", tt.spec, tt.rate, tt.burst, limit.Rate, limit.Burst)
+		}
+	}
+}
+
+func TestRateLimiter_Limit(t *testing.T) {
+	limiter := NewRateLimiter(nil, time.Minute)
+	defer limiter.Close()
+	now := time.Now()
+	limiter.now = func() time.Time { return now }
+
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	})
+	handler := limiter.Limit("POST /quotes", Limit{Rate: 1, Burst: 2})(ok)
+	do := func(remoteAddr string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, "/quotes", nil)
+		r.RemoteAddr = remoteAddr
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+
+	for i := 0; i < 2; i++ {
+		if w := do("10.0.0.1:1234"); w.Code != http.StatusCreated {
+			t.Fatalf("request %d: expected status %d, got %d", i, http.StatusCreated, w.Code)
+		}
+	}
+	w := do("10.0.0.1:1234")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected status %d, got %d", http.StatusTooManyRequests, w.Code)
+	}
+	if w.Header().Get("Retry-After") != "1" {
+		t.Errorf("expected Retry-After 1, got %q", w.Header().Get("Retry-After"))
+	}
+	if w.Header().Get("RateLimit-Limit") != "2" || w.Header().Get("RateLimit-Remaining") != "0" {
+		t.Errorf("unexpected rate limit headers %v", w.Header())
+	}
+
+	if w := do("10.0.0.2:1234"); w.Code != http.StatusCreated {
+		t.Errorf("expected other clients to be unaffected, got %d", w.Code)
+	}
+
+	now = now.Add(time.Second)
+	if w := do("10.0.0.1:1234"); w.Code != http.StatusCreated {
+		t.Errorf("expected bucket to refill, got %d", w.Code)
+	}
+}
+
+func TestRateLimiter_KeyedByPrincipal(t *testing.T) {
+	limiter := NewRateLimiter(nil, time.Minute)
+	defer limiter.Close()
+
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	handler := limiter.Limit("GET /quotes", Limit{Rate: 0.001, Burst: 1})(ok)
+	do := func(subject string) int {
+		r := httptest.NewRequest(http.MethodGet, "/quotes", nil)
+		r = r.WithContext(WithPrincipal(r.Context(), &Principal{Subject: subject}))
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	if do("apikey:1") != http.StatusOK || do("apikey:2") != http.StatusOK {
+		t.Fatal("expected first request per key to pass")
+	}
+	if do("apikey:1") != http.StatusTooManyRequests {
+		t.Error("expected second request for the same key to be limited")
+	}
+}
+
+func TestRateLimiter_ClientIP(t *testing.T) {
+	trusted, err := ParsePrefixes("10.0.0.0/8, 192.168.1.1")
+	if err != nil {
+		t.Fatalf("failed to parse prefixes: %v", err)
+	}
+	limiter := NewRateLimiter(trusted, time.Minute)
+	defer limiter.Close()
+
+	tests := []struct {
+		name   string
+		remote string
+		xff    string
+		want   string
+	}{
+		{"direct", "203.0.113.7:1000", "", "203.0.113.7"},
+		{"untrusted peer ignores header", "203.0.113.7:1000", "1.2.3.4", "203.0.113.7"},
+		{"trusted peer", "10.1.2.3:1000", "198.51.100.1", "198.51.100.1"},
+		{"spoofed left-most entry", "10.1.2.3:1000", "1.2.3.4, 198.51.100.1, 192.168.1.1", "198.51.100.1"},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.RemoteAddr = tt.remote
+		if tt.xff != "" {
+			r.Header.Set("X-Forwarded-For", tt.xff)
+		}
+		if got := limiter.ClientIP(r); got != tt.want {
+			t.Errorf("%s: expected %s, got %s", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestRateLimiter_EvictsIdleBuckets(t *testing.T) {
+	limiter := NewRateLimiter(nil, time.Minute)
+	defer limiter.Close()
+	now := time.Now()
+	limiter.now = func() time.Time { return now }
+
+	limiter.take("a", Limit{Rate: 1, Burst: 1})
+	now = now.Add(30 * time.Second)
+	limiter.take("b", Limit{Rate: 1, Burst: 1})
+	now = now.Add(45 * time.Second)
+	limiter.evict()
+
+	if limiter.Len() != 1 {
+		t.Errorf("expected 1 bucket after eviction, got %d", limiter.Len())
+	}
+}
+
+func TestRateLimiter_KeepsDrainedBuckets(t *testing.T) {
+	limiter := NewRateLimiter(nil, time.Minute)
+	defer limiter.Close()
+	now := time.Now()
+	limiter.now = func() time.Time { return now }
+	hourly := Limit{Rate: 10 / time.Hour.Seconds(), Burst: 10}
+
+	for range 10 {
+		limiter.take("a", hourly)
+	}
+	now = now.Add(10 * time.Minute)
+	limiter.evict()
+	if limiter.Len() != 1 {
+		t.Fatalf("expected the drained bucket to be kept, got %d buckets", limiter.Len())
+	}
+	// Ten minutes refill less than two of the ten tokens.
+	if _, remaining, _ := limiter.take("a", hourly); remaining >= 1 {
+		t.Errorf("expected idling not to refill the burst, %g tokens left", remaining)
+	}
+
+	now = now.Add(2 * time.Hour)
+	limiter.evict()
+	if limiter.Len() != 0 {
+		t.Errorf("expected the refilled bucket to be evicted, got %d buckets", limiter.Len())
+	}
+}
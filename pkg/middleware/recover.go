@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"quotes-mini-service/pkg/metrics"
+	"quotes-mini-service/pkg/res"
+	"runtime/debug"
+)
+
+var panicsTotal = metrics.NewCounter("http_panics_total", "Panics recovered in HTTP handlers.", "route")
+
+// Recover turns a handler panic into a 500 problem response and a structured
+// crash report. Place it inside New so the failed request is still logged.
+func Recover(log *slog.Logger) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		log := log.With(
+			slog.String("component", "middleware/recover"),
+		)
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			r = trackRoute(r)
+			wr, ok := w.(ResponseWriter)
+			if !ok {
+				wr = NewResponseWriter(w)
+			}
+			defer func() {
+				rec := recover()
+				if rec == nil {
+					return
+				}
+				// ErrAbortHandler is the sanctioned way to abort a response.
+				if err, ok := rec.(error); ok && errors.Is(err, http.ErrAbortHandler) {
+					panic(rec)
+				}
+				route := Pattern(r)
+				if route == "" {
+					route = "unmatched"
+				}
+				panicsTotal.Inc(route)
+				requestID := RequestIDFromContext(r.Context())
+				log.Error("panic recovered",
+					slog.String("panic", fmt.Sprint(rec)),
+					slog.String("request_id", requestID),
+					slog.String("route", route),
+					slog.String("method", r.Method),
+					slog.String("path", r.URL.Path),
+					slog.String("stack", string(debug.Stack())),
+				)
+				if wr.WroteHeader() {
+					// Too late for a clean error; make sure the client sees a
+					// broken response rather than a truncated success.
+					panic(http.ErrAbortHandler)
+				}
+				p := res.NewProblem(http.StatusInternalServerError, "unexpected error while handling the request")
+				p.Instance = r.URL.Path
+				p.RequestID = requestID
+				res.ProblemJson(wr, p)
+			}()
+			next.ServeHTTP(wr, r)
+		}
+		return http.HandlerFunc(fn)
+	}
+}
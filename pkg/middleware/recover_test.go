@@ -0,0 +1,88 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"quotes-mini-service/pkg/res"
+	"testing"
+)
+
+type ctxKey struct{}
+
+func TestRecover(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	router := http.NewServeMux()
+	router.HandleFunc("GET /quotes/random", func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	})
+	// Replacing the request between Recover and the mux must not hide the route.
+	rewrap := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), ctxKey{}, 1)))
+		})
+	}
+	handler := Chain(Router(router), RequestID, New(log), Recover(log), rewrap)
+	before := panicsTotal.Value("GET /quotes/random")
+
+	r := httptest.NewRequest(http.MethodGet, "/quotes/random", nil)
+	r.Header.Set(RequestIDHeader, "req-123")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("expected status %d, got %d", http.StatusInternalServerError, w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/problem+json" {
+		t.Errorf("expected problem content type, got %q", ct)
+	}
+	var p res.Problem
+	if err := json.NewDecoder(w.Body).Decode(&p); err != nil {
+		t.Fatalf("failed to decode problem: %v", err)
+	}
+	if p.Status != http.StatusInternalServerError || p.RequestID != "req-123" {
+		t.Errorf("unexpected problem %+v", p)
+	}
+	if got := panicsTotal.Value("GET /quotes/random"); got != before+1 {
+		t.Errorf("expected panic counter %v, got %v", before+1, got)
+	}
+}
+
+func TestRecover_AfterHeadersWritten(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	handler := Recover(log)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		panic("late")
+	}))
+
+	defer func() {
+		if rec := recover(); rec != http.ErrAbortHandler {
+			t.Errorf("expected ErrAbortHandler, got %v", rec)
+		}
+	}()
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+}
+
+func TestRequestID(t *testing.T) {
+	var seen string
+	handler := RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = RequestIDFromContext(r.Context())
+	}))
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	if len(seen) != 32 || w.Header().Get(RequestIDHeader) != seen {
+		t.Errorf("expected generated id echoed, got %q / %q", seen, w.Header().Get(RequestIDHeader))
+	}
+
+	r = httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set(RequestIDHeader, "bad id with spaces")
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+	if seen == "bad id with spaces" {
+		t.Error("expected malformed id to be replaced")
+	}
+}
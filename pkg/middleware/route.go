@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+)
+
+// Middleware that replaces the request (r.WithContext) hides the pattern the
+// mux stores on its own copy. A shared holder on the context lets outer
+// middleware read the matched route, even while a panic unwinds.
+type route struct {
+	pattern string
+}
+
+type routeKey struct{}
+
+func trackRoute(r *http.Request) *http.Request {
+	if _, ok := r.Context().Value(routeKey{}).(*route); ok {
+		return r
+	}
+	return r.WithContext(context.WithValue(r.Context(), routeKey{}, &route{}))
+}
+
+// Router wraps the mux and publishes the matched pattern to outer middleware.
+func Router(mux http.Handler) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		if rt, ok := r.Context().Value(routeKey{}).(*route); ok {
+			defer func() {
+				rt.pattern = r.Pattern
+			}()
+		}
+		mux.ServeHTTP(w, r)
+	}
+	return http.HandlerFunc(fn)
+}
+
+// Pattern returns the route pattern matched for r, if known.
+func Pattern(r *http.Request) string {
+	if r.Pattern != "" {
+		return r.Pattern
+	}
+	if rt, ok := r.Context().Value(routeKey{}).(*route); ok {
+		return rt.pattern
+	}
+	return ""
+}
+
+// Chain applies middlewares so that the first one listed is the outermost.
+func Chain(h http.Handler, middlewares ...func(http.Handler) http.Handler) http.Handler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		h = middlewares[i](h)
+	}
+	return h
+}
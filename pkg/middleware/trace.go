@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"quotes-mini-service/pkg/tracing"
+)
+
+func Trace(tracer *tracing.Tracer) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			ctx := tracing.Extract(r.Context(), r.Header)
+			ctx, span := tracer.Start(ctx, fmt.Sprintf("%s %s", r.Method, r.URL.Path), tracing.KindServer)
+			defer span.End()
+			span.SetAttributes(
+				tracing.String("http.request.method", r.Method),
+				tracing.String("url.path", r.URL.Path),
+				tracing.String("client.address", r.RemoteAddr),
+				tracing.String("user_agent.original", r.UserAgent()),
+			)
+			wr, ok := w.(ResponseWriter)
+			if !ok {
+				wr = NewResponseWriter(w)
+			}
+			r = trackRoute(r.WithContext(ctx))
+			next.ServeHTTP(wr, r)
+			// The matched pattern is only known once the mux has routed.
+			if pattern := Pattern(r); pattern != "" {
+				span.SetName(pattern)
+				span.SetAttributes(tracing.String("http.route", pattern))
+			}
+			span.SetAttributes(tracing.Int("http.response.status_code", wr.StatusCode()))
+			if wr.StatusCode() >= http.StatusInternalServerError {
+				span.SetStatus(tracing.StatusError, http.StatusText(wr.StatusCode()))
+			}
+		}
+		return http.HandlerFunc(fn)
+	}
+}
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"quotes-mini-service/pkg/tracing"
+	"strings"
+	"testing"
+)
+
+func TestTrace_PropagatesTraceparent(t *testing.T) {
+	var buf bytes.Buffer
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	tracer := tracing.New(log, "test", tracing.NewWriterExporter(&buf))
+
+	var inner tracing.SpanContext
+	router := http.NewServeMux()
+	router.HandleFunc("GET /quotes/{id}", func(w http.ResponseWriter, r *http.Request) {
+		inner = tracing.SpanContextFromContext(r.Context())
+		w.WriteHeader(http.StatusTeapot)
+	})
+	handler := Trace(tracer)(router)
+
+	r := httptest.NewRequest(http.MethodGet, "/quotes/1", nil)
+	r.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+
+	if inner.TraceID.String() != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("expected incoming trace id, got %s", inner.TraceID)
+	}
+	if err := tracer.Shutdown(context.Background()); err != nil {
+		t.Fatalf("shutdown: %v", err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, `"name":"GET /quotes/{id}"`) {
+		t.Errorf("expected span named after route, got %s", out)
+	}
+	if !strings.Contains(out, `"http.response.status_code":418`) {
+		t.Errorf("expected status code attribute, got %s", out)
+	}
+}
@@ -0,0 +1,123 @@
+package middleware
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"quotes-mini-service/pkg/openapi"
+	"quotes-mini-service/pkg/res"
+	"quotes-mini-service/pkg/sl"
+)
+
+type ValidateOptions struct {
+	Requests bool
+	// Responses checks what handlers write as well. JSON bodies are copied
+	// in memory, so it is meant for debugging and tests.
+	Responses bool
+	// OnViolation is called for responses that break the document. By
+	// default they are logged.
+	OnViolation func(r *http.Request, err error)
+}
+
+// Validate checks requests to the route registered under pattern against
+// its operation in doc and answers 400 with the offending fields. Routes
+// missing from doc are passed through.
+func Validate(log *slog.Logger, doc *openapi.Document, pattern string, opts ValidateOptions) func(next http.Handler) http.Handler {
+	log = log.With(slog.String("route", pattern))
+	violation := opts.OnViolation
+	if violation == nil {
+		violation = func(r *http.Request, err error) {
+			log.Error("response does not match the document", sl.Err(err))
+		}
+	}
+	return func(next http.Handler) http.Handler {
+		op, ok := doc.Operation(pattern)
+		if !ok || !opts.Requests && !opts.Responses {
+			return next
+		}
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			if opts.Requests {
+				var body []byte
+				if op.RequestBody != nil {
+					var err error
+					body, err = io.ReadAll(r.Body)
+					if err != nil {
+						var maxErr *http.MaxBytesError
+						if errors.As(err, &maxErr) {
+							res.Json(w, res.Error("request body too large"), http.StatusRequestEntityTooLarge)
+							return
+						}
+						res.Json(w, res.Error("failed to read request body"), http.StatusBadRequest)
+						return
+					}
+					r.Body = io.NopCloser(bytes.NewReader(body))
+				}
+				if err := doc.ValidateRequest(op, r, body); err != nil {
+					log.Info("request does not match the document", sl.Err(err))
+					res.Json(w, invalid(err), http.StatusBadRequest)
+					return
+				}
+			}
+			if !opts.Responses {
+				next.ServeHTTP(w, r)
+				return
+			}
+			cw := &contractWriter{ResponseWriter: w}
+			next.ServeHTTP(cw, r)
+			// Hijacked connections never write through cw.
+			if !cw.wroteHeader {
+				return
+			}
+			if err := doc.ValidateResponse(op, cw.status, cw.Header().Get("Content-Type"), cw.body.Bytes()); err != nil {
+				violation(r, err)
+			}
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
+func invalid(err error) res.Response {
+	resp := res.Error("request does not match the schema")
+	var verr *openapi.ValidationError
+	if errors.As(err, &verr) {
+		for _, fe := range verr.Errors {
+			resp.Details = append(resp.Details, res.FieldError{Field: fe.Path, Message: fe.Message})
+		}
+	}
+	return resp
+}
+
+// contractWriter copies JSON bodies for validation; streams and other
+// formats are passed through untouched.
+type contractWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	json        bool
+	body        bytes.Buffer
+}
+
+func (cw *contractWriter) WriteHeader(code int) {
+	if !cw.wroteHeader {
+		cw.wroteHeader = true
+		cw.status = code
+		cw.json = openapi.IsJSON(cw.Header().Get("Content-Type"))
+	}
+	cw.ResponseWriter.WriteHeader(code)
+}
+
+func (cw *contractWriter) Write(p []byte) (int, error) {
+	if !cw.wroteHeader {
+		cw.WriteHeader(http.StatusOK)
+	}
+	if cw.json {
+		cw.body.Write(p)
+	}
+	return cw.ResponseWriter.Write(p)
+}
+
+func (cw *contractWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
@@ -0,0 +1,126 @@
+package middleware
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"quotes-mini-service/pkg/openapi"
+	"quotes-mini-service/pkg/res"
+	"strings"
+	"testing"
+)
+
+type validateRequest struct {
+	Author string `json:"author"`
+	Quote  string `json:"quote"`
+}
+
+type validateResponse struct {
+	ID int `json:"id"`
+}
+
+func newValidateDocument(t *testing.T) *openapi.Document {
+	b := openapi.NewBuilder(openapi.Info{Title: "test", Version: "1"})
+	err := b.Add("PUT /quotes/{id}", openapi.Operation{
+		Query:     []openapi.Param{{Name: "limit", Schema: &openapi.Schema{Type: "integer"}}},
+		Request:   validateRequest{},
+		Responses: map[int]any{200: validateResponse{}, 400: res.Response{}},
+	})
+	if err != nil {
+		t.Fatalf("failed to document route: %v", err)
+	}
+	return b.Document()
+}
+
+func TestValidate_Requests(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	doc := newValidateDocument(t)
+	tests := []struct {
+		name    string
+		path    string
+		body    string
+		status  int
+		details []string
+	}{
+		{"valid", "/quotes/1?limit=5", `{"author": "Confucius", "quote": "q"}`, http.StatusOK, nil},
+		{"bad path", "/quotes/x", `{"author": "Confucius", "quote": "q"}`, http.StatusBadRequest, []string{"/path/id"}},
+		{"bad query", "/quotes/1?limit=many", `{"author": "Confucius", "quote": "q"}`, http.StatusBadRequest, []string{"/query/limit"}},
+		{"missing field", "/quotes/1", `{"author": "Confucius"}`, http.StatusBadRequest, []string{"/body/quote"}},
+		{"wrong types", "/quotes/1", `{"author": 1, "quote": "q", "extra": true}`, http.StatusBadRequest, []string{"/body/author", "/body/extra"}},
+		{"no body", "/quotes/1", "", http.StatusBadRequest, []string{"/body"}},
+	}
+	for _, tt := range tests {
+		router := http.NewServeMux()
+		var got string
+		router.Handle("PUT /quotes/{id}", Validate(log, doc, "PUT /quotes/{id}", ValidateOptions{Requests: true})(
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var req validateRequest
+				json.NewDecoder(r.Body).Decode(&req)
+				got = req.Author
+				res.Json(w, validateResponse{ID: 1}, http.StatusOK)
+			})))
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodPut, tt.path, strings.NewReader(tt.body)))
+		if rr.Code != tt.status {
+			t.Errorf("%s: expected status %d, got %d", tt.name, tt.status, rr.Code)
+			continue
+		}
+		if tt.status == http.StatusOK {
+			if got != "Confucius" {
+				t.Errorf("%s: expected the handler to read the body, got %q", tt.name, got)
+			}
+			continue
+		}
+		var resp res.Response
+		if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+			t.Fatalf("%s: failed to decode response: %v", tt.name, err)
+		}
+		var fields []string
+		for _, d := range resp.Details {
+			fields = append(fields, d.Field)
+		}
+		if strings.Join(fields, ",") != strings.Join(tt.details, ",") {
+			t.Errorf("%s: expected details for %v, got %+v", tt.name, tt.details, resp.Details)
+		}
+	}
+}
+
+func TestValidate_Responses(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	doc := newValidateDocument(t)
+	tests := []struct {
+		name      string
+		write     func(w http.ResponseWriter)
+		violation bool
+	}{
+		{"valid", func(w http.ResponseWriter) { res.Json(w, validateResponse{ID: 1}, http.StatusOK) }, false},
+		{"wrong body", func(w http.ResponseWriter) { res.Json(w, map[string]string{"id": "1"}, http.StatusOK) }, true},
+		{"undocumented status", func(w http.ResponseWriter) { res.Json(w, res.Error("gone"), http.StatusGone) }, true},
+		{"not json", func(w http.ResponseWriter) { w.Write([]byte("ok")) }, true},
+	}
+	for _, tt := range tests {
+		var violations int
+		h := Validate(log, doc, "PUT /quotes/{id}", ValidateOptions{
+			Responses:   true,
+			OnViolation: func(r *http.Request, err error) { violations++ },
+		})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { tt.write(w) }))
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest(http.MethodPut, "/quotes/1", nil))
+		if tt.violation != (violations > 0) {
+			t.Errorf("%s: expected violation %v, got %d", tt.name, tt.violation, violations)
+		}
+	}
+}
+
+func TestValidate_UndocumentedRoute(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	h := Validate(log, newValidateDocument(t), "GET /elsewhere", ValidateOptions{Requests: true, Responses: true})(next)
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/elsewhere?x=1", nil))
+	if rr.Code != http.StatusOK {
+		t.Errorf("expected undocumented routes to pass, got %d", rr.Code)
+	}
+}
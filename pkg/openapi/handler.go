@@ -0,0 +1,57 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	swaggerfiles "github.com/swaggo/files/v2"
+)
+
+// uiPolicy relaxes the API-wide Content-Security-Policy just enough for the
+// Swagger UI bundle, which is served from this origin and needs inline
+// styles and data: images.
+const uiPolicy = "default-src 'none'; script-src 'self'; style-src 'self' 'unsafe-inline'; " +
+	"img-src 'self' data:; connect-src 'self'; frame-ancestors 'none'"
+
+// Handler serves the document as JSON.
+func Handler(doc *Document) (http.HandlerFunc, error) {
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("openapi.Handler: encode document: %w", err)
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(data)
+	}, nil
+}
+
+// UI serves the embedded Swagger UI under prefix, pointed at specURL.
+func UI(prefix, specURL string) http.Handler {
+	prefix = strings.TrimSuffix(prefix, "/")
+	initializer := fmt.Sprintf(`window.onload = function() {
+  window.ui = SwaggerUIBundle({
+    url: %q,
+    dom_id: "#swagger-ui",
+    deepLinking: true,
+    presets: [SwaggerUIBundle.presets.apis, SwaggerUIStandalonePreset],
+    plugins: [SwaggerUIBundle.plugins.DownloadUrl],
+    layout: "StandaloneLayout"
+  });
+};
+`, specURL)
+	files := http.StripPrefix(prefix, http.FileServerFS(swaggerfiles.FS))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Security-Policy", uiPolicy)
+		switch strings.TrimPrefix(r.URL.Path, prefix) {
+		case "":
+			http.Redirect(w, r, prefix+"/", http.StatusMovedPermanently)
+		case "/swagger-initializer.js":
+			w.Header().Set("Content-Type", "text/javascript; charset=utf-8")
+			w.Write([]byte(initializer))
+		default:
+			files.ServeHTTP(w, r)
+		}
+	})
+}
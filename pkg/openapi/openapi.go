@@ -0,0 +1,274 @@
+// Package openapi builds an OpenAPI 3.1 document from route patterns and
+// the Go types handlers read and write, and validates values against it.
+package openapi
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const Version = "3.1.0"
+
+type Document struct {
+	OpenAPI    string               `json:"openapi"`
+	Info       Info                 `json:"info"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Components Components           `json:"components"`
+	// routes maps mux patterns to their operations, for lookups at runtime.
+	routes map[string]*OperationObject
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema         `json:"schemas"`
+	SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+	Name         string `json:"name,omitempty"`
+	In           string `json:"in,omitempty"`
+}
+
+type PathItem map[string]*OperationObject
+
+type OperationObject struct {
+	OperationID string                `json:"operationId,omitempty"`
+	Summary     string                `json:"summary,omitempty"`
+	Tags        []string              `json:"tags,omitempty"`
+	Parameters  []*Parameter          `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]*Response  `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+	Deprecated  bool                  `json:"deprecated,omitempty"`
+}
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                  `json:"required"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string                `json:"description"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Operation describes a route. Request and the values of Responses are
+// sample values of the Go types read and written as JSON; a nil response
+// has no body and a Media response is not JSON.
+type Operation struct {
+	Summary   string
+	Tags      []string
+	Query     []Param
+	Request   any
+	Responses map[int]any
+	// Scope is required from the caller when authentication is on.
+	Scope      string
+	Deprecated bool
+}
+
+// Param is a query parameter. Path parameters come from the route pattern
+// and are integers unless Builder.Path says otherwise.
+type Param struct {
+	Name        string
+	Description string
+	Required    bool
+	Schema      *Schema
+}
+
+// Media is a response body in some other format than JSON.
+type Media struct {
+	Type   string
+	Schema *Schema
+}
+
+type Builder struct {
+	doc     *Document
+	schemas *schemas
+	// Path overrides the schema of path parameters by name.
+	Path map[string]*Schema
+}
+
+func NewBuilder(info Info) *Builder {
+	g := newSchemas()
+	return &Builder{
+		doc: &Document{
+			OpenAPI:    Version,
+			Info:       info,
+			Paths:      map[string]*PathItem{},
+			Components: Components{Schemas: g.components},
+			routes:     map[string]*OperationObject{},
+		},
+		schemas: g,
+	}
+}
+
+// Security declares the ways callers authenticate. Operations with a
+// scope accept any of them.
+func (b *Builder) Security(schemes map[string]*SecurityScheme) {
+	b.doc.Components.SecuritySchemes = schemes
+}
+
+// Add documents the route registered under a ServeMux pattern such as
+// "GET /quotes/{id}".
+func (b *Builder) Add(pattern string, op Operation) error {
+	method, path, ok := strings.Cut(pattern, " ")
+	if !ok || method == "" || !strings.HasPrefix(path, "/") {
+		return fmt.Errorf("pattern %q needs a method and a path", pattern)
+	}
+	if _, ok := b.doc.routes[pattern]; ok {
+		return fmt.Errorf("pattern %q is already documented", pattern)
+	}
+	if len(op.Responses) == 0 {
+		return fmt.Errorf("pattern %q has no responses", pattern)
+	}
+	o := &OperationObject{
+		OperationID: operationID(method, path),
+		Summary:     op.Summary,
+		Tags:        op.Tags,
+		Responses:   map[string]*Response{},
+		Deprecated:  op.Deprecated,
+	}
+	for _, name := range pathParams(path) {
+		schema, ok := b.Path[name]
+		if !ok {
+			schema = &Schema{Type: "integer"}
+		}
+		o.Parameters = append(o.Parameters, &Parameter{Name: name, In: "path", Required: true, Schema: schema})
+	}
+	for _, p := range op.Query {
+		schema := p.Schema
+		if schema == nil {
+			schema = &Schema{Type: "string"}
+		}
+		o.Parameters = append(o.Parameters, &Parameter{
+			Name:        p.Name,
+			In:          "query",
+			Description: p.Description,
+			Required:    p.Required,
+			Schema:      schema,
+		})
+	}
+	if op.Request != nil {
+		o.RequestBody = &RequestBody{
+			Required: true,
+			Content:  map[string]*MediaType{"application/json": {Schema: b.Schema(op.Request)}},
+		}
+	}
+	for code, body := range op.Responses {
+		r := &Response{Description: http.StatusText(code)}
+		switch body := body.(type) {
+		case nil:
+		case Media:
+			r.Content = map[string]*MediaType{body.Type: {Schema: body.Schema}}
+		default:
+			r.Content = map[string]*MediaType{"application/json": {Schema: b.Schema(body)}}
+		}
+		o.Responses[strconv.Itoa(code)] = r
+	}
+	if op.Scope != "" {
+		for _, name := range b.securitySchemes() {
+			o.Security = append(o.Security, map[string][]string{name: {op.Scope}})
+		}
+	}
+	specPath := strings.ReplaceAll(path, "...}", "}")
+	item, ok := b.doc.Paths[specPath]
+	if !ok {
+		item = &PathItem{}
+		b.doc.Paths[specPath] = item
+	}
+	(*item)[strings.ToLower(method)] = o
+	b.doc.routes[pattern] = o
+	return nil
+}
+
+// Schema returns the schema of the Go type of v, registering named structs
+// as components.
+func (b *Builder) Schema(v any) *Schema {
+	return b.schemas.of(reflect.TypeOf(v))
+}
+
+func (b *Builder) Document() *Document {
+	return b.doc
+}
+
+func (b *Builder) securitySchemes() []string {
+	names := make([]string, 0, len(b.doc.Components.SecuritySchemes))
+	for name := range b.doc.Components.SecuritySchemes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Operation returns the operation documented for a mux pattern.
+func (d *Document) Operation(pattern string) (*OperationObject, bool) {
+	o, ok := d.routes[pattern]
+	return o, ok
+}
+
+// Patterns lists the documented mux patterns in order.
+func (d *Document) Patterns() []string {
+	patterns := make([]string, 0, len(d.routes))
+	for p := range d.routes {
+		patterns = append(patterns, p)
+	}
+	sort.Strings(patterns)
+	return patterns
+}
+
+func pathParams(path string) []string {
+	var names []string
+	for _, segment := range strings.Split(path, "/") {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			name := strings.TrimSuffix(strings.TrimSuffix(segment[1:len(segment)-1], "..."), "$")
+			if name != "" {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// operationID is derived from the route, e.g. "GET /quotes/{id}" becomes
+// "getQuotesById".
+func operationID(method, path string) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(method))
+	for _, segment := range strings.Split(path, "/") {
+		if segment == "" {
+			continue
+		}
+		if strings.HasPrefix(segment, "{") {
+			b.WriteString("By")
+			segment = strings.Trim(segment, "{}.$")
+		}
+		for _, part := range strings.FieldsFunc(segment, func(r rune) bool { return r == '-' || r == '_' }) {
+			b.WriteString(capitalize(part))
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,266 @@
+package openapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+type item struct {
+	ID      int        `json:"id" example:"7"`
+	Name    string     `json:"name" example:"first"`
+	Tags    []string   `json:"tags,omitempty" example:"go"`
+	Parent  *item      `json:"parent"`
+	Seen    *time.Time `json:"seen,omitempty"`
+	Ignored string     `json:"-"`
+	hidden  string
+}
+
+type page struct {
+	Items []item `json:"items"`
+	Count int    `json:"count"`
+}
+
+func newTestBuilder(t *testing.T) *Builder {
+	b := NewBuilder(Info{Title: "test", Version: "1"})
+	b.Security(map[string]*SecurityScheme{"apiKey": {Type: "apiKey", In: "header", Name: "X-API-Key"}})
+	err := b.Add("GET /items/{id}", Operation{
+		Summary:   "Get an item",
+		Query:     []Param{{Name: "q"}},
+		Responses: map[int]any{200: item{}, 404: nil},
+		Scope:     "read",
+	})
+	if err != nil {
+		t.Fatalf("failed to add operation: %v", err)
+	}
+	err = b.Add("POST /items", Operation{
+		Request:   item{},
+		Responses: map[int]any{201: page{}, 202: Media{Type: "text/plain", Schema: &Schema{Type: "string"}}},
+	})
+	if err != nil {
+		t.Fatalf("failed to add operation: %v", err)
+	}
+	return b
+}
+
+func TestBuilder(t *testing.T) {
+	b := newTestBuilder(t)
+	doc := b.Document()
+
+	if err := b.Add("GET /items/{id}", Operation{Responses: map[int]any{200: nil}}); err == nil {
+		t.Error("expected error for a duplicate pattern")
+	}
+	if err := b.Add("/items", Operation{Responses: map[int]any{200: nil}}); err == nil {
+		t.Error("expected error for a pattern without a method")
+	}
+
+	op, ok := doc.Operation("GET /items/{id}")
+	if !ok {
+		t.Fatal("expected operation to be found by pattern")
+	}
+	if op.OperationID != "getItemsById" {
+		t.Errorf("unexpected operation id %q", op.OperationID)
+	}
+	if len(op.Parameters) != 2 || op.Parameters[0].In != "path" || op.Parameters[0].Schema.Type != "integer" || op.Parameters[1].In != "query" {
+		t.Errorf("unexpected parameters: %+v", op.Parameters)
+	}
+	if len(op.Security) != 1 || !slices.Equal(op.Security[0]["apiKey"], []string{"read"}) {
+		t.Errorf("unexpected security: %v", op.Security)
+	}
+	if op.Responses["404"].Content != nil {
+		t.Error("expected 404 without content")
+	}
+	if (*doc.Paths["/items/{id}"])["get"] != op {
+		t.Error("expected operation under its path and method")
+	}
+
+	schema := doc.Components.Schemas["OpenapiItem"]
+	if schema == nil {
+		t.Fatalf("expected item component, got %v", doc.Components.Schemas)
+	}
+	if !slices.Equal(schema.Required, []string{"id", "name", "parent"}) {
+		t.Errorf("unexpected required fields: %v", schema.Required)
+	}
+	if _, ok := schema.Properties["Ignored"]; ok {
+		t.Error("expected json:\"-\" field to be skipped")
+	}
+	if schema.Properties["id"].Examples[0] != int64(7) {
+		t.Errorf("expected typed example, got %#v", schema.Properties["id"].Examples)
+	}
+	if schema.Properties["seen"].Format != "date-time" || schema.Properties["seen"].Type != "string" {
+		t.Errorf("unexpected time schema: %+v", schema.Properties["seen"])
+	}
+	if len(schema.Properties["parent"].AnyOf) != 2 {
+		t.Errorf("expected nullable reference, got %+v", schema.Properties["parent"])
+	}
+
+	data, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatalf("failed to encode document: %v", err)
+	}
+	for _, want := range []string{`"openapi":"3.1.0"`, `"additionalProperties":false`, `"$ref":"#/components/schemas/OpenapiPage"`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("expected %s in document", want)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	doc := newTestBuilder(t).Document()
+	op, _ := doc.Operation("POST /items")
+	schema := op.Responses["201"].Content["application/json"].Schema
+
+	valid := `{"items": [{"id": 1, "name": "a", "parent": null, "tags": ["x"]}], "count": 1}`
+	if err := doc.ValidateJSON(schema, []byte(valid)); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	invalid := `{"items": [{"id": 1.5, "parent": {"id": 2, "name": "b", "parent": null}, "extra": true}], "count": "1"}`
+	err := doc.ValidateJSON(schema, []byte(invalid))
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected ValidationError, got %v", err)
+	}
+	var got []string
+	for _, fe := range verr.Errors {
+		got = append(got, fe.String())
+	}
+	want := []string{
+		"/count: expected integer, got string",
+		"/items/0/name: is required",
+		"/items/0/extra: is not allowed",
+		"/items/0/id: expected integer, got number",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("unexpected errors:\n got %q\nwant %q", got, want)
+	}
+
+	if err := doc.ValidateJSON(schema, []byte(`{`)); err == nil {
+		t.Error("expected error for invalid JSON")
+	}
+}
+
+func TestValidateRequest(t *testing.T) {
+	b := newTestBuilder(t)
+	if err := b.Add("GET /search", Operation{
+		Query: []Param{
+			{Name: "page", Required: true, Schema: &Schema{Type: "integer", Minimum: ptr(1.0)}},
+			{Name: "exact", Schema: &Schema{Type: "boolean"}},
+		},
+		Responses: map[int]any{200: nil},
+	}); err != nil {
+		t.Fatalf("failed to add operation: %v", err)
+	}
+	doc := b.Document()
+	search, _ := doc.Operation("GET /search")
+	tests := []struct {
+		target string
+		want   []string
+	}{
+		{"/search?page=2&exact=true", nil},
+		{"/search", []string{"/query/page: is required"}},
+		{"/search?page=0&exact=maybe", []string{"/query/page: must be at least 1", `/query/exact: expected boolean, got "maybe"`}},
+	}
+	for _, tt := range tests {
+		err := doc.ValidateRequest(search, httptest.NewRequest(http.MethodGet, tt.target, nil), nil)
+		var got []string
+		var verr *ValidationError
+		if errors.As(err, &verr) {
+			for _, fe := range verr.Errors {
+				got = append(got, fe.String())
+			}
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%s: expected %q, got %q", tt.target, tt.want, got)
+		}
+	}
+
+	create, _ := doc.Operation("POST /items")
+	r := httptest.NewRequest(http.MethodPost, "/items", nil)
+	if err := doc.ValidateRequest(create, r, []byte(`{"id": 1, "name": "a", "parent": null}`)); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := doc.ValidateRequest(create, r, []byte(`{"id": "1"}`)); err == nil {
+		t.Error("expected error for an invalid body")
+	}
+}
+
+func TestValidateResponse(t *testing.T) {
+	doc := newTestBuilder(t).Document()
+	op, _ := doc.Operation("POST /items")
+	tests := []struct {
+		status      int
+		contentType string
+		body        string
+		ok          bool
+	}{
+		{http.StatusCreated, "application/json", `{"items": [], "count": 0}`, true},
+		{http.StatusCreated, "application/json", `{"items": []}`, false},
+		{http.StatusCreated, "text/html", `{"items": [], "count": 0}`, false},
+		{http.StatusAccepted, "text/plain", "queued", true},
+		{http.StatusTeapot, "application/json", `{}`, false},
+	}
+	for _, tt := range tests {
+		err := doc.ValidateResponse(op, tt.status, tt.contentType, []byte(tt.body))
+		if (err == nil) != tt.ok {
+			t.Errorf("%d %s: expected ok %v, got %v", tt.status, tt.body, tt.ok, err)
+		}
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestHandlers(t *testing.T) {
+	doc := newTestBuilder(t).Document()
+	spec, err := Handler(doc)
+	if err != nil {
+		t.Fatalf("failed to create handler: %v", err)
+	}
+	rr := httptest.NewRecorder()
+	spec(rr, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	if rr.Code != http.StatusOK || rr.Header().Get("Content-Type") != "application/json" {
+		t.Errorf("unexpected response: %d %s", rr.Code, rr.Header().Get("Content-Type"))
+	}
+	var served Document
+	if err := json.Unmarshal(rr.Body.Bytes(), &served); err != nil {
+		t.Fatalf("failed to decode served document: %v", err)
+	}
+	if _, ok := served.Paths["/items/{id}"]; !ok {
+		t.Error("expected paths in the served document")
+	}
+	if item := served.Components.Schemas["OpenapiItem"]; item == nil || !item.Closed || !slices.Equal(item.Properties["seen"].Types(), []string{"string"}) {
+		t.Errorf("expected schemas to survive a round trip, got %+v", item)
+	}
+
+	ui := UI("/docs/", "/openapi.json")
+	tests := []struct {
+		path   string
+		status int
+		body   string
+	}{
+		{path: "/docs/", status: http.StatusOK, body: "swagger-ui"},
+		{path: "/docs/swagger-initializer.js", status: http.StatusOK, body: `url: "/openapi.json"`},
+		{path: "/docs/swagger-ui-bundle.js", status: http.StatusOK},
+		{path: "/docs", status: http.StatusMovedPermanently},
+	}
+	for _, tt := range tests {
+		rr := httptest.NewRecorder()
+		ui.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if rr.Code != tt.status {
+			t.Errorf("%s: expected status %d, got %d", tt.path, tt.status, rr.Code)
+		}
+		if !strings.Contains(rr.Body.String(), tt.body) {
+			t.Errorf("%s: expected %q in body", tt.path, tt.body)
+		}
+		if !strings.Contains(rr.Header().Get("Content-Security-Policy"), "script-src 'self'") {
+			t.Errorf("%s: expected relaxed Content-Security-Policy, got %q", tt.path, rr.Header().Get("Content-Security-Policy"))
+		}
+	}
+}
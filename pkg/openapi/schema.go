@@ -0,0 +1,289 @@
+package openapi
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Schema is the subset of JSON Schema 2020-12 the service needs. Type is a
+// string, or a list when a value may also be null.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 any                `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	// Closed forbids properties that are not listed; it is written as
+	// additionalProperties: false.
+	Closed   bool      `json:"-"`
+	AnyOf    []*Schema `json:"anyOf,omitempty"`
+	Examples []any     `json:"examples,omitempty"`
+}
+
+func (s Schema) MarshalJSON() ([]byte, error) {
+	type plain Schema
+	data, err := json.Marshal(plain(s))
+	if err != nil || !s.Closed {
+		return data, err
+	}
+	// Splice the boolean form in; the struct field can only hold a schema.
+	if len(data) == 2 {
+		return []byte(`{"additionalProperties":false}`), nil
+	}
+	return append(data[:len(data)-1], []byte(`,"additionalProperties":false}`)...), nil
+}
+
+// UnmarshalJSON reads documents served by Handler back, for clients and
+// tests.
+func (s *Schema) UnmarshalJSON(data []byte) error {
+	type plain Schema
+	var raw struct {
+		plain
+		AdditionalProperties json.RawMessage `json:"additionalProperties,omitempty"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*s = Schema(raw.plain)
+	if types, ok := s.Type.([]any); ok {
+		names := make([]string, len(types))
+		for i, t := range types {
+			names[i], _ = t.(string)
+		}
+		s.Type = names
+	}
+	switch string(raw.AdditionalProperties) {
+	case "", "true":
+	case "false":
+		s.Closed = true
+	default:
+		s.AdditionalProperties = &Schema{}
+		return json.Unmarshal(raw.AdditionalProperties, s.AdditionalProperties)
+	}
+	return nil
+}
+
+// Types lists the JSON types the schema allows.
+func (s *Schema) Types() []string {
+	switch t := s.Type.(type) {
+	case string:
+		return []string{t}
+	case []string:
+		return t
+	}
+	return nil
+}
+
+var (
+	timeType      = reflect.TypeFor[time.Time]()
+	rawType       = reflect.TypeFor[json.RawMessage]()
+	marshalerType = reflect.TypeFor[json.Marshaler]()
+	textType      = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+// schemas turns Go types into schemas. Named structs become components
+// referenced with $ref, everything else is inlined.
+type schemas struct {
+	components map[string]*Schema
+	names      map[reflect.Type]string
+}
+
+func newSchemas() *schemas {
+	return &schemas{components: map[string]*Schema{}, names: map[reflect.Type]string{}}
+}
+
+func (g *schemas) of(t reflect.Type) *Schema {
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t == rawType:
+		return &Schema{}
+	case t.Kind() == reflect.Pointer:
+		return nullable(g.of(t.Elem()))
+	case t.Implements(marshalerType):
+		return &Schema{}
+	case t.Implements(textType):
+		return &Schema{Type: "string"}
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: g.of(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.of(t.Elem())}
+	case reflect.Struct:
+		return g.object(t)
+	}
+	return &Schema{}
+}
+
+func (g *schemas) object(t reflect.Type) *Schema {
+	if t.Name() == "" {
+		return g.fields(t)
+	}
+	name, ok := g.names[t]
+	if !ok {
+		name = g.componentName(t)
+		g.names[t] = name
+		g.components[name] = g.fields(t)
+	}
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+// componentName prefixes the package, and the domain for handler packages,
+// so that quote/handlers/save.Request becomes QuoteSaveRequest and does not
+// collide with other requests.
+func (g *schemas) componentName(t reflect.Type) string {
+	segments := strings.Split(t.PkgPath(), "/")
+	n := len(segments)
+	name := t.Name()
+	if !hasWordPrefix(name, segments[n-1]) {
+		name = capitalize(segments[n-1]) + capitalize(name)
+	}
+	if n >= 3 && segments[n-2] == "handlers" && !hasWordPrefix(name, segments[n-3]) {
+		name = capitalize(segments[n-3]) + name
+	}
+	base := name
+	for i := 2; g.components[name] != nil; i++ {
+		name = base + strconv.Itoa(i)
+	}
+	return name
+}
+
+// hasWordPrefix reports whether name starts with the word prefix, so that
+// "GetWithParamResponse" starts with "get" but "Response" not with "res".
+func hasWordPrefix(name, prefix string) bool {
+	if len(name) < len(prefix) || !strings.EqualFold(name[:len(prefix)], prefix) {
+		return false
+	}
+	return len(name) == len(prefix) || unicode.IsUpper(rune(name[len(prefix)]))
+}
+
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+func (g *schemas) fields(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: map[string]*Schema{}, Closed: true}
+	g.addFields(s, t)
+	return s
+}
+
+func (g *schemas) addFields(s *Schema, t reflect.Type) {
+	for i := range t.NumField() {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				g.addFields(s, ft)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		prop := g.of(f.Type)
+		omitempty := strings.Contains(opts, "omitempty")
+		if omitempty && f.Type.Kind() == reflect.Pointer {
+			// Omitted rather than null.
+			prop = g.of(f.Type.Elem())
+		}
+		if example, ok := f.Tag.Lookup("example"); ok {
+			prop = withExample(prop, f.Type, example)
+		}
+		s.Properties[name] = prop
+		if !omitempty {
+			s.Required = append(s.Required, name)
+		}
+	}
+}
+
+func nullable(s *Schema) *Schema {
+	switch {
+	case s.Ref != "":
+		return &Schema{AnyOf: []*Schema{s, {Type: "null"}}}
+	case s.Type == nil:
+		return s
+	}
+	cp := *s
+	cp.Type = append(cp.Types(), "null")
+	return &cp
+}
+
+// withExample parses the example tag for the field's kind; slices get a
+// one-element list.
+func withExample(s *Schema, t reflect.Type, example string) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	var v any
+	if t.Kind() == reflect.Slice && t.Elem().Kind() != reflect.Uint8 {
+		v = []any{parseExample(t.Elem(), example)}
+	} else {
+		v = parseExample(t, example)
+	}
+	if s.Ref != "" || len(s.AnyOf) > 0 {
+		return s
+	}
+	cp := *s
+	cp.Examples = []any{v}
+	return &cp
+}
+
+func parseExample(t reflect.Type, example string) any {
+	switch t.Kind() {
+	case reflect.Bool:
+		if b, err := strconv.ParseBool(example); err == nil {
+			return b
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if n, err := strconv.ParseInt(example, 10, 64); err == nil {
+			return n
+		}
+	case reflect.Float32, reflect.Float64:
+		if n, err := strconv.ParseFloat(example, 64); err == nil {
+			return n
+		}
+	}
+	return example
+}
@@ -0,0 +1,297 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// FieldError is one place where a value does not match its schema. Path
+// is a JSON pointer, empty for the value itself.
+type FieldError struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+func (e FieldError) String() string {
+	if e.Path == "" {
+		return e.Message
+	}
+	return e.Path + ": " + e.Message
+}
+
+type ValidationError struct {
+	Errors []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, fe := range e.Errors {
+		msgs[i] = fe.String()
+	}
+	return "schema validation failed: " + strings.Join(msgs, "; ")
+}
+
+// ValidateJSON decodes data and validates it against s.
+func (d *Document) ValidateJSON(s *Schema, data []byte) error {
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return &ValidationError{Errors: []FieldError{{Message: "invalid JSON: " + err.Error()}}}
+	}
+	return d.Validate(s, v)
+}
+
+// Validate checks a value decoded by encoding/json against s.
+func (d *Document) Validate(s *Schema, v any) error {
+	var errs []FieldError
+	d.validate(s, v, "", &errs)
+	if len(errs) > 0 {
+		return &ValidationError{Errors: errs}
+	}
+	return nil
+}
+
+// Resolve follows a $ref to a component schema.
+func (d *Document) Resolve(s *Schema) *Schema {
+	for s != nil && s.Ref != "" {
+		s = d.Components.Schemas[strings.TrimPrefix(s.Ref, "#/components/schemas/")]
+	}
+	return s
+}
+
+// ValidateRequest checks the path and query parameters of r and, when op
+// takes a body, the JSON in body. Errors point into "/path", "/query" and
+// "/body".
+func (d *Document) ValidateRequest(op *OperationObject, r *http.Request, body []byte) error {
+	var errs []FieldError
+	for _, p := range op.Parameters {
+		var raw string
+		var ok bool
+		switch p.In {
+		case "path":
+			raw = r.PathValue(p.Name)
+			ok = raw != ""
+		case "query":
+			ok = r.URL.Query().Has(p.Name)
+			raw = r.URL.Query().Get(p.Name)
+		default:
+			continue
+		}
+		path := "/" + p.In + "/" + p.Name
+		if !ok {
+			if p.Required {
+				errs = append(errs, FieldError{Path: path, Message: "is required"})
+			}
+			continue
+		}
+		schema := d.Resolve(p.Schema)
+		v, err := parseParam(schema, raw)
+		if err != nil {
+			errs = append(errs, FieldError{Path: path, Message: err.Error()})
+			continue
+		}
+		d.validate(schema, v, path, &errs)
+	}
+	if op.RequestBody != nil {
+		if media, ok := op.RequestBody.Content["application/json"]; ok {
+			d.validateBody(media.Schema, body, op.RequestBody.Required, &errs)
+		}
+	}
+	if len(errs) > 0 {
+		return &ValidationError{Errors: errs}
+	}
+	return nil
+}
+
+// ValidateResponse checks that status is documented for op with the media
+// type of contentType, and that a JSON body matches its schema.
+func (d *Document) ValidateResponse(op *OperationObject, status int, contentType string, body []byte) error {
+	resp, ok := op.Responses[strconv.Itoa(status)]
+	if !ok {
+		return &ValidationError{Errors: []FieldError{{Message: fmt.Sprintf("status %d is not documented", status)}}}
+	}
+	if len(resp.Content) == 0 {
+		return nil
+	}
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	media, ok := resp.Content[mediaType]
+	if !ok {
+		types := slices.Sorted(maps.Keys(resp.Content))
+		return &ValidationError{Errors: []FieldError{{Message: fmt.Sprintf("expected %s, got %q", strings.Join(types, " or "), contentType)}}}
+	}
+	if !IsJSON(contentType) {
+		return nil
+	}
+	var errs []FieldError
+	d.validateBody(media.Schema, body, true, &errs)
+	if len(errs) > 0 {
+		return &ValidationError{Errors: errs}
+	}
+	return nil
+}
+
+// IsJSON reports whether contentType is JSON, including +json types such as
+// application/problem+json.
+func IsJSON(contentType string) bool {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	return mediaType == "application/json" || strings.HasPrefix(mediaType, "application/") && strings.HasSuffix(mediaType, "+json")
+}
+
+func (d *Document) validateBody(s *Schema, body []byte, required bool, errs *[]FieldError) {
+	if len(strings.TrimSpace(string(body))) == 0 {
+		if required {
+			*errs = append(*errs, FieldError{Path: "/body", Message: "is required"})
+		}
+		return
+	}
+	var v any
+	if err := json.Unmarshal(body, &v); err != nil {
+		*errs = append(*errs, FieldError{Path: "/body", Message: "invalid JSON: " + err.Error()})
+		return
+	}
+	d.validate(s, v, "/body", errs)
+}
+
+// parseParam converts a path or query value to the JSON type of its schema.
+func parseParam(s *Schema, raw string) (any, error) {
+	if s == nil {
+		return raw, nil
+	}
+	types := s.Types()
+	switch {
+	case slices.Contains(types, "integer"):
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("expected integer, got %q", raw)
+		}
+		return float64(n), nil
+	case slices.Contains(types, "number"):
+		n, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, fmt.Errorf("expected number, got %q", raw)
+		}
+		return n, nil
+	case slices.Contains(types, "boolean"):
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("expected boolean, got %q", raw)
+		}
+		return b, nil
+	}
+	return raw, nil
+}
+
+func (d *Document) validate(s *Schema, v any, path string, errs *[]FieldError) {
+	fail := func(format string, args ...any) {
+		*errs = append(*errs, FieldError{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+	if s.Ref != "" {
+		resolved := d.Resolve(s)
+		if resolved == nil {
+			fail("unknown schema %s", s.Ref)
+			return
+		}
+		s = resolved
+	}
+	if len(s.AnyOf) > 0 {
+		for _, alt := range s.AnyOf {
+			var altErrs []FieldError
+			d.validate(alt, v, path, &altErrs)
+			if len(altErrs) == 0 {
+				return
+			}
+		}
+		fail("does not match any allowed schema")
+		return
+	}
+	if types := s.Types(); len(types) > 0 && !slices.Contains(types, jsonType(v)) &&
+		!(jsonType(v) == "integer" && slices.Contains(types, "number")) {
+		fail("expected %s, got %s", strings.Join(types, " or "), jsonType(v))
+		return
+	}
+	if len(s.Enum) > 0 && !slices.ContainsFunc(s.Enum, func(e any) bool { return fmt.Sprint(e) == fmt.Sprint(v) }) {
+		fail("must be one of %v", s.Enum)
+	}
+	switch v := v.(type) {
+	case string:
+		n := utf8.RuneCountInString(v)
+		if s.MinLength != nil && n < *s.MinLength {
+			fail("must be at least %d characters long", *s.MinLength)
+		}
+		if s.MaxLength != nil && n > *s.MaxLength {
+			fail("must be at most %d characters long", *s.MaxLength)
+		}
+		if s.Format == "date-time" {
+			if _, err := time.Parse(time.RFC3339, v); err != nil {
+				fail("must be an RFC 3339 date-time")
+			}
+		}
+	case float64:
+		if s.Minimum != nil && v < *s.Minimum {
+			fail("must be at least %v", *s.Minimum)
+		}
+		if s.Maximum != nil && v > *s.Maximum {
+			fail("must be at most %v", *s.Maximum)
+		}
+	case []any:
+		if s.MinItems != nil && len(v) < *s.MinItems {
+			fail("must have at least %d items", *s.MinItems)
+		}
+		if s.Items != nil {
+			for i, item := range v {
+				d.validate(s.Items, item, fmt.Sprintf("%s/%d", path, i), errs)
+			}
+		}
+	case map[string]any:
+		for _, name := range s.Required {
+			if _, ok := v[name]; !ok {
+				*errs = append(*errs, FieldError{Path: path + "/" + name, Message: "is required"})
+			}
+		}
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+		for _, name := range names {
+			prop, ok := s.Properties[name]
+			switch {
+			case ok:
+				d.validate(prop, v[name], path+"/"+name, errs)
+			case s.AdditionalProperties != nil:
+				d.validate(s.AdditionalProperties, v[name], path+"/"+name, errs)
+			case s.Closed:
+				*errs = append(*errs, FieldError{Path: path + "/" + name, Message: "is not allowed"})
+			}
+		}
+	}
+}
+
+func jsonType(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		if v == math.Trunc(v) && !math.IsInf(v, 0) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
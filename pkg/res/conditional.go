@@ -0,0 +1,66 @@
+package res
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// NotModified sets the ETag and Last-Modified validators on the response that
+// Render would make of data and answers with 304 when the request's
+// conditional headers show the client already has this version. The ETag
+// names the negotiated media type, so that each representation has its own.
+// If-None-Match takes precedence over If-Modified-Since. It reports whether
+// the response has been written.
+func NotModified(w http.ResponseWriter, r *http.Request, data any, etag string, modified time.Time) bool {
+	media := Negotiate(r.Header.Get("Accept"), offers(data))
+	if media == "" {
+		// Render answers 406; there is no representation to validate.
+		return false
+	}
+	h := w.Header()
+	if etag != "" {
+		etag = variant(etag, media)
+		h.Set("ETag", etag)
+	}
+	if !modified.IsZero() {
+		h.Set("Last-Modified", modified.UTC().Format(http.TimeFormat))
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		if etag == "" || !matchETag(inm, etag) {
+			return false
+		}
+	} else {
+		ims, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+		if err != nil || modified.IsZero() || modified.Truncate(time.Second).After(ims) {
+			return false
+		}
+	}
+	h.Del("Content-Type")
+	h.Del("Content-Length")
+	h.Add("Vary", "Accept")
+	w.WriteHeader(http.StatusNotModified)
+	return true
+}
+
+// variant appends the subtype of media to the opaque part of etag, so
+// W/"1-abc" becomes W/"1-abc-json".
+func variant(etag, media string) string {
+	_, subtype, _ := strings.Cut(media, "/")
+	return strings.TrimSuffix(etag, `"`) + "-" + subtype + `"`
+}
+
+// matchETag uses the weak comparison function, as required for If-None-Match.
+func matchETag(header, etag string) bool {
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
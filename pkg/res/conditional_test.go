@@ -0,0 +1,75 @@
+package res
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestNotModified(t *testing.T) {
+	modified := time.Date(2025, 5, 29, 12, 0, 0, 500, time.UTC)
+	tests := []struct {
+		name   string
+		header string
+		value  string
+		want   bool
+	}{
+		{"no conditions", "", "", false},
+		{"matching etag", "If-None-Match", `W/"1-abc-json"`, true},
+		{"strong form matches weakly", "If-None-Match", `"1-abc-json"`, true},
+		{"list", "If-None-Match", `"x", W/"1-abc-json"`, true},
+		{"other representation", "If-None-Match", `W/"1-abc-xml"`, false},
+		{"wildcard", "If-None-Match", "*", true},
+		{"other etag", "If-None-Match", `W/"2-def"`, false},
+		{"same time", "If-Modified-Since", modified.Format(http.TimeFormat), true},
+		{"older copy", "If-Modified-Since", modified.Add(-time.Hour).Format(http.TimeFormat), false},
+		{"bad date", "If-Modified-Since", "yesterday", false},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, "/quotes", nil)
+		if tt.header != "" {
+			r.Header.Set(tt.header, tt.value)
+		}
+		w := httptest.NewRecorder()
+		got := NotModified(w, r, nil, `W/"1-abc"`, modified)
+		if got != tt.want {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, got)
+		}
+		if got && w.Code != http.StatusNotModified {
+			t.Errorf("%s: expected status 304, got %d", tt.name, w.Code)
+		}
+		if got && w.Header().Get("Vary") != "Accept" {
+			t.Errorf("%s: expected Vary: Accept on 304", tt.name)
+		}
+		if w.Header().Get("ETag") != `W/"1-abc-json"` || w.Header().Get("Last-Modified") != "Thu, 29 May 2025 12:00:00 GMT" {
+			t.Errorf("%s: missing validators %v", tt.name, w.Header())
+		}
+	}
+}
+
+func TestNotModified_PerMediaType(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/quotes", nil)
+	r.Header.Set("Accept", "application/xml")
+	w := httptest.NewRecorder()
+	if NotModified(w, r, nil, `W/"1-abc"`, time.Time{}) {
+		t.Fatal("expected no 304 without conditions")
+	}
+	etag := w.Header().Get("ETag")
+	if etag != `W/"1-abc-xml"` {
+		t.Fatalf("expected the xml variant, got %s", etag)
+	}
+
+	r.Header.Set("If-None-Match", etag)
+	if !NotModified(httptest.NewRecorder(), r, nil, `W/"1-abc"`, time.Time{}) {
+		t.Error("expected 304 for the same representation")
+	}
+	r.Header.Set("Accept", "application/json")
+	if NotModified(httptest.NewRecorder(), r, nil, `W/"1-abc"`, time.Time{}) {
+		t.Error("expected the xml etag not to validate json")
+	}
+	r.Header.Set("Accept", "text/csv")
+	if NotModified(httptest.NewRecorder(), r, nil, `W/"1-abc"`, time.Time{}) {
+		t.Error("expected no 304 for a media type that cannot be produced")
+	}
+}
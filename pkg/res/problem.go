@@ -0,0 +1,33 @@
+package res
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// Problem is an RFC 9457 problem details document.
+type Problem struct {
+	Type      string `json:"type" example:"about:blank"`
+	Title     string `json:"title" example:"Internal Server Error"`
+	Status    int    `json:"status" example:"500"`
+	Detail    string `json:"detail,omitempty" example:"unexpected error while handling the request"`
+	Instance  string `json:"instance,omitempty" example:"/quotes/random"`
+	RequestID string `json:"request_id,omitempty" example:"4bf92f3577b34da6a3ce929d0e0e4736"`
+	// Errors lists the fields of a request that failed validation.
+	Errors []FieldError `json:"errors,omitempty"`
+}
+
+func NewProblem(status int, detail string) Problem {
+	return Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+	}
+}
+
+func ProblemJson(w http.ResponseWriter, p Problem) {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(p.Status)
+	json.NewEncoder(w).Encode(p)
+}
@@ -0,0 +1,120 @@
+package res
+
+import (
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	MediaJSON  = "application/json"
+	MediaXML   = "application/xml"
+	MediaCSV   = "text/csv"
+	MediaPlain = "text/plain"
+)
+
+// CSVMarshaler is implemented by response types that can be rendered as CSV.
+type CSVMarshaler interface {
+	MarshalCSV() (header []string, rows [][]string)
+}
+
+// TextMarshaler is implemented by response types with a plain text form.
+type TextMarshaler interface {
+	MarshalText() string
+}
+
+// Render writes data in the representation preferred by the request's Accept
+// header. JSON and XML are always offered; CSV and plain text only when data
+// implements CSVMarshaler or TextMarshaler. If nothing acceptable can be
+// produced the response is 406.
+func Render(w http.ResponseWriter, r *http.Request, data any, code int) {
+	offers := offers(data)
+	w.Header().Add("Vary", "Accept")
+	switch Negotiate(r.Header.Get("Accept"), offers) {
+	case MediaJSON:
+		Json(w, data, code)
+	case MediaXML:
+		w.Header().Set("Content-Type", MediaXML+"; charset=utf-8")
+		w.WriteHeader(code)
+		fmt.Fprint(w, xml.Header)
+		xml.NewEncoder(w).Encode(data)
+	case MediaCSV:
+		header, rows := data.(CSVMarshaler).MarshalCSV()
+		w.Header().Set("Content-Type", MediaCSV+"; charset=utf-8")
+		w.WriteHeader(code)
+		cw := csv.NewWriter(w)
+		cw.Write(header)
+		cw.WriteAll(rows)
+	case MediaPlain:
+		w.Header().Set("Content-Type", MediaPlain+"; charset=utf-8")
+		w.WriteHeader(code)
+		fmt.Fprintln(w, data.(TextMarshaler).MarshalText())
+	default:
+		Json(w, Error("none of the acceptable media types can be produced, supported: "+strings.Join(offers, ", ")),
+			http.StatusNotAcceptable)
+	}
+}
+
+// offers are the media types Render can produce for data.
+func offers(data any) []string {
+	offers := []string{MediaJSON, MediaXML}
+	if _, ok := data.(CSVMarshaler); ok {
+		offers = append(offers, MediaCSV)
+	}
+	if _, ok := data.(TextMarshaler); ok {
+		offers = append(offers, MediaPlain)
+	}
+	return offers
+}
+
+// Negotiate returns the offer with the highest quality in the Accept header,
+// preferring earlier offers on ties. An empty header accepts the first offer.
+func Negotiate(accept string, offers []string) string {
+	if strings.TrimSpace(accept) == "" {
+		return offers[0]
+	}
+	best, bestQ := "", 0.0
+	for _, offer := range offers {
+		if q := quality(accept, offer); q > bestQ {
+			best, bestQ = offer, q
+		}
+	}
+	return best
+}
+
+// quality returns the q-value of the most specific media range matching offer.
+func quality(accept, offer string) float64 {
+	offerType, offerSub, _ := strings.Cut(offer, "/")
+	q, specificity := 0.0, -1
+	for _, part := range strings.Split(accept, ",") {
+		mediaRange, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		rangeType, rangeSub, _ := strings.Cut(strings.ToLower(strings.TrimSpace(mediaRange)), "/")
+		s := -1
+		switch {
+		case rangeType == offerType && rangeSub == offerSub:
+			s = 2
+		case rangeType == offerType && rangeSub == "*":
+			s = 1
+		case rangeType == "*" && rangeSub == "*":
+			s = 0
+		case rangeType == "text" && rangeSub == "xml" && offer == MediaXML:
+			s = 2
+		}
+		if s <= specificity {
+			continue
+		}
+		specificity, q = s, 1.0
+		for _, p := range strings.Split(params, ";") {
+			k, v, ok := strings.Cut(strings.TrimSpace(p), "=")
+			if ok && strings.TrimSpace(k) == "q" {
+				if parsed, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+					q = parsed
+				}
+			}
+		}
+	}
+	return q
+}
@@ -0,0 +1,74 @@
+package res
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type item struct {
+	Name string `json:"name" xml:"name"`
+}
+
+func (i item) MarshalCSV() ([]string, [][]string) {
+	return []string{"name"}, [][]string{{i.Name}}
+}
+
+func (i item) MarshalText() string {
+	return i.Name
+}
+
+func TestNegotiate(t *testing.T) {
+	offers := []string{MediaJSON, MediaXML, MediaCSV, MediaPlain}
+	tests := []struct {
+		accept string
+		want   string
+	}{
+		{"", MediaJSON},
+		{"*/*", MediaJSON},
+		{"text/csv", MediaCSV},
+		{"text/*", MediaCSV},
+		{"text/xml", MediaXML},
+		{"application/xml;q=0.9, */*;q=0.8", MediaXML},
+		{"text/plain, application/json;q=0.5", MediaPlain},
+		{"image/png", ""},
+	}
+	for _, tt := range tests {
+		if got := Negotiate(tt.accept, offers); got != tt.want {
+			t.Errorf("%q: expected %q, got %q", tt.accept, tt.want, got)
+		}
+	}
+}
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		accept string
+		status int
+		ctype  string
+		body   string
+	}{
+		{"application/json", http.StatusOK, MediaJSON, `{"name":"Confucius"}`},
+		{"application/xml", http.StatusOK, MediaXML, "<item><name>Confucius</name></item>"},
+		{"text/csv", http.StatusOK, MediaCSV, "name\nConfucius\n"},
+		{"text/plain", http.StatusOK, MediaPlain, "Confucius\n"},
+		{"image/png", http.StatusNotAcceptable, MediaJSON, "none of the acceptable media types"},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Accept", tt.accept)
+		w := httptest.NewRecorder()
+
+		Render(w, r, item{Name: "Confucius"}, http.StatusOK)
+
+		if w.Code != tt.status {
+			t.Errorf("%s: expected status %d, got %d", tt.accept, tt.status, w.Code)
+		}
+		if !strings.HasPrefix(w.Header().Get("Content-Type"), tt.ctype) {
+			t.Errorf("%s: unexpected content type %q", tt.accept, w.Header().Get("Content-Type"))
+		}
+		if !strings.Contains(w.Body.String(), tt.body) {
+			t.Errorf("%s: expected %q in body %q", tt.accept, tt.body, w.Body.String())
+		}
+	}
+}
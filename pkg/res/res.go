@@ -8,8 +8,15 @@ import (
 const StatusError = "Error"
 
 type Response struct {
-	Status string `json:"status"`
-	Error  string `json:"error"`
+	Status  string       `json:"status"`
+	Error   string       `json:"error"`
+	Details []FieldError `json:"details,omitempty"`
+}
+
+// FieldError points at the part of a request that failed validation.
+type FieldError struct {
+	Field   string `json:"field" example:"/body/author"`
+	Message string `json:"message" example:"is required"`
 }
 
 func Json(w http.ResponseWriter, data any, code int) {
@@ -24,3 +31,13 @@ func Error(msg string) Response {
 		Error:  msg,
 	}
 }
+
+// Pagination describes a page of a collection. Next and Prev are links to
+// the neighbouring pages and are left out at either end.
+type Pagination struct {
+	Limit  int    `json:"limit" example:"20"`
+	Offset int    `json:"offset" example:"0"`
+	Total  int    `json:"total" example:"100"`
+	Next   string `json:"next,omitempty" example:"/v2/quotes?limit=20&offset=20"`
+	Prev   string `json:"prev,omitempty"`
+}
@@ -0,0 +1,375 @@
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+type SpanKind int
+
+const (
+	KindInternal SpanKind = 1
+	KindServer   SpanKind = 2
+	KindClient   SpanKind = 3
+)
+
+type StatusCode int
+
+const (
+	StatusUnset StatusCode = 0
+	StatusOK    StatusCode = 1
+	StatusError StatusCode = 2
+)
+
+type Attribute struct {
+	Key   string
+	Value any
+}
+
+func String(key, value string) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+func Int(key string, value int) Attribute {
+	return Attribute{Key: key, Value: int64(value)}
+}
+
+func Int64(key string, value int64) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+func Bool(key string, value bool) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// SpanData is an immutable snapshot of a finished span handed to exporters.
+type SpanData struct {
+	Name          string
+	Kind          SpanKind
+	TraceID       TraceID
+	SpanID        SpanID
+	ParentSpanID  SpanID
+	Start         time.Time
+	End           time.Time
+	Attributes    []Attribute
+	StatusCode    StatusCode
+	StatusMessage string
+}
+
+type Exporter interface {
+	Export(ctx context.Context, spans []SpanData) error
+	Shutdown(ctx context.Context) error
+}
+
+// Span is recorded only when sampled. Unsampled spans still carry the trace
+// through the context so that the decision of the caller reaches the services
+// downstream.
+type Span struct {
+	tracer  *Tracer
+	sampled bool
+	mu      sync.Mutex
+	data    SpanData
+	ended   bool
+}
+
+// Span methods are safe to call on a nil span, which is what Start returns
+// when no tracer is configured.
+func (s *Span) SetAttributes(attrs ...Attribute) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	s.data.Attributes = append(s.data.Attributes, attrs...)
+	s.mu.Unlock()
+}
+
+func (s *Span) SetName(name string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	s.data.Name = name
+	s.mu.Unlock()
+}
+
+func (s *Span) SetStatus(code StatusCode, msg string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	s.data.StatusCode = code
+	s.data.StatusMessage = msg
+	s.mu.Unlock()
+}
+
+func (s *Span) RecordError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.SetStatus(StatusError, err.Error())
+}
+
+func (s *Span) SpanContext() SpanContext {
+	if s == nil {
+		return SpanContext{}
+	}
+	return SpanContext{TraceID: s.data.TraceID, SpanID: s.data.SpanID, Sampled: s.sampled}
+}
+
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.data.End = time.Now()
+	data := s.data
+	data.Attributes = append([]Attribute(nil), s.data.Attributes...)
+	s.mu.Unlock()
+	if s.sampled {
+		s.tracer.enqueue(data)
+	}
+}
+
+type Option func(*Tracer)
+
+func WithBatchSize(n int) Option {
+	return func(t *Tracer) {
+		t.batchSize = n
+	}
+}
+
+func WithFlushInterval(d time.Duration) Option {
+	return func(t *Tracer) {
+		t.flushInterval = d
+	}
+}
+
+var ErrShutdown = errors.New("tracer is shut down")
+
+type Tracer struct {
+	service       string
+	exporter      Exporter
+	log           *slog.Logger
+	batchSize     int
+	flushInterval time.Duration
+	queue         chan SpanData
+	flush         chan chan struct{}
+	done          chan struct{}
+	closeOnce     sync.Once
+}
+
+func New(log *slog.Logger, service string, exporter Exporter, opts ...Option) *Tracer {
+	t := &Tracer{
+		service:       service,
+		exporter:      exporter,
+		log:           log.With(slog.String("component", "tracing")),
+		batchSize:     256,
+		flushInterval: 5 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	t.queue = make(chan SpanData, t.batchSize*4)
+	t.flush = make(chan chan struct{})
+	t.done = make(chan struct{})
+	go t.run()
+	return t
+}
+
+func (t *Tracer) Service() string {
+	return t.service
+}
+
+// Start opens a span that is a child of the span (local or remote) carried by
+// ctx and follows its sampling decision; new traces are sampled. A nil tracer
+// yields a nil span, so instrumentation stays optional.
+func (t *Tracer) Start(ctx context.Context, name string, kind SpanKind) (context.Context, *Span) {
+	if t == nil {
+		return ctx, nil
+	}
+	span := &Span{
+		tracer:  t,
+		sampled: true,
+		data: SpanData{
+			Name:  name,
+			Kind:  kind,
+			Start: time.Now(),
+		},
+	}
+	parent := SpanContextFromContext(ctx)
+	if parent.IsValid() {
+		span.data.TraceID = parent.TraceID
+		span.data.ParentSpanID = parent.SpanID
+		span.sampled = parent.Sampled
+	} else {
+		rand.Read(span.data.TraceID[:])
+	}
+	rand.Read(span.data.SpanID[:])
+	return contextWithSpan(ctx, span), span
+}
+
+// Flush blocks until every span ended so far has been handed to the exporter.
+// After Shutdown there is nothing left to hand over and it returns
+// ErrShutdown.
+func (t *Tracer) Flush(ctx context.Context) error {
+	ack := make(chan struct{})
+	select {
+	case t.flush <- ack:
+	case <-t.done:
+		return ErrShutdown
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	select {
+	case <-ack:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Shutdown flushes the pending spans and stops the tracer. Calls after the
+// first do nothing.
+func (t *Tracer) Shutdown(ctx context.Context) error {
+	err := t.Flush(ctx)
+	if errors.Is(err, ErrShutdown) {
+		return nil
+	}
+	t.closeOnce.Do(func() {
+		close(t.done)
+	})
+	if err != nil {
+		return err
+	}
+	return t.exporter.Shutdown(ctx)
+}
+
+func (t *Tracer) enqueue(data SpanData) {
+	select {
+	case <-t.done:
+		return
+	default:
+	}
+	select {
+	case t.queue <- data:
+	default:
+		t.log.Warn("span queue is full, dropping span", slog.String("span", data.Name))
+	}
+}
+
+func (t *Tracer) run() {
+	ticker := time.NewTicker(t.flushInterval)
+	defer ticker.Stop()
+	batch := make([]SpanData, 0, t.batchSize)
+	export := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := t.exporter.Export(context.Background(), batch); err != nil {
+			t.log.Error("failed to export spans", slog.String("error", err.Error()), slog.Int("spans", len(batch)))
+		}
+		batch = make([]SpanData, 0, t.batchSize)
+	}
+	drain := func() {
+		for {
+			select {
+			case data := <-t.queue:
+				batch = append(batch, data)
+			default:
+				return
+			}
+		}
+	}
+	for {
+		select {
+		case data := <-t.queue:
+			batch = append(batch, data)
+			if len(batch) >= t.batchSize {
+				export()
+			}
+		case <-ticker.C:
+			export()
+		case ack := <-t.flush:
+			drain()
+			export()
+			close(ack)
+		case <-t.done:
+			return
+		}
+	}
+}
+
+var (
+	defaultMu     sync.RWMutex
+	defaultTracer *Tracer
+)
+
+// SetDefault installs the tracer used by the package-level Start.
+func SetDefault(t *Tracer) {
+	defaultMu.Lock()
+	defaultTracer = t
+	defaultMu.Unlock()
+}
+
+func Default() *Tracer {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return defaultTracer
+}
+
+func Start(ctx context.Context, name string) (context.Context, *Span) {
+	return Default().Start(ctx, name, KindInternal)
+}
+
+// StartQuery starts the span of a repository method running a SQL
+// statement such as SELECT. Defer EndQuery with the method's named error.
+func StartQuery(ctx context.Context, name, statement string) (context.Context, *Span) {
+	ctx, span := Start(ctx, name)
+	span.SetAttributes(
+		String("db.system", "sqlite"),
+		String("db.operation", statement),
+	)
+	return ctx, span
+}
+
+// EndQuery records the error *err, if any, and ends the span.
+func EndQuery(span *Span, err *error) {
+	span.RecordError(*err)
+	span.End()
+}
+
+type spanKey struct{}
+
+type remoteKey struct{}
+
+func contextWithSpan(ctx context.Context, span *Span) context.Context {
+	return context.WithValue(ctx, spanKey{}, span)
+}
+
+func SpanFromContext(ctx context.Context) *Span {
+	span, _ := ctx.Value(spanKey{}).(*Span)
+	return span
+}
+
+// SpanContextFromContext returns the active local span's identifiers or, when
+// there is none, the remote parent extracted from incoming headers.
+func SpanContextFromContext(ctx context.Context) SpanContext {
+	if span := SpanFromContext(ctx); span != nil {
+		return span.SpanContext()
+	}
+	sc, _ := ctx.Value(remoteKey{}).(SpanContext)
+	return sc
+}
+
+func ContextWithRemoteSpanContext(ctx context.Context, sc SpanContext) context.Context {
+	return context.WithValue(ctx, remoteKey{}, sc)
+}
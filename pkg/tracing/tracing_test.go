@@ -0,0 +1,206 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestParseTraceparent(t *testing.T) {
+	const header = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	sc, err := ParseTraceparent(header)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sc.TraceID.String() != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("unexpected trace id %s", sc.TraceID)
+	}
+	if !sc.Sampled {
+		t.Error("expected sampled flag")
+	}
+	if sc.Traceparent() != header {
+		t.Errorf("expected %s, got %s", header, sc.Traceparent())
+	}
+}
+
+func TestParseTraceparent_Invalid(t *testing.T) {
+	invalid := []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-zzf067aa0ba902b7-01",
+	}
+	for _, h := range invalid {
+		if _, err := ParseTraceparent(h); err == nil {
+			t.Errorf("expected error for %q", h)
+		}
+	}
+}
+
+func TestTracer_ChildSpansShareTrace(t *testing.T) {
+	var buf bytes.Buffer
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	tracer := New(log, "test", NewWriterExporter(&buf))
+
+	h := http.Header{}
+	h.Set(TraceparentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	ctx := Extract(context.Background(), h)
+	ctx, parent := tracer.Start(ctx, "parent", KindServer)
+	_, child := tracer.Start(ctx, "child", KindInternal)
+	child.SetAttributes(String("db.operation", "SELECT"), Int("db.rows_returned", 3))
+	child.End()
+	parent.End()
+	if err := tracer.Shutdown(context.Background()); err != nil {
+		t.Fatalf("shutdown: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 exported spans, got %d", len(lines))
+	}
+	var spans []writerSpan
+	for _, line := range lines {
+		var s writerSpan
+		if err := json.Unmarshal([]byte(line), &s); err != nil {
+			t.Fatalf("failed to decode span: %v", err)
+		}
+		spans = append(spans, s)
+	}
+	if spans[0].Name != "child" || spans[1].Name != "parent" {
+		t.Fatalf("unexpected span order: %s, %s", spans[0].Name, spans[1].Name)
+	}
+	for _, s := range spans {
+		if s.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" {
+			t.Errorf("span %s: expected remote trace id, got %s", s.Name, s.TraceID)
+		}
+	}
+	if spans[1].ParentSpanID != "00f067aa0ba902b7" {
+		t.Errorf("expected remote parent span id, got %s", spans[1].ParentSpanID)
+	}
+	if spans[0].ParentSpanID != spans[1].SpanID {
+		t.Errorf("expected child parent %s, got %s", spans[1].SpanID, spans[0].ParentSpanID)
+	}
+	if spans[0].Attributes["db.rows_returned"] != float64(3) {
+		t.Errorf("expected rows attribute, got %v", spans[0].Attributes["db.rows_returned"])
+	}
+}
+
+func TestOTLPExporter_Export(t *testing.T) {
+	var got otlpRequest
+	var path string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		body, _ := io.ReadAll(r.Body)
+		json.Unmarshal(body, &got)
+	}))
+	defer srv.Close()
+
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	tracer := New(log, "quotes", NewOTLPExporter(srv.URL, "quotes", nil))
+	_, span := tracer.Start(context.Background(), "op", KindInternal)
+	span.End()
+	if err := tracer.Shutdown(context.Background()); err != nil {
+		t.Fatalf("shutdown: %v", err)
+	}
+
+	if path != "/v1/traces" {
+		t.Errorf("expected /v1/traces, got %s", path)
+	}
+	if len(got.ResourceSpans) != 1 || len(got.ResourceSpans[0].ScopeSpans[0].Spans) != 1 {
+		t.Fatalf("unexpected payload: %+v", got)
+	}
+	if got.ResourceSpans[0].ScopeSpans[0].Spans[0].Name != "op" {
+		t.Errorf("unexpected span name %s", got.ResourceSpans[0].ScopeSpans[0].Spans[0].Name)
+	}
+}
+
+func TestStart_WithoutTracer(t *testing.T) {
+	ctx, span := Start(context.Background(), "noop")
+	span.SetAttributes(String("k", "v"))
+	span.End()
+	if SpanFromContext(ctx) != nil {
+		t.Error("expected no span in context")
+	}
+}
+
+func TestStartQuery(t *testing.T) {
+	var buf bytes.Buffer
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	tracer := New(log, "test", NewWriterExporter(&buf))
+	SetDefault(tracer)
+	defer SetDefault(nil)
+
+	query := func() (err error) {
+		_, span := StartQuery(context.Background(), "repository.Delete", "DELETE")
+		defer EndQuery(span, &err)
+		return errors.New("no such row")
+	}
+	query()
+	if err := tracer.Shutdown(context.Background()); err != nil {
+		t.Fatalf("shutdown: %v", err)
+	}
+
+	var s writerSpan
+	if err := json.Unmarshal(buf.Bytes(), &s); err != nil {
+		t.Fatalf("failed to decode span: %v", err)
+	}
+	if s.Attributes["db.system"] != "sqlite" || s.Attributes["db.operation"] != "DELETE" {
+		t.Errorf("unexpected attributes %v", s.Attributes)
+	}
+	if s.Status != StatusError || s.Message != "no such row" {
+		t.Errorf("expected the returned error to be recorded, got %v %q", s.Status, s.Message)
+	}
+}
+
+func TestTracer_RespectsSampledFlag(t *testing.T) {
+	var buf bytes.Buffer
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	tracer := New(log, "test", NewWriterExporter(&buf))
+
+	h := http.Header{}
+	h.Set(TraceparentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+	ctx, parent := tracer.Start(Extract(context.Background(), h), "parent", KindServer)
+	_, child := tracer.Start(ctx, "child", KindInternal)
+	if child.SpanContext().Sampled {
+		t.Error("expected the child of an unsampled parent not to be sampled")
+	}
+	out := http.Header{}
+	Inject(ctx, out)
+	if !strings.HasSuffix(out.Get(TraceparentHeader), "-00") {
+		t.Errorf("expected the unsampled flag to be propagated, got %s", out.Get(TraceparentHeader))
+	}
+	child.End()
+	parent.End()
+	if err := tracer.Shutdown(context.Background()); err != nil {
+		t.Fatalf("shutdown: %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("expected no exported spans, got %s", buf.String())
+	}
+}
+
+func TestTracer_FlushAfterShutdown(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	tracer := New(log, "test", NewWriterExporter(io.Discard))
+	if err := tracer.Shutdown(context.Background()); err != nil {
+		t.Fatalf("shutdown: %v", err)
+	}
+
+	_, span := tracer.Start(context.Background(), "late", KindInternal)
+	span.End()
+	if err := tracer.Flush(context.Background()); !errors.Is(err, ErrShutdown) {
+		t.Errorf("expected ErrShutdown, got %v", err)
+	}
+	if err := tracer.Shutdown(context.Background()); err != nil {
+		t.Errorf("expected a second shutdown to do nothing, got %v", err)
+	}
+}
@@ -0,0 +1,414 @@
+// Package websocket implements the subset of RFC 6455 the service needs:
+// the server handshake, a client dialer for tests and tools, text and
+// binary messages, ping/pong and the closing handshake. Extensions and
+// subprotocols are not supported.
+package websocket
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	TextMessage   = 1
+	BinaryMessage = 2
+	CloseMessage  = 8
+	PingMessage   = 9
+	PongMessage   = 10
+
+	continuationFrame = 0
+)
+
+const (
+	CloseNormal          = 1000
+	CloseGoingAway       = 1001
+	CloseProtocolError   = 1002
+	CloseUnsupportedData = 1003
+	CloseNoStatus        = 1005
+	CloseInvalidPayload  = 1007
+	ClosePolicyViolation = 1008
+	CloseMessageTooBig   = 1009
+	CloseInternalError   = 1011
+)
+
+const (
+	acceptGUID       = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+	maxControlLength = 125
+	closeWriteWait   = time.Second
+	// DefaultReadLimit caps the size of an incoming message unless
+	// SetReadLimit says otherwise.
+	DefaultReadLimit = 64 << 10
+)
+
+var (
+	ErrBadHandshake = errors.New("websocket: bad handshake")
+	ErrClosed       = errors.New("websocket: connection closed")
+)
+
+// CloseError is returned by ReadMessage once the peer has sent a close
+// frame. The close frame has already been answered.
+type CloseError struct {
+	Code   int
+	Reason string
+}
+
+func (e *CloseError) Error() string {
+	return fmt.Sprintf("websocket: closed with code %d %s", e.Code, e.Reason)
+}
+
+// IsClose reports whether err is a CloseError or ErrClosed, i.e. the
+// connection ended through the closing handshake rather than a failure.
+func IsClose(err error) bool {
+	var ce *CloseError
+	return errors.As(err, &ce) || errors.Is(err, ErrClosed)
+}
+
+type Conn struct {
+	conn     net.Conn
+	br       *bufio.Reader
+	isServer bool
+
+	readLimit   int64
+	idleTimeout time.Duration
+
+	wmu     sync.Mutex
+	closing bool
+}
+
+// Upgrade performs the server side of the opening handshake and takes over
+// the connection. Nothing is written to w on failure, so the caller can
+// still send a normal error response.
+func Upgrade(w http.ResponseWriter, r *http.Request) (*Conn, error) {
+	const op = "websocket.Upgrade"
+	switch {
+	case r.Method != http.MethodGet:
+		return nil, fmt.Errorf("%s: method %s: %w", op, r.Method, ErrBadHandshake)
+	case !headerHasToken(r.Header, "Connection", "upgrade"):
+		return nil, fmt.Errorf("%s: missing Connection: upgrade: %w", op, ErrBadHandshake)
+	case !headerHasToken(r.Header, "Upgrade", "websocket"):
+		return nil, fmt.Errorf("%s: missing Upgrade: websocket: %w", op, ErrBadHandshake)
+	case r.Header.Get("Sec-WebSocket-Version") != "13":
+		return nil, fmt.Errorf("%s: unsupported version %q: %w", op, r.Header.Get("Sec-WebSocket-Version"), ErrBadHandshake)
+	}
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if decoded, err := base64.StdEncoding.DecodeString(key); err != nil || len(decoded) != 16 {
+		return nil, fmt.Errorf("%s: invalid Sec-WebSocket-Key: %w", op, ErrBadHandshake)
+	}
+	conn, brw, err := http.NewResponseController(w).Hijack()
+	if err != nil {
+		return nil, fmt.Errorf("%s: hijack: %w", op, err)
+	}
+	// The server's read and write timeouts stay on a hijacked connection.
+	conn.SetDeadline(time.Time{})
+	brw.WriteString("HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + acceptKey(key) + "\r\n\r\n")
+	if err = brw.Flush(); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("%s: write handshake: %w", op, err)
+	}
+	return newConn(conn, brw.Reader, true), nil
+}
+
+// Dial opens a client connection to a ws:// URL.
+func Dial(url string, header http.Header, timeout time.Duration) (*Conn, error) {
+	const op = "websocket.Dial"
+	req, err := http.NewRequest(http.MethodGet, strings.Replace(url, "ws://", "http://", 1), nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	nonce := make([]byte, 16)
+	rand.Read(nonce)
+	key := base64.StdEncoding.EncodeToString(nonce)
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Key", key)
+
+	conn, err := net.DialTimeout("tcp", req.URL.Host, timeout)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	conn.SetDeadline(time.Now().Add(timeout))
+	if err = req.Write(conn); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("%s: write request: %w", op, err)
+	}
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("%s: read response: %w", op, err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols || resp.Header.Get("Sec-WebSocket-Accept") != acceptKey(key) {
+		resp.Body.Close()
+		conn.Close()
+		return nil, fmt.Errorf("%s: status %d: %w", op, resp.StatusCode, ErrBadHandshake)
+	}
+	conn.SetDeadline(time.Time{})
+	return newConn(conn, br, false), nil
+}
+
+func newConn(conn net.Conn, br *bufio.Reader, isServer bool) *Conn {
+	return &Conn{
+		conn:      conn,
+		br:        br,
+		isServer:  isServer,
+		readLimit: DefaultReadLimit,
+	}
+}
+
+// SetReadLimit caps the size of a single message; larger messages close the
+// connection with CloseMessageTooBig.
+func (c *Conn) SetReadLimit(n int64) {
+	c.readLimit = n
+}
+
+// SetIdleTimeout fails ReadMessage when the peer sends no frame at all,
+// pongs included, for d.
+func (c *Conn) SetIdleTimeout(d time.Duration) {
+	c.idleTimeout = d
+}
+
+func (c *Conn) RemoteAddr() net.Addr {
+	return c.conn.RemoteAddr()
+}
+
+// ReadMessage returns the next text or binary message. Pings are answered
+// and pongs are swallowed; a close frame is echoed and reported as a
+// CloseError. ReadMessage must not be called concurrently.
+func (c *Conn) ReadMessage() (int, []byte, error) {
+	var (
+		msgType int
+		msg     []byte
+	)
+	for {
+		if c.idleTimeout > 0 {
+			c.conn.SetReadDeadline(time.Now().Add(c.idleTimeout))
+		}
+		fin, op, payload, err := c.readFrame()
+		if err != nil {
+			return 0, nil, err
+		}
+		switch op {
+		case PingMessage:
+			if err = c.WriteMessage(PongMessage, payload); err != nil {
+				return 0, nil, err
+			}
+			continue
+		case PongMessage:
+			continue
+		case CloseMessage:
+			return 0, nil, c.handleClose(payload)
+		case TextMessage, BinaryMessage:
+			if msgType != 0 {
+				return 0, nil, c.fail(CloseProtocolError, "expected continuation frame")
+			}
+			msgType = op
+		case continuationFrame:
+			if msgType == 0 {
+				return 0, nil, c.fail(CloseProtocolError, "unexpected continuation frame")
+			}
+		default:
+			return 0, nil, c.fail(CloseProtocolError, "unknown opcode")
+		}
+		if int64(len(msg)+len(payload)) > c.readLimit {
+			return 0, nil, c.fail(CloseMessageTooBig, "message too big")
+		}
+		msg = append(msg, payload...)
+		if !fin {
+			continue
+		}
+		if msgType == TextMessage && !utf8.Valid(msg) {
+			return 0, nil, c.fail(CloseInvalidPayload, "invalid utf-8")
+		}
+		return msgType, msg, nil
+	}
+}
+
+func (c *Conn) readFrame() (fin bool, op int, payload []byte, err error) {
+	var head [2]byte
+	if _, err = io.ReadFull(c.br, head[:]); err != nil {
+		return false, 0, nil, err
+	}
+	fin = head[0]&0x80 != 0
+	op = int(head[0] & 0x0f)
+	masked := head[1]&0x80 != 0
+	length := int64(head[1] & 0x7f)
+	if head[0]&0x70 != 0 {
+		return false, 0, nil, c.fail(CloseProtocolError, "reserved bits set")
+	}
+	if masked != c.isServer {
+		return false, 0, nil, c.fail(CloseProtocolError, "bad masking")
+	}
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err = io.ReadFull(c.br, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+		length = int64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err = io.ReadFull(c.br, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+		length = int64(binary.BigEndian.Uint64(ext[:]))
+	}
+	if op >= CloseMessage && (length > maxControlLength || !fin) {
+		return false, 0, nil, c.fail(CloseProtocolError, "invalid control frame")
+	}
+	if length < 0 || length > c.readLimit {
+		return false, 0, nil, c.fail(CloseMessageTooBig, "message too big")
+	}
+	var mask [4]byte
+	if masked {
+		if _, err = io.ReadFull(c.br, mask[:]); err != nil {
+			return false, 0, nil, err
+		}
+	}
+	payload = make([]byte, length)
+	if _, err = io.ReadFull(c.br, payload); err != nil {
+		return false, 0, nil, err
+	}
+	if masked {
+		maskBytes(mask, payload)
+	}
+	return fin, op, payload, nil
+}
+
+func (c *Conn) handleClose(payload []byte) error {
+	code, reason := CloseNoStatus, ""
+	switch {
+	case len(payload) == 1:
+		return c.fail(CloseProtocolError, "invalid close payload")
+	case len(payload) >= 2:
+		code = int(binary.BigEndian.Uint16(payload))
+		reason = string(payload[2:])
+		if !utf8.ValidString(reason) {
+			return c.fail(CloseProtocolError, "invalid close reason")
+		}
+	}
+	echo := code
+	if echo == CloseNoStatus {
+		echo = CloseNormal
+	}
+	c.WriteClose(echo, "")
+	c.conn.Close()
+	return &CloseError{Code: code, Reason: reason}
+}
+
+// fail closes the connection after a protocol violation by the peer.
+func (c *Conn) fail(code int, reason string) error {
+	c.WriteClose(code, reason)
+	c.conn.Close()
+	return &CloseError{Code: code, Reason: reason}
+}
+
+// WriteMessage sends a single unfragmented frame. It is safe to call from
+// several goroutines.
+func (c *Conn) WriteMessage(op int, data []byte) error {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+	if c.closing {
+		return ErrClosed
+	}
+	return c.writeFrame(op, data)
+}
+
+// WriteClose starts the closing handshake. Later writes fail with
+// ErrClosed.
+func (c *Conn) WriteClose(code int, reason string) error {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+	if c.closing {
+		return ErrClosed
+	}
+	c.closing = true
+	if len(reason) > maxControlLength-2 {
+		reason = reason[:maxControlLength-2]
+	}
+	payload := binary.BigEndian.AppendUint16(nil, uint16(code))
+	payload = append(payload, reason...)
+	c.conn.SetWriteDeadline(time.Now().Add(closeWriteWait))
+	return c.writeFrame(CloseMessage, payload)
+}
+
+func (c *Conn) writeFrame(op int, data []byte) error {
+	frame := []byte{0x80 | byte(op)}
+	var maskBit byte
+	if !c.isServer {
+		maskBit = 0x80
+	}
+	switch n := len(data); {
+	case n <= 125:
+		frame = append(frame, maskBit|byte(n))
+	case n <= 0xffff:
+		frame = append(frame, maskBit|126)
+		frame = binary.BigEndian.AppendUint16(frame, uint16(n))
+	default:
+		frame = append(frame, maskBit|127)
+		frame = binary.BigEndian.AppendUint64(frame, uint64(n))
+	}
+	if c.isServer {
+		frame = append(frame, data...)
+	} else {
+		var mask [4]byte
+		rand.Read(mask[:])
+		frame = append(frame, mask[:]...)
+		start := len(frame)
+		frame = append(frame, data...)
+		maskBytes(mask, frame[start:])
+	}
+	_, err := c.conn.Write(frame)
+	return err
+}
+
+// SetWriteDeadline bounds the next writes, so that a stalled peer cannot
+// block the writer forever.
+func (c *Conn) SetWriteDeadline(t time.Time) error {
+	return c.conn.SetWriteDeadline(t)
+}
+
+// Close closes the underlying connection without a closing handshake.
+func (c *Conn) Close() error {
+	return c.conn.Close()
+}
+
+func maskBytes(mask [4]byte, b []byte) {
+	for i := range b {
+		b[i] ^= mask[i%4]
+	}
+}
+
+func acceptKey(key string) string {
+	h := sha1.Sum([]byte(key + acceptGUID))
+	return base64.StdEncoding.EncodeToString(h[:])
+}
+
+func headerHasToken(h http.Header, name, token string) bool {
+	for _, v := range h.Values(name) {
+		for _, t := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(t), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,171 @@
+package websocket
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// echoServer answers every message with the same message.
+func echoServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := Upgrade(w, r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		defer conn.Close()
+		conn.SetReadLimit(1024)
+		for {
+			op, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			if err = conn.WriteMessage(op, data); err != nil {
+				return
+			}
+		}
+	}))
+}
+
+func wsURL(srv *httptest.Server) string {
+	return "ws" + strings.TrimPrefix(srv.URL, "http")
+}
+
+func TestAcceptKey(t *testing.T) {
+	// Example from RFC 6455, section 1.3.
+	if got := acceptKey("dGhlIHNhbXBsZSBub25jZQ=="); got != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Errorf("unexpected accept key %q", got)
+	}
+}
+
+func TestEcho(t *testing.T) {
+	srv := echoServer(t)
+	defer srv.Close()
+	conn, err := Dial(wsURL(srv), nil, time.Second)
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	defer conn.Close()
+
+	for _, msg := range []string{"hello", strings.Repeat("a", 200), ""} {
+		if err = conn.WriteMessage(TextMessage, []byte(msg)); err != nil {
+			t.Fatalf("failed to write: %v", err)
+		}
+		op, data, err := conn.ReadMessage()
+		if err != nil || op != TextMessage || string(data) != msg {
+			t.Errorf("expected echo of %d bytes, got op %d, %d bytes (%v)", len(msg), op, len(data), err)
+		}
+	}
+
+	// Pings are answered by the server and the pong is swallowed by the
+	// client, so the next read returns the following message.
+	conn.WriteMessage(PingMessage, []byte("ping"))
+	conn.WriteMessage(BinaryMessage, []byte{1, 2, 3})
+	if op, data, err := conn.ReadMessage(); err != nil || op != BinaryMessage || !bytes.Equal(data, []byte{1, 2, 3}) {
+		t.Errorf("unexpected message after ping: %d %v %v", op, data, err)
+	}
+
+	conn.WriteClose(CloseNormal, "bye")
+	_, _, err = conn.ReadMessage()
+	var ce *CloseError
+	if !errors.As(err, &ce) || ce.Code != CloseNormal {
+		t.Errorf("expected close echo with code %d, got %v", CloseNormal, err)
+	}
+}
+
+func TestReadLimit(t *testing.T) {
+	srv := echoServer(t)
+	defer srv.Close()
+	conn, err := Dial(wsURL(srv), nil, time.Second)
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	defer conn.Close()
+
+	conn.WriteMessage(TextMessage, make([]byte, 2048))
+	_, _, err = conn.ReadMessage()
+	var ce *CloseError
+	if !errors.As(err, &ce) || ce.Code != CloseMessageTooBig {
+		t.Errorf("expected close code %d, got %v", CloseMessageTooBig, err)
+	}
+}
+
+func TestFragmentedMessage(t *testing.T) {
+	server, client := net.Pipe()
+	defer client.Close()
+	c := newConn(server, bufio.NewReader(server), true)
+	mask := []byte{1, 2, 3, 4}
+	frame := func(head byte, payload string) []byte {
+		b := []byte{head, 0x80 | byte(len(payload))}
+		b = append(b, mask...)
+		for i := range len(payload) {
+			b = append(b, payload[i]^mask[i%4])
+		}
+		return b
+	}
+	go func() {
+		client.Write(frame(TextMessage, "Hel"))
+		client.Write(frame(0x80|PingMessage, ""))
+		client.Write(frame(0x80|continuationFrame, "lo"))
+	}()
+	go func() {
+		// Drain the pong.
+		buf := make([]byte, 16)
+		client.Read(buf)
+	}()
+	op, data, err := c.ReadMessage()
+	if err != nil || op != TextMessage || string(data) != "Hello" {
+		t.Errorf("expected reassembled message, got %d %q %v", op, data, err)
+	}
+}
+
+func TestUnmaskedClientFrame(t *testing.T) {
+	server, client := net.Pipe()
+	defer client.Close()
+	c := newConn(server, bufio.NewReader(server), true)
+	go func() {
+		client.Write([]byte{0x80 | TextMessage, 2, 'h', 'i'})
+		buf := make([]byte, 64)
+		client.Read(buf)
+	}()
+	_, _, err := c.ReadMessage()
+	var ce *CloseError
+	if !errors.As(err, &ce) || ce.Code != CloseProtocolError {
+		t.Errorf("expected protocol error, got %v", err)
+	}
+}
+
+func TestUpgrade_BadHandshake(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		header map[string]string
+	}{
+		{name: "plain request", method: http.MethodGet},
+		{name: "post", method: http.MethodPost, header: map[string]string{"Connection": "Upgrade", "Upgrade": "websocket", "Sec-WebSocket-Version": "13", "Sec-WebSocket-Key": "dGhlIHNhbXBsZSBub25jZQ=="}},
+		{name: "old version", method: http.MethodGet, header: map[string]string{"Connection": "keep-alive, Upgrade", "Upgrade": "websocket", "Sec-WebSocket-Version": "8", "Sec-WebSocket-Key": "dGhlIHNhbXBsZSBub25jZQ=="}},
+		{name: "bad key", method: http.MethodGet, header: map[string]string{"Connection": "Upgrade", "Upgrade": "websocket", "Sec-WebSocket-Version": "13", "Sec-WebSocket-Key": "short"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, "/", nil)
+			for k, v := range tt.header {
+				r.Header.Set(k, v)
+			}
+			w := httptest.NewRecorder()
+			if _, err := Upgrade(w, r); !errors.Is(err, ErrBadHandshake) {
+				t.Errorf("expected ErrBadHandshake, got %v", err)
+			}
+			if w.Code != http.StatusOK || w.Body.Len() != 0 {
+				t.Error("expected nothing to be written on failure")
+			}
+		})
+	}
+}